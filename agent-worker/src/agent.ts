@@ -188,18 +188,64 @@ export const toolDefinitions: ChatCompletionTool[] = [
       },
     },
   },
+  {
+    type: 'function',
+    function: {
+      name: 'finance_forecast',
+      description:
+        'Project the daily cash balance for the next months from recurring income/expenses, unpaid invoices and optional one-off items. Returns the lowest-balance day and any negative days.',
+      parameters: {
+        type: 'object',
+        properties: {
+          months: { type: 'number' },
+          startBalanceCents: { type: 'number' },
+          oneOffs: {
+            type: 'array',
+            items: {
+              type: 'object',
+              properties: {
+                date: { type: 'string', description: 'RFC3339, e.g. 2026-05-01T00:00:00Z.' },
+                amountCents: { type: 'number', description: 'Positive for inflows, negative for outflows.' },
+                description: { type: 'string' },
+              },
+              required: ['date', 'amountCents'],
+            },
+          },
+        },
+      },
+    },
+  },
   {
     type: 'function',
     function: {
       name: 'list_transactions',
-      description: 'List transactions with optional filters.',
+      description:
+        'Search transactions. Without year, from or to it lists the current month (all dates when q is set). Returns items, total and nextCursor; pass nextCursor back as cursor for the next page.',
       parameters: {
         type: 'object',
         properties: {
+          q: { type: 'string', description: 'Text to find in description or notes.' },
           year: { type: 'number' },
           month: { type: 'number' },
-          type: { type: 'string' },
+          from: { type: 'string', description: 'YYYY-MM-DD, inclusive.' },
+          to: { type: 'string', description: 'YYYY-MM-DD, inclusive.' },
+          type: { type: 'string', description: 'income, expense or transfer.' },
+          minAmountCents: { type: 'number' },
+          maxAmountCents: { type: 'number' },
+          currency: { type: 'string' },
           contactId: { type: 'string' },
+          projectId: { type: 'string' },
+          accountId: { type: 'string' },
+          invoiceId: { type: 'string' },
+          incomeSourceId: { type: 'string' },
+          expenseId: { type: 'string' },
+          goalId: { type: 'string', description: 'Savings goal the transactions contribute to.' },
+          sort: {
+            type: 'string',
+            enum: ['date_desc', 'date_asc', 'amount_desc', 'amount_asc', 'created_desc', 'created_asc'],
+          },
+          cursor: { type: 'string' },
+          limit: { type: 'number' },
         },
       },
     },
@@ -217,11 +263,39 @@ export const toolDefinitions: ChatCompletionTool[] = [
           description: { type: 'string' },
           contactId: { type: 'string' },
           date: { type: 'string' },
+          goalId: { type: 'string', description: 'Savings goal this transaction contributes to.' },
         },
         required: ['type', 'amountCents', 'description'],
       },
     },
   },
+  {
+    type: 'function',
+    function: {
+      name: 'list_savings_goals',
+      description:
+        'List savings goals with saved amount, remaining amount, percent, required monthly contribution to hit the deadline and status (achieved, on_track, off_track, overdue or open). Use q to find a goal by name, e.g. "notebook".',
+      parameters: {
+        type: 'object',
+        properties: {
+          q: { type: 'string', description: 'Text to find in the goal name.' },
+          archived: { type: 'boolean', description: 'Include archived goals.' },
+        },
+      },
+    },
+  },
+  {
+    type: 'function',
+    function: {
+      name: 'get_savings_goal',
+      description: 'Progress of one savings goal by id.',
+      parameters: {
+        type: 'object',
+        properties: { id: { type: 'string' } },
+        required: ['id'],
+      },
+    },
+  },
   {
     type: 'function',
     function: {
@@ -365,10 +439,22 @@ export async function runTool(
       return api.financeDashboard()
     case 'finance_summary':
       return api.financeSummary(num(args.year), num(args.month))
+    case 'finance_forecast':
+      return api.financeForecast(args)
     case 'list_transactions':
-      return api.listTransactions(stringParams(args, ['type', 'contactId'], numMap(args, ['year', 'month'])))
+      return api.listTransactions(
+        stringParams(
+          args,
+          ['q', 'from', 'to', 'type', 'currency', 'contactId', 'projectId', 'accountId', 'invoiceId', 'incomeSourceId', 'expenseId', 'goalId', 'sort', 'cursor'],
+          numMap(args, ['year', 'month', 'minAmountCents', 'maxAmountCents', 'limit']),
+        ),
+      )
     case 'create_transaction':
       return api.createTransaction(args)
+    case 'list_savings_goals':
+      return api.listSavingsGoals(stringParams(args, ['q', 'archived']))
+    case 'get_savings_goal':
+      return api.getSavingsGoal(String(args.id))
     case 'list_social_posts':
       return api.listSocialPosts(stringParams(args, ['projectId', 'platform', 'status', 'goal']))
     case 'list_post_templates':
@@ -145,6 +145,13 @@ export class ManagementClient {
     return request<unknown>(this.cfg, `/v1/internal/agent/tools/finance/calendar${q ? `?${q}` : ''}`)
   }
 
+  financeForecast(body: Record<string, unknown>) {
+    return request<unknown>(this.cfg, '/v1/internal/agent/tools/finance/forecast', {
+      method: 'POST',
+      body: JSON.stringify(body),
+    })
+  }
+
   listIncomeSources(params: Record<string, string> = {}) {
     const q = new URLSearchParams(params).toString()
     const suffix = q ? `?${q}` : ''
@@ -154,7 +161,10 @@ export class ManagementClient {
   listTransactions(params: Record<string, string> = {}) {
     const q = new URLSearchParams(params).toString()
     const suffix = q ? `?${q}` : ''
-    return request<unknown[]>(this.cfg, `/v1/internal/agent/tools/finance/transactions${suffix}`)
+    return request<{ items: unknown[]; total: number; nextCursor?: string }>(
+      this.cfg,
+      `/v1/internal/agent/tools/finance/transactions${suffix}`,
+    )
   }
 
   createTransaction(body: Record<string, unknown>) {
@@ -164,6 +174,16 @@ export class ManagementClient {
     })
   }
 
+  listSavingsGoals(params: Record<string, string> = {}) {
+    const q = new URLSearchParams(params).toString()
+    const suffix = q ? `?${q}` : ''
+    return request<unknown[]>(this.cfg, `/v1/internal/agent/tools/finance/goals${suffix}`)
+  }
+
+  getSavingsGoal(id: string) {
+    return request<unknown>(this.cfg, `/v1/internal/agent/tools/finance/goals/${id}`)
+  }
+
   createIncomeSource(body: Record<string, unknown>) {
     return request<unknown>(this.cfg, '/v1/internal/agent/tools/finance/income-sources', {
       method: 'POST',
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Category strings on finance rows are matched against finance_categories slugs, which are lower-case.
+-- Tables AutoMigrate has not created yet have nothing to normalize.
+DO $$
+BEGIN
+	IF to_regclass('expenses') IS NOT NULL THEN
+		UPDATE expenses SET category = LOWER(TRIM(category)) WHERE category <> LOWER(TRIM(category));
+	END IF;
+	IF to_regclass('invoice_items') IS NOT NULL THEN
+		UPDATE invoice_items SET category = LOWER(TRIM(category)) WHERE category <> LOWER(TRIM(category));
+	END IF;
+	IF to_regclass('budget_plans') IS NOT NULL THEN
+		UPDATE budget_plans SET category = LOWER(TRIM(category)) WHERE category <> LOWER(TRIM(category));
+	END IF;
+	IF to_regclass('installment_purchases') IS NOT NULL THEN
+		UPDATE installment_purchases SET category = LOWER(TRIM(category)) WHERE category <> LOWER(TRIM(category));
+	END IF;
+	IF to_regclass('income_sources') IS NOT NULL THEN
+		UPDATE income_sources SET type = LOWER(TRIM(type)) WHERE type <> LOWER(TRIM(type));
+	END IF;
+END $$;
+
+-- +goose Down
+-- Lower-casing is not reversible.
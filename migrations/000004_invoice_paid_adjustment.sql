@@ -0,0 +1,20 @@
+-- +goose Up
+-- Invoice paid amounts are now the sum of linked payment transactions. What was marked paid by hand
+-- beyond those payments is kept as an adjustment so it is not lost on the next recomputation.
+-- A fresh database gets the column from AutoMigrate instead.
+DO $$
+BEGIN
+	IF to_regclass('invoices') IS NOT NULL THEN
+		ALTER TABLE invoices ADD COLUMN IF NOT EXISTS paid_adjustment_cents bigint NOT NULL DEFAULT 0;
+		IF to_regclass('transactions') IS NOT NULL THEN
+			UPDATE invoices i SET paid_adjustment_cents = GREATEST(i.paid_cents - COALESCE((
+				SELECT SUM(t.amount_cents) FROM transactions t WHERE t.invoice_id = i.id AND t.type = 'expense'
+			), 0), 0);
+		ELSE
+			UPDATE invoices SET paid_adjustment_cents = GREATEST(paid_cents, 0);
+		END IF;
+	END IF;
+END $$;
+
+-- +goose Down
+-- The adjustment column is left in place; dropping it would lose the amounts paid by hand.
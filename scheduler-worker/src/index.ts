@@ -1,6 +1,14 @@
 import http from 'node:http'
 import { loadConfig } from './config.js'
-import { executeJob, fetchDueJobs, fetchDuePresenceReminders, sendPresenceReminder } from './management-client.js'
+import {
+  executeJob,
+  fetchDueJobs,
+  fetchDuePresenceReminders,
+  markOverdueInvoices,
+  runFinancePostings,
+  sendPresenceReminder,
+  snapshotNetWorth,
+} from './management-client.js'
 import pino from 'pino'
 
 const log = pino({ name: 'scheduler-worker' })
@@ -35,6 +43,33 @@ async function tick(cfg: ReturnType<typeof loadConfig>): Promise<void> {
       }
     }
   }
+
+  try {
+    const postings = await runFinancePostings(cfg)
+    if (postings.created > 0) {
+      log.info(postings, 'finance expected transactions posted')
+    }
+  } catch (err) {
+    log.error({ err }, 'finance postings failed')
+  }
+
+  try {
+    const overdue = await markOverdueInvoices(cfg)
+    if (overdue.marked > 0) {
+      log.info({ marked: overdue.marked, invoices: overdue.invoices.map((i) => i.id) }, 'finance invoices marked overdue')
+    }
+  } catch (err) {
+    log.error({ err }, 'finance overdue sweep failed')
+  }
+
+  try {
+    const snapshot = await snapshotNetWorth(cfg)
+    if (snapshot.created > 0) {
+      log.info({ created: snapshot.created, date: snapshot.date }, 'finance net worth snapshot taken')
+    }
+  } catch (err) {
+    log.error({ err }, 'finance net worth snapshot failed')
+  }
 }
 
 async function main(): Promise<void> {
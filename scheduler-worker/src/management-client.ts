@@ -40,6 +40,55 @@ export async function sendPresenceReminder(cfg: Config, postId: string): Promise
   return JSON.parse(text) as unknown
 }
 
+export type FinancePostingRun = {
+  created: number
+  from: string
+  to: string
+}
+
+export async function runFinancePostings(cfg: Config): Promise<FinancePostingRun> {
+  const res = await fetch(`${cfg.managementApiUrl}/v1/internal/finance/postings/run`, {
+    method: 'POST',
+    headers: headers(cfg),
+  })
+  const text = await res.text()
+  if (!res.ok) {
+    throw new Error(`finance postings http ${res.status}: ${text}`)
+  }
+  return JSON.parse(text) as FinancePostingRun
+}
+
+export type FinanceOverdueRun = {
+  marked: number
+  invoices: { id: string; name: string; dueDate: string; totalCents: number; paidCents: number }[]
+}
+
+export async function markOverdueInvoices(cfg: Config): Promise<FinanceOverdueRun> {
+  const res = await fetch(`${cfg.managementApiUrl}/v1/internal/finance/invoices/overdue/run`, {
+    method: 'POST',
+    headers: headers(cfg),
+  })
+  const text = await res.text()
+  if (!res.ok) {
+    throw new Error(`finance overdue http ${res.status}: ${text}`)
+  }
+  return JSON.parse(text) as FinanceOverdueRun
+}
+
+export type NetWorthSnapshotRun = { date: string; created: number }
+
+export async function snapshotNetWorth(cfg: Config): Promise<NetWorthSnapshotRun> {
+  const res = await fetch(`${cfg.managementApiUrl}/v1/internal/finance/net-worth/snapshots/run`, {
+    method: 'POST',
+    headers: headers(cfg),
+  })
+  const text = await res.text()
+  if (!res.ok) {
+    throw new Error(`finance net worth http ${res.status}: ${text}`)
+  }
+  return JSON.parse(text) as NetWorthSnapshotRun
+}
+
 function headers(cfg: Config): Record<string, string> {
   const h: Record<string, string> = { 'Content-Type': 'application/json' }
   if (cfg.workerApiKey) {
@@ -98,6 +98,23 @@ func main() {
 		&models.Invoice{},
 		&models.InvoiceItem{},
 		&models.BudgetPlan{},
+		&models.ExchangeRate{},
+		&models.FinanceSettings{},
+		&models.ExpectedTransaction{},
+		&models.TransactionImport{},
+		&models.InstallmentPurchase{},
+		&models.Account{},
+		&models.FinanceCategory{},
+		&models.CategorizationRule{},
+		&models.FinanceAttachment{},
+		&models.Receivable{},
+		&models.ReceivableItem{},
+		&models.TaxTable{},
+		&models.FinanceSplit{},
+		&models.FinanceSettlement{},
+		&models.NetWorthItem{},
+		&models.NetWorthSnapshot{},
+		&models.SavingsGoal{},
 		&models.MediaAsset{},
 		&models.Profile{},
 		&models.LeetcodeVideo{},
@@ -131,6 +148,15 @@ func main() {
 
 	financeRepo := financerepo.New(db)
 	financeSvc := financesvc.New(financeRepo)
+	if _, err := financeRepo.EnsureSettings(context.Background()); err != nil {
+		log.Fatalf("default finance settings: %v", err)
+	}
+	if err := financeRepo.EnsureCategories(context.Background()); err != nil {
+		log.Fatalf("finance categories: %v", err)
+	}
+	if err := financeRepo.EnsureTaxTables(context.Background()); err != nil {
+		log.Fatalf("finance tax tables: %v", err)
+	}
 
 	contactsRepo := contactsrepo.New(db)
 	contactsSvc := contactssvc.New(contactsRepo)
@@ -167,6 +193,7 @@ func main() {
 	log.Printf("media storage driver: %s", driver)
 	mediaRepo := mediarepo.New(db)
 	mediaSvc := mediasvc.New(mediaRepo, mediaStore, mediaBaseURL)
+	financeSvc.SetMediaUploader(mediaSvc)
 
 	profileRepo := profilerepo.New(db)
 	profileSvc := profilesvc.New(profileRepo)
@@ -205,8 +232,11 @@ func main() {
 	} else if err := messagingSvc.EnsureLeetcodeTemplates(context.Background(), waTemplates); err != nil {
 		log.Fatalf("leetcode messaging templates: %v", err)
 	}
+	if err := messagingSvc.EnsureFinanceTemplates(context.Background()); err != nil {
+		log.Fatalf("finance messaging templates: %v", err)
+	}
 
-	msgRenderer := msgtemplaterender.NewEngine(contentSvc, devSvc)
+	msgRenderer := msgtemplaterender.NewEngine(contentSvc, devSvc, financeSvc)
 	agentWorkerClient := agentworkerclient.New(agentworkerclient.Config{
 		BaseURL:     os.Getenv("AGENT_WORKER_URL"),
 		AgentAPIKey: strings.TrimSpace(os.Getenv("AGENT_API_KEY")),
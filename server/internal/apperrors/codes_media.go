@@ -19,6 +19,9 @@ const (
 	CodeMediaPostV1ServiceCreateFailed = "MEDIA_POST_V1_SERVICE_CREATE_FAILED"
 	MsgMediaPostV1ServiceCreateFailed  = "Failed to store media asset."
 
+	CodeMediaPatchV1ServiceUpdateFailed = "MEDIA_PATCH_V1_SERVICE_UPDATE_FAILED"
+	MsgMediaPatchV1ServiceUpdateFailed  = "Failed to update media asset."
+
 	CodeMediaDeleteV1ServiceNotFound = "MEDIA_DELETE_V1_SERVICE_NOT_FOUND"
 	MsgMediaDeleteV1ServiceNotFound  = "Media asset not found."
 )
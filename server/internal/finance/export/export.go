@@ -0,0 +1,114 @@
+// Package export writes tabular finance data as CSV, XLSX or JSON one row at a time, so large
+// exports never have to be held in memory.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+	JSON Format = "json"
+)
+
+// ParseFormat accepts csv, xlsx or json, case-insensitively; empty means csv.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.TrimSpace(strings.ToLower(s))); f {
+	case "":
+		return CSV, nil
+	case CSV, XLSX, JSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q", s)
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case JSON:
+		return "application/json"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// Money is an amount in cents. It is written as a decimal with two places: a number cell in XLSX
+// and a JSON number.
+type Money int64
+
+func (m Money) String() string {
+	v := int64(m)
+	sign := ""
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
+
+// Writer receives rows whose cells line up with the columns it was created with. Cells may be
+// string, int, int64, float64, bool, Money, time.Time or nil; anything else is written with fmt.
+// Close must be called to finish the document.
+type Writer interface {
+	Write(row []any) error
+	Close() error
+}
+
+// NewWriter starts a document on w and writes the header. name titles the XLSX sheet.
+func NewWriter(f Format, w io.Writer, name string, columns []string) (Writer, error) {
+	switch f {
+	case CSV:
+		return newCSVWriter(w, columns)
+	case XLSX:
+		return newXLSXWriter(w, name, columns)
+	case JSON:
+		return newJSONWriter(w, columns)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", f)
+	}
+}
+
+// text renders a cell for formats without types. Dates at midnight UTC are written without a time.
+func text(v any) string {
+	switch c := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return c
+	case int:
+		return strconv.Itoa(c)
+	case int64:
+		return strconv.FormatInt(c, 10)
+	case float64:
+		return strconv.FormatFloat(c, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(c)
+	case Money:
+		return c.String()
+	case time.Time:
+		if isDate(c) {
+			return c.Format("2006-01-02")
+		}
+		return c.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(c)
+	}
+}
+
+func isDate(t time.Time) bool {
+	t = t.UTC()
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testColumns = []string{"date", "description", "amount", "count"}
+
+var testRows = [][]any{
+	{time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), `Coffee, "large"`, Money(-1250), 1},
+	{nil, "Refund & tip", Money(99), int64(2)},
+}
+
+func writeAll(t *testing.T, f Format) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(f, &buf, "Transactions", testColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range testRows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	got := string(writeAll(t, CSV))
+	want := "date,description,amount,count\n2026-03-05,\"Coffee, \"\"large\"\"\",-12.50,1\n,Refund & tip,0.99,2\n"
+	if got != want {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestJSONWriterKeepsColumnOrderAndNumbers(t *testing.T) {
+	out := writeAll(t, JSON)
+	if !bytes.HasPrefix(out, []byte("[\n{\"date\":\"2026-03-05\",\"description\"")) {
+		t.Fatalf("unexpected start %q", out)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal(out, &rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0]["amount"] != -12.5 || rows[1]["date"] != nil {
+		t.Fatalf("unexpected rows %v", rows)
+	}
+}
+
+func TestXLSXWriterProducesSheet(t *testing.T) {
+	out := writeAll(t, XLSX)
+	zr, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		sheet = string(b)
+	}
+	for _, want := range []string{
+		`<c r="A2" s="2"><v>46086</v></c>`,
+		`<c r="C2" s="1"><v>-12.50</v></c>`,
+		`Refund &amp; tip`,
+		`<row r="3"><c r="B3"`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet missing %s", want)
+		}
+	}
+	if columnName(0) != "A" || columnName(25) != "Z" || columnName(26) != "AA" || columnName(701) != "ZZ" {
+		t.Error("unexpected column names")
+	}
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// jsonWriter streams an array of objects keyed by column, keeping the column order.
+type jsonWriter struct {
+	w    *bufio.Writer
+	keys [][]byte
+	rows int
+}
+
+func newJSONWriter(w io.Writer, columns []string) (*jsonWriter, error) {
+	jw := &jsonWriter{w: bufio.NewWriter(w), keys: make([][]byte, len(columns))}
+	for i, col := range columns {
+		key, err := json.Marshal(col)
+		if err != nil {
+			return nil, err
+		}
+		jw.keys[i] = key
+	}
+	if _, err := jw.w.WriteString("["); err != nil {
+		return nil, err
+	}
+	return jw, nil
+}
+
+func (jw *jsonWriter) Write(row []any) error {
+	if jw.rows > 0 {
+		jw.w.WriteString(",")
+	}
+	jw.rows++
+	jw.w.WriteString("\n{")
+	for i, key := range jw.keys {
+		if i > 0 {
+			jw.w.WriteString(",")
+		}
+		jw.w.Write(key)
+		jw.w.WriteString(":")
+		var cell any
+		if i < len(row) {
+			cell = row[i]
+		}
+		value, err := jsonValue(cell)
+		if err != nil {
+			return err
+		}
+		if _, err := jw.w.Write(value); err != nil {
+			return err
+		}
+	}
+	_, err := jw.w.WriteString("}")
+	return err
+}
+
+func (jw *jsonWriter) Close() error {
+	if jw.rows > 0 {
+		jw.w.WriteString("\n")
+	}
+	jw.w.WriteString("]\n")
+	return jw.w.Flush()
+}
+
+func jsonValue(v any) ([]byte, error) {
+	switch c := v.(type) {
+	case Money:
+		return []byte(c.String()), nil
+	case time.Time:
+		return json.Marshal(text(c))
+	default:
+		return json.Marshal(c)
+	}
+}
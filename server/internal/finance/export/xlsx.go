@@ -0,0 +1,207 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cell styles defined in xlsxStyles, by position in cellXfs.
+const (
+	styleDefault  = 0
+	styleMoney    = 1
+	styleDate     = 2
+	styleDateTime = 3
+	styleHeader   = 4
+)
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/><numFmt numFmtId="165" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="5">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`</cellXfs></styleSheet>`
+
+// xlsxWriter writes a single-sheet workbook with inline strings, so no shared string table has to
+// be collected before the sheet can be written.
+type xlsxWriter struct {
+	zip  *zip.Writer
+	w    *bufio.Writer
+	cols int
+	row  int
+}
+
+func newXLSXWriter(w io.Writer, name string, columns []string) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook(name)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return nil, err
+		}
+	}
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxWriter{zip: zw, w: bufio.NewWriter(sheet), cols: len(columns)}
+	xw.w.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	header := make([]any, len(columns))
+	for i, col := range columns {
+		header[i] = col
+	}
+	if err := xw.writeRow(header, styleHeader); err != nil {
+		return nil, err
+	}
+	return xw, nil
+}
+
+func (xw *xlsxWriter) Write(row []any) error {
+	return xw.writeRow(row, styleDefault)
+}
+
+func (xw *xlsxWriter) writeRow(row []any, style int) error {
+	xw.row++
+	r := strconv.Itoa(xw.row)
+	xw.w.WriteString(`<row r="` + r + `">`)
+	for i := 0; i < xw.cols && i < len(row); i++ {
+		if row[i] == nil {
+			continue
+		}
+		xw.writeCell(columnName(i)+r, row[i], style)
+	}
+	_, err := xw.w.WriteString("</row>")
+	return err
+}
+
+func (xw *xlsxWriter) writeCell(ref string, v any, style int) {
+	number := func(value string, s int) {
+		xw.w.WriteString(`<c r="` + ref + `"`)
+		if s != styleDefault {
+			xw.w.WriteString(` s="` + strconv.Itoa(s) + `"`)
+		}
+		xw.w.WriteString(`><v>` + value + `</v></c>`)
+	}
+	switch c := v.(type) {
+	case int:
+		number(strconv.Itoa(c), style)
+	case int64:
+		number(strconv.FormatInt(c, 10), style)
+	case float64:
+		number(strconv.FormatFloat(c, 'f', -1, 64), style)
+	case Money:
+		number(c.String(), styleMoney)
+	case time.Time:
+		s := styleDateTime
+		if isDate(c) {
+			s = styleDate
+		}
+		number(strconv.FormatFloat(excelSerial(c), 'f', -1, 64), s)
+	case bool:
+		b := "0"
+		if c {
+			b = "1"
+		}
+		xw.w.WriteString(`<c r="` + ref + `" t="b"><v>` + b + `</v></c>`)
+	default:
+		xw.w.WriteString(`<c r="` + ref + `" t="inlineStr"`)
+		if style != styleDefault {
+			xw.w.WriteString(` s="` + strconv.Itoa(style) + `"`)
+		}
+		xw.w.WriteString(`><is><t xml:space="preserve">`)
+		xml.EscapeText(xw.w, []byte(text(c)))
+		xw.w.WriteString(`</t></is></c>`)
+	}
+}
+
+func (xw *xlsxWriter) Close() error {
+	xw.w.WriteString(`</sheetData></worksheet>`)
+	if err := xw.w.Flush(); err != nil {
+		return err
+	}
+	return xw.zip.Close()
+}
+
+func xlsxWorkbook(name string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="`)
+	xml.EscapeText(&b, []byte(sheetName(name)))
+	b.WriteString(`" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+	return b.String()
+}
+
+// sheetName drops the characters Excel forbids in sheet names and keeps at most 31 of the rest.
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		return "Sheet1"
+	}
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	return name
+}
+
+// columnName converts a zero-based column index to its letters: 0 is A, 26 is AA.
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// excelSerial is t as days since 1899-12-30, the epoch spreadsheet dates count from.
+func excelSerial(t time.Time) float64 {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return t.UTC().Sub(epoch).Hours() / 24
+}
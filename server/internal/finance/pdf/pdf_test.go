@@ -0,0 +1,49 @@
+package pdf
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDocumentStructure(t *testing.T) {
+	doc := New("Fatura (1)")
+	doc.Text(50, 60, 12, true, "Olá, mundo")
+	doc.AddPage()
+	doc.TextRight(545, 60, 10, false, `a\b`)
+	out := doc.Bytes()
+
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatalf("missing header or trailer")
+	}
+	s := string(out)
+	if !strings.Contains(s, "/Count 2") {
+		t.Errorf("expected two pages")
+	}
+	if !strings.Contains(s, "(Ol\xe1, mundo) Tj") {
+		t.Errorf("text not WinAnsi encoded")
+	}
+	if !strings.Contains(s, `(a\\b) Tj`) || !strings.Contains(s, `/Title (Fatura \(1\))`) {
+		t.Errorf("literal strings not escaped")
+	}
+	xref := strings.Index(s, "xref\n")
+	if !strings.Contains(s, "startxref\n"+strconv.Itoa(xref)+"\n") {
+		t.Errorf("startxref does not point at the xref table")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	lines := Wrap("Desenvolvimento de software sob demanda", 10, 100, false)
+	if len(lines) < 2 {
+		t.Fatalf("expected wrapping, got %q", lines)
+	}
+	for _, l := range lines {
+		if Width(l, 10, false) > 100 && strings.Contains(l, " ") {
+			t.Errorf("line %q is too wide", l)
+		}
+	}
+	if got := Wrap("", 10, 100, false); len(got) != 1 || got[0] != "" {
+		t.Errorf("unexpected wrap of empty text %q", got)
+	}
+}
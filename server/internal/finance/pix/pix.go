@@ -0,0 +1,150 @@
+// Package pix builds Pix "copia e cola" payloads (EMV QR Code / BR Code, as specified by the Banco
+// Central do Brasil). A static payload carries the receiver's Pix key; a dynamic one carries the
+// location URL a PSP returned for a charge instead.
+package pix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Payload describes one charge. Key or URL is required; AmountCents zero leaves the amount open.
+type Payload struct {
+	Key          string
+	URL          string
+	MerchantName string
+	MerchantCity string
+	AmountCents  int64
+	// TxID identifies the charge for reconciliation: up to 25 letters and digits, "***" when empty.
+	TxID        string
+	Description string
+}
+
+var (
+	ErrMissingKey  = errors.New("pix: key or url is required")
+	ErrMissingName = errors.New("pix: merchant name and city are required")
+	ErrInvalidTxID = errors.New("pix: txid must be up to 25 letters or digits")
+)
+
+// BRCode renders the payload string, CRC included.
+func BRCode(p Payload) (string, error) {
+	key, url := strings.TrimSpace(p.Key), strings.TrimSpace(p.URL)
+	if key == "" && url == "" {
+		return "", ErrMissingKey
+	}
+	name := truncate(ascii(p.MerchantName), 25)
+	city := truncate(ascii(p.MerchantCity), 15)
+	if name == "" || city == "" {
+		return "", ErrMissingName
+	}
+	txid := strings.TrimSpace(p.TxID)
+	if txid == "" {
+		txid = "***"
+	} else if !validTxID(txid) {
+		return "", ErrInvalidTxID
+	}
+
+	var account strings.Builder
+	account.WriteString(field("00", "br.gov.bcb.pix"))
+	if url != "" {
+		account.WriteString(field("25", strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")))
+	} else {
+		account.WriteString(field("01", key))
+		if desc := ascii(p.Description); desc != "" {
+			// The merchant account template holds at most 99 characters.
+			if room := 99 - account.Len() - 4; room > 0 {
+				account.WriteString(field("02", truncate(desc, room)))
+			}
+		}
+	}
+	if account.Len() > 99 {
+		return "", errors.New("pix: key or url too long")
+	}
+
+	var b strings.Builder
+	b.WriteString(field("00", "01"))
+	if url != "" || p.AmountCents > 0 {
+		// 12: the code is meant for a single payment.
+		b.WriteString(field("01", "12"))
+	}
+	b.WriteString(field("26", account.String()))
+	b.WriteString(field("52", "0000"))
+	b.WriteString(field("53", "986"))
+	if p.AmountCents > 0 {
+		b.WriteString(field("54", fmt.Sprintf("%d.%02d", p.AmountCents/100, p.AmountCents%100)))
+	}
+	b.WriteString(field("58", "BR"))
+	b.WriteString(field("59", name))
+	b.WriteString(field("60", city))
+	b.WriteString(field("62", field("05", txid)))
+	b.WriteString("6304")
+	return b.String() + fmt.Sprintf("%04X", crc16(b.String())), nil
+}
+
+// TxID derives a valid txid from an arbitrary reference such as a receivable number.
+func TxID(ref string) string {
+	var b strings.Builder
+	for _, r := range ref {
+		if r < 128 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	return truncate(b.String(), 25)
+}
+
+func field(id, value string) string {
+	return id + fmt.Sprintf("%02d", len(value)) + value
+}
+
+func validTxID(s string) bool {
+	if len(s) > 25 {
+		return false
+	}
+	return TxID(s) == s
+}
+
+// ascii drops accents and anything outside printable ASCII, as payment apps expect.
+func ascii(s string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(s) {
+		if base, ok := unaccented[r]; ok {
+			r = base
+		}
+		if r >= 32 && r < 127 {
+			b.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+var unaccented = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'é': 'e', 'ê': 'e', 'è': 'e', 'í': 'i', 'ì': 'i',
+	'ó': 'o', 'ô': 'o', 'õ': 'o', 'ò': 'o', 'ö': 'o', 'ú': 'u', 'ù': 'u', 'ü': 'u', 'ç': 'c', 'ñ': 'n',
+	'Á': 'A', 'À': 'A', 'Â': 'A', 'Ã': 'A', 'Ä': 'A', 'É': 'E', 'Ê': 'E', 'È': 'E', 'Í': 'I', 'Ì': 'I',
+	'Ó': 'O', 'Ô': 'O', 'Õ': 'O', 'Ò': 'O', 'Ö': 'O', 'Ú': 'U', 'Ù': 'U', 'Ü': 'U', 'Ç': 'C', 'Ñ': 'N',
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return strings.TrimSpace(s[:n])
+	}
+	return s
+}
+
+// crc16 is CRC-16/CCITT-FALSE (polynomial 0x1021, initial value 0xFFFF).
+func crc16(s string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,64 @@
+package pix
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBRCodeMatchesManualExample(t *testing.T) {
+	// Example from the BR Code manual of the Banco Central do Brasil.
+	got, err := BRCode(Payload{
+		Key:          "123e4567-e12b-12d1-a456-426655440000",
+		MerchantName: "Fulano de Tal",
+		MerchantCity: "BRASILIA",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D"
+	if got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestBRCodeWithAmountAndTxID(t *testing.T) {
+	got, err := BRCode(Payload{
+		Key:          "dev@example.com",
+		MerchantName: "João da Conceição",
+		MerchantCity: "São Paulo",
+		AmountCents:  150050,
+		TxID:         TxID("2026-0007"),
+		Description:  "Fatura 2026-0007",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "00020101021226570014br.gov.bcb.pix0115dev@example.com0216Fatura 2026-0007" +
+		"52040000530398654071500.505802BR5917Joao da Conceicao6009Sao Paulo" +
+		"62120508202600076304"
+	if !strings.HasPrefix(got, want) || len(got) != len(want)+4 {
+		t.Fatalf("got  %s\nwant %s....", got, want)
+	}
+	if crc := fmt.Sprintf("%04X", crc16(want)); !strings.HasSuffix(got, crc) {
+		t.Errorf("crc %s does not match %s", got[len(want):], crc)
+	}
+}
+
+func TestBRCodeValidation(t *testing.T) {
+	if _, err := BRCode(Payload{MerchantName: "A", MerchantCity: "B"}); err != ErrMissingKey {
+		t.Errorf("expected ErrMissingKey, got %v", err)
+	}
+	if _, err := BRCode(Payload{Key: "k", MerchantName: "A", MerchantCity: "B", TxID: "a-b"}); err != ErrInvalidTxID {
+		t.Errorf("expected ErrInvalidTxID, got %v", err)
+	}
+	if got, _ := BRCode(Payload{URL: "https://psp.example.com/qr/v2/abc", MerchantName: "A", MerchantCity: "B"}); !strings.Contains(got, "2525psp.example.com/qr/v2/abc") {
+		t.Errorf("dynamic payload without location: %s", got)
+	}
+}
+
+func TestCRC16CheckValue(t *testing.T) {
+	if got := crc16("123456789"); got != 0x29B1 {
+		t.Fatalf("got %04X", got)
+	}
+}
@@ -0,0 +1,174 @@
+package qr
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomonKnownVector(t *testing.T) {
+	// "HELLO WORLD" as version 1-M alphanumeric data codewords, from the specification walkthrough.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	if got := formatBits(5); got != 0b100000011001110 {
+		t.Errorf("format bits for M/5 = %015b", got)
+	}
+	c := &Code{Size: 45, modules: grid(45), function: grid(45)}
+	c.drawFunctionPatterns(7)
+	// Version 7 information word is 000111110010010100; bit 2 lands at (Size-11+2, 0).
+	if !c.modules[0][45-9] || c.modules[0][45-11] {
+		t.Errorf("unexpected version information modules")
+	}
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	cases := map[int][]int{2: {6, 18}, 7: {6, 22, 38}, 14: {6, 26, 46, 66}, 20: {6, 34, 62, 90}}
+	for v, want := range cases {
+		got := alignmentPositions(v)
+		if len(got) != len(want) {
+			t.Fatalf("version %d: %v", v, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("version %d: %v, want %v", v, got, want)
+			}
+		}
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	payload := "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D"
+	for _, text := range []string{"hello", payload, strings.Repeat("pix", 120)} {
+		c, err := Encode(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := decode(t, c); got != text {
+			t.Fatalf("round trip of %d bytes returned %q", len(text), got)
+		}
+	}
+	if _, err := Encode(strings.Repeat("x", 700)); err != ErrTooLong {
+		t.Errorf("expected ErrTooLong, got %v", err)
+	}
+}
+
+func TestPNG(t *testing.T) {
+	c, err := Encode("hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := c.PNG(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if side := (c.Size + 8) * 4; img.Bounds().Dx() != side {
+		t.Errorf("width %d, want %d", img.Bounds().Dx(), side)
+	}
+}
+
+// decode reads a symbol back: format bits, unmasking, codeword order, block de-interleaving with a
+// syndrome check on every block, then the byte segment.
+func decode(t *testing.T, c *Code) string {
+	t.Helper()
+	version := (c.Size - 17) / 4
+	var format int
+	for i := 0; i < 15; i++ {
+		dark := c.modules[8][c.Size-1-i]
+		if i >= 8 {
+			dark = c.modules[c.Size-15+i][8]
+		}
+		if dark {
+			format |= 1 << i
+		}
+	}
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if formatBits(m) == format {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("format bits %015b are not level M", format)
+	}
+	c.applyMask(mask)
+	defer c.applyMask(mask)
+
+	raw := rawDataModules(version) / 8
+	stream := make([]byte, raw)
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if !c.function[y][x] && i < raw*8 {
+					if c.modules[y][x] {
+						stream[i>>3] |= 1 << (7 - i&7)
+					}
+					i++
+				}
+			}
+		}
+	}
+
+	numBlocks, eccLen := eccBlocks[version], eccPerBlock[version]
+	short := numBlocks - raw%numBlocks
+	shortLen := raw / numBlocks
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for col := 0; col <= shortLen; col++ {
+		for b := range blocks {
+			if col == shortLen-eccLen && b < short {
+				continue
+			}
+			blocks[b] = append(blocks[b], stream[k])
+			k++
+		}
+	}
+	var data []byte
+	for _, block := range blocks {
+		for r, root := 0, byte(1); r < eccLen; r, root = r+1, gfMul(root, 2) {
+			var sum byte
+			for _, cw := range block {
+				sum = gfMul(sum, root) ^ cw
+			}
+			if sum != 0 {
+				t.Fatalf("block syndrome %d is %d", r, sum)
+			}
+		}
+		data = append(data, block[:len(block)-eccLen]...)
+	}
+
+	read := func(pos, n int) int {
+		v := 0
+		for j := 0; j < n; j++ {
+			v = v<<1 | int(data[(pos+j)>>3]>>(7-(pos+j)&7)&1)
+		}
+		return v
+	}
+	if mode := read(0, 4); mode != 4 {
+		t.Fatalf("mode %d is not byte mode", mode)
+	}
+	n := read(4, countBits(version))
+	out := make([]byte, n)
+	for j := range out {
+		out[j] = byte(read(4+countBits(version)+8*j, 8))
+	}
+	return string(out)
+}
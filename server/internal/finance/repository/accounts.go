@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+func (r *Repository) ListAccounts(ctx context.Context, includeArchived bool) ([]models.Account, error) {
+	var out []models.Account
+	q := r.db.WithContext(ctx).Order("archived ASC, name ASC")
+	if !includeArchived {
+		q = q.Where("archived = ?", false)
+	}
+	if err := q.Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("list accounts: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) FindAccount(ctx context.Context, id uuid.UUID) (*models.Account, error) {
+	var row models.Account
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find account: %w", err)
+	}
+	return &row, nil
+}
+
+func (r *Repository) CreateAccount(ctx context.Context, row *models.Account) error {
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		return fmt.Errorf("create account: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) SaveAccount(ctx context.Context, row *models.Account) error {
+	if err := r.db.WithContext(ctx).Save(row).Error; err != nil {
+		return fmt.Errorf("save account: %w", err)
+	}
+	return nil
+}
+
+// DeleteAccount removes the account. Its net worth item keeps the recorded snapshots, drops to zero
+// on closedOn and becomes a manual item; savings goals tracking it lose their account.
+func (r *Repository) DeleteAccount(ctx context.Context, id uuid.UUID, closedOn time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ?", id).Delete(&models.Account{})
+		if res.Error != nil {
+			return fmt.Errorf("delete account: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		var items []models.NetWorthItem
+		if err := tx.Where("account_id = ?", id).Find(&items).Error; err != nil {
+			return fmt.Errorf("find net worth item: %w", err)
+		}
+		for _, item := range items {
+			err := (&Repository{db: tx}).SaveNetWorthSnapshot(ctx, &models.NetWorthSnapshot{ItemID: item.ID, Date: closedOn, Notes: "Account deleted"})
+			if err != nil {
+				return err
+			}
+		}
+		err := tx.Model(&models.NetWorthItem{}).
+			Where("account_id = ?", id).
+			Updates(map[string]any{"account_id": nil, "source": "manual"}).Error
+		if err != nil {
+			return fmt.Errorf("detach net worth item: %w", err)
+		}
+		err = tx.Model(&models.SavingsGoal{}).
+			Where("account_id = ?", id).
+			Update("account_id", nil).Error
+		if err != nil {
+			return fmt.Errorf("detach savings goals: %w", err)
+		}
+		return nil
+	})
+}
+
+// CountAccountTransactions counts transactions that move money in or out of the account.
+func (r *Repository) CountAccountTransactions(ctx context.Context, id uuid.UUID) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Where("account_id = ? OR to_account_id = ?", id, id).
+		Count(&n).Error
+	if err != nil {
+		return 0, fmt.Errorf("count account transactions: %w", err)
+	}
+	return n, nil
+}
+
+// SumAccountMovements returns the net change of each account from its opening date up to asOf
+// (inclusive, nil for no limit). Income adds, expenses and outgoing transfers subtract, and incoming
+// transfers add the received amount.
+func (r *Repository) SumAccountMovements(ctx context.Context, asOf *time.Time) (map[uuid.UUID]int64, error) {
+	var rows []struct {
+		AccountID uuid.UUID
+		Cents     int64
+	}
+	limit := ""
+	var args []any
+	if asOf != nil {
+		limit = " AND t.date <= ?"
+		args = append(args, *asOf, *asOf)
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT account_id, SUM(cents) AS cents FROM (
+			SELECT t.account_id AS account_id,
+				CASE WHEN t.type = 'income' THEN t.amount_cents ELSE -t.amount_cents END AS cents
+			FROM transactions t
+			JOIN accounts a ON a.id = t.account_id
+			WHERE t.date >= a.opening_date`+limit+`
+			UNION ALL
+			SELECT t.to_account_id AS account_id, COALESCE(t.to_amount_cents, t.amount_cents) AS cents
+			FROM transactions t
+			JOIN accounts a ON a.id = t.to_account_id
+			WHERE t.type = 'transfer' AND t.date >= a.opening_date`+limit+`
+		) m GROUP BY account_id`, args...).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("sum account movements: %w", err)
+	}
+	out := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		out[row.AccountID] = row.Cents
+	}
+	return out, nil
+}
+
+// ListAccountTransactions returns the account's transactions from its opening date up to to (nil for
+// no limit), oldest first, so a running balance can be computed.
+func (r *Repository) ListAccountTransactions(ctx context.Context, acc *models.Account, to *time.Time) ([]models.Transaction, error) {
+	var out []models.Transaction
+	q := r.db.WithContext(ctx).
+		Where("(account_id = ? OR (to_account_id = ? AND type = ?)) AND date >= ?", acc.ID, acc.ID, "transfer", acc.OpeningDate).
+		Order("date ASC, created_at ASC")
+	if to != nil {
+		q = q.Where("date <= ?", *to)
+	}
+	if err := q.Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("list account transactions: %w", err)
+	}
+	return out, nil
+}
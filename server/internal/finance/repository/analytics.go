@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// LedgerMonthRow is the income or expense of one month, category and currency.
+type LedgerMonthRow struct {
+	Month       time.Time
+	Type        string
+	Category    string
+	Currency    string
+	AmountCents int64
+	Count       int64
+}
+
+// SumLedgerByMonth totals income and expense transactions dated within [from, to) per calendar
+// month, type, category and currency in a single query.
+func (r *Repository) SumLedgerByMonth(ctx context.Context, from, to time.Time) ([]LedgerMonthRow, error) {
+	var rows []LedgerMonthRow
+	err := r.db.WithContext(ctx).
+		Table("transactions").
+		Select("DATE_TRUNC('month', transactions.date)::date AS month, transactions.type AS type, "+ledgerCategoryExpr+" AS category, transactions.currency AS currency, COALESCE(SUM(transactions.amount_cents), 0) AS amount_cents, COUNT(*) AS count").
+		Joins("LEFT JOIN expenses e ON e.id = transactions.expense_id").
+		Joins("LEFT JOIN income_sources inc ON inc.id = transactions.income_source_id").
+		Where("transactions.type IN ? AND transactions.date >= ? AND transactions.date < ?", []string{"income", "expense"}, from, to).
+		Group("1, 2, 3, 4").
+		Order("1, 2, 3, 4").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("sum ledger by month: %w", err)
+	}
+	return rows, nil
+}
+
+// MerchantRow is what was spent with one merchant in one currency. Merchants are expense
+// descriptions compared case-insensitively; Merchant keeps one of the original spellings.
+type MerchantRow struct {
+	MerchantKey string
+	Merchant    string
+	Currency    string
+	AmountCents int64
+	Count       int64
+	LastDate    time.Time
+}
+
+// SumExpensesByMerchant totals expense transactions dated within [from, to) per description and
+// currency, largest first, keeping the limit largest merchants of each currency: amounts in different
+// currencies cannot be ranked against each other before conversion.
+func (r *Repository) SumExpensesByMerchant(ctx context.Context, from, to time.Time, limit int) ([]MerchantRow, error) {
+	var rows []MerchantRow
+	totals := r.db.WithContext(ctx).
+		Table("transactions").
+		Select("LOWER(TRIM(description)) AS merchant_key, MAX(TRIM(description)) AS merchant, currency, SUM(amount_cents) AS amount_cents, COUNT(*) AS count, MAX(date) AS last_date, "+
+			"ROW_NUMBER() OVER (PARTITION BY currency ORDER BY SUM(amount_cents) DESC) AS merchant_rank").
+		Where("type = ? AND date >= ? AND date < ?", "expense", from, to).
+		Group("1, 3")
+	err := r.db.WithContext(ctx).
+		Table("(?) AS totals", totals).
+		Select("merchant_key, merchant, currency, amount_cents, count, last_date").
+		Where("merchant_rank <= ?", limit).
+		Order("amount_cents DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("sum expenses by merchant: %w", err)
+	}
+	return rows, nil
+}
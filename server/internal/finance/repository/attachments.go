@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateAttachment links a media asset to an owner; linking the same asset twice is a no-op.
+func (r *Repository) CreateAttachment(ctx context.Context, row *models.FinanceAttachment) error {
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "owner_type"}, {Name: "owner_id"}, {Name: "media_id"}},
+			DoNothing: true,
+		}).
+		Create(row).Error
+	if err != nil {
+		return fmt.Errorf("create finance attachment: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) DeleteAttachment(ctx context.Context, ownerType string, ownerID, mediaID uuid.UUID) error {
+	res := r.db.WithContext(ctx).
+		Delete(&models.FinanceAttachment{}, "owner_type = ? AND owner_id = ? AND media_id = ?", ownerType, ownerID, mediaID)
+	if res.Error != nil {
+		return fmt.Errorf("delete finance attachment: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListAttachments returns the media attached to each owner, oldest attachment first. Links whose
+// asset has been deleted are left out.
+func (r *Repository) ListAttachments(ctx context.Context, ownerType string, ownerIDs []uuid.UUID) (map[uuid.UUID][]models.MediaAsset, error) {
+	out := make(map[uuid.UUID][]models.MediaAsset, len(ownerIDs))
+	if len(ownerIDs) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		OwnerID uuid.UUID
+		models.MediaAsset
+	}
+	err := r.db.WithContext(ctx).
+		Table("finance_attachments").
+		Select("finance_attachments.owner_id, media_assets.*").
+		Joins("JOIN media_assets ON media_assets.id = finance_attachments.media_id").
+		Where("finance_attachments.owner_type = ? AND finance_attachments.owner_id IN ?", ownerType, ownerIDs).
+		Order("finance_attachments.created_at ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("list finance attachments: %w", err)
+	}
+	for _, row := range rows {
+		out[row.OwnerID] = append(out[row.OwnerID], row.MediaAsset)
+	}
+	return out, nil
+}
+
+func deleteAttachments(tx *gorm.DB, ownerType string, ownerIDs any) error {
+	err := tx.Where("owner_type = ? AND owner_id IN (?)", ownerType, ownerIDs).Delete(&models.FinanceAttachment{}).Error
+	if err != nil {
+		return fmt.Errorf("delete %s attachments: %w", ownerType, err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+// ListBudgetsRange returns budgets from (fromYear, fromMonth) through (toYear, toMonth) inclusive.
+func (r *Repository) ListBudgetsRange(ctx context.Context, fromYear, fromMonth, toYear, toMonth int) ([]models.BudgetPlan, error) {
+	var out []models.BudgetPlan
+	err := r.db.WithContext(ctx).
+		Where("year * 12 + month >= ? AND year * 12 + month <= ?", fromYear*12+fromMonth, toYear*12+toMonth).
+		Order("year ASC, month ASC, category ASC").
+		Find(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("list budgets range: %w", err)
+	}
+	return out, nil
+}
+
+// SumBudgetActuals returns spending per category in [from, to): expense transactions not tied to an
+// invoice (invoice payments would double count) plus card invoice items, dated by the invoice due
+// date. Invoice items carry no currency, so their Currency is empty.
+func (r *Repository) SumBudgetActuals(ctx context.Context, from, to time.Time) ([]AmountRow, error) {
+	var txRows []AmountRow
+	err := r.db.WithContext(ctx).Model(&models.Transaction{}).
+		Select(expenseCategoryExpr+" as key, transactions.currency as currency, transactions.date as date, COALESCE(SUM(transactions.amount_cents), 0) as amount_cents").
+		Joins("LEFT JOIN expenses e ON e.id = transactions.expense_id").
+		Where("transactions.type = ? AND transactions.invoice_id IS NULL", "expense").
+		Where("transactions.date >= ? AND transactions.date < ?", from, to).
+		Group(expenseCategoryExpr + ", transactions.currency, transactions.date").
+		Scan(&txRows).Error
+	if err != nil {
+		return nil, fmt.Errorf("sum budget transactions: %w", err)
+	}
+	var itemRows []AmountRow
+	err = r.db.WithContext(ctx).Model(&models.InvoiceItem{}).
+		Select("invoice_items.category as key, '' as currency, invoices.due_date as date, COALESCE(SUM(invoice_items.amount_cents), 0) as amount_cents").
+		Joins("JOIN invoices ON invoices.id = invoice_items.invoice_id").
+		Where("invoices.due_date >= ? AND invoices.due_date < ?", from, to).
+		Group("invoice_items.category, invoices.due_date").
+		Scan(&itemRows).Error
+	if err != nil {
+		return nil, fmt.Errorf("sum budget invoice items: %w", err)
+	}
+	return append(txRows, itemRows...), nil
+}
+
+// CopyBudgets creates the given rows and saves updated ones in one transaction.
+func (r *Repository) CopyBudgets(ctx context.Context, create, update []models.BudgetPlan) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range create {
+			if create[i].ID == uuid.Nil {
+				create[i].ID = uuid.New()
+			}
+		}
+		if len(create) > 0 {
+			if err := tx.Create(&create).Error; err != nil {
+				return fmt.Errorf("create budgets: %w", err)
+			}
+		}
+		for i := range update {
+			if err := tx.Save(&update[i]).Error; err != nil {
+				return fmt.Errorf("save budget: %w", err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultCategories are the categories the API used to hard-code, seeded on every start.
+var defaultCategories = map[string][]string{
+	"expense": {"subscription", "utilities", "rent", "food", "investment", "transport", "health", "other"},
+	"income":  {"salary", "freelance", "saas", "business", "other"},
+}
+
+func (r *Repository) ListCategories(ctx context.Context, includeArchived bool) ([]models.FinanceCategory, error) {
+	var out []models.FinanceCategory
+	q := r.db.WithContext(ctx).Order("kind ASC, name ASC")
+	if !includeArchived {
+		q = q.Where("archived = ?", false)
+	}
+	if err := q.Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("list categories: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) FindCategory(ctx context.Context, id uuid.UUID) (*models.FinanceCategory, error) {
+	var row models.FinanceCategory
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find category: %w", err)
+	}
+	return &row, nil
+}
+
+func (r *Repository) CreateCategory(ctx context.Context, row *models.FinanceCategory) error {
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		return fmt.Errorf("create category: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) SaveCategory(ctx context.Context, row *models.FinanceCategory) error {
+	if err := r.db.WithContext(ctx).Save(row).Error; err != nil {
+		return fmt.Errorf("save category: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	res := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.FinanceCategory{})
+	if res.Error != nil {
+		return fmt.Errorf("delete category: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CountCategoryUsage counts the rows that reference the category, including its child categories.
+func (r *Repository) CountCategoryUsage(ctx context.Context, cat *models.FinanceCategory) (int64, error) {
+	db := r.db.WithContext(ctx)
+	var total int64
+	count := func(q *gorm.DB) error {
+		var n int64
+		if err := q.Count(&n).Error; err != nil {
+			return err
+		}
+		total += n
+		return nil
+	}
+	queries := []*gorm.DB{
+		db.Model(&models.FinanceCategory{}).Where("parent_id = ?", cat.ID),
+		db.Model(&models.Transaction{}).Where("category = ? AND type = ?", cat.Slug, cat.Kind),
+		db.Model(&models.CategorizationRule{}).Where("set_category = ? AND type = ?", cat.Slug, cat.Kind),
+	}
+	if cat.Kind == "income" {
+		queries = append(queries, db.Model(&models.IncomeSource{}).Where("type = ?", cat.Slug))
+	} else {
+		queries = append(queries,
+			db.Model(&models.Expense{}).Where("category = ?", cat.Slug),
+			db.Model(&models.InvoiceItem{}).Where("category = ?", cat.Slug),
+			db.Model(&models.BudgetPlan{}).Where("category = ?", cat.Slug),
+			db.Model(&models.InstallmentPurchase{}).Where("category = ?", cat.Slug),
+		)
+	}
+	for _, q := range queries {
+		if err := count(q); err != nil {
+			return 0, fmt.Errorf("count category usage: %w", err)
+		}
+	}
+	return total, nil
+}
+
+// EnsureCategories seeds the default categories and creates one for every category string already
+// stored on expenses, invoice items, budgets, installment purchases and income sources (lower-cased by
+// migration 000003 so they match the slugs). It is safe to run repeatedly.
+func (r *Repository) EnsureCategories(ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		used := map[string][]string{}
+		for kind, sources := range map[string][][2]string{
+			"expense": {{"expenses", "category"}, {"invoice_items", "category"}, {"budget_plans", "category"}, {"installment_purchases", "category"}},
+			"income":  {{"income_sources", "type"}},
+		} {
+			for _, src := range sources {
+				table, col := src[0], src[1]
+				var values []string
+				err := tx.Table(table).Distinct(col).Where(col+" <> ''").Pluck(col, &values).Error
+				if err != nil {
+					return fmt.Errorf("list %s.%s: %w", table, col, err)
+				}
+				used[kind] = append(used[kind], values...)
+			}
+		}
+		var rows []models.FinanceCategory
+		seen := map[string]bool{}
+		for kind, slugs := range defaultCategories {
+			for _, slug := range append(append([]string{}, slugs...), used[kind]...) {
+				if seen[kind+"/"+slug] {
+					continue
+				}
+				seen[kind+"/"+slug] = true
+				rows = append(rows, models.FinanceCategory{ID: uuid.New(), Kind: kind, Slug: slug, Name: categoryName(slug)})
+			}
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "kind"}, {Name: "slug"}},
+			DoNothing: true,
+		}).Create(&rows).Error
+		if err != nil {
+			return fmt.Errorf("seed categories: %w", err)
+		}
+		return nil
+	})
+}
+
+func categoryName(slug string) string {
+	name := []rune(strings.ReplaceAll(slug, "_", " "))
+	if len(name) == 0 {
+		return ""
+	}
+	return strings.ToUpper(string(name[:1])) + string(name[1:])
+}
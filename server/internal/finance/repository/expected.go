@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExpectedTransactionFilter struct {
+	Status     string
+	SourceType string
+	SourceID   *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+}
+
+func (r *Repository) ListExpectedTransactions(ctx context.Context, f ExpectedTransactionFilter) ([]models.ExpectedTransaction, error) {
+	var out []models.ExpectedTransaction
+	q := r.db.WithContext(ctx).Order("due_date DESC, description ASC")
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.SourceType != "" {
+		q = q.Where("source_type = ?", f.SourceType)
+	}
+	if f.SourceID != nil {
+		q = q.Where("source_id = ?", *f.SourceID)
+	}
+	if f.From != nil {
+		q = q.Where("due_date >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("due_date <= ?", *f.To)
+	}
+	if err := q.Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("list expected transactions: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) FindExpectedTransaction(ctx context.Context, id uuid.UUID) (*models.ExpectedTransaction, error) {
+	var row models.ExpectedTransaction
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find expected transaction: %w", err)
+	}
+	return &row, nil
+}
+
+// InsertExpectedTransactions adds the rows that do not exist yet for their (source, due date) and
+// reports how many were created. Occurrences already confirmed or skipped are left untouched.
+func (r *Repository) InsertExpectedTransactions(ctx context.Context, rows []models.ExpectedTransaction) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	for i := range rows {
+		if rows[i].ID == uuid.Nil {
+			rows[i].ID = uuid.New()
+		}
+	}
+	res := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "source_type"}, {Name: "source_id"}, {Name: "due_date"}},
+			DoNothing: true,
+		}).
+		CreateInBatches(rows, 200)
+	if res.Error != nil {
+		return 0, fmt.Errorf("insert expected transactions: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// DeletePendingExpected removes the pending occurrences of one source; confirmed and skipped ones stay
+// as history.
+func (r *Repository) DeletePendingExpected(ctx context.Context, sourceType string, sourceID uuid.UUID) error {
+	err := r.db.WithContext(ctx).
+		Where("source_type = ? AND source_id = ? AND status = ?", sourceType, sourceID, "pending").
+		Delete(&models.ExpectedTransaction{}).Error
+	if err != nil {
+		return fmt.Errorf("delete pending expected transactions: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) SaveExpectedTransaction(ctx context.Context, row *models.ExpectedTransaction) error {
+	if err := r.db.WithContext(ctx).Save(row).Error; err != nil {
+		return fmt.Errorf("save expected transaction: %w", err)
+	}
+	return nil
+}
+
+// FindExpectedByTransaction returns the occurrence confirmed by a transaction, if any.
+func (r *Repository) FindExpectedByTransaction(ctx context.Context, transactionID uuid.UUID) (*models.ExpectedTransaction, error) {
+	var row models.ExpectedTransaction
+	err := r.db.WithContext(ctx).Where("transaction_id = ?", transactionID).First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find expected transaction: %w", err)
+	}
+	return &row, nil
+}
+
+// FindPendingExpectedNear returns the oldest pending occurrence of a source due within [from, to].
+func (r *Repository) FindPendingExpectedNear(ctx context.Context, sourceType string, sourceID uuid.UUID, from, to time.Time) (*models.ExpectedTransaction, error) {
+	var row models.ExpectedTransaction
+	err := r.db.WithContext(ctx).
+		Where("source_type = ? AND source_id = ? AND status = ?", sourceType, sourceID, "pending").
+		Where("due_date >= ? AND due_date <= ?", from, to).
+		Order("due_date ASC").
+		First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find pending expected transaction: %w", err)
+	}
+	return &row, nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize is how many rows are scanned before they are handed to the export callback.
+const exportBatchSize = 500
+
+// InvoiceFilter narrows invoices; From and To bound the due date to [From, To).
+type InvoiceFilter struct {
+	Status string
+	From   *time.Time
+	To     *time.Time
+}
+
+// ScheduleFilter narrows income sources and expenses to those in effect during [From, To): created
+// before To and not ended before From.
+type ScheduleFilter struct {
+	ActiveOnly bool
+	ContactID  *uuid.UUID
+	ProjectID  *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+}
+
+func (r *Repository) StreamTransactions(ctx context.Context, f TransactionFilter, fn func([]models.Transaction) error) error {
+	if err := streamRows(r.transactionQuery(ctx, f).Order("date DESC, created_at DESC"), fn); err != nil {
+		return fmt.Errorf("stream transactions: %w", err)
+	}
+	return nil
+}
+
+// StreamInvoices hands over invoices with their items loaded, one batch at a time.
+func (r *Repository) StreamInvoices(ctx context.Context, f InvoiceFilter, fn func([]models.Invoice) error) error {
+	q := r.db.WithContext(ctx).Model(&models.Invoice{}).Order("due_date DESC, id ASC")
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.From != nil {
+		q = q.Where("due_date >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("due_date < ?", *f.To)
+	}
+	err := streamRows(q, func(batch []models.Invoice) error {
+		ids := make([]uuid.UUID, len(batch))
+		for i, inv := range batch {
+			ids[i] = inv.ID
+		}
+		var items []models.InvoiceItem
+		err := r.db.WithContext(ctx).Where("invoice_id IN ?", ids).Order("date ASC, created_at ASC").Find(&items).Error
+		if err != nil {
+			return err
+		}
+		byInvoice := make(map[uuid.UUID][]models.InvoiceItem, len(batch))
+		for _, item := range items {
+			byInvoice[item.InvoiceID] = append(byInvoice[item.InvoiceID], item)
+		}
+		for i := range batch {
+			batch[i].Items = byInvoice[batch[i].ID]
+		}
+		return fn(batch)
+	})
+	if err != nil {
+		return fmt.Errorf("stream invoices: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) StreamIncomeSources(ctx context.Context, f ScheduleFilter, fn func([]models.IncomeSource) error) error {
+	q := scheduleQuery(r.db.WithContext(ctx).Model(&models.IncomeSource{}), f)
+	if f.ContactID != nil {
+		q = q.Where("contact_id = ?", *f.ContactID)
+	}
+	if err := streamRows(q, fn); err != nil {
+		return fmt.Errorf("stream income sources: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) StreamExpenses(ctx context.Context, f ScheduleFilter, fn func([]models.Expense) error) error {
+	q := scheduleQuery(r.db.WithContext(ctx).Model(&models.Expense{}), f)
+	if err := streamRows(q, fn); err != nil {
+		return fmt.Errorf("stream expenses: %w", err)
+	}
+	return nil
+}
+
+// StreamBudgets hands over budgets whose month falls in [fromIndex, toIndex], counted as
+// year*12+month; zero leaves that side open.
+func (r *Repository) StreamBudgets(ctx context.Context, fromIndex, toIndex int, fn func([]models.BudgetPlan) error) error {
+	q := r.db.WithContext(ctx).Model(&models.BudgetPlan{}).Order("year ASC, month ASC, category ASC")
+	if fromIndex > 0 {
+		q = q.Where("year * 12 + month >= ?", fromIndex)
+	}
+	if toIndex > 0 {
+		q = q.Where("year * 12 + month <= ?", toIndex)
+	}
+	if err := streamRows(q, fn); err != nil {
+		return fmt.Errorf("stream budgets: %w", err)
+	}
+	return nil
+}
+
+func scheduleQuery(q *gorm.DB, f ScheduleFilter) *gorm.DB {
+	q = q.Order("name ASC, id ASC")
+	if f.ActiveOnly {
+		q = q.Where("active = ?", true)
+	}
+	if f.ProjectID != nil {
+		q = q.Where("project_id = ?", *f.ProjectID)
+	}
+	if f.From != nil {
+		q = q.Where("end_date IS NULL OR end_date >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("created_at < ?", *f.To)
+	}
+	return q
+}
+
+// streamRows reads q with a cursor and passes the rows to fn in batches, so no more than one batch
+// is held in memory at a time.
+func streamRows[T any](q *gorm.DB, fn func([]T) error) error {
+	rows, err := q.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	batch := make([]T, 0, exportBatchSize)
+	for rows.Next() {
+		var row T
+		if err := q.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		batch = append(batch, row)
+		if len(batch) == exportBatchSize {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = make([]T, 0, exportBatchSize)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExchangeRateFilter struct {
+	Currency string
+	From     *time.Time
+	To       *time.Time
+}
+
+func (r *Repository) ListExchangeRates(ctx context.Context, f ExchangeRateFilter) ([]models.ExchangeRate, error) {
+	var out []models.ExchangeRate
+	q := r.db.WithContext(ctx).Order("date DESC, base_currency ASC, quote_currency ASC")
+	if f.Currency != "" {
+		q = q.Where("base_currency = ? OR quote_currency = ?", f.Currency, f.Currency)
+	}
+	if f.From != nil {
+		q = q.Where("date >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("date <= ?", *f.To)
+	}
+	if err := q.Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("list exchange rates: %w", err)
+	}
+	return out, nil
+}
+
+// UpsertExchangeRates writes rows keyed by (date, base, quote), replacing the rate when the pair already exists.
+func (r *Repository) UpsertExchangeRates(ctx context.Context, rows []models.ExchangeRate) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	for i := range rows {
+		if rows[i].ID == uuid.Nil {
+			rows[i].ID = uuid.New()
+		}
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "date"}, {Name: "base_currency"}, {Name: "quote_currency"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+		}).CreateInBatches(rows, 500).Error
+		if err != nil {
+			return fmt.Errorf("upsert exchange rates: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *Repository) DeleteExchangeRate(ctx context.Context, id uuid.UUID) error {
+	res := r.db.WithContext(ctx).Delete(&models.ExchangeRate{}, "id = ?", id)
+	if res.Error != nil {
+		return fmt.Errorf("delete exchange rate: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListRatesUntil returns every rate up to (and including) until, oldest first.
+func (r *Repository) ListRatesUntil(ctx context.Context, until time.Time) ([]models.ExchangeRate, error) {
+	var out []models.ExchangeRate
+	err := r.db.WithContext(ctx).
+		Where("date <= ?", until).
+		Order("date ASC").
+		Find(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("list rates: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) FindExchangeRate(ctx context.Context, date time.Time, base, quote string) (*models.ExchangeRate, error) {
+	var row models.ExchangeRate
+	err := r.db.WithContext(ctx).
+		Where("date = ? AND base_currency = ? AND quote_currency = ?", date, base, quote).
+		First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find exchange rate: %w", err)
+	}
+	return &row, nil
+}
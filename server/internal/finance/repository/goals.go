@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+// ListSavingsGoals returns goals ordered by deadline (open-ended last); query matches the name.
+func (r *Repository) ListSavingsGoals(ctx context.Context, includeArchived bool, query string) ([]models.SavingsGoal, error) {
+	var out []models.SavingsGoal
+	q := r.db.WithContext(ctx).Order("deadline ASC NULLS LAST, name ASC")
+	if !includeArchived {
+		q = q.Where("archived = ?", false)
+	}
+	if term := strings.TrimSpace(query); term != "" {
+		q = q.Where("name ILIKE ?", "%"+escapeLike(term)+"%")
+	}
+	if err := q.Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("list savings goals: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) FindSavingsGoal(ctx context.Context, id uuid.UUID) (*models.SavingsGoal, error) {
+	var row models.SavingsGoal
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find savings goal: %w", err)
+	}
+	return &row, nil
+}
+
+func (r *Repository) CreateSavingsGoal(ctx context.Context, row *models.SavingsGoal) error {
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		return fmt.Errorf("create savings goal: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) SaveSavingsGoal(ctx context.Context, row *models.SavingsGoal) error {
+	if err := r.db.WithContext(ctx).Save(row).Error; err != nil {
+		return fmt.Errorf("save savings goal: %w", err)
+	}
+	return nil
+}
+
+// DeleteSavingsGoal removes the goal and untags its transactions, which are kept.
+func (r *Repository) DeleteSavingsGoal(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.SavingsGoal{}, "id = ?", id)
+		if res.Error != nil {
+			return fmt.Errorf("delete savings goal: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		err := tx.Model(&models.Transaction{}).Where("goal_id = ?", id).Update("goal_id", nil).Error
+		if err != nil {
+			return fmt.Errorf("untag goal transactions: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListGoalTransactions returns the transactions tagged with any of goalIDs, oldest first.
+func (r *Repository) ListGoalTransactions(ctx context.Context, goalIDs []uuid.UUID) ([]models.Transaction, error) {
+	if len(goalIDs) == 0 {
+		return nil, nil
+	}
+	var out []models.Transaction
+	err := r.db.WithContext(ctx).
+		Where("goal_id IN ?", goalIDs).
+		Order("date ASC, created_at ASC").
+		Find(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("list goal transactions: %w", err)
+	}
+	return out, nil
+}
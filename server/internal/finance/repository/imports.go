@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+func (r *Repository) ListImports(ctx context.Context) ([]models.TransactionImport, error) {
+	var out []models.TransactionImport
+	err := r.db.WithContext(ctx).
+		Omit("rows").
+		Order("created_at DESC").
+		Find(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("list imports: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) FindImport(ctx context.Context, id uuid.UUID) (*models.TransactionImport, error) {
+	var row models.TransactionImport
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find import: %w", err)
+	}
+	return &row, nil
+}
+
+func (r *Repository) CreateImport(ctx context.Context, row *models.TransactionImport) error {
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		return fmt.Errorf("create import: %w", err)
+	}
+	return nil
+}
+
+// ListTransactionsBetween returns transactions dated within [from, to], used to fingerprint-match imports.
+func (r *Repository) ListTransactionsBetween(ctx context.Context, from, to time.Time) ([]models.Transaction, error) {
+	var out []models.Transaction
+	err := r.db.WithContext(ctx).
+		Where("date >= ? AND date <= ?", from, to).
+		Find(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("list transactions between: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) ListTransactionsByExternalIDs(ctx context.Context, ids []string) ([]models.Transaction, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var out []models.Transaction
+	if err := r.db.WithContext(ctx).Where("external_id IN ?", ids).Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("list transactions by external id: %w", err)
+	}
+	return out, nil
+}
+
+// CommitImport inserts the batch and marks the import committed in one database transaction. The
+// status moves from preview first, so a concurrent commit of the same import gets
+// gorm.ErrRecordNotFound instead of inserting the rows twice.
+func (r *Repository) CommitImport(ctx context.Context, imp *models.TransactionImport, rows []models.Transaction) error {
+	for i := range rows {
+		if rows[i].ID == uuid.Nil {
+			rows[i].ID = uuid.New()
+		}
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.TransactionImport{}).
+			Where("id = ? AND status = ?", imp.ID, "preview").
+			Update("status", "committed")
+		if res.Error != nil {
+			return fmt.Errorf("claim import: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if len(rows) > 0 {
+			if err := tx.CreateInBatches(rows, 200).Error; err != nil {
+				return fmt.Errorf("create imported transactions: %w", err)
+			}
+		}
+		if err := tx.Save(imp).Error; err != nil {
+			return fmt.Errorf("save import: %w", err)
+		}
+		return nil
+	})
+}
+
+// UndoImport deletes every transaction the import created, reopening expected occurrences they had
+// confirmed, and marks the import undone.
+func (r *Repository) UndoImport(ctx context.Context, imp *models.TransactionImport) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		sub := tx.Model(&models.Transaction{}).Select("id").Where("import_id = ?", imp.ID)
+		err := tx.Model(&models.ExpectedTransaction{}).
+			Where("transaction_id IN (?)", sub).
+			Updates(map[string]any{
+				"status":              "pending",
+				"transaction_id":      nil,
+				"actual_amount_cents": nil,
+				"actual_date":         nil,
+				"resolved_at":         nil,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("reopen expected transactions: %w", err)
+		}
+		imported := tx.Model(&models.Transaction{}).Select("id").Where("import_id = ?", imp.ID)
+		if err := deleteAttachments(tx, models.FinanceAttachmentTransaction, imported); err != nil {
+			return err
+		}
+		if err := deleteSplits(tx, models.FinanceSplitTransaction, imported); err != nil {
+			return err
+		}
+		if err := tx.Where("import_id = ?", imp.ID).Delete(&models.Transaction{}).Error; err != nil {
+			return fmt.Errorf("delete imported transactions: %w", err)
+		}
+		if err := tx.Save(imp).Error; err != nil {
+			return fmt.Errorf("save import: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+// FindInvoiceByDueDate returns the invoice due on the given day, narrowed to a card when cardLastFour is set.
+func (r *Repository) FindInvoiceByDueDate(ctx context.Context, dueDate time.Time, cardLastFour string) (*models.Invoice, error) {
+	var row models.Invoice
+	q := r.db.WithContext(ctx).Where("due_date = ?", dueDate)
+	if cardLastFour != "" {
+		q = q.Where("card_last_four = ?", cardLastFour)
+	}
+	err := q.Order("created_at ASC").First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find invoice by due date: %w", err)
+	}
+	return &row, nil
+}
+
+// AddInvoiceItems inserts items into one invoice and refreshes its total in the same transaction.
+func (r *Repository) AddInvoiceItems(ctx context.Context, invoiceID uuid.UUID, items []models.InvoiceItem) error {
+	for i := range items {
+		if items[i].ID == uuid.Nil {
+			items[i].ID = uuid.New()
+		}
+		items[i].InvoiceID = invoiceID
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(items) > 0 {
+			if err := tx.CreateInBatches(items, 200).Error; err != nil {
+				return fmt.Errorf("create invoice items: %w", err)
+			}
+		}
+		return (&Repository{db: tx}).RecalcInvoiceTotal(ctx, invoiceID)
+	})
+}
+
+// ListInvoicePayments returns the expense transactions recorded against an invoice.
+func (r *Repository) ListInvoicePayments(ctx context.Context, invoiceID uuid.UUID) ([]models.Transaction, error) {
+	var out []models.Transaction
+	err := r.db.WithContext(ctx).
+		Where("invoice_id = ? AND type = ?", invoiceID, "expense").
+		Order("date ASC, created_at ASC").
+		Find(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("list invoice payments: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) SumInvoicePayments(ctx context.Context, invoiceID uuid.UUID) (int64, error) {
+	var sum int64
+	err := r.db.WithContext(ctx).Model(&models.Transaction{}).
+		Where("invoice_id = ? AND type = ?", invoiceID, "expense").
+		Select("COALESCE(SUM(amount_cents), 0)").
+		Scan(&sum).Error
+	if err != nil {
+		return 0, fmt.Errorf("sum invoice payments: %w", err)
+	}
+	return sum, nil
+}
+
+func (r *Repository) UpdateInvoicePaid(ctx context.Context, invoiceID uuid.UUID, paidCents int64, status string) error {
+	err := r.db.WithContext(ctx).Model(&models.Invoice{}).
+		Where("id = ?", invoiceID).
+		Updates(map[string]any{"paid_cents": paidCents, "status": status}).Error
+	if err != nil {
+		return fmt.Errorf("update invoice paid: %w", err)
+	}
+	return nil
+}
+
+// InvoiceStatusFor is the status an invoice's amounts imply on today: paid once payments cover a
+// positive total, otherwise overdue after the due date and open until then.
+func InvoiceStatusFor(totalCents, paidCents int64, dueDate, today time.Time) string {
+	due := time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, time.UTC)
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	switch {
+	case totalCents > 0 && paidCents >= totalCents:
+		return "paid"
+	case due.Before(today):
+		return "overdue"
+	default:
+		return "open"
+	}
+}
+
+// MarkOverdueInvoices flips open invoices due before today to overdue and returns the ones it changed.
+func (r *Repository) MarkOverdueInvoices(ctx context.Context, today time.Time) ([]models.Invoice, error) {
+	var out []models.Invoice
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("status = ? AND due_date < ?", "open", today).Order("due_date ASC").Find(&out).Error; err != nil {
+			return fmt.Errorf("list overdue invoices: %w", err)
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		ids := make([]uuid.UUID, 0, len(out))
+		for i := range out {
+			ids = append(ids, out[i].ID)
+			out[i].Status = "overdue"
+		}
+		if err := tx.Model(&models.Invoice{}).Where("id IN ?", ids).Update("status", "overdue").Error; err != nil {
+			return fmt.Errorf("mark invoices overdue: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInvoiceStatusFor(t *testing.T) {
+	due := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name        string
+		total, paid int64
+		today       time.Time
+		want        string
+	}{
+		{"open before due", 100000, 0, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), "open"},
+		{"open on the due day", 100000, 0, time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC), "open"},
+		{"overdue after due", 100000, 0, time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC), "overdue"},
+		{"partial stays open", 100000, 40000, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), "open"},
+		{"partial past due is overdue", 100000, 99999, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), "overdue"},
+		{"paid in full", 100000, 100000, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), "paid"},
+		{"overpaid", 100000, 120000, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), "paid"},
+		{"total grew past paid", 150000, 100000, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), "open"},
+		{"empty invoice is not paid", 0, 0, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), "open"},
+	}
+	for _, c := range cases {
+		if got := InvoiceStatusFor(c.total, c.paid, due, c.today); got != c.want {
+			t.Fatalf("%s: got %q want %q", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *Repository) ListNetWorthItems(ctx context.Context, includeArchived bool) ([]models.NetWorthItem, error) {
+	var out []models.NetWorthItem
+	q := r.db.WithContext(ctx).Order("kind ASC, name ASC")
+	if !includeArchived {
+		q = q.Where("archived = ?", false)
+	}
+	if err := q.Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("list net worth items: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) FindNetWorthItem(ctx context.Context, id uuid.UUID) (*models.NetWorthItem, error) {
+	var row models.NetWorthItem
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find net worth item: %w", err)
+	}
+	return &row, nil
+}
+
+func (r *Repository) CreateNetWorthItem(ctx context.Context, row *models.NetWorthItem) error {
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		return fmt.Errorf("create net worth item: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) SaveNetWorthItem(ctx context.Context, row *models.NetWorthItem) error {
+	if err := r.db.WithContext(ctx).Save(row).Error; err != nil {
+		return fmt.Errorf("save net worth item: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) DeleteNetWorthItem(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.NetWorthItem{}, "id = ?", id)
+		if res.Error != nil {
+			return fmt.Errorf("delete net worth item: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Delete(&models.NetWorthSnapshot{}, "item_id = ?", id).Error; err != nil {
+			return fmt.Errorf("delete net worth snapshots: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *Repository) ListNetWorthSnapshots(ctx context.Context, itemID uuid.UUID) ([]models.NetWorthSnapshot, error) {
+	var out []models.NetWorthSnapshot
+	err := r.db.WithContext(ctx).
+		Where("item_id = ?", itemID).
+		Order("date DESC").
+		Find(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("list net worth snapshots: %w", err)
+	}
+	return out, nil
+}
+
+// ListNetWorthSnapshotsUntil returns every snapshot dated up to to, oldest first.
+func (r *Repository) ListNetWorthSnapshotsUntil(ctx context.Context, to time.Time) ([]models.NetWorthSnapshot, error) {
+	var out []models.NetWorthSnapshot
+	err := r.db.WithContext(ctx).
+		Where("date <= ?", to).
+		Order("date ASC").
+		Find(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("list net worth snapshots: %w", err)
+	}
+	return out, nil
+}
+
+// LatestNetWorthSnapshots returns the most recent snapshot of each item.
+func (r *Repository) LatestNetWorthSnapshots(ctx context.Context) (map[uuid.UUID]models.NetWorthSnapshot, error) {
+	var rows []models.NetWorthSnapshot
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (item_id) * FROM net_worth_snapshots ORDER BY item_id, date DESC`).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("latest net worth snapshots: %w", err)
+	}
+	out := make(map[uuid.UUID]models.NetWorthSnapshot, len(rows))
+	for _, row := range rows {
+		out[row.ItemID] = row
+	}
+	return out, nil
+}
+
+// SaveNetWorthSnapshot records the item's value on row.Date, replacing a snapshot of the same day.
+func (r *Repository) SaveNetWorthSnapshot(ctx context.Context, row *models.NetWorthSnapshot) error {
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "item_id"}, {Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"value_cents", "auto", "notes", "updated_at"}),
+		}).
+		Create(row).Error
+	if err != nil {
+		return fmt.Errorf("save net worth snapshot: %w", err)
+	}
+	return nil
+}
+
+// InsertNetWorthSnapshots stores computed snapshots and reports how many were created. Items that
+// already have a snapshot on the same day, such as a manual correction, keep it.
+func (r *Repository) InsertNetWorthSnapshots(ctx context.Context, rows []models.NetWorthSnapshot) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	for i := range rows {
+		if rows[i].ID == uuid.Nil {
+			rows[i].ID = uuid.New()
+		}
+	}
+	res := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "item_id"}, {Name: "date"}},
+			DoNothing: true,
+		}).
+		Create(&rows)
+	if res.Error != nil {
+		return 0, fmt.Errorf("insert net worth snapshots: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+func (r *Repository) DeleteNetWorthSnapshot(ctx context.Context, itemID, id uuid.UUID) error {
+	res := r.db.WithContext(ctx).Delete(&models.NetWorthSnapshot{}, "id = ? AND item_id = ?", id, itemID)
+	if res.Error != nil {
+		return fmt.Errorf("delete net worth snapshot: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// HasAutoNetWorthSnapshot reports whether the job already ran for a date between from and to.
+func (r *Repository) HasAutoNetWorthSnapshot(ctx context.Context, from, to time.Time) (bool, error) {
+	var n int64
+	err := r.db.WithContext(ctx).
+		Model(&models.NetWorthSnapshot{}).
+		Where("auto = ? AND date >= ? AND date <= ?", true, from, to).
+		Limit(1).
+		Count(&n).Error
+	if err != nil {
+		return false, fmt.Errorf("count net worth snapshots: %w", err)
+	}
+	return n > 0, nil
+}
+
+// SumOpenInvoiceBalance is what is still owed on open and overdue card invoices.
+func (r *Repository) SumOpenInvoiceBalance(ctx context.Context) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Invoice{}).
+		Where("status IN ?", []string{"open", "overdue"}).
+		Select("COALESCE(SUM(GREATEST(total_cents - paid_cents, 0)), 0)").
+		Scan(&total).Error
+	if err != nil {
+		return 0, fmt.Errorf("sum open invoices: %w", err)
+	}
+	return total, nil
+}
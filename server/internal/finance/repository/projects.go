@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProjectLedgerRow is a per-day subtotal of income or expense attributed to a project.
+type ProjectLedgerRow struct {
+	ProjectID   uuid.UUID
+	Type        string
+	Category    string
+	Currency    string
+	Date        time.Time
+	AmountCents int64
+}
+
+// ledgerCategoryExpr is the category an income or expense transaction counts under: its own, else
+// the type of its income source or the category of its expense, else "other". Queries using it must
+// LEFT JOIN expenses as e and income_sources as inc.
+const ledgerCategoryExpr = "COALESCE(NULLIF(transactions.category, ''), CASE WHEN transactions.type = 'income' THEN inc.type ELSE e.category END, 'other')"
+
+// SumProjectLedger totals income and expense transactions dated within [from, to) per project. A
+// transaction belongs to its own project, else to the project of its expense or income source; the
+// category follows the same fallback. projectID limits the result to one project.
+func (r *Repository) SumProjectLedger(ctx context.Context, from, to time.Time, projectID *uuid.UUID) ([]ProjectLedgerRow, error) {
+	const projectExpr = "COALESCE(transactions.project_id, e.project_id, inc.project_id)"
+	q := r.db.WithContext(ctx).
+		Table("transactions").
+		Select(projectExpr+" AS project_id, transactions.type AS type, "+ledgerCategoryExpr+" AS category, transactions.currency AS currency, transactions.date AS date, COALESCE(SUM(transactions.amount_cents), 0) AS amount_cents").
+		Joins("LEFT JOIN expenses e ON e.id = transactions.expense_id").
+		Joins("LEFT JOIN income_sources inc ON inc.id = transactions.income_source_id").
+		Where("transactions.type IN ? AND transactions.date >= ? AND transactions.date < ?", []string{"income", "expense"}, from, to).
+		Where(projectExpr + " IS NOT NULL").
+		Group(projectExpr + ", transactions.type, " + ledgerCategoryExpr + ", transactions.currency, transactions.date")
+	if projectID != nil {
+		q = q.Where(projectExpr+" = ?", *projectID)
+	}
+	var rows []ProjectLedgerRow
+	if err := q.Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("sum project ledger: %w", err)
+	}
+	return rows, nil
+}
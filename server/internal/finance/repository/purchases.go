@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+// PurchaseInstallment is an invoice item generated by an installment purchase, with the state of the
+// invoice it sits on.
+type PurchaseInstallment struct {
+	models.InvoiceItem
+	InvoiceStatus  string    `json:"invoiceStatus"`
+	InvoiceDueDate time.Time `json:"invoiceDueDate"`
+}
+
+func (r *Repository) ListInstallmentPurchases(ctx context.Context, status string) ([]models.InstallmentPurchase, error) {
+	var out []models.InstallmentPurchase
+	q := r.db.WithContext(ctx).Order("purchase_date DESC")
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if err := q.Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("list installment purchases: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) FindInstallmentPurchase(ctx context.Context, id uuid.UUID) (*models.InstallmentPurchase, error) {
+	var row models.InstallmentPurchase
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find installment purchase: %w", err)
+	}
+	return &row, nil
+}
+
+// ListPurchaseInstallments returns the invoice items of the given purchases ordered by invoice due date.
+func (r *Repository) ListPurchaseInstallments(ctx context.Context, purchaseIDs []uuid.UUID) ([]PurchaseInstallment, error) {
+	if len(purchaseIDs) == 0 {
+		return nil, nil
+	}
+	var out []PurchaseInstallment
+	err := r.db.WithContext(ctx).
+		Table("invoice_items").
+		Select("invoice_items.*, invoices.status AS invoice_status, invoices.due_date AS invoice_due_date").
+		Joins("JOIN invoices ON invoices.id = invoice_items.invoice_id").
+		Where("invoice_items.purchase_id IN ?", purchaseIDs).
+		Order("invoices.due_date ASC").
+		Scan(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("list purchase installments: %w", err)
+	}
+	return out, nil
+}
+
+// FindCardInvoiceInMonth returns the card's invoice due within [from, to], if any. An empty card never
+// matches: card-less invoices cannot be told apart.
+func (r *Repository) FindCardInvoiceInMonth(ctx context.Context, cardLastFour string, from, to time.Time) (*models.Invoice, error) {
+	if cardLastFour == "" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	var row models.Invoice
+	err := r.db.WithContext(ctx).
+		Where("card_last_four = ? AND due_date >= ? AND due_date <= ?", cardLastFour, from, to).
+		Order("due_date ASC").
+		First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find card invoice: %w", err)
+	}
+	return &row, nil
+}
+
+// LatestCardInvoice returns the most recent invoice for a card, used to infer its due day.
+func (r *Repository) LatestCardInvoice(ctx context.Context, cardLastFour string) (*models.Invoice, error) {
+	var row models.Invoice
+	err := r.db.WithContext(ctx).
+		Where("card_last_four = ?", cardLastFour).
+		Order("due_date DESC").
+		First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("latest card invoice: %w", err)
+	}
+	return &row, nil
+}
+
+// SyncInstallmentPurchase saves the purchase and replaces its installments on invoices that are not
+// paid: new invoices are created, items that carry an ID are updated in place and keep their splits and
+// attachments, other unpaid items are removed, new items are inserted, and every touched invoice
+// total is recalculated, all in one transaction.
+func (r *Repository) SyncInstallmentPurchase(ctx context.Context, p *models.InstallmentPurchase, invoices []models.Invoice, items []models.InvoiceItem) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	var keep []uuid.UUID
+	for _, item := range items {
+		if item.ID != uuid.Nil {
+			keep = append(keep, item.ID)
+		}
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(p).Error; err != nil {
+			return fmt.Errorf("save installment purchase: %w", err)
+		}
+		if len(invoices) > 0 {
+			if err := tx.Create(&invoices).Error; err != nil {
+				return fmt.Errorf("create invoices: %w", err)
+			}
+		}
+		touched, err := removeUnpaidInstallments(tx, p.ID, keep)
+		if err != nil {
+			return err
+		}
+		var created []models.InvoiceItem
+		for i := range items {
+			items[i].PurchaseID = &p.ID
+			touched[items[i].InvoiceID] = true
+			if items[i].ID == uuid.Nil {
+				items[i].ID = uuid.New()
+				created = append(created, items[i])
+				continue
+			}
+			if err := tx.Save(&items[i]).Error; err != nil {
+				return fmt.Errorf("update installment: %w", err)
+			}
+		}
+		if len(created) > 0 {
+			if err := tx.Create(&created).Error; err != nil {
+				return fmt.Errorf("create installments: %w", err)
+			}
+		}
+		return recalcInvoices(ctx, tx, touched)
+	})
+}
+
+// DeleteInstallmentPurchase removes the purchase and its installments on unpaid invoices; items on
+// paid invoices stay as history and are unlinked.
+func (r *Repository) DeleteInstallmentPurchase(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		touched, err := removeUnpaidInstallments(tx, id, nil)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&models.InvoiceItem{}).Where("purchase_id = ?", id).Update("purchase_id", nil).Error; err != nil {
+			return fmt.Errorf("unlink installments: %w", err)
+		}
+		res := tx.Delete(&models.InstallmentPurchase{}, "id = ?", id)
+		if res.Error != nil {
+			return fmt.Errorf("delete installment purchase: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return recalcInvoices(ctx, tx, touched)
+	})
+}
+
+// SumCommittedInstallments totals installments still to be paid: items on open or overdue invoices.
+func (r *Repository) SumCommittedInstallments(ctx context.Context) (int64, error) {
+	var sum int64
+	err := r.db.WithContext(ctx).
+		Table("invoice_items").
+		Joins("JOIN invoices ON invoices.id = invoice_items.invoice_id").
+		Where("invoice_items.purchase_id IS NOT NULL AND invoices.status <> ?", "paid").
+		Select("COALESCE(SUM(invoice_items.amount_cents), 0)").
+		Scan(&sum).Error
+	if err != nil {
+		return 0, fmt.Errorf("sum committed installments: %w", err)
+	}
+	return sum, nil
+}
+
+// removeUnpaidInstallments deletes the purchase's items on unpaid invoices, except those in keep, and
+// returns every unpaid invoice the purchase had items on.
+func removeUnpaidInstallments(tx *gorm.DB, purchaseID uuid.UUID, keep []uuid.UUID) (map[uuid.UUID]bool, error) {
+	unpaid := tx.Model(&models.Invoice{}).Select("id").Where("status <> ?", "paid")
+	var invoiceIDs []uuid.UUID
+	err := tx.Model(&models.InvoiceItem{}).
+		Where("purchase_id = ? AND invoice_id IN (?)", purchaseID, unpaid).
+		Distinct().
+		Pluck("invoice_id", &invoiceIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("list installment invoices: %w", err)
+	}
+	touched := make(map[uuid.UUID]bool, len(invoiceIDs))
+	for _, id := range invoiceIDs {
+		touched[id] = true
+	}
+	if len(invoiceIDs) > 0 {
+		stale := func() *gorm.DB {
+			q := tx.Model(&models.InvoiceItem{}).Where("purchase_id = ? AND invoice_id IN ?", purchaseID, invoiceIDs)
+			if len(keep) > 0 {
+				q = q.Where("id NOT IN ?", keep)
+			}
+			return q
+		}
+		if err := deleteAttachments(tx, models.FinanceAttachmentInvoiceItem, stale().Select("id")); err != nil {
+			return nil, err
+		}
+		if err := deleteSplits(tx, models.FinanceSplitInvoiceItem, stale().Select("id")); err != nil {
+			return nil, err
+		}
+		if err := stale().Delete(&models.InvoiceItem{}).Error; err != nil {
+			return nil, fmt.Errorf("delete installments: %w", err)
+		}
+	}
+	return touched, nil
+}
+
+func recalcInvoices(ctx context.Context, tx *gorm.DB, ids map[uuid.UUID]bool) error {
+	inner := &Repository{db: tx}
+	for id := range ids {
+		if err := inner.RecalcInvoiceTotal(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+type ReceivableFilter struct {
+	Status    string
+	ContactID *uuid.UUID
+	ProjectID *uuid.UUID
+}
+
+func (r *Repository) ListReceivables(ctx context.Context, f ReceivableFilter) ([]models.Receivable, error) {
+	var out []models.Receivable
+	q := r.db.WithContext(ctx).Order("due_date DESC, created_at DESC")
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.ContactID != nil {
+		q = q.Where("contact_id = ?", *f.ContactID)
+	}
+	if f.ProjectID != nil {
+		q = q.Where("project_id = ?", *f.ProjectID)
+	}
+	if err := q.Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("list receivables: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) FindReceivable(ctx context.Context, id uuid.UUID) (*models.Receivable, error) {
+	var row models.Receivable
+	err := r.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Where("id = ?", id).
+		First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find receivable: %w", err)
+	}
+	return &row, nil
+}
+
+// CreateReceivable inserts the receivable with its items.
+func (r *Repository) CreateReceivable(ctx context.Context, row *models.Receivable) error {
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	prepareReceivableItems(row)
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		return fmt.Errorf("create receivable: %w", err)
+	}
+	return nil
+}
+
+// SaveReceivable updates the receivable and replaces its items with row.Items.
+func (r *Repository) SaveReceivable(ctx context.Context, row *models.Receivable) error {
+	prepareReceivableItems(row)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Items").Save(row).Error; err != nil {
+			return fmt.Errorf("save receivable: %w", err)
+		}
+		if err := tx.Delete(&models.ReceivableItem{}, "receivable_id = ?", row.ID).Error; err != nil {
+			return fmt.Errorf("delete receivable items: %w", err)
+		}
+		if len(row.Items) > 0 {
+			if err := tx.Create(&row.Items).Error; err != nil {
+				return fmt.Errorf("create receivable items: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func prepareReceivableItems(row *models.Receivable) {
+	for i := range row.Items {
+		row.Items[i].ID = uuid.New()
+		row.Items[i].ReceivableID = row.ID
+		row.Items[i].Position = i
+	}
+}
+
+func (r *Repository) DeleteReceivable(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.Receivable{}, "id = ?", id)
+		if res.Error != nil {
+			return fmt.Errorf("delete receivable: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Delete(&models.ReceivableItem{}, "receivable_id = ?", id).Error; err != nil {
+			return fmt.Errorf("delete receivable items: %w", err)
+		}
+		return nil
+	})
+}
+
+// IssueReceivable moves a draft to sent, assigning the next number of the issue year ("2026-0001").
+// The table is locked for the numbering so concurrent issues cannot take the same number. It returns
+// gorm.ErrRecordNotFound when the receivable is missing or no longer a draft.
+func (r *Repository) IssueReceivable(ctx context.Context, id uuid.UUID, issueDate time.Time) (string, error) {
+	var number string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE receivables IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+			return fmt.Errorf("lock receivables: %w", err)
+		}
+		// The sequence is compared as a number: as text "2026-10000" sorts before "2026-9999".
+		prefix := strconv.Itoa(issueDate.Year()) + "-"
+		var seq int
+		err := tx.Model(&models.Receivable{}).
+			Where("number ~ ?", "^"+prefix+"[0-9]+$").
+			Select("COALESCE(MAX(CAST(SUBSTRING(number FROM ?) AS integer)), 0)", len(prefix)+1).
+			Scan(&seq).Error
+		if err != nil {
+			return fmt.Errorf("last receivable number: %w", err)
+		}
+		number = fmt.Sprintf("%s%04d", prefix, seq+1)
+
+		res := tx.Model(&models.Receivable{}).
+			Where("id = ? AND status = ?", id, "draft").
+			Updates(map[string]any{"number": number, "issue_date": issueDate, "status": "sent"})
+		if res.Error != nil {
+			return fmt.Errorf("issue receivable: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return number, nil
+}
+
+func (r *Repository) SetReceivablePDF(ctx context.Context, id uuid.UUID, mediaID *uuid.UUID) error {
+	err := r.db.WithContext(ctx).Model(&models.Receivable{}).
+		Where("id = ?", id).
+		Update("pdf_media_id", mediaID).Error
+	if err != nil {
+		return fmt.Errorf("set receivable pdf: %w", err)
+	}
+	return nil
+}
+
+// MarkReceivablePaid links the payment transaction to a sent receivable. It returns
+// gorm.ErrRecordNotFound when the receivable is no longer sent.
+func (r *Repository) MarkReceivablePaid(ctx context.Context, id, transactionID uuid.UUID, paidAt time.Time) error {
+	res := r.db.WithContext(ctx).Model(&models.Receivable{}).
+		Where("id = ? AND status = ?", id, "sent").
+		Updates(map[string]any{"status": "paid", "transaction_id": transactionID, "paid_at": paidAt})
+	if res.Error != nil {
+		return fmt.Errorf("mark receivable paid: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// VoidReceivable voids a draft or sent receivable. It returns gorm.ErrRecordNotFound when the
+// receivable is already paid or void.
+func (r *Repository) VoidReceivable(ctx context.Context, id uuid.UUID, voidedAt time.Time) error {
+	res := r.db.WithContext(ctx).Model(&models.Receivable{}).
+		Where("id = ? AND status IN ?", id, []string{"draft", "sent"}).
+		Updates(map[string]any{"status": "void", "voided_at": voidedAt})
+	if res.Error != nil {
+		return fmt.Errorf("void receivable: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// reopenReceivables puts receivables paid by the given transaction back to sent.
+func reopenReceivables(tx *gorm.DB, transactionID uuid.UUID) error {
+	err := tx.Model(&models.Receivable{}).
+		Where("transaction_id = ?", transactionID).
+		Updates(map[string]any{"status": "sent", "transaction_id": nil, "paid_at": nil}).Error
+	if err != nil {
+		return fmt.Errorf("reopen receivables: %w", err)
+	}
+	return nil
+}
+
+// SetReceivablePix stores the receivable's BR Code and its QR code image (nil until rendered).
+func (r *Repository) SetReceivablePix(ctx context.Context, id uuid.UUID, payload string, mediaID *uuid.UUID) error {
+	err := r.db.WithContext(ctx).Model(&models.Receivable{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"pix_payload": payload, "pix_media_id": mediaID}).Error
+	if err != nil {
+		return fmt.Errorf("set receivable pix: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,6 +20,13 @@ func New(db *gorm.DB) *Repository {
 	return &Repository{db: db}
 }
 
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *Repository) Transaction(ctx context.Context, fn func(*Repository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&Repository{db: tx})
+	})
+}
+
 func (r *Repository) ListIncomeSources(ctx context.Context, f IncomeSourceFilter) ([]models.IncomeSource, error) {
 	var out []models.IncomeSource
 	q := r.db.WithContext(ctx).Order("name ASC")
@@ -72,15 +80,18 @@ func (r *Repository) SaveIncomeSource(ctx context.Context, row *models.IncomeSou
 	return nil
 }
 
+// DeleteIncomeSource removes the income source and its pending expected occurrences.
 func (r *Repository) DeleteIncomeSource(ctx context.Context, id uuid.UUID) error {
-	res := r.db.WithContext(ctx).Delete(&models.IncomeSource{}, "id = ?", id)
-	if res.Error != nil {
-		return fmt.Errorf("delete income source: %w", res.Error)
-	}
-	if res.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
-	}
-	return nil
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.IncomeSource{}, "id = ?", id)
+		if res.Error != nil {
+			return fmt.Errorf("delete income source: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return (&Repository{db: tx}).DeletePendingExpected(ctx, "income", id)
+	})
 }
 
 func (r *Repository) ListExpenses(ctx context.Context, activeOnly bool) ([]models.Expense, error) {
@@ -124,28 +135,91 @@ func (r *Repository) SaveExpense(ctx context.Context, row *models.Expense) error
 	return nil
 }
 
+// DeleteExpense removes the expense and its pending expected occurrences.
 func (r *Repository) DeleteExpense(ctx context.Context, id uuid.UUID) error {
-	res := r.db.WithContext(ctx).Delete(&models.Expense{}, "id = ?", id)
-	if res.Error != nil {
-		return fmt.Errorf("delete expense: %w", res.Error)
-	}
-	if res.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
-	}
-	return nil
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.Expense{}, "id = ?", id)
+		if res.Error != nil {
+			return fmt.Errorf("delete expense: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return (&Repository{db: tx}).DeletePendingExpected(ctx, "expense", id)
+	})
 }
 
+// TransactionFilter narrows transactions; From and To bound the date to [From, To). Query matches
+// description or notes case-insensitively.
 type TransactionFilter struct {
-	Type      string
-	Year      int
-	Month     int
-	ProjectID *uuid.UUID
-	ContactID *uuid.UUID
+	Type           string
+	Year           int
+	Month          int
+	From           *time.Time
+	To             *time.Time
+	Query          string
+	MinAmountCents *int64
+	MaxAmountCents *int64
+	Currency       string
+	ProjectID      *uuid.UUID
+	ContactID      *uuid.UUID
+	AccountID      *uuid.UUID
+	InvoiceID      *uuid.UUID
+	IncomeSourceID *uuid.UUID
+	ExpenseID      *uuid.UUID
+	GoalID         *uuid.UUID
+}
+
+// TransactionSort orders a search by Column (date, amount_cents or created_at); id breaks ties so
+// cursors stay stable.
+type TransactionSort struct {
+	Column string
+	Desc   bool
+}
+
+// TransactionCursor holds the sort value and id of the last row of the previous page.
+type TransactionCursor struct {
+	Value any
+	ID    uuid.UUID
 }
 
 func (r *Repository) ListTransactions(ctx context.Context, f TransactionFilter) ([]models.Transaction, error) {
 	var out []models.Transaction
-	q := r.db.WithContext(ctx).Order("date DESC, created_at DESC")
+	if err := r.transactionQuery(ctx, f).Order("date DESC, created_at DESC").Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("list transactions: %w", err)
+	}
+	return out, nil
+}
+
+// SearchTransactions returns up to limit transactions after the cursor in the given order, and how
+// many match the filter in total.
+func (r *Repository) SearchTransactions(ctx context.Context, f TransactionFilter, sort TransactionSort, after *TransactionCursor, limit int) ([]models.Transaction, int64, error) {
+	switch sort.Column {
+	case "date", "amount_cents", "created_at":
+	default:
+		return nil, 0, fmt.Errorf("search transactions: unknown sort column %q", sort.Column)
+	}
+	var total int64
+	if err := r.transactionQuery(ctx, f).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count transactions: %w", err)
+	}
+	dir, cmp := "ASC", ">"
+	if sort.Desc {
+		dir, cmp = "DESC", "<"
+	}
+	q := r.transactionQuery(ctx, f).Order(sort.Column + " " + dir + ", id " + dir)
+	if after != nil {
+		q = q.Where("("+sort.Column+", id) "+cmp+" (?, ?)", after.Value, after.ID)
+	}
+	var out []models.Transaction
+	if err := q.Limit(limit).Find(&out).Error; err != nil {
+		return nil, 0, fmt.Errorf("search transactions: %w", err)
+	}
+	return out, total, nil
+}
+
+func (r *Repository) transactionQuery(ctx context.Context, f TransactionFilter) *gorm.DB {
+	q := r.db.WithContext(ctx).Model(&models.Transaction{})
 	if f.Type != "" {
 		q = q.Where("type = ?", f.Type)
 	}
@@ -154,16 +228,54 @@ func (r *Repository) ListTransactions(ctx context.Context, f TransactionFilter)
 		end := start.AddDate(0, 1, 0)
 		q = q.Where("date >= ? AND date < ?", start, end)
 	}
+	if f.From != nil {
+		q = q.Where("date >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("date < ?", *f.To)
+	}
 	if f.ProjectID != nil {
 		q = q.Where("project_id = ?", *f.ProjectID)
 	}
 	if f.ContactID != nil {
 		q = q.Where("contact_id = ?", *f.ContactID)
 	}
-	if err := q.Find(&out).Error; err != nil {
-		return nil, fmt.Errorf("list transactions: %w", err)
+	if f.AccountID != nil {
+		q = q.Where("account_id = ? OR to_account_id = ?", *f.AccountID, *f.AccountID)
 	}
-	return out, nil
+	if f.InvoiceID != nil {
+		q = q.Where("invoice_id = ?", *f.InvoiceID)
+	}
+	if f.IncomeSourceID != nil {
+		q = q.Where("income_source_id = ?", *f.IncomeSourceID)
+	}
+	if f.ExpenseID != nil {
+		q = q.Where("expense_id = ?", *f.ExpenseID)
+	}
+	if f.GoalID != nil {
+		q = q.Where("goal_id = ?", *f.GoalID)
+	}
+	if f.Currency != "" {
+		q = q.Where("currency = ?", f.Currency)
+	}
+	if f.MinAmountCents != nil {
+		q = q.Where("amount_cents >= ?", *f.MinAmountCents)
+	}
+	if f.MaxAmountCents != nil {
+		q = q.Where("amount_cents <= ?", *f.MaxAmountCents)
+	}
+	if term := strings.TrimSpace(f.Query); term != "" {
+		like := "%" + escapeLike(term) + "%"
+		q = q.Where("description ILIKE ? OR notes ILIKE ?", like, like)
+	}
+	return q
+}
+
+func escapeLike(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `%`, `\%`)
+	s = strings.ReplaceAll(s, `_`, `\_`)
+	return s
 }
 
 func (r *Repository) FindTransaction(ctx context.Context, id uuid.UUID) (*models.Transaction, error) {
@@ -195,48 +307,60 @@ func (r *Repository) SaveTransaction(ctx context.Context, row *models.Transactio
 	return nil
 }
 
+// DeleteTransaction removes the transaction and reopens any expected occurrence it had confirmed and
+// any receivable it paid.
 func (r *Repository) DeleteTransaction(ctx context.Context, id uuid.UUID) error {
-	res := r.db.WithContext(ctx).Delete(&models.Transaction{}, "id = ?", id)
-	if res.Error != nil {
-		return fmt.Errorf("delete transaction: %w", res.Error)
-	}
-	if res.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
-	}
-	return nil
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.Transaction{}, "id = ?", id)
+		if res.Error != nil {
+			return fmt.Errorf("delete transaction: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := deleteAttachments(tx, models.FinanceAttachmentTransaction, []uuid.UUID{id}); err != nil {
+			return err
+		}
+		if err := deleteSplits(tx, models.FinanceSplitTransaction, []uuid.UUID{id}); err != nil {
+			return err
+		}
+		err := tx.Model(&models.ExpectedTransaction{}).
+			Where("transaction_id = ?", id).
+			Updates(map[string]any{
+				"status":              "pending",
+				"transaction_id":      nil,
+				"actual_amount_cents": nil,
+				"actual_date":         nil,
+				"resolved_at":         nil,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("reopen expected transactions: %w", err)
+		}
+		return reopenReceivables(tx, id)
+	})
 }
 
-type MonthTotals struct {
-	IncomeCents  int64
-	ExpenseCents int64
+// AmountRow is a per-day, per-currency subtotal. Key holds the grouping value (transaction type or category).
+type AmountRow struct {
+	Key         string
+	Currency    string
+	Date        time.Time
+	AmountCents int64
 }
 
-func (r *Repository) SumTransactionsByMonth(ctx context.Context, year, month int) (MonthTotals, error) {
+func (r *Repository) SumTransactionsByMonth(ctx context.Context, year, month int) ([]AmountRow, error) {
 	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 	end := start.AddDate(0, 1, 0)
-	var totals MonthTotals
-	type row struct {
-		Type        string
-		AmountCents int64
-	}
-	var rows []row
+	var rows []AmountRow
 	err := r.db.WithContext(ctx).Model(&models.Transaction{}).
-		Select("type, COALESCE(SUM(amount_cents), 0) as amount_cents").
+		Select("type as key, currency, date, COALESCE(SUM(amount_cents), 0) as amount_cents").
 		Where("date >= ? AND date < ?", start, end).
-		Group("type").
+		Group("type, currency, date").
 		Scan(&rows).Error
 	if err != nil {
-		return totals, fmt.Errorf("sum transactions: %w", err)
-	}
-	for _, rw := range rows {
-		switch rw.Type {
-		case "income":
-			totals.IncomeCents = rw.AmountCents
-		case "expense":
-			totals.ExpenseCents = rw.AmountCents
-		}
+		return nil, fmt.Errorf("sum transactions: %w", err)
 	}
-	return totals, nil
+	return rows, nil
 }
 
 func (r *Repository) ListInvoices(ctx context.Context, status string) ([]models.Invoice, error) {
@@ -287,6 +411,16 @@ func (r *Repository) SaveInvoice(ctx context.Context, row *models.Invoice) error
 
 func (r *Repository) DeleteInvoice(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		items := tx.Model(&models.InvoiceItem{}).Select("id").Where("invoice_id = ?", id)
+		if err := deleteAttachments(tx, models.FinanceAttachmentInvoiceItem, items); err != nil {
+			return err
+		}
+		if err := deleteSplits(tx, models.FinanceSplitInvoiceItem, items); err != nil {
+			return err
+		}
+		if err := deleteAttachments(tx, models.FinanceAttachmentInvoice, []uuid.UUID{id}); err != nil {
+			return err
+		}
 		if err := tx.Delete(&models.InvoiceItem{}, "invoice_id = ?", id).Error; err != nil {
 			return fmt.Errorf("delete invoice items: %w", err)
 		}
@@ -312,27 +446,50 @@ func (r *Repository) CreateInvoiceItem(ctx context.Context, row *models.InvoiceI
 }
 
 func (r *Repository) DeleteInvoiceItem(ctx context.Context, invoiceID, itemID uuid.UUID) error {
-	res := r.db.WithContext(ctx).Delete(&models.InvoiceItem{}, "id = ? AND invoice_id = ?", itemID, invoiceID)
-	if res.Error != nil {
-		return fmt.Errorf("delete invoice item: %w", res.Error)
-	}
-	if res.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
-	}
-	return nil
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.InvoiceItem{}, "id = ? AND invoice_id = ?", itemID, invoiceID)
+		if res.Error != nil {
+			return fmt.Errorf("delete invoice item: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := deleteAttachments(tx, models.FinanceAttachmentInvoiceItem, []uuid.UUID{itemID}); err != nil {
+			return err
+		}
+		return deleteSplits(tx, models.FinanceSplitInvoiceItem, []uuid.UUID{itemID})
+	})
 }
 
+// RecalcInvoiceTotal sums the invoice's items into its total and moves its status to match, so a paid
+// invoice that gains items is open again.
 func (r *Repository) RecalcInvoiceTotal(ctx context.Context, invoiceID uuid.UUID) error {
-	var sum int64
-	if err := r.db.WithContext(ctx).Model(&models.InvoiceItem{}).
-		Where("invoice_id = ?", invoiceID).
-		Select("COALESCE(SUM(amount_cents), 0)").
-		Scan(&sum).Error; err != nil {
-		return fmt.Errorf("sum invoice items: %w", err)
-	}
-	return r.db.WithContext(ctx).Model(&models.Invoice{}).
-		Where("id = ?", invoiceID).
-		Update("total_cents", sum).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var sum int64
+		if err := tx.Model(&models.InvoiceItem{}).
+			Where("invoice_id = ?", invoiceID).
+			Select("COALESCE(SUM(amount_cents), 0)").
+			Scan(&sum).Error; err != nil {
+			return fmt.Errorf("sum invoice items: %w", err)
+		}
+		var inv models.Invoice
+		if err := tx.Select("id", "paid_cents", "due_date").Where("id = ?", invoiceID).First(&inv).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return fmt.Errorf("find invoice: %w", err)
+		}
+		err := tx.Model(&models.Invoice{}).
+			Where("id = ?", invoiceID).
+			Updates(map[string]any{
+				"total_cents": sum,
+				"status":      InvoiceStatusFor(sum, inv.PaidCents, inv.DueDate, time.Now().UTC()),
+			}).Error
+		if err != nil {
+			return fmt.Errorf("update invoice total: %w", err)
+		}
+		return nil
+	})
 }
 
 func (r *Repository) ListInvoicesDueBetween(ctx context.Context, from, to time.Time) ([]models.Invoice, error) {
@@ -348,23 +505,10 @@ func (r *Repository) ListInvoicesDueBetween(ctx context.Context, from, to time.T
 	return out, nil
 }
 
-func (r *Repository) ListExpensesDueBetween(ctx context.Context, from, to time.Time) ([]models.Expense, error) {
-	var out []models.Expense
-	err := r.db.WithContext(ctx).
-		Where("active = ?", true).
-		Where("frequency = ?", "one_time").
-		Where("due_date IS NOT NULL AND due_date >= ? AND due_date <= ?", from, to).
-		Order("due_date ASC").
-		Find(&out).Error
-	if err != nil {
-		return nil, fmt.Errorf("list expenses due: %w", err)
-	}
-	return out, nil
-}
-
+// CountOpenInvoices counts the invoices still to be paid, overdue ones included.
 func (r *Repository) CountOpenInvoices(ctx context.Context) (int64, error) {
 	var n int64
-	err := r.db.WithContext(ctx).Model(&models.Invoice{}).Where("status = ?", "open").Count(&n).Error
+	err := r.db.WithContext(ctx).Model(&models.Invoice{}).Where("status IN ?", []string{"open", "overdue"}).Count(&n).Error
 	return n, err
 }
 
@@ -423,30 +567,27 @@ func (r *Repository) DeleteBudget(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (r *Repository) SumExpensesByCategory(ctx context.Context, year, month int) (map[string]int64, error) {
+// expenseCategoryExpr is the category an expense transaction counts under: its own, else its
+// expense's, else "other". Queries using it must LEFT JOIN expenses as e.
+const expenseCategoryExpr = "COALESCE(NULLIF(transactions.category, ''), e.category, 'other')"
+
+func (r *Repository) SumExpensesByCategory(ctx context.Context, year, month int) ([]AmountRow, error) {
 	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 	end := start.AddDate(0, 1, 0)
-	type row struct {
-		Category    string
-		AmountCents int64
-	}
-	var rows []row
+	var rows []AmountRow
 	err := r.db.WithContext(ctx).Model(&models.Transaction{}).
-		Select("COALESCE(e.category, 'other') as category, COALESCE(SUM(transactions.amount_cents), 0) as amount_cents").
+		Select(expenseCategoryExpr+" as key, transactions.currency as currency, transactions.date as date, COALESCE(SUM(transactions.amount_cents), 0) as amount_cents").
 		Joins("LEFT JOIN expenses e ON e.id = transactions.expense_id").
 		Where("transactions.type = ? AND transactions.date >= ? AND transactions.date < ?", "expense", start, end).
-		Group("e.category").
+		Group(expenseCategoryExpr + ", transactions.currency, transactions.date").
 		Scan(&rows).Error
 	if err != nil {
 		return nil, fmt.Errorf("sum expenses by category: %w", err)
 	}
-	out := make(map[string]int64, len(rows))
-	for _, rw := range rows {
-		cat := rw.Category
-		if cat == "" {
-			cat = "other"
+	for i := range rows {
+		if rows[i].Key == "" {
+			rows[i].Key = "other"
 		}
-		out[cat] = rw.AmountCents
 	}
-	return out, nil
+	return rows, nil
 }
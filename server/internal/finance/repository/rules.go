@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+func (r *Repository) ListRules(ctx context.Context, activeOnly bool) ([]models.CategorizationRule, error) {
+	var out []models.CategorizationRule
+	q := r.db.WithContext(ctx).Order("priority ASC, created_at ASC")
+	if activeOnly {
+		q = q.Where("active = ?", true)
+	}
+	if err := q.Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("list rules: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) FindRule(ctx context.Context, id uuid.UUID) (*models.CategorizationRule, error) {
+	var row models.CategorizationRule
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find rule: %w", err)
+	}
+	return &row, nil
+}
+
+func (r *Repository) CreateRule(ctx context.Context, row *models.CategorizationRule) error {
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		return fmt.Errorf("create rule: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) SaveRule(ctx context.Context, row *models.CategorizationRule) error {
+	if err := r.db.WithContext(ctx).Save(row).Error; err != nil {
+		return fmt.Errorf("save rule: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	res := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.CategorizationRule{})
+	if res.Error != nil {
+		return fmt.Errorf("delete rule: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SaveTransactions updates the given transactions in one database transaction.
+func (r *Repository) SaveTransactions(ctx context.Context, rows []models.Transaction) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range rows {
+			if err := tx.Save(&rows[i]).Error; err != nil {
+				return fmt.Errorf("save transaction: %w", err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+const financeSettingsID = "00000000-0000-0000-0000-000000000001"
+
+func (r *Repository) GetSettings(ctx context.Context) (*models.FinanceSettings, error) {
+	var row models.FinanceSettings
+	err := r.db.WithContext(ctx).Where("id = ?", financeSettingsID).First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("get finance settings: %w", err)
+	}
+	return &row, nil
+}
+
+func (r *Repository) EnsureSettings(ctx context.Context) (*models.FinanceSettings, error) {
+	row, err := r.GetSettings(ctx)
+	if err == nil {
+		return row, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	id, _ := uuid.Parse(financeSettingsID)
+	row = &models.FinanceSettings{
+		ID:                id,
+		ReportingCurrency: "BRL",
+	}
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		return nil, fmt.Errorf("create finance settings: %w", err)
+	}
+	return row, nil
+}
+
+func (r *Repository) SaveSettings(ctx context.Context, row *models.FinanceSettings) error {
+	if err := r.db.WithContext(ctx).Save(row).Error; err != nil {
+		return fmt.Errorf("save finance settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+func (r *Repository) ListSplits(ctx context.Context, ownerType string, ownerID uuid.UUID) ([]models.FinanceSplit, error) {
+	var out []models.FinanceSplit
+	err := r.db.WithContext(ctx).
+		Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).
+		Order("created_at ASC").
+		Find(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("list splits: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) ListContactSplits(ctx context.Context, contactID uuid.UUID) ([]models.FinanceSplit, error) {
+	var out []models.FinanceSplit
+	err := r.db.WithContext(ctx).
+		Where("contact_id = ?", contactID).
+		Order("date ASC, created_at ASC").
+		Find(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("list contact splits: %w", err)
+	}
+	return out, nil
+}
+
+// ReplaceSplits swaps the shares of a transaction or invoice item for rows; empty rows removes them.
+func (r *Repository) ReplaceSplits(ctx context.Context, ownerType string, ownerID uuid.UUID, rows []models.FinanceSplit) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deleteSplits(tx, ownerType, []uuid.UUID{ownerID}); err != nil {
+			return err
+		}
+		for i := range rows {
+			if rows[i].ID == uuid.Nil {
+				rows[i].ID = uuid.New()
+			}
+			rows[i].OwnerType, rows[i].OwnerID = ownerType, ownerID
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return fmt.Errorf("create splits: %w", err)
+		}
+		return nil
+	})
+}
+
+func deleteSplits(tx *gorm.DB, ownerType string, ownerIDs any) error {
+	err := tx.Where("owner_type = ? AND owner_id IN (?)", ownerType, ownerIDs).Delete(&models.FinanceSplit{}).Error
+	if err != nil {
+		return fmt.Errorf("delete %s splits: %w", ownerType, err)
+	}
+	return nil
+}
+
+func (r *Repository) ListSettlements(ctx context.Context, contactID uuid.UUID) ([]models.FinanceSettlement, error) {
+	var out []models.FinanceSettlement
+	err := r.db.WithContext(ctx).
+		Where("contact_id = ?", contactID).
+		Order("date ASC, created_at ASC").
+		Find(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("list settlements: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) CreateSettlement(ctx context.Context, row *models.FinanceSettlement) error {
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		return fmt.Errorf("create settlement: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) DeleteSettlement(ctx context.Context, id uuid.UUID) error {
+	res := r.db.WithContext(ctx).Delete(&models.FinanceSettlement{}, "id = ?", id)
+	if res.Error != nil {
+		return fmt.Errorf("delete settlement: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *Repository) FindSettlement(ctx context.Context, id uuid.UUID) (*models.FinanceSettlement, error) {
+	var row models.FinanceSettlement
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find settlement: %w", err)
+	}
+	return &row, nil
+}
+
+// BalanceRow is what a contact owes us in one currency (negative when we owe them).
+type BalanceRow struct {
+	ContactID   uuid.UUID
+	Currency    string
+	AmountCents int64
+}
+
+// SumBalances nets shares against settlements per contact and currency, leaving out settled ones.
+// A nil contactID sums every contact.
+func (r *Repository) SumBalances(ctx context.Context, contactID *uuid.UUID) ([]BalanceRow, error) {
+	where, args := "", []any{}
+	if contactID != nil {
+		where, args = "WHERE contact_id = ?", []any{*contactID, *contactID}
+	}
+	var out []BalanceRow
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT contact_id, currency, SUM(amount_cents) AS amount_cents FROM (
+			SELECT contact_id, currency, amount_cents FROM finance_splits `+where+`
+			UNION ALL
+			SELECT contact_id, currency, -amount_cents FROM finance_settlements `+where+`
+		) AS entries
+		GROUP BY contact_id, currency
+		HAVING SUM(amount_cents) <> 0
+		ORDER BY contact_id, currency`, args...).
+		Scan(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("sum balances: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+// ChargeRow is an outgoing payment, either an expense transaction or a card invoice item. Invoice
+// items carry no currency of their own.
+type ChargeRow struct {
+	Source      string
+	ID          uuid.UUID
+	Description string
+	AmountCents int64
+	Currency    string
+	Date        time.Time
+	Category    string
+	ExpenseID   *uuid.UUID
+}
+
+// ListCharges returns charges dated on or after since, oldest first. Invoice payments, installment
+// purchases and refunds are left out since they are not subscriptions.
+func (r *Repository) ListCharges(ctx context.Context, since time.Time) ([]ChargeRow, error) {
+	var out []ChargeRow
+	err := r.db.WithContext(ctx).Raw(`
+SELECT 'transaction' AS source, id, description, amount_cents, currency, date, category, expense_id
+FROM transactions
+WHERE type = 'expense' AND invoice_id IS NULL AND amount_cents > 0 AND date >= ?
+UNION ALL
+SELECT 'invoice_item' AS source, id, description, amount_cents, '' AS currency, date, category, NULL AS expense_id
+FROM invoice_items
+WHERE purchase_id IS NULL AND COALESCE(installment, '') = '' AND amount_cents > 0 AND date >= ?
+ORDER BY date ASC, id ASC`, since, since).Scan(&out).Error
+	if err != nil {
+		return nil, fmt.Errorf("list charges: %w", err)
+	}
+	return out, nil
+}
+
+// LinkTransactionsToExpense sets expense_id on the given transactions that are not linked yet.
+func (r *Repository) LinkTransactionsToExpense(ctx context.Context, ids []uuid.UUID, expenseID uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	res := r.db.WithContext(ctx).Model(&models.Transaction{}).
+		Where("id IN ? AND expense_id IS NULL", ids).
+		Update("expense_id", expenseID)
+	if res.Error != nil {
+		return 0, fmt.Errorf("link transactions to expense: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
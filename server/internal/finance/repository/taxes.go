@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultTaxTables are the official tables seeded on every start; existing rows are left as edited.
+var defaultTaxTables = []struct {
+	regime, annex, validFrom string
+	brackets                 []models.TaxBracket
+	fixedCents               int64
+	notes                    string
+	reduction                models.TaxReduction
+}{
+	{"carne_leao", "", "2024-02-01", []models.TaxBracket{
+		{UpToCents: 225920},
+		{UpToCents: 282665, Rate: 7.5, DeductionCents: 16944},
+		{UpToCents: 375105, Rate: 15, DeductionCents: 38144},
+		{UpToCents: 466468, Rate: 22.5, DeductionCents: 66277},
+		{Rate: 27.5, DeductionCents: 89600},
+	}, 0, "Tabela progressiva mensal (Lei 14.848/2024)", models.TaxReduction{}},
+	{"carne_leao", "", "2025-05-01", []models.TaxBracket{
+		{UpToCents: 242880},
+		{UpToCents: 282665, Rate: 7.5, DeductionCents: 18216},
+		{UpToCents: 375105, Rate: 15, DeductionCents: 39416},
+		{UpToCents: 466468, Rate: 22.5, DeductionCents: 67549},
+		{Rate: 27.5, DeductionCents: 90873},
+	}, 0, "Tabela progressiva mensal (Lei 15.191/2025)", models.TaxReduction{}},
+	{"carne_leao", "", "2026-01-01", []models.TaxBracket{
+		{UpToCents: 242880},
+		{UpToCents: 282665, Rate: 7.5, DeductionCents: 18216},
+		{UpToCents: 375105, Rate: 15, DeductionCents: 39416},
+		{UpToCents: 466468, Rate: 22.5, DeductionCents: 67549},
+		{Rate: 27.5, DeductionCents: 90873},
+	}, 0, "Tabela progressiva mensal com redução (Lei 15.270/2025)", models.TaxReduction{
+		ExemptUpToCents: 500000, PhaseOutUpToCents: 735000, BaseCents: 97862, Rate: 13.3145,
+	}},
+	{"simples", "III", "2018-01-01", []models.TaxBracket{
+		{UpToCents: 18000000, Rate: 6},
+		{UpToCents: 36000000, Rate: 11.2, DeductionCents: 936000},
+		{UpToCents: 72000000, Rate: 13.5, DeductionCents: 1764000},
+		{UpToCents: 180000000, Rate: 16, DeductionCents: 3564000},
+		{UpToCents: 360000000, Rate: 21, DeductionCents: 12564000},
+		{UpToCents: 480000000, Rate: 33, DeductionCents: 64800000},
+	}, 0, "Anexo III (LC 123/2006, LC 155/2016)", models.TaxReduction{}},
+	{"simples", "V", "2018-01-01", []models.TaxBracket{
+		{UpToCents: 18000000, Rate: 15.5},
+		{UpToCents: 36000000, Rate: 18, DeductionCents: 450000},
+		{UpToCents: 72000000, Rate: 19.5, DeductionCents: 990000},
+		{UpToCents: 180000000, Rate: 20.5, DeductionCents: 1710000},
+		{UpToCents: 360000000, Rate: 23, DeductionCents: 6210000},
+		{UpToCents: 480000000, Rate: 30.5, DeductionCents: 54000000},
+	}, 0, "Anexo V (LC 123/2006, LC 155/2016)", models.TaxReduction{}},
+	{"mei", "", "2024-01-01", nil, 7560, "Serviços: INSS 5% do salário mínimo + ISS R$ 5,00", models.TaxReduction{}},
+	{"mei", "", "2025-01-01", nil, 8090, "Serviços: INSS 5% do salário mínimo + ISS R$ 5,00", models.TaxReduction{}},
+	{"mei", "", "2026-01-01", nil, 8605, "Serviços: INSS 5% do salário mínimo + ISS R$ 5,00", models.TaxReduction{}},
+}
+
+// EnsureTaxTables seeds the default tax tables. It is safe to run repeatedly.
+func (r *Repository) EnsureTaxTables(ctx context.Context) error {
+	rows := make([]models.TaxTable, 0, len(defaultTaxTables))
+	for _, d := range defaultTaxTables {
+		validFrom, err := time.Parse("2006-01-02", d.validFrom)
+		if err != nil {
+			return fmt.Errorf("parse tax table date: %w", err)
+		}
+		brackets := d.brackets
+		if brackets == nil {
+			brackets = []models.TaxBracket{}
+		}
+		raw, err := json.Marshal(brackets)
+		if err != nil {
+			return fmt.Errorf("encode tax brackets: %w", err)
+		}
+		rows = append(rows, models.TaxTable{
+			ID:         uuid.New(),
+			Regime:     d.regime,
+			Annex:      d.annex,
+			ValidFrom:  validFrom,
+			Brackets:   datatypes.JSON(raw),
+			FixedCents: d.fixedCents,
+			Reduction:  d.reduction,
+			Notes:      d.notes,
+		})
+	}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "regime"}, {Name: "annex"}, {Name: "valid_from"}},
+		DoNothing: true,
+	}).Create(&rows).Error
+	if err != nil {
+		return fmt.Errorf("seed tax tables: %w", err)
+	}
+	return nil
+}
+
+// ListTaxTables returns the tables of a regime (all regimes when empty), oldest first.
+func (r *Repository) ListTaxTables(ctx context.Context, regime string) ([]models.TaxTable, error) {
+	var out []models.TaxTable
+	q := r.db.WithContext(ctx).Order("regime ASC, annex ASC, valid_from ASC")
+	if regime != "" {
+		q = q.Where("regime = ?", regime)
+	}
+	if err := q.Find(&out).Error; err != nil {
+		return nil, fmt.Errorf("list tax tables: %w", err)
+	}
+	return out, nil
+}
+
+func (r *Repository) FindTaxTable(ctx context.Context, id uuid.UUID) (*models.TaxTable, error) {
+	var row models.TaxTable
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("find tax table: %w", err)
+	}
+	return &row, nil
+}
+
+func (r *Repository) CreateTaxTable(ctx context.Context, row *models.TaxTable) error {
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		return fmt.Errorf("create tax table: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) SaveTaxTable(ctx context.Context, row *models.TaxTable) error {
+	if err := r.db.WithContext(ctx).Save(row).Error; err != nil {
+		return fmt.Errorf("save tax table: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) DeleteTaxTable(ctx context.Context, id uuid.UUID) error {
+	res := r.db.WithContext(ctx).Delete(&models.TaxTable{}, "id = ?", id)
+	if res.Error != nil {
+		return fmt.Errorf("delete tax table: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+type CreateAccountInput struct {
+	Name                string
+	Type                string
+	Currency            string
+	OpeningBalanceCents int64
+	OpeningDate         *time.Time
+	Notes               string
+}
+
+type UpdateAccountInput struct {
+	Name                *string
+	Type                *string
+	Currency            *string
+	OpeningBalanceCents *int64
+	OpeningDate         *time.Time
+	Archived            *bool
+	Notes               *string
+}
+
+// AccountWithBalance is an account with its current balance in the account's own currency.
+type AccountWithBalance struct {
+	models.Account
+	BalanceCents int64 `json:"balanceCents"`
+}
+
+// AccountLedgerEntry is a transaction as seen from one account: SignedCents is the change to the
+// account's balance and BalanceCents the running balance after it.
+type AccountLedgerEntry struct {
+	models.Transaction
+	SignedCents  int64 `json:"signedCents"`
+	BalanceCents int64 `json:"balanceCents"`
+}
+
+type AccountLedger struct {
+	Account           models.Account       `json:"account"`
+	From              *time.Time           `json:"from,omitempty"`
+	To                *time.Time           `json:"to,omitempty"`
+	StartBalanceCents int64                `json:"startBalanceCents"`
+	EndBalanceCents   int64                `json:"endBalanceCents"`
+	Entries           []AccountLedgerEntry `json:"entries"`
+}
+
+func (s *Service) ListAccounts(ctx context.Context, includeArchived bool) ([]AccountWithBalance, error) {
+	rows, err := s.repo.ListAccounts(ctx, includeArchived)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load accounts.", err)
+	}
+	sums, err := s.repo.SumAccountMovements(ctx, nil)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to compute balances.", err)
+	}
+	out := make([]AccountWithBalance, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, AccountWithBalance{Account: row, BalanceCents: row.OpeningBalanceCents + sums[row.ID]})
+	}
+	return out, nil
+}
+
+func (s *Service) GetAccount(ctx context.Context, id uuid.UUID) (*AccountWithBalance, error) {
+	row, err := s.findAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sums, err := s.repo.SumAccountMovements(ctx, nil)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to compute balances.", err)
+	}
+	return &AccountWithBalance{Account: *row, BalanceCents: row.OpeningBalanceCents + sums[row.ID]}, nil
+}
+
+func (s *Service) CreateAccount(ctx context.Context, in CreateAccountInput) (*AccountWithBalance, error) {
+	opening := dateOnly(time.Now().UTC())
+	if in.OpeningDate != nil && !in.OpeningDate.IsZero() {
+		opening = dateOnly(*in.OpeningDate)
+	}
+	row := &models.Account{
+		Name:                strings.TrimSpace(in.Name),
+		Type:                strings.TrimSpace(strings.ToLower(in.Type)),
+		Currency:            normalizeCurrency(in.Currency),
+		OpeningBalanceCents: in.OpeningBalanceCents,
+		OpeningDate:         opening,
+		Notes:               strings.TrimSpace(in.Notes),
+	}
+	if row.Type == "" {
+		row.Type = "checking"
+	}
+	if err := validateAccount(row); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateAccount(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to create account.", err)
+	}
+	return &AccountWithBalance{Account: *row, BalanceCents: row.OpeningBalanceCents}, nil
+}
+
+func (s *Service) UpdateAccount(ctx context.Context, id uuid.UUID, in UpdateAccountInput) (*AccountWithBalance, error) {
+	row, err := s.findAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if in.Name != nil {
+		row.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Type != nil {
+		row.Type = strings.TrimSpace(strings.ToLower(*in.Type))
+	}
+	if in.Currency != nil {
+		row.Currency = normalizeCurrency(*in.Currency)
+	}
+	if in.OpeningBalanceCents != nil {
+		row.OpeningBalanceCents = *in.OpeningBalanceCents
+	}
+	if in.OpeningDate != nil && !in.OpeningDate.IsZero() {
+		row.OpeningDate = dateOnly(*in.OpeningDate)
+	}
+	if in.Archived != nil {
+		row.Archived = *in.Archived
+	}
+	if in.Notes != nil {
+		row.Notes = strings.TrimSpace(*in.Notes)
+	}
+	if err := validateAccount(row); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveAccount(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update account.", err)
+	}
+	return s.GetAccount(ctx, id)
+}
+
+// DeleteAccount removes an account nothing points at; accounts with history should be archived.
+func (s *Service) DeleteAccount(ctx context.Context, id uuid.UUID) error {
+	n, err := s.repo.CountAccountTransactions(ctx, id)
+	if err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to delete account.", err)
+	}
+	if n > 0 {
+		return apperrors.Invalid(apperrors.CodeInternal, "Account has transactions; archive it instead.")
+	}
+	if err := s.repo.DeleteAccount(ctx, id, dateOnly(time.Now().UTC())); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound(apperrors.CodeInternal, "Account not found.")
+		}
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to delete account.", err)
+	}
+	return nil
+}
+
+// AccountLedger lists the account's transactions with a running balance. Entries before from are
+// folded into the starting balance.
+func (s *Service) AccountLedger(ctx context.Context, id uuid.UUID, from, to *time.Time) (*AccountLedger, error) {
+	acc, err := s.findAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.ListAccountTransactions(ctx, acc, to)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load transactions.", err)
+	}
+	entries, start, end := runningBalance(acc, rows, from)
+	return &AccountLedger{
+		Account:           *acc,
+		From:              from,
+		To:                to,
+		StartBalanceCents: start,
+		EndBalanceCents:   end,
+		Entries:           entries,
+	}, nil
+}
+
+// accountsBalance sums the balance of every active account up to asOf in the reporting currency.
+func (s *Service) accountsBalance(ctx context.Context, conv *converter, asOf time.Time) (int64, error) {
+	rows, err := s.repo.ListAccounts(ctx, false)
+	if err != nil {
+		return 0, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load accounts.", err)
+	}
+	sums, err := s.repo.SumAccountMovements(ctx, &asOf)
+	if err != nil {
+		return 0, apperrors.InternalCause(apperrors.CodeInternal, "Failed to compute balances.", err)
+	}
+	var total int64
+	for _, row := range rows {
+		if row.OpeningDate.After(asOf) {
+			continue
+		}
+		total += conv.convert(row.OpeningBalanceCents+sums[row.ID], row.Currency, asOf)
+	}
+	return total, nil
+}
+
+// validateTransactionAccounts checks the accounts a transaction points at. Transfers need two distinct
+// accounts and take the source account's currency; other types drop any transfer fields.
+func (s *Service) validateTransactionAccounts(ctx context.Context, row *models.Transaction) error {
+	if row.Type != "transfer" {
+		row.ToAccountID, row.ToAmountCents = nil, nil
+		if row.AccountID != nil {
+			if _, err := s.findAccount(ctx, *row.AccountID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if row.AccountID == nil || row.ToAccountID == nil {
+		return apperrors.Invalid(apperrors.CodeInternal, "Transfers need accountId and toAccountId.")
+	}
+	if *row.AccountID == *row.ToAccountID {
+		return apperrors.Invalid(apperrors.CodeInternal, "Transfer accounts must differ.")
+	}
+	if row.ToAmountCents != nil && *row.ToAmountCents <= 0 {
+		return apperrors.Invalid(apperrors.CodeInternal, "toAmountCents must be positive.")
+	}
+	src, err := s.findAccount(ctx, *row.AccountID)
+	if err != nil {
+		return err
+	}
+	dst, err := s.findAccount(ctx, *row.ToAccountID)
+	if err != nil {
+		return err
+	}
+	row.Currency = src.Currency
+	if row.ToAmountCents == nil && dst.Currency != src.Currency {
+		return apperrors.Invalid(apperrors.CodeInternal, "toAmountCents is required for transfers between currencies.")
+	}
+	return nil
+}
+
+func (s *Service) findAccount(ctx context.Context, id uuid.UUID) (*models.Account, error) {
+	row, err := s.repo.FindAccount(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound(apperrors.CodeInternal, "Account not found.")
+		}
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load account.", err)
+	}
+	return row, nil
+}
+
+func validateAccount(row *models.Account) error {
+	if row.Name == "" {
+		return apperrors.Invalid(apperrors.CodeInternal, "Name is required.")
+	}
+	switch row.Type {
+	case "checking", "savings", "cash", "credit_card", "investment":
+	default:
+		return apperrors.Invalid(apperrors.CodeInternal, "Type must be checking, savings, cash, credit_card or investment.")
+	}
+	return nil
+}
+
+// accountDelta is how a transaction changes the given account's balance.
+func accountDelta(accountID uuid.UUID, tx models.Transaction) int64 {
+	if tx.Type == "transfer" && tx.ToAccountID != nil && *tx.ToAccountID == accountID {
+		if tx.ToAmountCents != nil {
+			return *tx.ToAmountCents
+		}
+		return tx.AmountCents
+	}
+	if tx.Type == "income" {
+		return tx.AmountCents
+	}
+	return -tx.AmountCents
+}
+
+// runningBalance walks rows oldest first from the opening balance. Rows dated before from only move
+// the starting balance.
+func runningBalance(acc *models.Account, rows []models.Transaction, from *time.Time) ([]AccountLedgerEntry, int64, int64) {
+	balance := acc.OpeningBalanceCents
+	start := balance
+	entries := []AccountLedgerEntry{}
+	for _, tx := range rows {
+		delta := accountDelta(acc.ID, tx)
+		balance += delta
+		if from != nil && tx.Date.Before(dateOnly(*from)) {
+			start = balance
+			continue
+		}
+		entries = append(entries, AccountLedgerEntry{Transaction: tx, SignedCents: delta, BalanceCents: balance})
+	}
+	return entries, start, balance
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func TestRunningBalanceAppliesTransfersOnBothSides(t *testing.T) {
+	checking := &models.Account{ID: uuid.New(), OpeningBalanceCents: 10000, OpeningDate: day(2026, 1, 1)}
+	savings := uuid.New()
+	received := int64(2500)
+	rows := []models.Transaction{
+		{Type: "income", AmountCents: 5000, Date: day(2026, 1, 5), AccountID: &checking.ID},
+		{Type: "expense", AmountCents: 1200, Date: day(2026, 1, 10), AccountID: &checking.ID},
+		{Type: "transfer", AmountCents: 3000, Date: day(2026, 1, 15), AccountID: &checking.ID, ToAccountID: &savings},
+		{Type: "transfer", AmountCents: 9999, ToAmountCents: &received, Date: day(2026, 1, 20), AccountID: &savings, ToAccountID: &checking.ID},
+	}
+	from := day(2026, 1, 12)
+	entries, start, end := runningBalance(checking, rows, &from)
+	if start != 13800 || end != 13300 {
+		t.Fatalf("got start %d end %d", start, end)
+	}
+	if len(entries) != 2 || entries[0].SignedCents != -3000 || entries[1].SignedCents != 2500 || entries[1].BalanceCents != 13300 {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/repository"
+)
+
+const (
+	defaultAnalyticsMonths = 12
+	maxAnalyticsMonths     = 120
+	defaultTopMerchants    = 10
+	maxTopMerchants        = 50
+)
+
+type AnalyticsInput struct {
+	From         *time.Time
+	To           *time.Time
+	TopMerchants int
+}
+
+type AnalyticsTotals struct {
+	IncomeCents  int64 `json:"incomeCents"`
+	ExpenseCents int64 `json:"expenseCents"`
+	NetCents     int64 `json:"netCents"`
+}
+
+// AnalyticsDelta is the change against the same period a year earlier. Percentages are nil when the
+// earlier amount is zero.
+type AnalyticsDelta struct {
+	IncomeCents  int64    `json:"incomeCents"`
+	ExpenseCents int64    `json:"expenseCents"`
+	NetCents     int64    `json:"netCents"`
+	IncomePct    *float64 `json:"incomePct"`
+	ExpensePct   *float64 `json:"expensePct"`
+	NetPct       *float64 `json:"netPct"`
+}
+
+// AnalyticsBucket is one calendar month of the range, clipped to From and To.
+type AnalyticsBucket struct {
+	Month string    `json:"month"`
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	AnalyticsTotals
+	IncomeByCategory  map[string]int64 `json:"incomeByCategory"`
+	ExpenseByCategory map[string]int64 `json:"expenseByCategory"`
+	PreviousYear      AnalyticsTotals  `json:"previousYear"`
+	YearOverYear      AnalyticsDelta   `json:"yearOverYear"`
+}
+
+type MerchantTotal struct {
+	Merchant    string    `json:"merchant"`
+	AmountCents int64     `json:"amountCents"`
+	Count       int64     `json:"count"`
+	LastDate    time.Time `json:"lastDate"`
+}
+
+// FinanceAnalytics covers an arbitrary date range in the reporting currency. Averages are per month
+// of the range.
+type FinanceAnalytics struct {
+	ReportingCurrency  string            `json:"reportingCurrency"`
+	From               time.Time         `json:"from"`
+	To                 time.Time         `json:"to"`
+	Buckets            []AnalyticsBucket `json:"buckets"`
+	Totals             AnalyticsTotals   `json:"totals"`
+	PreviousYearTotals AnalyticsTotals   `json:"previousYearTotals"`
+	YearOverYear       AnalyticsDelta    `json:"yearOverYear"`
+	MonthlyAverage     AnalyticsTotals   `json:"monthlyAverage"`
+	TopMerchants       []MerchantTotal   `json:"topMerchants"`
+	MissingRates       []string          `json:"missingRates,omitempty"`
+}
+
+// Analytics aggregates income and expense between From and To (default the last 12 months) into
+// monthly buckets with a category breakdown and the same months of the previous year. Totals come
+// from grouped queries; amounts in other currencies are converted at each bucket's last day.
+func (s *Service) Analytics(ctx context.Context, in AnalyticsInput) (*FinanceAnalytics, error) {
+	to := dateOnly(time.Now().UTC())
+	if in.To != nil && !in.To.IsZero() {
+		to = dateOnly(*in.To)
+	}
+	from := time.Date(to.Year(), to.Month()-defaultAnalyticsMonths+1, 1, 0, 0, 0, 0, time.UTC)
+	if in.From != nil && !in.From.IsZero() {
+		from = dateOnly(*in.From)
+	}
+	if from.After(to) {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "from must not be after to.")
+	}
+	if months := dateMonthIndex(to) - dateMonthIndex(from) + 1; months > maxAnalyticsMonths {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Analytics range is limited to 120 months.")
+	}
+	top := in.TopMerchants
+	if top <= 0 {
+		top = defaultTopMerchants
+	}
+	if top > maxTopMerchants {
+		top = maxTopMerchants
+	}
+
+	current, err := s.repo.SumLedgerByMonth(ctx, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to compute analytics.", err)
+	}
+	previous, err := s.repo.SumLedgerByMonth(ctx, from.AddDate(-1, 0, 0), to.AddDate(-1, 0, 1))
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to compute analytics.", err)
+	}
+	merchants, err := s.repo.SumExpensesByMerchant(ctx, from, to.AddDate(0, 0, 1), top)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to compute merchant totals.", err)
+	}
+	conv, err := s.newConverter(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &FinanceAnalytics{
+		ReportingCurrency: conv.target,
+		From:              from,
+		To:                to,
+		Buckets:           analyticsBuckets(from, to, current, previous, conv.convert),
+		TopMerchants:      topMerchants(merchants, top, to, conv.convert),
+	}
+	for _, b := range out.Buckets {
+		out.Totals = out.Totals.add(b.AnalyticsTotals)
+		out.PreviousYearTotals = out.PreviousYearTotals.add(b.PreviousYear)
+	}
+	out.YearOverYear = analyticsDelta(out.Totals, out.PreviousYearTotals)
+	if n := int64(len(out.Buckets)); n > 0 {
+		out.MonthlyAverage = AnalyticsTotals{
+			IncomeCents:  out.Totals.IncomeCents / n,
+			ExpenseCents: out.Totals.ExpenseCents / n,
+			NetCents:     out.Totals.NetCents / n,
+		}
+	}
+	out.MissingRates = conv.missingCurrencies()
+	return out, nil
+}
+
+func (t AnalyticsTotals) add(o AnalyticsTotals) AnalyticsTotals {
+	return AnalyticsTotals{
+		IncomeCents:  t.IncomeCents + o.IncomeCents,
+		ExpenseCents: t.ExpenseCents + o.ExpenseCents,
+		NetCents:     t.NetCents + o.NetCents,
+	}
+}
+
+// analyticsBuckets spreads the monthly rows over one bucket per month from from to to. previous holds
+// the rows of the range one year earlier, matched to the bucket twelve months later.
+func analyticsBuckets(from, to time.Time, current, previous []repository.LedgerMonthRow, convert func(int64, string, time.Time) int64) []AnalyticsBucket {
+	first := dateMonthIndex(from)
+	buckets := make([]AnalyticsBucket, dateMonthIndex(to)-first+1)
+	for i := range buckets {
+		start := time.Date(from.Year(), from.Month()+time.Month(i), 1, 0, 0, 0, 0, time.UTC)
+		end := start.AddDate(0, 1, -1)
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		buckets[i] = AnalyticsBucket{
+			Month:             start.Format("2006-01"),
+			From:              start,
+			To:                end,
+			IncomeByCategory:  map[string]int64{},
+			ExpenseByCategory: map[string]int64{},
+		}
+	}
+	for _, row := range current {
+		i := dateMonthIndex(row.Month) - first
+		if i < 0 || i >= len(buckets) {
+			continue
+		}
+		b := &buckets[i]
+		amount := convert(row.AmountCents, row.Currency, b.To)
+		if row.Type == "income" {
+			b.IncomeCents += amount
+			b.IncomeByCategory[row.Category] += amount
+		} else {
+			b.ExpenseCents += amount
+			b.ExpenseByCategory[row.Category] += amount
+		}
+	}
+	for _, row := range previous {
+		i := dateMonthIndex(row.Month) + 12 - first
+		if i < 0 || i >= len(buckets) {
+			continue
+		}
+		b := &buckets[i]
+		amount := convert(row.AmountCents, row.Currency, b.To.AddDate(-1, 0, 0))
+		if row.Type == "income" {
+			b.PreviousYear.IncomeCents += amount
+		} else {
+			b.PreviousYear.ExpenseCents += amount
+		}
+	}
+	for i := range buckets {
+		b := &buckets[i]
+		b.NetCents = b.IncomeCents - b.ExpenseCents
+		b.PreviousYear.NetCents = b.PreviousYear.IncomeCents - b.PreviousYear.ExpenseCents
+		b.YearOverYear = analyticsDelta(b.AnalyticsTotals, b.PreviousYear)
+	}
+	return buckets
+}
+
+func analyticsDelta(cur, prev AnalyticsTotals) AnalyticsDelta {
+	return AnalyticsDelta{
+		IncomeCents:  cur.IncomeCents - prev.IncomeCents,
+		ExpenseCents: cur.ExpenseCents - prev.ExpenseCents,
+		NetCents:     cur.NetCents - prev.NetCents,
+		IncomePct:    changePct(cur.IncomeCents, prev.IncomeCents),
+		ExpensePct:   changePct(cur.ExpenseCents, prev.ExpenseCents),
+		NetPct:       changePct(cur.NetCents, prev.NetCents),
+	}
+}
+
+// changePct is the change from prev to cur relative to the size of prev, rounded to two decimals.
+func changePct(cur, prev int64) *float64 {
+	if prev == 0 {
+		return nil
+	}
+	pct := math.Round(float64(cur-prev)*10000/math.Abs(float64(prev))) / 100
+	return &pct
+}
+
+// topMerchants merges the per-currency merchant rows converted at the end of the range and keeps the
+// n largest.
+func topMerchants(rows []repository.MerchantRow, n int, asOf time.Time, convert func(int64, string, time.Time) int64) []MerchantTotal {
+	byKey := map[string]*MerchantTotal{}
+	var keys []string
+	for _, row := range rows {
+		m, ok := byKey[row.MerchantKey]
+		if !ok {
+			m = &MerchantTotal{Merchant: row.Merchant}
+			byKey[row.MerchantKey] = m
+			keys = append(keys, row.MerchantKey)
+		}
+		m.AmountCents += convert(row.AmountCents, row.Currency, asOf)
+		m.Count += row.Count
+		if row.LastDate.After(m.LastDate) {
+			m.LastDate = row.LastDate
+		}
+	}
+	out := make([]MerchantTotal, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, *byKey[k])
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].AmountCents > out[j].AmountCents })
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+func dateMonthIndex(t time.Time) int {
+	return monthIndex(t.Year(), int(t.Month()))
+}
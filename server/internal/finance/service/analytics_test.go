@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/finance/repository"
+)
+
+func TestAnalyticsBuckets(t *testing.T) {
+	current := []repository.LedgerMonthRow{
+		{Month: day(2025, 1, 1), Type: "income", Category: "salary", Currency: "BRL", AmountCents: 500000},
+		{Month: day(2025, 1, 1), Type: "expense", Category: "food", Currency: "BRL", AmountCents: 80000},
+		{Month: day(2025, 1, 1), Type: "expense", Category: "food", Currency: "USD", AmountCents: 1000},
+		{Month: day(2025, 3, 1), Type: "expense", Category: "rent", Currency: "BRL", AmountCents: 200000},
+	}
+	previous := []repository.LedgerMonthRow{
+		{Month: day(2024, 1, 1), Type: "income", Category: "salary", Currency: "BRL", AmountCents: 400000},
+		{Month: day(2024, 3, 1), Type: "expense", Category: "rent", Currency: "BRL", AmountCents: 250000},
+	}
+	// USD at 5 BRL.
+	convert := func(amount int64, currency string, _ time.Time) int64 {
+		if currency == "USD" {
+			return amount * 5
+		}
+		return amount
+	}
+	buckets := analyticsBuckets(day(2025, 1, 15), day(2025, 3, 10), current, previous, convert)
+	if len(buckets) != 3 {
+		t.Fatalf("got %d buckets", len(buckets))
+	}
+	jan, feb, mar := buckets[0], buckets[1], buckets[2]
+	if jan.Month != "2025-01" || !jan.From.Equal(day(2025, 1, 15)) || !jan.To.Equal(day(2025, 1, 31)) || !mar.To.Equal(day(2025, 3, 10)) {
+		t.Fatalf("bucket bounds: %+v %+v", jan, mar)
+	}
+	if jan.IncomeCents != 500000 || jan.ExpenseCents != 85000 || jan.NetCents != 415000 || jan.ExpenseByCategory["food"] != 85000 {
+		t.Fatalf("january: %+v", jan)
+	}
+	if jan.PreviousYear.IncomeCents != 400000 || *jan.YearOverYear.IncomePct != 25 {
+		t.Fatalf("january year over year: %+v", jan.YearOverYear)
+	}
+	if feb.NetCents != 0 || feb.YearOverYear.IncomePct != nil {
+		t.Fatalf("empty february: %+v", feb)
+	}
+	if mar.YearOverYear.ExpenseCents != -50000 || *mar.YearOverYear.ExpensePct != -20 || *mar.YearOverYear.NetPct != 20 {
+		t.Fatalf("march year over year: %+v", mar.YearOverYear)
+	}
+}
+
+func TestTopMerchants(t *testing.T) {
+	rows := []repository.MerchantRow{
+		{MerchantKey: "mercado", Merchant: "Mercado", Currency: "BRL", AmountCents: 30000, Count: 3, LastDate: day(2025, 3, 1)},
+		{MerchantKey: "uber", Merchant: "Uber", Currency: "BRL", AmountCents: 20000, Count: 10, LastDate: day(2025, 3, 5)},
+		{MerchantKey: "uber", Merchant: "UBER", Currency: "USD", AmountCents: 4000, Count: 2, LastDate: day(2025, 2, 1)},
+		{MerchantKey: "padaria", Merchant: "Padaria", Currency: "BRL", AmountCents: 5000, Count: 5, LastDate: day(2025, 1, 1)},
+	}
+	convert := func(amount int64, currency string, _ time.Time) int64 {
+		if currency == "USD" {
+			return amount * 5
+		}
+		return amount
+	}
+	got := topMerchants(rows, 2, day(2025, 3, 31), convert)
+	if len(got) != 2 || got[0].Merchant != "Uber" || got[0].AmountCents != 40000 || got[0].Count != 12 || !got[0].LastDate.Equal(day(2025, 3, 5)) {
+		t.Fatalf("top merchants: %+v", got)
+	}
+	if got[1].Merchant != "Mercado" {
+		t.Fatalf("second merchant: %+v", got[1])
+	}
+}
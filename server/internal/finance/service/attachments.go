@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+// AttachmentOwner is the record a media asset is attached to. InvoiceID is required for invoice
+// items, which must belong to that invoice.
+type AttachmentOwner struct {
+	Type      string
+	ID        uuid.UUID
+	InvoiceID uuid.UUID
+}
+
+// CheckAttachmentOwner reports a not found error when the owner does not exist, so uploads can be
+// rejected before anything is stored.
+func (s *Service) CheckAttachmentOwner(ctx context.Context, o AttachmentOwner) error {
+	switch o.Type {
+	case models.FinanceAttachmentTransaction:
+		if _, err := s.repo.FindTransaction(ctx, o.ID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound(apperrors.CodeInternal, "Transaction not found.")
+			}
+			return apperrors.InternalCause(apperrors.CodeInternal, "Failed to load transaction.", err)
+		}
+	case models.FinanceAttachmentInvoice:
+		if _, err := s.repo.FindInvoice(ctx, o.ID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound(apperrors.CodeInternal, "Invoice not found.")
+			}
+			return apperrors.InternalCause(apperrors.CodeInternal, "Failed to load invoice.", err)
+		}
+	case models.FinanceAttachmentInvoiceItem:
+		inv, err := s.repo.FindInvoice(ctx, o.InvoiceID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound(apperrors.CodeInternal, "Invoice not found.")
+			}
+			return apperrors.InternalCause(apperrors.CodeInternal, "Failed to load invoice.", err)
+		}
+		for _, item := range inv.Items {
+			if item.ID == o.ID {
+				return nil
+			}
+		}
+		return apperrors.NotFound(apperrors.CodeInternal, "Invoice item not found.")
+	default:
+		return apperrors.Invalid(apperrors.CodeInternal, "Attachment owner must be a transaction, invoice or invoice item.")
+	}
+	return nil
+}
+
+// AttachMedia links an existing media asset to the owner. The caller is responsible for checking
+// the asset exists and for making it private.
+func (s *Service) AttachMedia(ctx context.Context, o AttachmentOwner, mediaID uuid.UUID) error {
+	if err := s.CheckAttachmentOwner(ctx, o); err != nil {
+		return err
+	}
+	row := &models.FinanceAttachment{OwnerType: o.Type, OwnerID: o.ID, MediaID: mediaID}
+	if err := s.repo.CreateAttachment(ctx, row); err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to attach media.", err)
+	}
+	return nil
+}
+
+// DetachMedia removes the link only; the media asset itself is kept.
+func (s *Service) DetachMedia(ctx context.Context, o AttachmentOwner, mediaID uuid.UUID) error {
+	if err := s.CheckAttachmentOwner(ctx, o); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteAttachment(ctx, o.Type, o.ID, mediaID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound(apperrors.CodeInternal, "Attachment not found.")
+		}
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to detach media.", err)
+	}
+	return nil
+}
+
+func (s *Service) loadTransactionAttachments(ctx context.Context, row *models.Transaction) error {
+	byOwner, err := s.repo.ListAttachments(ctx, models.FinanceAttachmentTransaction, []uuid.UUID{row.ID})
+	if err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to load attachments.", err)
+	}
+	row.Attachments = byOwner[row.ID]
+	return nil
+}
+
+// loadInvoiceAttachments fills the attachments of the invoice and of each of its items.
+func (s *Service) loadInvoiceAttachments(ctx context.Context, row *models.Invoice) error {
+	byInvoice, err := s.repo.ListAttachments(ctx, models.FinanceAttachmentInvoice, []uuid.UUID{row.ID})
+	if err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to load attachments.", err)
+	}
+	row.Attachments = byInvoice[row.ID]
+	if len(row.Items) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(row.Items))
+	for i, item := range row.Items {
+		ids[i] = item.ID
+	}
+	byItem, err := s.repo.ListAttachments(ctx, models.FinanceAttachmentInvoiceItem, ids)
+	if err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to load attachments.", err)
+	}
+	for i := range row.Items {
+		row.Items[i].Attachments = byItem[row.Items[i].ID]
+	}
+	return nil
+}
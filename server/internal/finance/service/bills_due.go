@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/repository"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+const (
+	defaultBillsDueDays = 7
+	maxBillsDueDays     = 90
+)
+
+// DueBill is an invoice or an expense occurrence still waiting to be paid.
+type DueBill struct {
+	Date        time.Time `json:"date"`
+	Type        string    `json:"type"`
+	Title       string    `json:"title"`
+	AmountCents int64     `json:"amountCents"`
+	Currency    string    `json:"currency"`
+	Overdue     bool      `json:"overdue"`
+	AutoPay     bool      `json:"autoPay"`
+	RefID       uuid.UUID `json:"refId"`
+}
+
+// BillsDue lists what is overdue plus what falls due in [From, To]. Totals are in the reporting
+// currency.
+type BillsDue struct {
+	From              time.Time `json:"from"`
+	To                time.Time `json:"to"`
+	ReportingCurrency string    `json:"reportingCurrency"`
+	Bills             []DueBill `json:"bills"`
+	TotalDueCents     int64     `json:"totalDueCents"`
+	OverdueCount      int       `json:"overdueCount"`
+	OverdueCents      int64     `json:"overdueCents"`
+	MissingRates      []string  `json:"missingRates,omitempty"`
+}
+
+// BillsDue gathers open invoices and scheduled expenses due within days of today (7 by default),
+// along with overdue invoices. Expense occurrences already confirmed or skipped are left out.
+func (s *Service) BillsDue(ctx context.Context, today time.Time, days int) (*BillsDue, error) {
+	if days <= 0 {
+		days = defaultBillsDueDays
+	}
+	if days > maxBillsDueDays {
+		days = maxBillsDueDays
+	}
+	from := dateOnly(today)
+	to := from.AddDate(0, 0, days)
+
+	overdue, err := s.repo.ListInvoicesDueBetween(ctx, time.Time{}, from.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load overdue invoices.", err)
+	}
+	upcoming, err := s.repo.ListInvoicesDueBetween(ctx, from, to)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load upcoming invoices.", err)
+	}
+	expenses, err := s.repo.ListExpenses(ctx, true)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load expenses.", err)
+	}
+	expected, err := s.repo.ListExpectedTransactions(ctx, repository.ExpectedTransactionFilter{SourceType: "expense", From: &from, To: &to})
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load expected transactions.", err)
+	}
+	conv, err := s.newConverter(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	invoiceCurrency, err := s.invoiceCurrency(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bills := dueBills(append(overdue, upcoming...), expenses, expected, invoiceCurrency, from, to)
+	out := &BillsDue{From: from, To: to, ReportingCurrency: conv.target, Bills: bills}
+	for _, b := range bills {
+		amount := conv.convert(b.AmountCents, b.Currency, b.Date)
+		out.TotalDueCents += amount
+		if b.Overdue {
+			out.OverdueCount++
+			out.OverdueCents += amount
+		}
+	}
+	out.MissingRates = conv.missingCurrencies()
+	return out, nil
+}
+
+// dueBills merges unpaid invoices and the expense occurrences in [from, to] that have not been
+// resolved, ordered by date. Invoices are in currency, the one of the card accounts.
+func dueBills(invoices []models.Invoice, expenses []models.Expense, expected []models.ExpectedTransaction, currency string, from, to time.Time) []DueBill {
+	resolved := map[string]bool{}
+	for _, e := range expected {
+		if e.Status != expectedPending {
+			resolved[e.SourceID.String()+dateOnly(e.DueDate).Format("2006-01-02")] = true
+		}
+	}
+	bills := []DueBill{}
+	for _, inv := range invoices {
+		due := inv.TotalCents - inv.PaidCents
+		if due <= 0 {
+			continue
+		}
+		bills = append(bills, DueBill{
+			Date:        dateOnly(inv.DueDate),
+			Type:        "invoice",
+			Title:       inv.Name,
+			AmountCents: due,
+			Currency:    currency,
+			Overdue:     dateOnly(inv.DueDate).Before(from),
+			RefID:       inv.ID,
+		})
+	}
+	for _, exp := range expenses {
+		for _, d := range expenseSchedule(exp).occurrences(from, to) {
+			if resolved[exp.ID.String()+d.Format("2006-01-02")] {
+				continue
+			}
+			bills = append(bills, DueBill{
+				Date:        d,
+				Type:        "expense",
+				Title:       exp.Name,
+				AmountCents: exp.AmountCents,
+				Currency:    normalizeCurrency(exp.Currency),
+				AutoPay:     exp.AutoPay,
+				RefID:       exp.ID,
+			})
+		}
+	}
+	sort.SliceStable(bills, func(i, j int) bool {
+		return bills[i].Date.Before(bills[j].Date)
+	})
+	return bills
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func TestDueBillsSkipsPaidAndResolvedAndSortsByDate(t *testing.T) {
+	rentDue, gymDue := day(2026, 10, 20), day(2026, 10, 18)
+	rent := models.Expense{ID: uuid.New(), Name: "Rent", AmountCents: 200000, Currency: "brl", Frequency: "one_time", DueDate: &rentDue, CreatedAt: day(2026, 1, 1)}
+	gym := models.Expense{ID: uuid.New(), Name: "Gym", AmountCents: 9000, Currency: "BRL", Frequency: "one_time", DueDate: &gymDue, CreatedAt: day(2026, 1, 1)}
+	invoices := []models.Invoice{
+		{ID: uuid.New(), Name: "Card", DueDate: day(2026, 10, 10), TotalCents: 50000, PaidCents: 20000},
+		{ID: uuid.New(), Name: "Paid card", DueDate: day(2026, 10, 19), TotalCents: 1000, PaidCents: 1000},
+	}
+	expected := []models.ExpectedTransaction{
+		{SourceType: "expense", SourceID: gym.ID, DueDate: gymDue, Status: expectedConfirmed},
+	}
+
+	bills := dueBills(invoices, []models.Expense{rent, gym}, expected, "BRL", day(2026, 10, 16), day(2026, 10, 23))
+	if len(bills) != 2 {
+		t.Fatalf("expected 2 bills, got %+v", bills)
+	}
+	if bills[0].Title != "Card" || !bills[0].Overdue || bills[0].AmountCents != 30000 {
+		t.Errorf("unexpected overdue invoice %+v", bills[0])
+	}
+	if bills[1].Title != "Rent" || bills[1].Overdue || bills[1].Currency != "BRL" {
+		t.Errorf("unexpected expense %+v", bills[1])
+	}
+}
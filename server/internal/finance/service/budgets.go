@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+// rolloverLookback bounds how many earlier months feed unspent amounts into the reported month.
+const rolloverLookback = 12
+
+type BudgetReportLine struct {
+	Category       string  `json:"category"`
+	Budgeted       bool    `json:"budgeted"`
+	Rollover       bool    `json:"rollover"`
+	PlannedCents   int64   `json:"plannedCents"`
+	CarryInCents   int64   `json:"carryInCents"`
+	AvailableCents int64   `json:"availableCents"`
+	ActualCents    int64   `json:"actualCents"`
+	RemainingCents int64   `json:"remainingCents"`
+	PercentUsed    float64 `json:"percentUsed"`
+}
+
+// BudgetReport compares planned and actual spending for one month, in the reporting currency.
+type BudgetReport struct {
+	Year              int                `json:"year"`
+	Month             int                `json:"month"`
+	ReportingCurrency string             `json:"reportingCurrency"`
+	Lines             []BudgetReportLine `json:"lines"`
+	PlannedCents      int64              `json:"plannedCents"`
+	CarryInCents      int64              `json:"carryInCents"`
+	ActualCents       int64              `json:"actualCents"`
+	RemainingCents    int64              `json:"remainingCents"`
+	MissingRates      []string           `json:"missingRates,omitempty"`
+}
+
+type CopyBudgetsInput struct {
+	FromYear  int
+	FromMonth int
+	ToYear    int
+	ToMonth   int
+	// Overwrite replaces amounts of categories already budgeted in the target month.
+	Overwrite bool
+}
+
+type CopyBudgetsResult struct {
+	Created int                 `json:"created"`
+	Updated int                 `json:"updated"`
+	Skipped int                 `json:"skipped"`
+	Budgets []models.BudgetPlan `json:"budgets"`
+}
+
+// BudgetReport returns planned vs actual per category. Actual spending is expense transactions
+// (invoice payments excluded) plus card invoice items falling due in the month. Spending in a
+// subcategory without its own budget counts against the nearest budgeted parent. Budgets flagged
+// Rollover pass their unspent amount on to the next month.
+func (s *Service) BudgetReport(ctx context.Context, year, month int) (*BudgetReport, error) {
+	year, month = parseYearMonth(year, month)
+	target := monthIndex(year, month)
+	first := target - rolloverLookback
+	fy, fm := monthFromIndex(first)
+	budgets, err := s.repo.ListBudgetsRange(ctx, fy, fm, year, month)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load budgets.", err)
+	}
+	from := time.Date(fy, time.Month(fm), 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC)
+	rows, err := s.repo.SumBudgetActuals(ctx, from, to)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to compute actuals.", err)
+	}
+	conv, err := s.newConverter(ctx, to.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categoryIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	planned := map[int]map[string]models.BudgetPlan{}
+	for _, b := range budgets {
+		idx := monthIndex(b.Year, b.Month)
+		if planned[idx] == nil {
+			planned[idx] = map[string]models.BudgetPlan{}
+		}
+		planned[idx][b.Category] = b
+	}
+	actuals := map[int]map[string]int64{}
+	for _, row := range rows {
+		cur := row.Currency
+		if cur == "" {
+			cur = conv.target
+		}
+		idx := monthIndex(row.Date.Year(), int(row.Date.Month()))
+		if actuals[idx] == nil {
+			actuals[idx] = map[string]int64{}
+		}
+		key := categories.budgetKey("expense", firstNonEmpty(row.Key, "other"), planned[idx])
+		actuals[idx][key] += conv.convert(row.AmountCents, cur, row.Date)
+	}
+
+	out := &BudgetReport{
+		Year:              year,
+		Month:             month,
+		ReportingCurrency: conv.target,
+		Lines:             budgetLines(planned, actuals, first, target),
+		MissingRates:      conv.missingCurrencies(),
+	}
+	for _, line := range out.Lines {
+		out.PlannedCents += line.PlannedCents
+		out.CarryInCents += line.CarryInCents
+		out.ActualCents += line.ActualCents
+		out.RemainingCents += line.RemainingCents
+	}
+	return out, nil
+}
+
+// CopyBudgets copies every budget of one month into another.
+func (s *Service) CopyBudgets(ctx context.Context, in CopyBudgetsInput) (*CopyBudgetsResult, error) {
+	if in.FromYear <= 0 || in.FromMonth < 1 || in.FromMonth > 12 || in.ToYear <= 0 || in.ToMonth < 1 || in.ToMonth > 12 {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Source and target year and month are required.")
+	}
+	if in.FromYear == in.ToYear && in.FromMonth == in.ToMonth {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Source and target months must differ.")
+	}
+	source, err := s.repo.ListBudgets(ctx, in.FromYear, in.FromMonth)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load budgets.", err)
+	}
+	if len(source) == 0 {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "No budgets to copy.")
+	}
+	existing, err := s.repo.ListBudgets(ctx, in.ToYear, in.ToMonth)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load budgets.", err)
+	}
+	byCategory := map[string]models.BudgetPlan{}
+	for _, b := range existing {
+		byCategory[b.Category] = b
+	}
+	res := &CopyBudgetsResult{}
+	var create, update []models.BudgetPlan
+	for _, b := range source {
+		if cur, ok := byCategory[b.Category]; ok {
+			if !in.Overwrite {
+				res.Skipped++
+				continue
+			}
+			cur.PlannedCents, cur.Rollover, cur.Notes = b.PlannedCents, b.Rollover, b.Notes
+			update = append(update, cur)
+			continue
+		}
+		create = append(create, models.BudgetPlan{
+			Year:         in.ToYear,
+			Month:        in.ToMonth,
+			Category:     b.Category,
+			PlannedCents: b.PlannedCents,
+			Rollover:     b.Rollover,
+			Notes:        b.Notes,
+		})
+	}
+	if err := s.repo.CopyBudgets(ctx, create, update); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to copy budgets.", err)
+	}
+	res.Created, res.Updated = len(create), len(update)
+	res.Budgets, err = s.repo.ListBudgets(ctx, in.ToYear, in.ToMonth)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load budgets.", err)
+	}
+	return res, nil
+}
+
+// budgetLines builds the report for month index target. Months first..target-1 only feed the carry:
+// a rollover budget passes planned + carry - actual to the next month when that is positive.
+func budgetLines(planned map[int]map[string]models.BudgetPlan, actuals map[int]map[string]int64, first, target int) []BudgetReportLine {
+	carry := map[string]int64{}
+	for idx := first; idx < target; idx++ {
+		next := map[string]int64{}
+		for cat, b := range planned[idx] {
+			if !b.Rollover {
+				continue
+			}
+			if left := b.PlannedCents + carry[cat] - actuals[idx][cat]; left > 0 {
+				next[cat] = left
+			}
+		}
+		carry = next
+	}
+
+	cats := map[string]bool{}
+	for cat := range planned[target] {
+		cats[cat] = true
+	}
+	for cat := range actuals[target] {
+		cats[cat] = true
+	}
+	for cat := range carry {
+		cats[cat] = true
+	}
+	lines := make([]BudgetReportLine, 0, len(cats))
+	for cat := range cats {
+		b, budgeted := planned[target][cat]
+		line := BudgetReportLine{
+			Category:     cat,
+			Budgeted:     budgeted,
+			Rollover:     b.Rollover,
+			PlannedCents: b.PlannedCents,
+			CarryInCents: carry[cat],
+			ActualCents:  actuals[target][cat],
+		}
+		line.AvailableCents = line.PlannedCents + line.CarryInCents
+		line.RemainingCents = line.AvailableCents - line.ActualCents
+		if line.AvailableCents > 0 {
+			line.PercentUsed = math.Round(float64(line.ActualCents)*1000/float64(line.AvailableCents)) / 10
+		}
+		lines = append(lines, line)
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].Category < lines[j].Category })
+	return lines
+}
+
+func monthIndex(year, month int) int {
+	return year*12 + month - 1
+}
+
+func monthFromIndex(idx int) (int, int) {
+	return floorDiv(idx, 12), floorMod(idx, 12) + 1
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func TestBudgetLinesRollsOverUnspentAmounts(t *testing.T) {
+	jan, feb, mar := monthIndex(2026, 1), monthIndex(2026, 2), monthIndex(2026, 3)
+	planned := map[int]map[string]models.BudgetPlan{
+		jan: {"food": {Category: "food", PlannedCents: 1000, Rollover: true}},
+		feb: {"food": {Category: "food", PlannedCents: 1000, Rollover: true}, "rent": {Category: "rent", PlannedCents: 5000}},
+		mar: {"food": {Category: "food", PlannedCents: 1000, Rollover: true}},
+	}
+	actuals := map[int]map[string]int64{
+		jan: {"food": 600},
+		feb: {"food": 900, "rent": 4000},
+		mar: {"food": 700, "transport": 300},
+	}
+	lines := budgetLines(planned, actuals, jan, mar)
+	if len(lines) != 2 {
+		t.Fatalf("got %+v", lines)
+	}
+	food := lines[0]
+	// Jan leaves 400, Feb leaves 1000+400-900 = 500 for March.
+	if food.CarryInCents != 500 || food.AvailableCents != 1500 || food.RemainingCents != 800 || food.PercentUsed != 46.7 {
+		t.Fatalf("unexpected food line %+v", food)
+	}
+	transport := lines[1]
+	if transport.Budgeted || transport.ActualCents != 300 || transport.RemainingCents != -300 {
+		t.Fatalf("unexpected transport line %+v", transport)
+	}
+}
+
+func TestMonthIndexRoundTrip(t *testing.T) {
+	y, m := monthFromIndex(monthIndex(2026, 1) - 1)
+	if y != 2025 || m != 12 {
+		t.Fatalf("got %d-%d", y, m)
+	}
+}
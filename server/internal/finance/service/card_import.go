@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/statement"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+type CardStatementInput struct {
+	// Preset names a layout in statement.CardPresets; empty means Mapping is used as-is.
+	Preset  string
+	Mapping statement.CardMapping
+	// InvoiceID targets an existing invoice; otherwise the invoice due on DueDate is found or created.
+	InvoiceID    *uuid.UUID
+	DueDate      *time.Time
+	Name         string
+	CardLastFour string
+	Reader       io.Reader
+}
+
+type CardStatementResult struct {
+	Invoice         *models.Invoice `json:"invoice"`
+	CreatedInvoice  bool            `json:"createdInvoice"`
+	ItemsCreated    int             `json:"itemsCreated"`
+	Duplicates      int             `json:"duplicates"`
+	SkippedPayments int             `json:"skippedPayments"`
+}
+
+// CardPresetNames lists the built-in card statement layouts.
+func CardPresetNames() []string {
+	return []string{"nubank", "inter"}
+}
+
+// ImportCardStatement reads a card statement CSV into invoice items. Bill payments are skipped,
+// refunds become negative items, and lines already on the invoice are not added twice.
+func (s *Service) ImportCardStatement(ctx context.Context, in CardStatementInput) (*CardStatementResult, error) {
+	mapping := in.Mapping
+	preset := strings.TrimSpace(strings.ToLower(in.Preset))
+	if preset != "" {
+		p, ok := statement.CardPresets[preset]
+		if !ok {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Unknown preset. Use one of: "+strings.Join(CardPresetNames(), ", ")+".")
+		}
+		mapping = p
+	}
+	entries, err := statement.ParseCardCSV(in.Reader, mapping)
+	if err != nil {
+		if errors.Is(err, statement.ErrNoEntries) {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "No transactions found in file.")
+		}
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Could not parse statement: "+err.Error())
+	}
+
+	res := &CardStatementResult{}
+	invoice, created, err := s.resolveStatementInvoice(ctx, in, preset)
+	if err != nil {
+		return nil, err
+	}
+	res.CreatedInvoice = created
+
+	categories, err := s.categoryIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.activeRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]int{}
+	for _, item := range invoice.Items {
+		seen[statement.Fingerprint(item.Date, item.AmountCents, item.Description)]++
+	}
+	var items []models.InvoiceItem
+	for _, e := range entries {
+		if e.AmountCents == 0 {
+			continue
+		}
+		if e.AmountCents < 0 && e.IsPayment() {
+			res.SkippedPayments++
+			continue
+		}
+		desc := truncateRunes(firstNonEmpty(strings.TrimSpace(e.Description), "Card purchase"), 500)
+		fp := statement.Fingerprint(e.Date, e.AmountCents, desc)
+		if seen[fp] > 0 {
+			seen[fp]--
+			res.Duplicates++
+			continue
+		}
+		item := models.InvoiceItem{
+			Description: desc,
+			AmountCents: e.AmountCents,
+			Date:        e.Date,
+			Category:    cardCategory(categories, e.Category),
+			Installment: e.Installment(),
+		}
+		rules.applyToInvoiceItem(&item)
+		items = append(items, item)
+	}
+	if err := s.repo.AddInvoiceItems(ctx, invoice.ID, items); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to create invoice items.", err)
+	}
+	res.ItemsCreated = len(items)
+	res.Invoice, err = s.GetInvoice(ctx, invoice.ID)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *Service) resolveStatementInvoice(ctx context.Context, in CardStatementInput, preset string) (*models.Invoice, bool, error) {
+	if in.InvoiceID != nil {
+		row, err := s.GetInvoice(ctx, *in.InvoiceID)
+		return row, false, err
+	}
+	if in.DueDate == nil || in.DueDate.IsZero() {
+		return nil, false, apperrors.Invalid(apperrors.CodeInternal, "Invoice id or due date is required.")
+	}
+	due := in.DueDate.UTC().Truncate(24 * time.Hour)
+	lastFour := strings.TrimSpace(in.CardLastFour)
+	row, err := s.repo.FindInvoiceByDueDate(ctx, due, lastFour)
+	if err == nil {
+		full, err := s.GetInvoice(ctx, row.ID)
+		return full, false, err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load invoice.", err)
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		name = "Card"
+		if preset != "" {
+			name = strings.ToUpper(preset[:1]) + preset[1:]
+		}
+		name += " " + due.Format("01/2006")
+	}
+	created, err := s.CreateInvoice(ctx, CreateInvoiceInput{Name: name, CardLastFour: lastFour, DueDate: due})
+	if err != nil {
+		return nil, false, err
+	}
+	return created, true, nil
+}
+
+// cardCategory maps the category labels Brazilian card exports use onto expense categories, falling
+// back to "other" for labels with no active category.
+func cardCategory(categories *categoryIndex, raw string) string {
+	c := statement.NormalizeDescription(raw)
+	switch {
+	case c == "":
+		return "other"
+	case strings.Contains(c, "restaurante"), strings.Contains(c, "supermercado"), strings.Contains(c, "alimenta"), strings.Contains(c, "mercado"):
+		return "food"
+	case strings.Contains(c, "transporte"), strings.Contains(c, "combustivel"), strings.Contains(c, "posto"):
+		return "transport"
+	case strings.Contains(c, "saude"), strings.Contains(c, "farmacia"), strings.Contains(c, "drogaria"):
+		return "health"
+	case strings.Contains(c, "servicos"), strings.Contains(c, "assinatura"):
+		return "subscription"
+	}
+	if slug, err := categories.resolve("expense", c); err == nil {
+		return slug
+	}
+	return "other"
+}
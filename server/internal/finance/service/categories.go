@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+type CreateCategoryInput struct {
+	Kind     string
+	Slug     string
+	Name     string
+	ParentID *uuid.UUID
+	Color    string
+}
+
+type UpdateCategoryInput struct {
+	Name      *string
+	ParentID  *uuid.UUID
+	ParentSet bool
+	Color     *string
+	Archived  *bool
+}
+
+func (s *Service) ListCategories(ctx context.Context, kind string, includeArchived bool) ([]models.FinanceCategory, error) {
+	rows, err := s.repo.ListCategories(ctx, includeArchived)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load categories.", err)
+	}
+	kind = strings.TrimSpace(strings.ToLower(kind))
+	if kind == "" {
+		return rows, nil
+	}
+	out := make([]models.FinanceCategory, 0, len(rows))
+	for _, row := range rows {
+		if row.Kind == kind {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (s *Service) GetCategory(ctx context.Context, id uuid.UUID) (*models.FinanceCategory, error) {
+	row, err := s.repo.FindCategory(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound(apperrors.CodeInternal, "Category not found.")
+		}
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load category.", err)
+	}
+	return row, nil
+}
+
+func (s *Service) CreateCategory(ctx context.Context, in CreateCategoryInput) (*models.FinanceCategory, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Name is required.")
+	}
+	kind := strings.TrimSpace(strings.ToLower(in.Kind))
+	if kind == "" {
+		kind = "expense"
+	}
+	if kind != "expense" && kind != "income" {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Kind must be expense or income.")
+	}
+	slug := categorySlug(firstNonEmpty(in.Slug, name))
+	if slug == "" {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Slug is required.")
+	}
+	idx, err := s.categoryIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := idx.find(kind, slug); ok {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, fmt.Sprintf("Category %q already exists.", slug))
+	}
+	row := &models.FinanceCategory{
+		ID:       uuid.New(),
+		Kind:     kind,
+		Slug:     slug,
+		Name:     name,
+		ParentID: in.ParentID,
+		Color:    strings.TrimSpace(in.Color),
+	}
+	if err := idx.validateParent(row); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateCategory(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to create category.", err)
+	}
+	return row, nil
+}
+
+// UpdateCategory changes name, parent, color or archived state. Kind and slug are fixed because
+// other rows store the slug.
+func (s *Service) UpdateCategory(ctx context.Context, id uuid.UUID, in UpdateCategoryInput) (*models.FinanceCategory, error) {
+	row, err := s.GetCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if in.Name != nil {
+		name := strings.TrimSpace(*in.Name)
+		if name == "" {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Name is required.")
+		}
+		row.Name = name
+	}
+	if in.ParentSet {
+		row.ParentID = in.ParentID
+	}
+	if in.Color != nil {
+		row.Color = strings.TrimSpace(*in.Color)
+	}
+	if in.Archived != nil {
+		row.Archived = *in.Archived
+	}
+	idx, err := s.categoryIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := idx.validateParent(row); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveCategory(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update category.", err)
+	}
+	return row, nil
+}
+
+// DeleteCategory removes a category nothing references; used categories should be archived.
+func (s *Service) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	row, err := s.GetCategory(ctx, id)
+	if err != nil {
+		return err
+	}
+	n, err := s.repo.CountCategoryUsage(ctx, row)
+	if err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to delete category.", err)
+	}
+	if n > 0 {
+		return apperrors.Invalid(apperrors.CodeInternal, "Category is in use; archive it instead.")
+	}
+	if err := s.repo.DeleteCategory(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound(apperrors.CodeInternal, "Category not found.")
+		}
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to delete category.", err)
+	}
+	return nil
+}
+
+// resolveCategory returns the slug of the active category of the given kind that raw names. Empty
+// input means "other".
+func (s *Service) resolveCategory(ctx context.Context, kind, raw string) (string, error) {
+	idx, err := s.categoryIndex(ctx)
+	if err != nil {
+		return "", err
+	}
+	return idx.resolve(kind, raw)
+}
+
+// transactionCategory returns the category to store on a transaction. Empty keeps the category of the
+// linked expense or income source; transfers have none.
+func (s *Service) transactionCategory(ctx context.Context, txType, raw string) (string, error) {
+	if txType == "transfer" || strings.TrimSpace(raw) == "" {
+		return "", nil
+	}
+	return s.resolveCategory(ctx, txType, raw)
+}
+
+func (s *Service) categoryIndex(ctx context.Context) (*categoryIndex, error) {
+	rows, err := s.repo.ListCategories(ctx, true)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load categories.", err)
+	}
+	return newCategoryIndex(rows), nil
+}
+
+// categoryIndex looks categories up by kind and slug and walks their parents.
+type categoryIndex struct {
+	byID   map[uuid.UUID]models.FinanceCategory
+	bySlug map[string]models.FinanceCategory
+}
+
+func newCategoryIndex(rows []models.FinanceCategory) *categoryIndex {
+	idx := &categoryIndex{
+		byID:   make(map[uuid.UUID]models.FinanceCategory, len(rows)),
+		bySlug: make(map[string]models.FinanceCategory, len(rows)),
+	}
+	for _, row := range rows {
+		idx.byID[row.ID] = row
+		idx.bySlug[row.Kind+"/"+row.Slug] = row
+	}
+	return idx
+}
+
+func (idx *categoryIndex) find(kind, slug string) (models.FinanceCategory, bool) {
+	row, ok := idx.bySlug[kind+"/"+slug]
+	return row, ok
+}
+
+func (idx *categoryIndex) resolve(kind, raw string) (string, error) {
+	slug := categorySlug(raw)
+	if slug == "" {
+		slug = "other"
+	}
+	row, ok := idx.find(kind, slug)
+	if !ok {
+		return "", apperrors.Invalid(apperrors.CodeInternal, fmt.Sprintf("Unknown %s category %q.", kind, slug))
+	}
+	if row.Archived {
+		return "", apperrors.Invalid(apperrors.CodeInternal, fmt.Sprintf("Category %q is archived.", slug))
+	}
+	return slug, nil
+}
+
+// lineage returns slug followed by its ancestors, nearest first.
+func (idx *categoryIndex) lineage(kind, slug string) []string {
+	out := []string{slug}
+	row, ok := idx.find(kind, slug)
+	for ok && row.ParentID != nil && len(out) <= len(idx.byID) {
+		row, ok = idx.byID[*row.ParentID]
+		if ok {
+			out = append(out, row.Slug)
+		}
+	}
+	return out
+}
+
+// root returns the top-level ancestor of slug, or slug itself when it has no parent or is unknown.
+func (idx *categoryIndex) root(kind, slug string) string {
+	l := idx.lineage(kind, slug)
+	return l[len(l)-1]
+}
+
+// budgetKey returns the nearest of slug and its ancestors that has a budget, or slug when none does.
+func (idx *categoryIndex) budgetKey(kind, slug string, budgeted map[string]models.BudgetPlan) string {
+	for _, s := range idx.lineage(kind, slug) {
+		if _, ok := budgeted[s]; ok {
+			return s
+		}
+	}
+	return slug
+}
+
+// validateParent checks the parent exists, has the same kind and is not a descendant of row.
+func (idx *categoryIndex) validateParent(row *models.FinanceCategory) error {
+	if row.ParentID == nil {
+		return nil
+	}
+	parent, ok := idx.byID[*row.ParentID]
+	if !ok {
+		return apperrors.Invalid(apperrors.CodeInternal, "Parent category not found.")
+	}
+	if parent.Kind != row.Kind {
+		return apperrors.Invalid(apperrors.CodeInternal, "Parent category must be of the same kind.")
+	}
+	for _, slug := range idx.lineage(parent.Kind, parent.Slug) {
+		if slug == row.Slug {
+			return apperrors.Invalid(apperrors.CodeInternal, "A category cannot be nested under itself.")
+		}
+	}
+	return nil
+}
+
+// categorySlug lower-cases raw and joins its words with single underscores, dropping punctuation.
+func categorySlug(raw string) string {
+	var b strings.Builder
+	sep := false
+	for _, r := range strings.TrimSpace(strings.ToLower(raw)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			if sep && b.Len() > 0 {
+				b.WriteRune('_')
+			}
+			sep = false
+			b.WriteRune(r)
+		case r == '_', r == ' ', r == '-', r == '/':
+			sep = true
+		}
+	}
+	return truncateRunes(b.String(), 64)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func TestCategoryIndexRollsUpToParents(t *testing.T) {
+	food := models.FinanceCategory{ID: uuid.New(), Kind: "expense", Slug: "food"}
+	dining := models.FinanceCategory{ID: uuid.New(), Kind: "expense", Slug: "dining", ParentID: &food.ID}
+	coffee := models.FinanceCategory{ID: uuid.New(), Kind: "expense", Slug: "coffee", ParentID: &dining.ID}
+	idx := newCategoryIndex([]models.FinanceCategory{food, dining, coffee})
+
+	if got := idx.root("expense", "coffee"); got != "food" {
+		t.Fatalf("root = %q", got)
+	}
+	if got := idx.root("expense", "unknown"); got != "unknown" {
+		t.Fatalf("root of unknown = %q", got)
+	}
+	budgeted := map[string]models.BudgetPlan{"dining": {}}
+	if got := idx.budgetKey("expense", "coffee", budgeted); got != "dining" {
+		t.Fatalf("budgetKey = %q", got)
+	}
+	food.ParentID = &coffee.ID
+	if err := idx.validateParent(&food); err == nil {
+		t.Fatal("expected a cycle to be rejected")
+	}
+}
+
+func TestCategorySlug(t *testing.T) {
+	cases := map[string]string{
+		"  Eating Out ":    "eating_out",
+		"Saúde - Farmácia": "saúde_farmácia",
+		"food":             "food",
+		"!!":               "",
+	}
+	for in, want := range cases {
+		if got := categorySlug(in); got != want {
+			t.Fatalf("categorySlug(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -2,17 +2,28 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/woragis/management/backend/server/internal/models"
 )
 
 type ContactFinance struct {
-	ContactID      uuid.UUID              `json:"contactId"`
-	IncomeSources  []models.IncomeSource  `json:"incomeSources"`
-	Transactions   []models.Transaction   `json:"transactions"`
-	IncomeCents    int64                  `json:"incomeCents"`
-	ExpenseCents   int64                  `json:"expenseCents"`
+	ContactID         uuid.UUID              `json:"contactId"`
+	ReportingCurrency string                 `json:"reportingCurrency"`
+	IncomeSources     []models.IncomeSource  `json:"incomeSources"`
+	Transactions      []ConvertedTransaction `json:"transactions"`
+	IncomeCents       int64                  `json:"incomeCents"`
+	ExpenseCents      int64                  `json:"expenseCents"`
+	MissingRates      []string               `json:"missingRates,omitempty"`
+
+	// OpenReceivables are the issued, unpaid billing documents, with their Pix code when in BRL.
+	OpenReceivables []models.Receivable `json:"openReceivables"`
+
+	// Balances is what the contact owes us on shared expenses per currency (negative when we owe
+	// them); BalanceCents totals it in the reporting currency.
+	Balances     []CurrencyBalance `json:"balances"`
+	BalanceCents int64             `json:"balanceCents"`
 }
 
 func (s *Service) ContactFinance(ctx context.Context, contactID uuid.UUID) (*ContactFinance, error) {
@@ -27,20 +38,42 @@ func (s *Service) ContactFinance(ctx context.Context, contactID uuid.UUID) (*Con
 	if err != nil {
 		return nil, err
 	}
+	open, err := s.ListReceivables(ctx, ReceivableFilter{Status: "sent", ContactID: &contactID})
+	if err != nil {
+		return nil, err
+	}
+	balances, err := s.contactBalances(ctx, contactID)
+	if err != nil {
+		return nil, err
+	}
+	conv, err := s.newConverter(ctx, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	converted := conv.transactions(txs)
 	var incomeCents, expenseCents int64
-	for _, tx := range txs {
+	for _, tx := range converted {
 		switch tx.Type {
 		case "income":
-			incomeCents += tx.AmountCents
+			incomeCents += tx.ReportingAmountCents
 		case "expense":
-			expenseCents += tx.AmountCents
+			expenseCents += tx.ReportingAmountCents
 		}
 	}
+	var balanceCents int64
+	for _, b := range balances {
+		balanceCents += conv.convert(b.AmountCents, b.Currency, time.Now().UTC())
+	}
 	return &ContactFinance{
-		ContactID:     contactID,
-		IncomeSources: incomes,
-		Transactions:  txs,
-		IncomeCents:   incomeCents,
-		ExpenseCents:  expenseCents,
+		ContactID:         contactID,
+		ReportingCurrency: conv.target,
+		IncomeSources:     incomes,
+		Transactions:      converted,
+		IncomeCents:       incomeCents,
+		ExpenseCents:      expenseCents,
+		MissingRates:      conv.missingCurrencies(),
+		OpenReceivables:   open,
+		Balances:          balances,
+		BalanceCents:      balanceCents,
 	}, nil
 }
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/export"
+	"github.com/woragis/management/backend/server/internal/finance/repository"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+// ExportInput selects what to export. From and To are inclusive dates; without them Year (and
+// Month) pick the range, and without either everything is exported. The other filters match the
+// list endpoints of each dataset.
+type ExportInput struct {
+	Dataset    string
+	Format     string
+	From       *time.Time
+	To         *time.Time
+	Year       int
+	Month      int
+	Type       string
+	Status     string
+	ProjectID  *uuid.UUID
+	ContactID  *uuid.UUID
+	AccountID  *uuid.UUID
+	ActiveOnly bool
+}
+
+// ExportStart is called once the input is valid, right before the first byte is written; it
+// returns where the document goes.
+type ExportStart func(format export.Format, filename string) io.Writer
+
+var exportDatasets = map[string]string{
+	"transactions":   "Transactions",
+	"invoices":       "Invoices",
+	"income-sources": "Income sources",
+	"expenses":       "Expenses",
+	"budgets":        "Budgets",
+}
+
+// Export streams a dataset as CSV, XLSX or JSON. Validation errors are returned before start is
+// called; errors after that mean the document was cut short.
+func (s *Service) Export(ctx context.Context, in ExportInput, start ExportStart) error {
+	dataset := strings.TrimSpace(strings.ToLower(in.Dataset))
+	title, ok := exportDatasets[dataset]
+	if !ok {
+		return apperrors.Invalid(apperrors.CodeInternal, "Dataset must be transactions, invoices, income-sources, expenses or budgets.")
+	}
+	format, err := export.ParseFormat(in.Format)
+	if err != nil {
+		return apperrors.Invalid(apperrors.CodeInternal, "Format must be csv, xlsx or json.")
+	}
+	from, to, err := exportRange(in)
+	if err != nil {
+		return err
+	}
+	if in.Type != "" && normalizeTransactionType(in.Type) == "" {
+		return apperrors.Invalid(apperrors.CodeInternal, "Type must be income, expense or transfer.")
+	}
+
+	var columns []string
+	var run func(w export.Writer) error
+	switch dataset {
+	case "transactions":
+		columns = []string{"id", "date", "type", "description", "category", "amount", "currency", "accountId", "toAccountId", "toAmount", "projectId", "contactId", "incomeSourceId", "expenseId", "invoiceId", "externalId", "notes"}
+		f := repository.TransactionFilter{
+			Type:      normalizeTransactionType(in.Type),
+			From:      from,
+			To:        to,
+			ProjectID: in.ProjectID,
+			ContactID: in.ContactID,
+			AccountID: in.AccountID,
+		}
+		run = func(w export.Writer) error {
+			return s.repo.StreamTransactions(ctx, f, func(rows []models.Transaction) error {
+				for _, tx := range rows {
+					var toAmount any
+					if tx.ToAmountCents != nil {
+						toAmount = export.Money(*tx.ToAmountCents)
+					}
+					err := w.Write([]any{tx.ID.String(), tx.Date, tx.Type, tx.Description, tx.Category, export.Money(tx.AmountCents), tx.Currency,
+						exportID(tx.AccountID), exportID(tx.ToAccountID), toAmount, exportID(tx.ProjectID), exportID(tx.ContactID),
+						exportID(tx.IncomeSourceID), exportID(tx.ExpenseID), exportID(tx.InvoiceID), tx.ExternalID, tx.Notes})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+	case "invoices":
+		columns = []string{"invoiceId", "invoiceName", "cardLastFour", "dueDate", "status", "total", "paid", "itemId", "itemDate", "itemDescription", "itemCategory", "itemAmount", "installment", "itemNotes"}
+		f := repository.InvoiceFilter{Status: strings.TrimSpace(strings.ToLower(in.Status)), From: from, To: to}
+		run = func(w export.Writer) error {
+			return s.repo.StreamInvoices(ctx, f, func(rows []models.Invoice) error {
+				for _, inv := range rows {
+					head := []any{inv.ID.String(), inv.Name, inv.CardLastFour, inv.DueDate, inv.Status, export.Money(inv.TotalCents), export.Money(inv.PaidCents)}
+					if len(inv.Items) == 0 {
+						if err := w.Write(head); err != nil {
+							return err
+						}
+						continue
+					}
+					for _, item := range inv.Items {
+						err := w.Write(append(head[:7:7], item.ID.String(), item.Date, item.Description, item.Category, export.Money(item.AmountCents), item.Installment, item.Notes))
+						if err != nil {
+							return err
+						}
+					}
+				}
+				return nil
+			})
+		}
+	case "income-sources":
+		columns = []string{"id", "name", "type", "amount", "currency", "frequency", "interval", "dayOfMonth", "anchorDate", "endDate", "projectId", "contactId", "active", "notes", "createdAt"}
+		f := repository.ScheduleFilter{ActiveOnly: in.ActiveOnly, ContactID: in.ContactID, ProjectID: in.ProjectID, From: from, To: to}
+		run = func(w export.Writer) error {
+			return s.repo.StreamIncomeSources(ctx, f, func(rows []models.IncomeSource) error {
+				for _, inc := range rows {
+					err := w.Write([]any{inc.ID.String(), inc.Name, inc.Type, export.Money(inc.AmountCents), inc.Currency, inc.Frequency, inc.Interval, inc.DayOfMonth,
+						exportDate(inc.AnchorDate), exportDate(inc.EndDate), exportID(inc.ProjectID), exportID(inc.ContactID), inc.Active, inc.Notes, inc.CreatedAt})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+	case "expenses":
+		columns = []string{"id", "name", "category", "amount", "currency", "frequency", "interval", "dayOfMonth", "dueDate", "anchorDate", "endDate", "autoPay", "projectId", "active", "notes", "createdAt"}
+		f := repository.ScheduleFilter{ActiveOnly: in.ActiveOnly, ProjectID: in.ProjectID, From: from, To: to}
+		run = func(w export.Writer) error {
+			return s.repo.StreamExpenses(ctx, f, func(rows []models.Expense) error {
+				for _, exp := range rows {
+					err := w.Write([]any{exp.ID.String(), exp.Name, exp.Category, export.Money(exp.AmountCents), exp.Currency, exp.Frequency, exp.Interval, exp.DayOfMonth,
+						exportDate(exp.DueDate), exportDate(exp.AnchorDate), exportDate(exp.EndDate), exp.AutoPay, exportID(exp.ProjectID), exp.Active, exp.Notes, exp.CreatedAt})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+	case "budgets":
+		columns = []string{"id", "year", "month", "category", "planned", "rollover", "notes"}
+		var fromIndex, toIndex int
+		if from != nil {
+			fromIndex = monthIndex(from.Year(), int(from.Month()))
+		}
+		if to != nil {
+			last := to.AddDate(0, 0, -1)
+			toIndex = monthIndex(last.Year(), int(last.Month()))
+		}
+		run = func(w export.Writer) error {
+			return s.repo.StreamBudgets(ctx, fromIndex, toIndex, func(rows []models.BudgetPlan) error {
+				for _, b := range rows {
+					if err := w.Write([]any{b.ID.String(), b.Year, b.Month, b.Category, export.Money(b.PlannedCents), b.Rollover, b.Notes}); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+	}
+
+	w, err := export.NewWriter(format, start(format, exportFilename(dataset, from, to, format)), title, columns)
+	if err != nil {
+		return fmt.Errorf("start %s export: %w", dataset, err)
+	}
+	if err := run(w); err != nil {
+		return fmt.Errorf("export %s: %w", dataset, err)
+	}
+	return w.Close()
+}
+
+// exportRange turns the input into [from, to) bounds; either may be nil for an open side.
+func exportRange(in ExportInput) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+	switch {
+	case in.From != nil || in.To != nil:
+		if in.From != nil {
+			d := dateOnly(*in.From)
+			from = &d
+		}
+		if in.To != nil {
+			d := dateOnly(*in.To).AddDate(0, 0, 1)
+			to = &d
+		}
+		if from != nil && to != nil && !from.Before(*to) {
+			return nil, nil, apperrors.Invalid(apperrors.CodeInternal, "From must not be after to.")
+		}
+	case in.Year > 0:
+		start := time.Date(in.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		end := start.AddDate(1, 0, 0)
+		if in.Month >= 1 && in.Month <= 12 {
+			start = time.Date(in.Year, time.Month(in.Month), 1, 0, 0, 0, 0, time.UTC)
+			end = start.AddDate(0, 1, 0)
+		}
+		from, to = &start, &end
+	}
+	return from, to, nil
+}
+
+func exportFilename(dataset string, from, to *time.Time, format export.Format) string {
+	name := dataset
+	if from != nil {
+		name += "_" + from.Format("2006-01-02")
+	}
+	if to != nil {
+		name += "_to_" + to.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	return name + "." + format.Extension()
+}
+
+func exportID(id *uuid.UUID) any {
+	if id == nil {
+		return nil
+	}
+	return id.String()
+}
+
+func exportDate(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
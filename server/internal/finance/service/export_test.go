@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/woragis/management/backend/server/internal/finance/export"
+)
+
+func TestExportRange(t *testing.T) {
+	from, to, err := exportRange(ExportInput{Year: 2025})
+	if err != nil || !from.Equal(day(2025, 1, 1)) || !to.Equal(day(2026, 1, 1)) {
+		t.Fatalf("year: got %v %v %v", from, to, err)
+	}
+	start, end := day(2025, 3, 10), day(2025, 3, 31)
+	from, to, err = exportRange(ExportInput{From: &start, To: &end, Year: 2024})
+	if err != nil || !from.Equal(start) || !to.Equal(day(2025, 4, 1)) {
+		t.Fatalf("range: got %v %v %v", from, to, err)
+	}
+	if got := exportFilename("transactions", from, to, export.XLSX); got != "transactions_2025-03-10_to_2025-03-31.xlsx" {
+		t.Fatalf("unexpected filename %s", got)
+	}
+	if _, _, err := exportRange(ExportInput{From: &end, To: &start}); err == nil {
+		t.Fatal("expected error for reversed range")
+	}
+	if from, to, _ := exportRange(ExportInput{}); from != nil || to != nil {
+		t.Fatal("expected open range")
+	}
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/repository"
+)
+
+const (
+	defaultForecastMonths = 3
+	maxForecastMonths     = 24
+)
+
+// ForecastItem is a known one-off inflow (positive) or outflow (negative) to include in a forecast.
+type ForecastItem struct {
+	Date        time.Time `json:"date"`
+	AmountCents int64     `json:"amountCents"`
+	Currency    string    `json:"currency"`
+	Description string    `json:"description"`
+}
+
+type ForecastInput struct {
+	// StartBalanceCents is the balance at the start of From, in the reporting currency. When nil the
+	// balances of all active accounts are used.
+	StartBalanceCents *int64
+	From              *time.Time
+	Months            int
+	OneOffs           []ForecastItem
+}
+
+// ForecastEvent is one projected movement. AmountCents is signed and in the reporting currency.
+type ForecastEvent struct {
+	Date        time.Time `json:"date"`
+	Kind        string    `json:"kind"`
+	Title       string    `json:"title"`
+	AmountCents int64     `json:"amountCents"`
+	RefID       string    `json:"refId,omitempty"`
+}
+
+type ForecastPoint struct {
+	Date         time.Time `json:"date"`
+	InflowCents  int64     `json:"inflowCents"`
+	OutflowCents int64     `json:"outflowCents"`
+	BalanceCents int64     `json:"balanceCents"`
+}
+
+type CashFlowForecast struct {
+	ReportingCurrency  string          `json:"reportingCurrency"`
+	From               time.Time       `json:"from"`
+	To                 time.Time       `json:"to"`
+	StartBalanceCents  int64           `json:"startBalanceCents"`
+	EndBalanceCents    int64           `json:"endBalanceCents"`
+	LowestBalanceCents int64           `json:"lowestBalanceCents"`
+	LowestBalanceDate  time.Time       `json:"lowestBalanceDate"`
+	NegativeDays       []time.Time     `json:"negativeDays"`
+	Points             []ForecastPoint `json:"points"`
+	Events             []ForecastEvent `json:"events"`
+	MissingRates       []string        `json:"missingRates,omitempty"`
+}
+
+// Forecast projects the daily balance from a starting balance (by default the sum of account balances) using recurring income and expense
+// schedules, unpaid invoices (overdue ones land on the first day), future-dated transactions that are
+// not tied to a schedule or invoice, and the given one-off items.
+func (s *Service) Forecast(ctx context.Context, in ForecastInput) (*CashFlowForecast, error) {
+	from := dateOnly(time.Now().UTC())
+	if in.From != nil && !in.From.IsZero() {
+		from = dateOnly(*in.From)
+	}
+	months := in.Months
+	if months <= 0 {
+		months = defaultForecastMonths
+	}
+	if months > maxForecastMonths {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Forecast horizon is limited to 24 months.")
+	}
+	to := from.AddDate(0, months, -1)
+
+	incomes, err := s.repo.ListIncomeSources(ctx, repository.IncomeSourceFilter{ActiveOnly: true})
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load income sources.", err)
+	}
+	expenses, err := s.repo.ListExpenses(ctx, true)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load expenses.", err)
+	}
+	invoices, err := s.repo.ListInvoicesDueBetween(ctx, time.Time{}, to)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load invoices.", err)
+	}
+	future, err := s.repo.ListTransactionsBetween(ctx, from, to)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load transactions.", err)
+	}
+	conv, err := s.newConverter(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	invoiceCurrency, err := s.invoiceCurrency(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var startBalance int64
+	if in.StartBalanceCents != nil {
+		startBalance = *in.StartBalanceCents
+	} else {
+		startBalance, err = s.accountsBalance(ctx, conv, from.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var events []ForecastEvent
+	for _, ev := range scheduledEvents(incomes, expenses, from, to) {
+		date, _ := time.Parse("2006-01-02", ev.Date)
+		amount := conv.convert(ev.AmountCents, ev.Currency, date)
+		if ev.Type == "expense" {
+			amount = -amount
+		}
+		events = append(events, ForecastEvent{Date: date, Kind: ev.Type, Title: ev.Title, AmountCents: amount, RefID: ev.RefID})
+	}
+	for _, inv := range invoices {
+		due := inv.TotalCents - inv.PaidCents
+		if due <= 0 {
+			continue
+		}
+		date := laterDate(dateOnly(inv.DueDate), from)
+		events = append(events, ForecastEvent{
+			Date:        date,
+			Kind:        "invoice",
+			Title:       inv.Name,
+			AmountCents: -conv.convert(due, invoiceCurrency, date),
+			RefID:       inv.ID.String(),
+		})
+	}
+	today := dateOnly(time.Now().UTC())
+	for _, tx := range future {
+		if !tx.Date.After(today) || tx.Type == "transfer" || tx.IncomeSourceID != nil || tx.ExpenseID != nil || tx.InvoiceID != nil {
+			continue
+		}
+		amount := conv.convert(tx.AmountCents, tx.Currency, tx.Date)
+		events = append(events, ForecastEvent{
+			Date:        dateOnly(tx.Date),
+			Kind:        "transaction",
+			Title:       tx.Description,
+			AmountCents: signedAmount(tx.Type, amount),
+			RefID:       tx.ID.String(),
+		})
+	}
+	for _, item := range in.OneOffs {
+		if item.Date.IsZero() || item.AmountCents == 0 {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "One-off items need a date and a non-zero amount.")
+		}
+		date := dateOnly(item.Date)
+		if date.Before(from) || date.After(to) {
+			continue
+		}
+		title := strings.TrimSpace(item.Description)
+		if title == "" {
+			title = "One-off"
+		}
+		events = append(events, ForecastEvent{
+			Date:        date,
+			Kind:        "one_off",
+			Title:       title,
+			AmountCents: conv.convert(item.AmountCents, item.Currency, date),
+		})
+	}
+
+	out := buildForecast(from, to, startBalance, events)
+	out.ReportingCurrency = conv.target
+	out.MissingRates = conv.missingCurrencies()
+	return out, nil
+}
+
+// buildForecast folds events into one point per day between from and to inclusive.
+func buildForecast(from, to time.Time, startBalance int64, events []ForecastEvent) *CashFlowForecast {
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Date.Before(events[j].Date) })
+	out := &CashFlowForecast{
+		From:               from,
+		To:                 to,
+		StartBalanceCents:  startBalance,
+		LowestBalanceCents: startBalance,
+		LowestBalanceDate:  from,
+		NegativeDays:       []time.Time{},
+		Points:             []ForecastPoint{},
+		Events:             events,
+	}
+	if out.Events == nil {
+		out.Events = []ForecastEvent{}
+	}
+	balance := startBalance
+	i := 0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		p := ForecastPoint{Date: day}
+		for ; i < len(events) && !events[i].Date.After(day); i++ {
+			if events[i].AmountCents >= 0 {
+				p.InflowCents += events[i].AmountCents
+			} else {
+				p.OutflowCents -= events[i].AmountCents
+			}
+		}
+		balance += p.InflowCents - p.OutflowCents
+		p.BalanceCents = balance
+		out.Points = append(out.Points, p)
+		if balance < out.LowestBalanceCents {
+			out.LowestBalanceCents, out.LowestBalanceDate = balance, day
+		}
+		if balance < 0 {
+			out.NegativeDays = append(out.NegativeDays, day)
+		}
+	}
+	out.EndBalanceCents = balance
+	return out
+}
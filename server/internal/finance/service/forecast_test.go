@@ -0,0 +1,28 @@
+package service
+
+import "testing"
+
+func TestBuildForecastTracksLowestAndNegativeDays(t *testing.T) {
+	from := day(2026, 3, 1)
+	events := []ForecastEvent{
+		{Date: day(2026, 3, 5), AmountCents: -1500},
+		{Date: day(2026, 3, 3), AmountCents: -200},
+		{Date: day(2026, 3, 6), AmountCents: 3000},
+	}
+	f := buildForecast(from, day(2026, 3, 7), 1000, events)
+	if len(f.Points) != 7 {
+		t.Fatalf("got %d points", len(f.Points))
+	}
+	if f.EndBalanceCents != 2300 {
+		t.Fatalf("end balance %d", f.EndBalanceCents)
+	}
+	if f.LowestBalanceCents != -700 || !f.LowestBalanceDate.Equal(day(2026, 3, 5)) {
+		t.Fatalf("lowest %d on %v", f.LowestBalanceCents, f.LowestBalanceDate)
+	}
+	if len(f.NegativeDays) != 1 || !f.NegativeDays[0].Equal(day(2026, 3, 5)) {
+		t.Fatalf("negative days %v", f.NegativeDays)
+	}
+	if p := f.Points[4]; p.OutflowCents != 1500 || p.InflowCents != 0 {
+		t.Fatalf("unexpected point %+v", p)
+	}
+}
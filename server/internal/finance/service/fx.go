@@ -0,0 +1,421 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/repository"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+type UpdateSettingsInput struct {
+	ReportingCurrency *string
+	BillingName       *string
+	BillingTaxID      *string
+	BillingEmail      *string
+	BillingAddress    *string
+	PixKey            *string
+	PixMerchantName   *string
+	PixMerchantCity   *string
+	TaxRegime         *string
+	TaxAnnex          *string
+	TaxBracket        *int
+}
+
+func (s *Service) GetSettings(ctx context.Context) (*models.FinanceSettings, error) {
+	row, err := s.repo.EnsureSettings(ctx)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load finance settings.", err)
+	}
+	return row, nil
+}
+
+func (s *Service) UpdateSettings(ctx context.Context, in UpdateSettingsInput) (*models.FinanceSettings, error) {
+	row, err := s.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if in.ReportingCurrency != nil {
+		cur := normalizeCurrency(*in.ReportingCurrency)
+		if !validCurrencyCode(cur) {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Reporting currency must be a 3-letter code.")
+		}
+		row.ReportingCurrency = cur
+	}
+	if in.BillingName != nil {
+		row.BillingName = strings.TrimSpace(*in.BillingName)
+	}
+	if in.BillingTaxID != nil {
+		row.BillingTaxID = strings.TrimSpace(*in.BillingTaxID)
+	}
+	if in.BillingEmail != nil {
+		row.BillingEmail = strings.TrimSpace(*in.BillingEmail)
+	}
+	if in.BillingAddress != nil {
+		row.BillingAddress = strings.TrimSpace(*in.BillingAddress)
+	}
+	if in.PixKey != nil {
+		row.PixKey = strings.TrimSpace(*in.PixKey)
+	}
+	if in.PixMerchantName != nil {
+		row.PixMerchantName = strings.TrimSpace(*in.PixMerchantName)
+	}
+	if in.PixMerchantCity != nil {
+		row.PixMerchantCity = strings.TrimSpace(*in.PixMerchantCity)
+	}
+	if in.TaxRegime != nil {
+		regime := strings.TrimSpace(strings.ToLower(*in.TaxRegime))
+		if regime != "" && !taxRegimes[regime] {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Tax regime must be carne_leao, simples or mei.")
+		}
+		row.TaxRegime = regime
+	}
+	if in.TaxAnnex != nil {
+		annex := strings.TrimSpace(strings.ToUpper(*in.TaxAnnex))
+		if annex != "" && !simplesAnnexes[annex] {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Tax annex must be I, II, III, IV or V.")
+		}
+		row.TaxAnnex = annex
+	}
+	if in.TaxBracket != nil {
+		if *in.TaxBracket < 0 {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Tax bracket cannot be negative.")
+		}
+		row.TaxBracket = *in.TaxBracket
+	}
+	if err := s.repo.SaveSettings(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update finance settings.", err)
+	}
+	return row, nil
+}
+
+type ExchangeRateFilter struct {
+	Currency string
+	From     *time.Time
+	To       *time.Time
+}
+
+type CreateExchangeRateInput struct {
+	Date          time.Time
+	BaseCurrency  string
+	QuoteCurrency string
+	Rate          float64
+}
+
+func (s *Service) ListExchangeRates(ctx context.Context, f ExchangeRateFilter) ([]models.ExchangeRate, error) {
+	cur := ""
+	if strings.TrimSpace(f.Currency) != "" {
+		cur = normalizeCurrency(f.Currency)
+	}
+	rows, err := s.repo.ListExchangeRates(ctx, repository.ExchangeRateFilter{
+		Currency: cur,
+		From:     f.From,
+		To:       f.To,
+	})
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load exchange rates.", err)
+	}
+	return rows, nil
+}
+
+// CreateExchangeRate records a manual rate; an existing rate for the same pair and day is replaced.
+func (s *Service) CreateExchangeRate(ctx context.Context, in CreateExchangeRateInput) (*models.ExchangeRate, error) {
+	row, err := buildExchangeRate(in, "manual")
+	if err != nil {
+		return nil, err
+	}
+	rows := []models.ExchangeRate{*row}
+	if err := s.repo.UpsertExchangeRates(ctx, rows); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to save exchange rate.", err)
+	}
+	saved, err := s.repo.FindExchangeRate(ctx, row.Date, row.BaseCurrency, row.QuoteCurrency)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load exchange rate.", err)
+	}
+	return saved, nil
+}
+
+func (s *Service) DeleteExchangeRate(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.DeleteExchangeRate(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound(apperrors.CodeInternal, "Exchange rate not found.")
+		}
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to delete exchange rate.", err)
+	}
+	return nil
+}
+
+type ExchangeRateImportResult struct {
+	Imported int `json:"imported"`
+}
+
+// ImportExchangeRatesCSV reads daily rates with a header row containing date, base, quote and rate
+// columns. Comma or semicolon delimiters and decimal commas (as in BCB exports) are accepted.
+func (s *Service) ImportExchangeRatesCSV(ctx context.Context, r io.Reader) (*ExchangeRateImportResult, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Failed to read CSV file.")
+	}
+	text := strings.TrimPrefix(string(raw), "\ufeff")
+	reader := csv.NewReader(strings.NewReader(text))
+	firstLine, _, _ := strings.Cut(text, "\n")
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "CSV file is malformed.")
+	}
+	if len(records) < 2 {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "CSV file has no rate rows.")
+	}
+	cols := map[string]int{}
+	for i, h := range records[0] {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range []string{"date", "base", "quote", "rate"} {
+		if _, ok := cols[required]; !ok {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "CSV header must include date, base, quote and rate.")
+		}
+	}
+	rows := make([]models.ExchangeRate, 0, len(records)-1)
+	for n, rec := range records[1:] {
+		line := n + 2
+		field := func(name string) string {
+			i := cols[name]
+			if i >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[i])
+		}
+		if field("date") == "" && field("rate") == "" {
+			continue
+		}
+		date, err := parseFlexibleDate(field("date"))
+		if err != nil {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Invalid date on CSV line "+strconv.Itoa(line)+".")
+		}
+		rate, err := parseDecimal(field("rate"))
+		if err != nil {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Invalid rate on CSV line "+strconv.Itoa(line)+".")
+		}
+		row, err := buildExchangeRate(CreateExchangeRateInput{
+			Date:          date,
+			BaseCurrency:  field("base"),
+			QuoteCurrency: field("quote"),
+			Rate:          rate,
+		}, "csv")
+		if err != nil {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Invalid rate on CSV line "+strconv.Itoa(line)+".")
+		}
+		rows = append(rows, *row)
+	}
+	if err := s.repo.UpsertExchangeRates(ctx, rows); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to import exchange rates.", err)
+	}
+	return &ExchangeRateImportResult{Imported: len(rows)}, nil
+}
+
+func buildExchangeRate(in CreateExchangeRateInput, source string) (*models.ExchangeRate, error) {
+	base := normalizeCurrency(in.BaseCurrency)
+	quote := normalizeCurrency(in.QuoteCurrency)
+	if !validCurrencyCode(base) || !validCurrencyCode(quote) {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Currencies must be 3-letter codes.")
+	}
+	if base == quote {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Base and quote currencies must differ.")
+	}
+	if in.Rate <= 0 || math.IsNaN(in.Rate) || math.IsInf(in.Rate, 0) {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Rate must be positive.")
+	}
+	if in.Date.IsZero() {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Date is required.")
+	}
+	return &models.ExchangeRate{
+		Date:          in.Date.UTC().Truncate(24 * time.Hour),
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          in.Rate,
+		Source:        source,
+	}, nil
+}
+
+// CurrencyTotals holds amounts in their original currency, before conversion.
+type CurrencyTotals struct {
+	IncomeCents  int64 `json:"incomeCents"`
+	ExpenseCents int64 `json:"expenseCents"`
+}
+
+// ConvertedTransaction is a transaction with its value in the reporting currency alongside the original amount.
+type ConvertedTransaction struct {
+	models.Transaction
+	ReportingAmountCents int64 `json:"reportingAmountCents"`
+}
+
+// converter turns amounts into the reporting currency using the rate in effect on each date.
+type converter struct {
+	target     string
+	pairs      map[string][]models.ExchangeRate
+	currencies []string
+	missing    map[string]bool
+}
+
+func (s *Service) newConverter(ctx context.Context, until time.Time) (*converter, error) {
+	settings, err := s.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.newConverterTo(ctx, normalizeCurrency(settings.ReportingCurrency), until)
+}
+
+// newConverterTo converts into target rather than the reporting currency.
+func (s *Service) newConverterTo(ctx context.Context, target string, until time.Time) (*converter, error) {
+	rates, err := s.repo.ListRatesUntil(ctx, until)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load exchange rates.", err)
+	}
+	return newRateConverter(target, rates), nil
+}
+
+// newRateConverter indexes rates, sorted oldest first, by pair.
+func newRateConverter(target string, rates []models.ExchangeRate) *converter {
+	c := &converter{
+		target:  target,
+		pairs:   map[string][]models.ExchangeRate{},
+		missing: map[string]bool{},
+	}
+	seen := map[string]bool{}
+	for _, r := range rates {
+		key := r.BaseCurrency + "/" + r.QuoteCurrency
+		c.pairs[key] = append(c.pairs[key], r)
+		for _, cur := range []string{r.BaseCurrency, r.QuoteCurrency} {
+			if !seen[cur] {
+				seen[cur] = true
+				c.currencies = append(c.currencies, cur)
+			}
+		}
+	}
+	sort.Strings(c.currencies)
+	return c
+}
+
+// convert returns amount expressed in the reporting currency, through a direct or inverse rate or,
+// failing both, a cross rate via one intermediate currency. Amounts without any rate convert to 0,
+// so they stay out of totals, and their currency is remembered as missing.
+func (c *converter) convert(amount int64, currency string, date time.Time) int64 {
+	currency = normalizeCurrency(currency)
+	if currency == c.target || amount == 0 {
+		return amount
+	}
+	if rate, ok := c.pairRate(currency, c.target, date); ok {
+		return int64(math.Round(float64(amount) * rate))
+	}
+	for _, via := range c.currencies {
+		if via == currency || via == c.target {
+			continue
+		}
+		first, ok := c.pairRate(currency, via, date)
+		if !ok {
+			continue
+		}
+		if second, ok := c.pairRate(via, c.target, date); ok {
+			return int64(math.Round(float64(amount) * first * second))
+		}
+	}
+	c.missing[currency] = true
+	return 0
+}
+
+// pairRate is the rate turning from into to on date, read from either direction of the pair.
+func (c *converter) pairRate(from, to string, date time.Time) (float64, bool) {
+	if rate, ok := c.rateOn(from+"/"+to, date); ok {
+		return rate, true
+	}
+	if rate, ok := c.rateOn(to+"/"+from, date); ok {
+		return 1 / rate, true
+	}
+	return 0, false
+}
+
+// rateOn picks the latest rate published on or before date, falling back to the earliest one
+// after it so a transaction predating the first imported rate still converts.
+func (c *converter) rateOn(pair string, date time.Time) (float64, bool) {
+	rates := c.pairs[pair]
+	if len(rates) == 0 {
+		return 0, false
+	}
+	day := date.UTC().Truncate(24 * time.Hour)
+	i := sort.Search(len(rates), func(i int) bool { return rates[i].Date.After(day) })
+	if i == 0 {
+		return rates[0].Rate, true
+	}
+	return rates[i-1].Rate, true
+}
+
+func (c *converter) missingCurrencies() []string {
+	if len(c.missing) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(c.missing))
+	for cur := range c.missing {
+		out = append(out, cur)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (c *converter) transactions(rows []models.Transaction) []ConvertedTransaction {
+	out := make([]ConvertedTransaction, 0, len(rows))
+	for _, tx := range rows {
+		out = append(out, ConvertedTransaction{
+			Transaction:          tx,
+			ReportingAmountCents: c.convert(tx.AmountCents, tx.Currency, tx.Date),
+		})
+	}
+	return out
+}
+
+func validCurrencyCode(c string) bool {
+	if len(c) != 3 {
+		return false
+	}
+	for _, r := range c {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func parseFlexibleDate(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	var lastErr error
+	for _, layout := range []string{"2006-01-02", "02/01/2006", time.RFC3339} {
+		t, err := time.Parse(layout, v)
+		if err == nil {
+			return t.UTC(), nil
+		}
+		lastErr = err
+	}
+	return time.Time{}, lastErr
+}
+
+func parseDecimal(v string) (float64, error) {
+	v = strings.TrimSpace(v)
+	if strings.Contains(v, ",") {
+		v = strings.ReplaceAll(v, ".", "")
+		v = strings.ReplaceAll(v, ",", ".")
+	}
+	return strconv.ParseFloat(v, 64)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func TestConverter(t *testing.T) {
+	rate := func(date time.Time, base, quote string, r float64) models.ExchangeRate {
+		return models.ExchangeRate{Date: date, BaseCurrency: base, QuoteCurrency: quote, Rate: r}
+	}
+	conv := newRateConverter("BRL", []models.ExchangeRate{
+		rate(day(2025, 1, 1), "USD", "BRL", 5),
+		rate(day(2025, 1, 1), "BRL", "ARS", 200),
+		rate(day(2025, 1, 1), "EUR", "USD", 1.1),
+		rate(day(2025, 2, 1), "USD", "BRL", 6),
+	})
+	cases := []struct {
+		name     string
+		amount   int64
+		currency string
+		date     time.Time
+		want     int64
+	}{
+		{"reporting currency", 1000, "brl", day(2025, 1, 15), 1000},
+		{"direct pair", 1000, "USD", day(2025, 1, 15), 5000},
+		{"rate of the same day", 1000, "USD", day(2025, 2, 1), 6000},
+		{"latest rate on or before the date", 1000, "USD", day(2025, 3, 10), 6000},
+		{"before the first rate", 1000, "USD", day(2024, 6, 1), 5000},
+		{"inverse pair", 20000, "ARS", day(2025, 1, 15), 100},
+		{"cross rate", 1000, "EUR", day(2025, 1, 15), 5500},
+		{"missing rate", 1000, "JPY", day(2025, 1, 15), 0},
+	}
+	for _, c := range cases {
+		if got := conv.convert(c.amount, c.currency, c.date); got != c.want {
+			t.Fatalf("%s: got %d want %d", c.name, got, c.want)
+		}
+	}
+	if got := conv.missingCurrencies(); len(got) != 1 || got[0] != "JPY" {
+		t.Fatalf("missing currencies: %v", got)
+	}
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+type CreateSavingsGoalInput struct {
+	Name        string
+	TargetCents int64
+	Currency    string
+	StartDate   *time.Time
+	Deadline    *time.Time
+	AccountID   *uuid.UUID
+	Category    string
+	Notes       string
+}
+
+type UpdateSavingsGoalInput struct {
+	Name        *string
+	TargetCents *int64
+	Currency    *string
+	StartDate   *time.Time
+	Deadline    *time.Time
+	DeadlineSet bool
+	AccountID   *uuid.UUID
+	AccountSet  bool
+	Category    *string
+	Archived    *bool
+	Notes       *string
+}
+
+// SavingsGoalProgress is a goal with its contributions converted to the goal's currency. Status is
+// achieved, on_track or off_track (against a straight line from StartDate to Deadline), overdue when
+// the deadline passed short of the target, or open for goals without a deadline. MonthsLeft counts
+// the current month.
+type SavingsGoalProgress struct {
+	models.SavingsGoal
+	SavedCents           int64      `json:"savedCents"`
+	RemainingCents       int64      `json:"remainingCents"`
+	Percent              float64    `json:"percent"`
+	ExpectedCents        int64      `json:"expectedCents"`
+	MonthsLeft           int        `json:"monthsLeft"`
+	RequiredMonthlyCents int64      `json:"requiredMonthlyCents"`
+	Status               string     `json:"status"`
+	LastContributionDate *time.Time `json:"lastContributionDate,omitempty"`
+	MissingRates         []string   `json:"missingRates,omitempty"`
+}
+
+func (s *Service) ListSavingsGoals(ctx context.Context, includeArchived bool, query string) ([]SavingsGoalProgress, error) {
+	rows, err := s.repo.ListSavingsGoals(ctx, includeArchived, query)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load savings goals.", err)
+	}
+	return s.goalsProgress(ctx, rows, time.Now().UTC())
+}
+
+func (s *Service) GetSavingsGoal(ctx context.Context, id uuid.UUID) (*SavingsGoalProgress, error) {
+	row, err := s.findSavingsGoal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s.goalsProgress(ctx, []models.SavingsGoal{*row}, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return &out[0], nil
+}
+
+func (s *Service) CreateSavingsGoal(ctx context.Context, in CreateSavingsGoalInput) (*SavingsGoalProgress, error) {
+	start := dateOnly(time.Now().UTC())
+	if in.StartDate != nil && !in.StartDate.IsZero() {
+		start = dateOnly(*in.StartDate)
+	}
+	row := &models.SavingsGoal{
+		Name:        strings.TrimSpace(in.Name),
+		TargetCents: in.TargetCents,
+		Currency:    normalizeCurrency(in.Currency),
+		StartDate:   start,
+		AccountID:   in.AccountID,
+		Category:    categorySlug(in.Category),
+		Notes:       strings.TrimSpace(in.Notes),
+	}
+	if in.Deadline != nil && !in.Deadline.IsZero() {
+		d := dateOnly(*in.Deadline)
+		row.Deadline = &d
+	}
+	if err := s.validateSavingsGoal(ctx, row); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateSavingsGoal(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to create savings goal.", err)
+	}
+	out := goalProgress(*row, 0, dateOnly(time.Now().UTC()))
+	return &out, nil
+}
+
+func (s *Service) UpdateSavingsGoal(ctx context.Context, id uuid.UUID, in UpdateSavingsGoalInput) (*SavingsGoalProgress, error) {
+	row, err := s.findSavingsGoal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if in.Name != nil {
+		row.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.TargetCents != nil {
+		row.TargetCents = *in.TargetCents
+	}
+	if in.Currency != nil {
+		row.Currency = normalizeCurrency(*in.Currency)
+	}
+	if in.StartDate != nil && !in.StartDate.IsZero() {
+		row.StartDate = dateOnly(*in.StartDate)
+	}
+	if in.DeadlineSet {
+		row.Deadline = nil
+		if in.Deadline != nil && !in.Deadline.IsZero() {
+			d := dateOnly(*in.Deadline)
+			row.Deadline = &d
+		}
+	}
+	if in.AccountSet {
+		row.AccountID = in.AccountID
+		if in.AccountID != nil && *in.AccountID == uuid.Nil {
+			row.AccountID = nil
+		}
+	}
+	if in.Category != nil {
+		row.Category = categorySlug(*in.Category)
+	}
+	if in.Archived != nil {
+		row.Archived = *in.Archived
+	}
+	if in.Notes != nil {
+		row.Notes = strings.TrimSpace(*in.Notes)
+	}
+	if err := s.validateSavingsGoal(ctx, row); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveSavingsGoal(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update savings goal.", err)
+	}
+	return s.GetSavingsGoal(ctx, id)
+}
+
+// DeleteSavingsGoal removes the goal; its transactions stay in the ledger untagged.
+func (s *Service) DeleteSavingsGoal(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.DeleteSavingsGoal(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound(apperrors.CodeInternal, "Savings goal not found.")
+		}
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to delete savings goal.", err)
+	}
+	return nil
+}
+
+// SavingsGoalContributions lists the transactions tagged with the goal, oldest first.
+func (s *Service) SavingsGoalContributions(ctx context.Context, id uuid.UUID) ([]models.Transaction, error) {
+	if _, err := s.findSavingsGoal(ctx, id); err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.ListGoalTransactions(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load transactions.", err)
+	}
+	return rows, nil
+}
+
+// goalsProgress sums the contributions of each goal in its own currency, converting transactions at
+// their date.
+func (s *Service) goalsProgress(ctx context.Context, goals []models.SavingsGoal, now time.Time) ([]SavingsGoalProgress, error) {
+	today := dateOnly(now)
+	ids := make([]uuid.UUID, 0, len(goals))
+	for _, g := range goals {
+		ids = append(ids, g.ID)
+	}
+	txs, err := s.repo.ListGoalTransactions(ctx, ids)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load transactions.", err)
+	}
+	byGoal := map[uuid.UUID][]models.Transaction{}
+	for _, tx := range txs {
+		byGoal[*tx.GoalID] = append(byGoal[*tx.GoalID], tx)
+	}
+	convs := map[string]*converter{}
+	out := make([]SavingsGoalProgress, 0, len(goals))
+	for _, g := range goals {
+		conv, ok := convs[g.Currency]
+		if !ok {
+			if conv, err = s.newConverterTo(ctx, g.Currency, today); err != nil {
+				return nil, err
+			}
+			convs[g.Currency] = conv
+		}
+		var saved int64
+		for _, tx := range byGoal[g.ID] {
+			saved += conv.convert(goalContribution(g, tx), tx.Currency, tx.Date)
+		}
+		p := goalProgress(g, saved, today)
+		if n := len(byGoal[g.ID]); n > 0 {
+			last := byGoal[g.ID][n-1].Date
+			p.LastContributionDate = &last
+		}
+		p.MissingRates = conv.missingCurrencies()
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// tagSavingsGoal checks the goal a new transaction names or, when it names none, links it to the only
+// active goal whose account or category it matches. A nil UUID opts out of the matching.
+func (s *Service) tagSavingsGoal(ctx context.Context, row *models.Transaction) error {
+	if row.GoalID != nil {
+		if *row.GoalID == uuid.Nil {
+			row.GoalID = nil
+			return nil
+		}
+		_, err := s.findSavingsGoal(ctx, *row.GoalID)
+		return err
+	}
+	goals, err := s.repo.ListSavingsGoals(ctx, false, "")
+	if err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to load savings goals.", err)
+	}
+	row.GoalID = matchSavingsGoal(goals, row)
+	return nil
+}
+
+func (s *Service) findSavingsGoal(ctx context.Context, id uuid.UUID) (*models.SavingsGoal, error) {
+	row, err := s.repo.FindSavingsGoal(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound(apperrors.CodeInternal, "Savings goal not found.")
+		}
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load savings goal.", err)
+	}
+	return row, nil
+}
+
+func (s *Service) validateSavingsGoal(ctx context.Context, row *models.SavingsGoal) error {
+	if row.Name == "" {
+		return apperrors.Invalid(apperrors.CodeInternal, "Name is required.")
+	}
+	if row.TargetCents <= 0 {
+		return apperrors.Invalid(apperrors.CodeInternal, "targetCents must be positive.")
+	}
+	if row.Deadline != nil && row.Deadline.Before(row.StartDate) {
+		return apperrors.Invalid(apperrors.CodeInternal, "Deadline must not be before the start date.")
+	}
+	if row.AccountID != nil {
+		if _, err := s.findAccount(ctx, *row.AccountID); err != nil {
+			return err
+		}
+	}
+	if row.Category != "" {
+		idx, err := s.categoryIndex(ctx)
+		if err != nil {
+			return err
+		}
+		_, isExpense := idx.find("expense", row.Category)
+		_, isIncome := idx.find("income", row.Category)
+		if !isExpense && !isIncome {
+			return apperrors.Invalid(apperrors.CodeInternal, "Unknown category.")
+		}
+	}
+	return nil
+}
+
+// goalContribution is how a tagged transaction moves the goal: transfers out of the goal's account
+// are withdrawals, everything else adds.
+func goalContribution(goal models.SavingsGoal, tx models.Transaction) int64 {
+	if tx.Type == "transfer" && goal.AccountID != nil && tx.AccountID != nil && *tx.AccountID == *goal.AccountID {
+		return -tx.AmountCents
+	}
+	return tx.AmountCents
+}
+
+// matchSavingsGoal returns the goal a transaction belongs to by account or category, or nil when no
+// goal or more than one matches.
+func matchSavingsGoal(goals []models.SavingsGoal, tx *models.Transaction) *uuid.UUID {
+	var found *uuid.UUID
+	for _, g := range goals {
+		byAccount := tx.Type == "transfer" && g.AccountID != nil && tx.ToAccountID != nil && *tx.ToAccountID == *g.AccountID
+		byCategory := g.Category != "" && tx.Type != "transfer" && tx.Category == g.Category
+		if !byAccount && !byCategory {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		id := g.ID
+		found = &id
+	}
+	return found
+}
+
+// goalProgress compares saved with the target. The expected amount grows linearly from StartDate to
+// Deadline; the required monthly contribution spreads what is left over the remaining months.
+func goalProgress(goal models.SavingsGoal, saved int64, today time.Time) SavingsGoalProgress {
+	p := SavingsGoalProgress{SavingsGoal: goal, SavedCents: saved, RemainingCents: goal.TargetCents - saved}
+	if p.RemainingCents < 0 {
+		p.RemainingCents = 0
+	}
+	if goal.TargetCents > 0 {
+		p.Percent = math.Round(float64(saved)*10000/float64(goal.TargetCents)) / 100
+	}
+	switch {
+	case p.RemainingCents == 0:
+		p.Status = "achieved"
+		p.ExpectedCents = goal.TargetCents
+	case goal.Deadline == nil:
+		p.Status = "open"
+	case today.After(*goal.Deadline):
+		p.Status = "overdue"
+		p.ExpectedCents = goal.TargetCents
+		p.RequiredMonthlyCents = p.RemainingCents
+	default:
+		deadline := *goal.Deadline
+		p.MonthsLeft = (deadline.Year()-today.Year())*12 + int(deadline.Month()-today.Month()) + 1
+		p.RequiredMonthlyCents = (p.RemainingCents + int64(p.MonthsLeft) - 1) / int64(p.MonthsLeft)
+		p.ExpectedCents = goal.TargetCents
+		if total := deadline.Sub(goal.StartDate); total > 0 {
+			elapsed := today.Sub(goal.StartDate)
+			if elapsed < 0 {
+				elapsed = 0
+			}
+			p.ExpectedCents = int64(math.Round(float64(goal.TargetCents) * float64(elapsed) / float64(total)))
+		}
+		p.Status = "on_track"
+		if saved < p.ExpectedCents {
+			p.Status = "off_track"
+		}
+	}
+	return p
+}
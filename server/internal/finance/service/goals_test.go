@@ -0,0 +1,62 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func TestGoalProgress(t *testing.T) {
+	deadline := day(2025, 12, 31)
+	goal := models.SavingsGoal{TargetCents: 600000, StartDate: day(2025, 1, 1), Deadline: &deadline}
+
+	// Halfway through the year with a third saved: behind the straight line.
+	p := goalProgress(goal, 200000, day(2025, 7, 2))
+	if p.Status != "off_track" || p.RemainingCents != 400000 || p.MonthsLeft != 6 || p.RequiredMonthlyCents != 66667 {
+		t.Fatalf("off track: %+v", p)
+	}
+	if p.ExpectedCents != 300000 || p.Percent != 33.33 {
+		t.Fatalf("expected %d percent %v", p.ExpectedCents, p.Percent)
+	}
+	if p := goalProgress(goal, 350000, day(2025, 7, 2)); p.Status != "on_track" {
+		t.Fatalf("on track: %+v", p)
+	}
+	if p := goalProgress(goal, 600500, day(2025, 7, 2)); p.Status != "achieved" || p.RemainingCents != 0 || p.RequiredMonthlyCents != 0 {
+		t.Fatalf("achieved: %+v", p)
+	}
+	if p := goalProgress(goal, 500000, day(2026, 1, 5)); p.Status != "overdue" || p.RequiredMonthlyCents != 100000 {
+		t.Fatalf("overdue: %+v", p)
+	}
+	if p := goalProgress(models.SavingsGoal{TargetCents: 1000}, 0, day(2025, 7, 2)); p.Status != "open" || p.MonthsLeft != 0 {
+		t.Fatalf("open: %+v", p)
+	}
+}
+
+func TestMatchSavingsGoal(t *testing.T) {
+	savings := uuid.New()
+	notebook := models.SavingsGoal{ID: uuid.New(), AccountID: &savings}
+	trip := models.SavingsGoal{ID: uuid.New(), Category: "travel"}
+	goals := []models.SavingsGoal{notebook, trip}
+
+	if got := matchSavingsGoal(goals, &models.Transaction{Type: "transfer", ToAccountID: &savings}); got == nil || *got != notebook.ID {
+		t.Fatalf("transfer into the goal account: %v", got)
+	}
+	if got := matchSavingsGoal(goals, &models.Transaction{Type: "expense", Category: "travel"}); got == nil || *got != trip.ID {
+		t.Fatalf("category: %v", got)
+	}
+	if got := matchSavingsGoal(goals, &models.Transaction{Type: "transfer", AccountID: &savings}); got != nil {
+		t.Fatalf("transfer out of the goal account must not match: %v", got)
+	}
+	both := append(goals, models.SavingsGoal{ID: uuid.New(), Category: "travel"})
+	if got := matchSavingsGoal(both, &models.Transaction{Type: "expense", Category: "travel"}); got != nil {
+		t.Fatalf("ambiguous match: %v", got)
+	}
+
+	if c := goalContribution(notebook, models.Transaction{Type: "transfer", AccountID: &savings, AmountCents: 5000}); c != -5000 {
+		t.Fatalf("withdrawal: %d", c)
+	}
+	if c := goalContribution(notebook, models.Transaction{Type: "expense", AmountCents: 5000}); c != 5000 {
+		t.Fatalf("contribution: %d", c)
+	}
+}
@@ -0,0 +1,362 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/statement"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	importPreview   = "preview"
+	importCommitted = "committed"
+	importUndone    = "undone"
+)
+
+// ImportRow is one parsed statement line as shown in the preview.
+type ImportRow struct {
+	Index           int        `json:"index"`
+	Date            time.Time  `json:"date"`
+	Type            string     `json:"type"`
+	AmountCents     int64      `json:"amountCents"`
+	Currency        string     `json:"currency"`
+	Description     string     `json:"description"`
+	ExternalID      string     `json:"externalId,omitempty"`
+	Duplicate       bool       `json:"duplicate"`
+	DuplicateReason string     `json:"duplicateReason,omitempty"`
+	DuplicateOf     *uuid.UUID `json:"duplicateOf,omitempty"`
+	TransactionID   *uuid.UUID `json:"transactionId,omitempty"`
+}
+
+// ImportDetail is an import with its rows decoded.
+type ImportDetail struct {
+	models.TransactionImport
+	Rows []ImportRow `json:"rows"`
+}
+
+type PreviewImportInput struct {
+	Filename  string
+	Format    string
+	Currency  string
+	AccountID *uuid.UUID
+	Mapping   statement.CSVMapping
+	Reader    io.Reader
+}
+
+type CommitImportInput struct {
+	// SkipRows lists row indexes to leave out.
+	SkipRows []int
+	// ForceRows lists duplicate row indexes to import anyway.
+	ForceRows []int
+}
+
+func (s *Service) ListImports(ctx context.Context) ([]models.TransactionImport, error) {
+	rows, err := s.repo.ListImports(ctx)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load imports.", err)
+	}
+	return rows, nil
+}
+
+func (s *Service) GetImport(ctx context.Context, id uuid.UUID) (*ImportDetail, error) {
+	imp, err := s.repo.FindImport(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound(apperrors.CodeInternal, "Import not found.")
+		}
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load import.", err)
+	}
+	return decodeImport(imp)
+}
+
+// PreviewImport parses an OFX or CSV statement, flags rows that already exist and stores the result
+// so it can be committed without uploading the file again.
+func (s *Service) PreviewImport(ctx context.Context, in PreviewImportInput) (*ImportDetail, error) {
+	data, err := io.ReadAll(in.Reader)
+	if err != nil {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Failed to read statement file.")
+	}
+	format := strings.TrimSpace(strings.ToLower(in.Format))
+	if format == "" {
+		format = "csv"
+		ext := strings.ToLower(filepath.Ext(in.Filename))
+		if ext == ".ofx" || ext == ".qfx" || statement.LooksLikeOFX(data) {
+			format = "ofx"
+		}
+	}
+	currency := in.Currency
+	if in.AccountID != nil {
+		acc, err := s.findAccount(ctx, *in.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		currency = firstNonEmpty(currency, acc.Currency)
+	}
+	var entries []statement.Entry
+	var mapping datatypes.JSON
+	switch format {
+	case "ofx":
+		entries, err = statement.ParseOFX(bytes.NewReader(data))
+	case "csv":
+		entries, err = statement.ParseCSV(bytes.NewReader(data), in.Mapping)
+		mapping, _ = json.Marshal(in.Mapping)
+	default:
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Format must be ofx or csv.")
+	}
+	if err != nil {
+		if errors.Is(err, statement.ErrNoEntries) {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "No transactions found in file.")
+		}
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Could not parse statement: "+err.Error())
+	}
+
+	rows := make([]ImportRow, 0, len(entries))
+	for _, e := range entries {
+		if e.AmountCents == 0 {
+			continue
+		}
+		row := ImportRow{
+			Index:       len(rows),
+			Date:        e.Date,
+			Type:        "income",
+			AmountCents: e.AmountCents,
+			Currency:    normalizeCurrency(firstNonEmpty(e.Currency, currency)),
+			Description: truncateRunes(firstNonEmpty(strings.TrimSpace(e.Description), "Imported transaction"), 500),
+			ExternalID:  truncateRunes(strings.TrimSpace(e.ExternalID), 128),
+		}
+		if e.AmountCents < 0 {
+			row.Type = "expense"
+			row.AmountCents = -e.AmountCents
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "No transactions found in file.")
+	}
+	if err := s.markDuplicates(ctx, rows); err != nil {
+		return nil, err
+	}
+	imp := &models.TransactionImport{
+		Filename:  truncateRunes(in.Filename, 255),
+		Format:    format,
+		Status:    importPreview,
+		AccountID: in.AccountID,
+		Mapping:   mapping,
+	}
+	if err := setImportRows(imp, rows); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateImport(ctx, imp); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to save import.", err)
+	}
+	return &ImportDetail{TransactionImport: *imp, Rows: rows}, nil
+}
+
+// CommitImport creates transactions for every previewed row that is not skipped and not a duplicate
+// (unless forced). Duplicates are re-checked first since the ledger may have changed since the preview.
+func (s *Service) CommitImport(ctx context.Context, id uuid.UUID, in CommitImportInput) (*ImportDetail, error) {
+	detail, err := s.GetImport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if detail.Status != importPreview {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Import was already "+detail.Status+".")
+	}
+	rows := detail.Rows
+	if err := s.markDuplicates(ctx, rows); err != nil {
+		return nil, err
+	}
+	rules, err := s.activeRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	skip := indexSet(in.SkipRows)
+	force := indexSet(in.ForceRows)
+	var txs []models.Transaction
+	for i := range rows {
+		row := &rows[i]
+		if skip[row.Index] || (row.Duplicate && !force[row.Index]) {
+			continue
+		}
+		txID := uuid.New()
+		row.TransactionID = &txID
+		tx := models.Transaction{
+			ID:          txID,
+			Type:        row.Type,
+			AmountCents: row.AmountCents,
+			Currency:    row.Currency,
+			Description: row.Description,
+			Date:        row.Date.UTC().Truncate(24 * time.Hour),
+			ImportID:    &detail.ID,
+			AccountID:   detail.AccountID,
+			ExternalID:  row.ExternalID,
+		}
+		rules.applyToTransaction(&tx, false)
+		txs = append(txs, tx)
+	}
+	if len(txs) == 0 {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Nothing to import: every row is skipped or a duplicate.")
+	}
+	imp := detail.TransactionImport
+	now := time.Now().UTC()
+	imp.Status = importCommitted
+	imp.CommittedAt = &now
+	imp.ImportedCount = len(txs)
+	if err := setImportRows(&imp, rows); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CommitImport(ctx, &imp, txs); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ConflictErr(apperrors.CodeInternal, "Import was already committed.")
+		}
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to commit import.", err)
+	}
+	return &ImportDetail{TransactionImport: imp, Rows: rows}, nil
+}
+
+// UndoImport deletes the transactions a committed import created.
+func (s *Service) UndoImport(ctx context.Context, id uuid.UUID) (*ImportDetail, error) {
+	detail, err := s.GetImport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if detail.Status != importCommitted {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Only committed imports can be undone.")
+	}
+	imp := detail.TransactionImport
+	now := time.Now().UTC()
+	imp.Status = importUndone
+	imp.UndoneAt = &now
+	if err := s.repo.UndoImport(ctx, &imp); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to undo import.", err)
+	}
+	return &ImportDetail{TransactionImport: imp, Rows: detail.Rows}, nil
+}
+
+// markDuplicates flags rows whose FITID already exists (or repeats within the file) and rows matching
+// an existing transaction by date, signed amount and normalized description. Each existing
+// transaction absorbs at most one row, so two identical coffees on the same day survive.
+func (s *Service) markDuplicates(ctx context.Context, rows []ImportRow) error {
+	var extIDs []string
+	minDate, maxDate := rows[0].Date, rows[0].Date
+	for _, row := range rows {
+		if row.ExternalID != "" {
+			extIDs = append(extIDs, row.ExternalID)
+		}
+		if row.Date.Before(minDate) {
+			minDate = row.Date
+		}
+		if row.Date.After(maxDate) {
+			maxDate = row.Date
+		}
+	}
+	byExternal := map[string]uuid.UUID{}
+	existing, err := s.repo.ListTransactionsByExternalIDs(ctx, extIDs)
+	if err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to check duplicates.", err)
+	}
+	for _, tx := range existing {
+		byExternal[tx.ExternalID] = tx.ID
+	}
+	inRange, err := s.repo.ListTransactionsBetween(ctx, minDate, maxDate)
+	if err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to check duplicates.", err)
+	}
+	byPrint := map[string][]uuid.UUID{}
+	for _, tx := range inRange {
+		fp := statement.Fingerprint(tx.Date, signedAmount(tx.Type, tx.AmountCents), tx.Description)
+		byPrint[fp] = append(byPrint[fp], tx.ID)
+	}
+	seen := map[string]bool{}
+	for i := range rows {
+		row := &rows[i]
+		row.Duplicate, row.DuplicateReason, row.DuplicateOf = false, "", nil
+		if row.ExternalID != "" {
+			if id, ok := byExternal[row.ExternalID]; ok {
+				row.Duplicate, row.DuplicateReason, row.DuplicateOf = true, "fitid", &id
+				continue
+			}
+			if seen[row.ExternalID] {
+				row.Duplicate, row.DuplicateReason = true, "file"
+				continue
+			}
+			seen[row.ExternalID] = true
+		}
+		fp := statement.Fingerprint(row.Date, signedAmount(row.Type, row.AmountCents), row.Description)
+		if ids := byPrint[fp]; len(ids) > 0 {
+			id := ids[0]
+			byPrint[fp] = ids[1:]
+			row.Duplicate, row.DuplicateReason, row.DuplicateOf = true, "fingerprint", &id
+		}
+	}
+	return nil
+}
+
+func decodeImport(imp *models.TransactionImport) (*ImportDetail, error) {
+	var rows []ImportRow
+	if len(imp.Rows) > 0 {
+		if err := json.Unmarshal(imp.Rows, &rows); err != nil {
+			return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to decode import rows.", err)
+		}
+	}
+	return &ImportDetail{TransactionImport: *imp, Rows: rows}, nil
+}
+
+func setImportRows(imp *models.TransactionImport, rows []ImportRow) error {
+	raw, err := json.Marshal(rows)
+	if err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to encode import rows.", err)
+	}
+	imp.Rows = raw
+	imp.RowCount = len(rows)
+	imp.DuplicateCount = 0
+	for _, row := range rows {
+		if row.Duplicate {
+			imp.DuplicateCount++
+		}
+	}
+	return nil
+}
+
+func signedAmount(txType string, cents int64) int64 {
+	if txType == "expense" {
+		return -cents
+	}
+	return cents
+}
+
+func indexSet(idx []int) map[int]bool {
+	out := make(map[int]bool, len(idx))
+	for _, i := range idx {
+		out[i] = true
+	}
+	return out
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/repository"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+type RecordInvoicePaymentInput struct {
+	// AmountCents defaults to the outstanding balance when nil.
+	AmountCents *int64
+	Date        *time.Time
+	Currency    string
+	Description string
+	Notes       string
+}
+
+// OverdueRun reports the invoices a sweep moved to overdue.
+type OverdueRun struct {
+	Marked   int              `json:"marked"`
+	Invoices []models.Invoice `json:"invoices"`
+}
+
+func (s *Service) ListInvoicePayments(ctx context.Context, invoiceID uuid.UUID) ([]models.Transaction, error) {
+	if _, err := s.GetInvoice(ctx, invoiceID); err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.ListInvoicePayments(ctx, invoiceID)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load payments.", err)
+	}
+	return rows, nil
+}
+
+// RecordInvoicePayment books a payment as an expense transaction linked to the invoice; the invoice's
+// paid amount and status follow from its payments.
+func (s *Service) RecordInvoicePayment(ctx context.Context, invoiceID uuid.UUID, in RecordInvoicePaymentInput) (*models.Transaction, error) {
+	inv, err := s.GetInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	amount := inv.TotalCents - inv.PaidCents
+	if in.AmountCents != nil {
+		amount = *in.AmountCents
+	}
+	if amount <= 0 {
+		if in.AmountCents == nil {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Invoice has no outstanding balance.")
+		}
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Amount must be positive.")
+	}
+	desc := strings.TrimSpace(in.Description)
+	if desc == "" {
+		desc = "Payment: " + inv.Name
+	}
+	var date time.Time
+	if in.Date != nil {
+		date = *in.Date
+	}
+	return s.CreateTransaction(ctx, CreateTransactionInput{
+		Type:        "expense",
+		AmountCents: amount,
+		Currency:    in.Currency,
+		Description: desc,
+		Date:        date,
+		InvoiceID:   &inv.ID,
+		Notes:       in.Notes,
+	})
+}
+
+func (s *Service) DeleteInvoicePayment(ctx context.Context, invoiceID, transactionID uuid.UUID) error {
+	row, err := s.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+	if row.InvoiceID == nil || *row.InvoiceID != invoiceID || row.Type != "expense" {
+		return apperrors.NotFound(apperrors.CodeInternal, "Payment not found.")
+	}
+	return s.DeleteTransaction(ctx, transactionID)
+}
+
+// MarkOverdueInvoices moves open invoices past their due date to overdue.
+func (s *Service) MarkOverdueInvoices(ctx context.Context, now time.Time) (*OverdueRun, error) {
+	rows, err := s.repo.MarkOverdueInvoices(ctx, dateOnly(now))
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to mark overdue invoices.", err)
+	}
+	if rows == nil {
+		rows = []models.Invoice{}
+	}
+	return &OverdueRun{Marked: len(rows), Invoices: rows}, nil
+}
+
+// refreshInvoicePayments recomputes PaidCents from linked payments plus the invoice's manual adjustment
+// and moves the invoice between open, overdue and paid accordingly.
+func (s *Service) refreshInvoicePayments(ctx context.Context, invoiceID *uuid.UUID) error {
+	if invoiceID == nil {
+		return nil
+	}
+	inv, err := s.repo.FindInvoice(ctx, *invoiceID)
+	if err != nil {
+		// Transactions may point at invoices that were deleted; there is nothing to update then.
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to load invoice.", err)
+	}
+	paid, err := s.repo.SumInvoicePayments(ctx, inv.ID)
+	if err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to sum invoice payments.", err)
+	}
+	paid += inv.PaidAdjustmentCents
+	status := repository.InvoiceStatusFor(inv.TotalCents, paid, inv.DueDate, time.Now().UTC())
+	if err := s.repo.UpdateInvoicePaid(ctx, inv.ID, paid, status); err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to update invoice.", err)
+	}
+	return nil
+}
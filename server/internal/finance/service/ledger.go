@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"errors"
+	"log"
+	"sort"
 	"strings"
 	"time"
 
@@ -19,12 +21,18 @@ type CreateTransactionInput struct {
 	Currency       string
 	Description    string
 	Date           time.Time
+	Category       string
 	IncomeSourceID *uuid.UUID
 	ExpenseID      *uuid.UUID
 	ProjectID      *uuid.UUID
 	ContactID      *uuid.UUID
 	InvoiceID      *uuid.UUID
+	AccountID      *uuid.UUID
+	ToAccountID    *uuid.UUID
+	ToAmountCents  *int64
 	Notes          string
+	Deductible     bool
+	GoalID         *uuid.UUID
 }
 
 type UpdateTransactionInput struct {
@@ -33,6 +41,7 @@ type UpdateTransactionInput struct {
 	Currency        *string
 	Description     *string
 	Date            *time.Time
+	Category        *string
 	IncomeSourceID  *uuid.UUID
 	IncomeSourceSet bool
 	ExpenseID       *uuid.UUID
@@ -43,26 +52,41 @@ type UpdateTransactionInput struct {
 	ContactSet      bool
 	InvoiceID       *uuid.UUID
 	InvoiceSet      bool
+	AccountID       *uuid.UUID
+	AccountSet      bool
+	ToAccountID     *uuid.UUID
+	ToAccountSet    bool
+	ToAmountCents   *int64
 	Notes           *string
+	Deductible      *bool
+	GoalID          *uuid.UUID
+	GoalSet         bool
 }
 
+// TransactionFilter selects transactions. From and To are inclusive dates and take precedence over
+// Year and Month; without any of them the current month is used, unless Query is set, which then
+// searches all dates.
 type TransactionFilter struct {
-	Type      string
-	Year      int
-	Month     int
-	ProjectID *uuid.UUID
-	ContactID *uuid.UUID
+	Type           string
+	Year           int
+	Month          int
+	From           *time.Time
+	To             *time.Time
+	Query          string
+	MinAmountCents *int64
+	MaxAmountCents *int64
+	Currency       string
+	ProjectID      *uuid.UUID
+	ContactID      *uuid.UUID
+	AccountID      *uuid.UUID
+	InvoiceID      *uuid.UUID
+	IncomeSourceID *uuid.UUID
+	ExpenseID      *uuid.UUID
+	GoalID         *uuid.UUID
 }
 
 func (s *Service) ListTransactions(ctx context.Context, f TransactionFilter) ([]models.Transaction, error) {
-	year, month := parseYearMonth(f.Year, f.Month)
-	rows, err := s.repo.ListTransactions(ctx, repository.TransactionFilter{
-		Type:      f.Type,
-		Year:      year,
-		Month:     month,
-		ProjectID: f.ProjectID,
-		ContactID: f.ContactID,
-	})
+	rows, err := s.repo.ListTransactions(ctx, f.repository())
 	if err != nil {
 		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load transactions.", err)
 	}
@@ -77,13 +101,38 @@ func (s *Service) GetTransaction(ctx context.Context, id uuid.UUID) (*models.Tra
 		}
 		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load transaction.", err)
 	}
+	if err := s.loadTransactionAttachments(ctx, row); err != nil {
+		return nil, err
+	}
 	return row, nil
 }
 
 func (s *Service) CreateTransaction(ctx context.Context, in CreateTransactionInput) (*models.Transaction, error) {
+	row, err := s.newTransaction(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.repo.CreateTransaction(ctx, row); err != nil {
+			return apperrors.InternalCause(apperrors.CodeInternal, "Failed to create transaction.", err)
+		}
+		if err := tx.settleExpectedFor(ctx, row); err != nil {
+			return err
+		}
+		return tx.refreshInvoicePayments(ctx, row.InvoiceID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// newTransaction validates the input and builds the row to insert, with its category resolved, the
+// rules applied, its accounts checked and its savings goal tagged.
+func (s *Service) newTransaction(ctx context.Context, in CreateTransactionInput) (*models.Transaction, error) {
 	txType := normalizeTransactionType(in.Type)
 	if txType == "" {
-		return nil, apperrors.Invalid(apperrors.CodeInternal, "Type must be income or expense.")
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Type must be income, expense or transfer.")
 	}
 	desc := strings.TrimSpace(in.Description)
 	if desc == "" {
@@ -99,21 +148,39 @@ func (s *Service) CreateTransaction(ctx context.Context, in CreateTransactionInp
 	if date.IsZero() {
 		date = time.Now().UTC()
 	}
+	category, err := s.transactionCategory(ctx, txType, in.Category)
+	if err != nil {
+		return nil, err
+	}
 	row := &models.Transaction{
 		Type:           txType,
 		AmountCents:    in.AmountCents,
 		Currency:       normalizeCurrency(in.Currency),
 		Description:    desc,
 		Date:           date.UTC().Truncate(24 * time.Hour),
+		Category:       category,
 		IncomeSourceID: in.IncomeSourceID,
 		ExpenseID:      in.ExpenseID,
 		ProjectID:      in.ProjectID,
 		ContactID:      in.ContactID,
 		InvoiceID:      in.InvoiceID,
+		AccountID:      in.AccountID,
+		ToAccountID:    in.ToAccountID,
+		ToAmountCents:  in.ToAmountCents,
 		Notes:          strings.TrimSpace(in.Notes),
+		Deductible:     in.Deductible,
+		GoalID:         in.GoalID,
 	}
-	if err := s.repo.CreateTransaction(ctx, row); err != nil {
-		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to create transaction.", err)
+	rules, err := s.activeRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules.applyToTransaction(row, false)
+	if err := s.validateTransactionAccounts(ctx, row); err != nil {
+		return nil, err
+	}
+	if err := s.tagSavingsGoal(ctx, row); err != nil {
+		return nil, err
 	}
 	return row, nil
 }
@@ -123,10 +190,11 @@ func (s *Service) UpdateTransaction(ctx context.Context, id uuid.UUID, in Update
 	if err != nil {
 		return nil, err
 	}
+	prevInvoiceID, prevAmount := row.InvoiceID, row.AmountCents
 	if in.Type != nil {
 		t := normalizeTransactionType(*in.Type)
 		if t == "" {
-			return nil, apperrors.Invalid(apperrors.CodeInternal, "Type must be income or expense.")
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Type must be income, expense or transfer.")
 		}
 		row.Type = t
 	}
@@ -149,6 +217,15 @@ func (s *Service) UpdateTransaction(ctx context.Context, id uuid.UUID, in Update
 	if in.Date != nil {
 		row.Date = in.Date.UTC().Truncate(24 * time.Hour)
 	}
+	if in.Category != nil || in.Type != nil {
+		raw := row.Category
+		if in.Category != nil {
+			raw = *in.Category
+		}
+		if row.Category, err = s.transactionCategory(ctx, row.Type, raw); err != nil {
+			return nil, err
+		}
+	}
 	if in.IncomeSourceSet {
 		row.IncomeSourceID = in.IncomeSourceID
 	}
@@ -167,33 +244,84 @@ func (s *Service) UpdateTransaction(ctx context.Context, id uuid.UUID, in Update
 	if in.InvoiceSet {
 		row.InvoiceID = in.InvoiceID
 	}
+	if in.AccountSet {
+		row.AccountID = in.AccountID
+	}
+	if in.ToAccountSet {
+		row.ToAccountID = in.ToAccountID
+	}
+	if in.ToAmountCents != nil {
+		row.ToAmountCents = in.ToAmountCents
+	}
 	if in.Notes != nil {
 		row.Notes = strings.TrimSpace(*in.Notes)
 	}
-	if err := s.repo.SaveTransaction(ctx, row); err != nil {
-		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update transaction.", err)
+	if in.Deductible != nil {
+		row.Deductible = *in.Deductible
+	}
+	if in.GoalSet {
+		row.GoalID = in.GoalID
+		if in.GoalID != nil && *in.GoalID == uuid.Nil {
+			row.GoalID = nil
+		}
+		if row.GoalID != nil {
+			if _, err := s.findSavingsGoal(ctx, *row.GoalID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := s.validateTransactionAccounts(ctx, row); err != nil {
+		return nil, err
+	}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.repo.SaveTransaction(ctx, row); err != nil {
+			return apperrors.InternalCause(apperrors.CodeInternal, "Failed to update transaction.", err)
+		}
+		if err := tx.refreshTransactionSplits(ctx, row, prevAmount); err != nil {
+			return err
+		}
+		if err := tx.refreshInvoicePayments(ctx, row.InvoiceID); err != nil {
+			return err
+		}
+		if prevInvoiceID != nil && (row.InvoiceID == nil || *prevInvoiceID != *row.InvoiceID) {
+			return tx.refreshInvoicePayments(ctx, prevInvoiceID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return row, nil
 }
 
 func (s *Service) DeleteTransaction(ctx context.Context, id uuid.UUID) error {
-	if err := s.repo.DeleteTransaction(ctx, id); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return apperrors.NotFound(apperrors.CodeInternal, "Transaction not found.")
-		}
-		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to delete transaction.", err)
+	row, err := s.GetTransaction(ctx, id)
+	if err != nil {
+		return err
 	}
-	return nil
+	return s.inTx(ctx, func(tx *Service) error {
+		if err := tx.repo.DeleteTransaction(ctx, id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound(apperrors.CodeInternal, "Transaction not found.")
+			}
+			return apperrors.InternalCause(apperrors.CodeInternal, "Failed to delete transaction.", err)
+		}
+		return tx.refreshInvoicePayments(ctx, row.InvoiceID)
+	})
 }
 
 type MonthlySummary struct {
-	Year          int            `json:"year"`
-	Month         int            `json:"month"`
-	IncomeCents   int64          `json:"incomeCents"`
-	ExpenseCents  int64          `json:"expenseCents"`
-	NetCents      int64          `json:"netCents"`
-	ByCategory    map[string]int64 `json:"byCategory"`
-	Budgets       []models.BudgetPlan `json:"budgets,omitempty"`
+	Year              int                       `json:"year"`
+	Month             int                       `json:"month"`
+	ReportingCurrency string                    `json:"reportingCurrency"`
+	IncomeCents       int64                     `json:"incomeCents"`
+	ExpenseCents      int64                     `json:"expenseCents"`
+	NetCents          int64                     `json:"netCents"`
+	ByCategory        map[string]int64          `json:"byCategory"`
+	ByParentCategory  map[string]int64          `json:"byParentCategory"`
+	ByCurrency        map[string]CurrencyTotals `json:"byCurrency"`
+	MissingRates      []string                  `json:"missingRates,omitempty"`
+	Budgets           []models.BudgetPlan       `json:"budgets,omitempty"`
 }
 
 func (s *Service) MonthlySummary(ctx context.Context, year, month int) (*MonthlySummary, error) {
@@ -210,20 +338,50 @@ func (s *Service) MonthlySummary(ctx context.Context, year, month int) (*Monthly
 	if err != nil {
 		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load budgets.", err)
 	}
-	return &MonthlySummary{
-		Year:         year,
-		Month:        month,
-		IncomeCents:  totals.IncomeCents,
-		ExpenseCents: totals.ExpenseCents,
-		NetCents:     totals.IncomeCents - totals.ExpenseCents,
-		ByCategory:   byCat,
-		Budgets:      budgets,
-	}, nil
+	conv, err := s.newConverter(ctx, time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return nil, err
+	}
+	out := &MonthlySummary{
+		Year:              year,
+		Month:             month,
+		ReportingCurrency: conv.target,
+		ByCategory:        map[string]int64{},
+		ByParentCategory:  map[string]int64{},
+		ByCurrency:        map[string]CurrencyTotals{},
+		Budgets:           budgets,
+	}
+	for _, row := range totals {
+		cur := normalizeCurrency(row.Currency)
+		amount := conv.convert(row.AmountCents, cur, row.Date)
+		ct := out.ByCurrency[cur]
+		switch row.Key {
+		case "income":
+			out.IncomeCents += amount
+			ct.IncomeCents += row.AmountCents
+		case "expense":
+			out.ExpenseCents += amount
+			ct.ExpenseCents += row.AmountCents
+		}
+		out.ByCurrency[cur] = ct
+	}
+	categories, err := s.categoryIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range byCat {
+		amount := conv.convert(row.AmountCents, row.Currency, row.Date)
+		out.ByCategory[row.Key] += amount
+		out.ByParentCategory[categories.root("expense", row.Key)] += amount
+	}
+	out.NetCents = out.IncomeCents - out.ExpenseCents
+	out.MissingRates = conv.missingCurrencies()
+	return out, nil
 }
 
 func normalizeTransactionType(t string) string {
 	switch strings.TrimSpace(strings.ToLower(t)) {
-	case "income", "expense":
+	case "income", "expense", "transfer":
 		return strings.TrimSpace(strings.ToLower(t))
 	default:
 		return ""
@@ -236,8 +394,6 @@ type CreateInvoiceInput struct {
 	DueDate      time.Time
 	ClosedAt     *time.Time
 	TotalCents   int64
-	PaidCents    int64
-	Status       string
 	Notes        string
 }
 
@@ -248,8 +404,6 @@ type UpdateInvoiceInput struct {
 	ClosedAt     *time.Time
 	ClosedAtSet  bool
 	TotalCents   *int64
-	PaidCents    *int64
-	Status       *string
 	Notes        *string
 }
 
@@ -269,6 +423,9 @@ func (s *Service) GetInvoice(ctx context.Context, id uuid.UUID) (*models.Invoice
 		}
 		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load invoice.", err)
 	}
+	if err := s.loadInvoiceAttachments(ctx, row); err != nil {
+		return nil, err
+	}
 	return row, nil
 }
 
@@ -286,10 +443,10 @@ func (s *Service) CreateInvoice(ctx context.Context, in CreateInvoiceInput) (*mo
 		DueDate:      in.DueDate.UTC().Truncate(24 * time.Hour),
 		ClosedAt:     in.ClosedAt,
 		TotalCents:   in.TotalCents,
-		PaidCents:    in.PaidCents,
-		Status:       normalizeInvoiceStatus(in.Status),
 		Notes:        strings.TrimSpace(in.Notes),
 	}
+	// Nothing is paid until payments are linked to the invoice.
+	row.Status = repository.InvoiceStatusFor(row.TotalCents, 0, row.DueDate, time.Now().UTC())
 	if err := s.repo.CreateInvoice(ctx, row); err != nil {
 		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to create invoice.", err)
 	}
@@ -320,11 +477,8 @@ func (s *Service) UpdateInvoice(ctx context.Context, id uuid.UUID, in UpdateInvo
 	if in.TotalCents != nil {
 		row.TotalCents = *in.TotalCents
 	}
-	if in.PaidCents != nil {
-		row.PaidCents = *in.PaidCents
-	}
-	if in.Status != nil {
-		row.Status = normalizeInvoiceStatus(*in.Status)
+	if in.TotalCents != nil || in.DueDate != nil {
+		row.Status = repository.InvoiceStatusFor(row.TotalCents, row.PaidCents, row.DueDate, time.Now().UTC())
 	}
 	if in.Notes != nil {
 		row.Notes = strings.TrimSpace(*in.Notes)
@@ -369,15 +523,24 @@ func (s *Service) CreateInvoiceItem(ctx context.Context, invoiceID uuid.UUID, in
 	if date.IsZero() {
 		date = time.Now().UTC()
 	}
+	category, err := s.resolveCategory(ctx, "expense", in.Category)
+	if err != nil {
+		return nil, err
+	}
 	row := &models.InvoiceItem{
 		InvoiceID:   invoiceID,
 		Description: desc,
 		AmountCents: in.AmountCents,
 		Date:        date.UTC().Truncate(24 * time.Hour),
-		Category:    normalizeExpenseCategory(in.Category),
+		Category:    category,
 		Installment: strings.TrimSpace(in.Installment),
 		Notes:       strings.TrimSpace(in.Notes),
 	}
+	rules, err := s.activeRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules.applyToInvoiceItem(row)
 	if err := s.repo.CreateInvoiceItem(ctx, row); err != nil {
 		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to create invoice item.", err)
 	}
@@ -400,20 +563,12 @@ func (s *Service) DeleteInvoiceItem(ctx context.Context, invoiceID, itemID uuid.
 	return nil
 }
 
-func normalizeInvoiceStatus(st string) string {
-	switch strings.TrimSpace(strings.ToLower(st)) {
-	case "paid", "overdue":
-		return strings.TrimSpace(strings.ToLower(st))
-	default:
-		return "open"
-	}
-}
-
 type CreateBudgetInput struct {
 	Year         int
 	Month        int
 	Category     string
 	PlannedCents int64
+	Rollover     bool
 	Notes        string
 }
 
@@ -422,6 +577,7 @@ type UpdateBudgetInput struct {
 	Month        *int
 	Category     *string
 	PlannedCents *int64
+	Rollover     *bool
 	Notes        *string
 }
 
@@ -447,15 +603,19 @@ func (s *Service) GetBudget(ctx context.Context, id uuid.UUID) (*models.BudgetPl
 
 func (s *Service) CreateBudget(ctx context.Context, in CreateBudgetInput) (*models.BudgetPlan, error) {
 	year, month := parseYearMonth(in.Year, in.Month)
-	cat := normalizeExpenseCategory(in.Category)
-	if cat == "" {
+	if strings.TrimSpace(in.Category) == "" {
 		return nil, apperrors.Invalid(apperrors.CodeInternal, "Category is required.")
 	}
+	cat, err := s.resolveCategory(ctx, "expense", in.Category)
+	if err != nil {
+		return nil, err
+	}
 	row := &models.BudgetPlan{
 		Year:         year,
 		Month:        month,
 		Category:     cat,
 		PlannedCents: in.PlannedCents,
+		Rollover:     in.Rollover,
 		Notes:        strings.TrimSpace(in.Notes),
 	}
 	if err := s.repo.CreateBudget(ctx, row); err != nil {
@@ -476,11 +636,16 @@ func (s *Service) UpdateBudget(ctx context.Context, id uuid.UUID, in UpdateBudge
 		row.Month = *in.Month
 	}
 	if in.Category != nil {
-		row.Category = normalizeExpenseCategory(*in.Category)
+		if row.Category, err = s.resolveCategory(ctx, "expense", *in.Category); err != nil {
+			return nil, err
+		}
 	}
 	if in.PlannedCents != nil {
 		row.PlannedCents = *in.PlannedCents
 	}
+	if in.Rollover != nil {
+		row.Rollover = *in.Rollover
+	}
 	if in.Notes != nil {
 		row.Notes = strings.TrimSpace(*in.Notes)
 	}
@@ -505,30 +670,53 @@ type CalendarEvent struct {
 	Type        string `json:"type"`
 	Title       string `json:"title"`
 	AmountCents int64  `json:"amountCents"`
+	Currency    string `json:"currency,omitempty"`
 	RefID       string `json:"refId"`
 }
 
 type FinanceDashboard struct {
-	MonthIncomeCents   int64             `json:"monthIncomeCents"`
-	MonthExpenseCents  int64             `json:"monthExpenseCents"`
-	MonthNetCents      int64             `json:"monthNetCents"`
-	OpenInvoiceCount   int64             `json:"openInvoiceCount"`
-	ActiveIncomeCount  int               `json:"activeIncomeCount"`
-	ActiveExpenseCount int               `json:"activeExpenseCount"`
-	UpcomingInvoices   []models.Invoice  `json:"upcomingInvoices"`
-	UpcomingExpenses   []models.Expense  `json:"upcomingExpenses"`
+	ReportingCurrency  string                `json:"reportingCurrency"`
+	MonthIncomeCents   int64                 `json:"monthIncomeCents"`
+	MonthExpenseCents  int64                 `json:"monthExpenseCents"`
+	MonthNetCents      int64                 `json:"monthNetCents"`
+	MissingRates       []string              `json:"missingRates,omitempty"`
+	OpenInvoiceCount   int64                 `json:"openInvoiceCount"`
+	CommittedCents     int64                 `json:"committedCents"`
+	OverdueCents       int64                 `json:"overdueCents"`
+	OverdueInvoices    []models.Invoice      `json:"overdueInvoices"`
+	ActiveIncomeCount  int                   `json:"activeIncomeCount"`
+	ActiveExpenseCount int                   `json:"activeExpenseCount"`
+	UpcomingInvoices   []models.Invoice      `json:"upcomingInvoices"`
+	UpcomingExpenses   []models.Expense      `json:"upcomingExpenses"`
+	UpcomingEvents     []CalendarEvent       `json:"upcomingEvents"`
+	Projects           []ProjectPL           `json:"projects"`
+	Goals              []SavingsGoalProgress `json:"goals"`
 }
 
 func (s *Service) Dashboard(ctx context.Context) (*FinanceDashboard, error) {
 	now := time.Now().UTC()
-	totals, err := s.repo.SumTransactionsByMonth(ctx, now.Year(), int(now.Month()))
+	summary, err := s.MonthlySummary(ctx, now.Year(), int(now.Month()))
 	if err != nil {
-		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to compute dashboard.", err)
+		return nil, err
 	}
 	openCount, err := s.repo.CountOpenInvoices(ctx)
 	if err != nil {
 		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to count invoices.", err)
 	}
+	committed, err := s.repo.SumCommittedInstallments(ctx)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to sum installments.", err)
+	}
+	overdue, err := s.repo.ListInvoices(ctx, "overdue")
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load overdue invoices.", err)
+	}
+	var overdueCents int64
+	for _, inv := range overdue {
+		if due := inv.TotalCents - inv.PaidCents; due > 0 {
+			overdueCents += due
+		}
+	}
 	incomes, err := s.repo.ListIncomeSources(ctx, repository.IncomeSourceFilter{ActiveOnly: true})
 	if err != nil {
 		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load income sources.", err)
@@ -537,89 +725,65 @@ func (s *Service) Dashboard(ctx context.Context) (*FinanceDashboard, error) {
 	if err != nil {
 		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load expenses.", err)
 	}
-	upcoming, err := s.repo.ListInvoicesDueBetween(ctx, now, now.AddDate(0, 0, 30))
+	horizon := now.AddDate(0, 0, 30)
+	upcoming, err := s.repo.ListInvoicesDueBetween(ctx, now, horizon)
 	if err != nil {
 		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load upcoming invoices.", err)
 	}
-	upcomingExpenses, err := s.repo.ListExpensesDueBetween(ctx, now, now.AddDate(0, 0, 30))
+	upcomingExpenses := []models.Expense{}
+	for _, exp := range expenses {
+		if len(expenseSchedule(exp).occurrences(now, horizon)) > 0 {
+			upcomingExpenses = append(upcomingExpenses, exp)
+		}
+	}
+	events := scheduledEvents(incomes, expenses, now, horizon)
+	sortCalendarEvents(events)
+	// Project P&L and goals are extras on the dashboard; a failure there should not hide the rest.
+	var projectPL []ProjectPL
+	if projects, err := s.ProjectsPL(ctx, ProjectPLInput{Year: now.Year(), Month: int(now.Month()), Months: 1}); err != nil {
+		log.Printf("finance dashboard: project P&L: %v", err)
+	} else {
+		projectPL = projects.Projects
+	}
+	goals, err := s.ListSavingsGoals(ctx, false, "")
 	if err != nil {
-		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load upcoming expenses.", err)
+		log.Printf("finance dashboard: savings goals: %v", err)
 	}
 	return &FinanceDashboard{
-		MonthIncomeCents:   totals.IncomeCents,
-		MonthExpenseCents:  totals.ExpenseCents,
-		MonthNetCents:      totals.IncomeCents - totals.ExpenseCents,
+		ReportingCurrency:  summary.ReportingCurrency,
+		MonthIncomeCents:   summary.IncomeCents,
+		MonthExpenseCents:  summary.ExpenseCents,
+		MonthNetCents:      summary.NetCents,
+		MissingRates:       summary.MissingRates,
 		OpenInvoiceCount:   openCount,
+		CommittedCents:     committed,
+		OverdueCents:       overdueCents,
+		OverdueInvoices:    overdue,
 		ActiveIncomeCount:  len(incomes),
 		ActiveExpenseCount: len(expenses),
 		UpcomingInvoices:   upcoming,
 		UpcomingExpenses:   upcomingExpenses,
+		UpcomingEvents:     events,
+		Projects:           projectPL,
+		Goals:              goals,
 	}, nil
 }
 
 func (s *Service) Calendar(ctx context.Context, year, month int) ([]CalendarEvent, error) {
 	year, month = parseYearMonth(year, month)
-	daysInMonth := time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
-	var events []CalendarEvent
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, -1)
 
 	incomes, err := s.repo.ListIncomeSources(ctx, repository.IncomeSourceFilter{ActiveOnly: true})
 	if err != nil {
 		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load income sources.", err)
 	}
-	for _, inc := range incomes {
-		if inc.Frequency != "monthly" {
-			continue
-		}
-		day := inc.DayOfMonth
-		if day > daysInMonth {
-			day = daysInMonth
-		}
-		d := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
-		events = append(events, CalendarEvent{
-			Date:        d.Format("2006-01-02"),
-			Type:        "income",
-			Title:       inc.Name,
-			AmountCents: inc.AmountCents,
-			RefID:       inc.ID.String(),
-		})
-	}
-
 	expenses, err := s.repo.ListExpenses(ctx, true)
 	if err != nil {
 		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load expenses.", err)
 	}
-	for _, exp := range expenses {
-		if exp.Frequency == "one_time" && exp.DueDate != nil {
-			if exp.DueDate.Year() == year && int(exp.DueDate.Month()) == month {
-				events = append(events, CalendarEvent{
-					Date:        exp.DueDate.Format("2006-01-02"),
-					Type:        "expense",
-					Title:       exp.Name,
-					AmountCents: exp.AmountCents,
-					RefID:       exp.ID.String(),
-				})
-			}
-			continue
-		}
-		if exp.Frequency != "monthly" {
-			continue
-		}
-		day := exp.DayOfMonth
-		if day > daysInMonth {
-			day = daysInMonth
-		}
-		d := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
-		events = append(events, CalendarEvent{
-			Date:        d.Format("2006-01-02"),
-			Type:        "expense",
-			Title:       exp.Name,
-			AmountCents: exp.AmountCents,
-			RefID:       exp.ID.String(),
-		})
-	}
+	events := scheduledEvents(incomes, expenses, start, end)
 
-	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
-	end := start.AddDate(0, 1, -1)
 	invoices, err := s.repo.ListInvoicesDueBetween(ctx, start, end)
 	if err != nil {
 		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load invoices.", err)
@@ -633,5 +797,42 @@ func (s *Service) Calendar(ctx context.Context, year, month int) ([]CalendarEven
 			RefID:       inv.ID.String(),
 		})
 	}
+	sortCalendarEvents(events)
 	return events, nil
 }
+
+// scheduledEvents expands every income source and expense into its occurrences within [from, to].
+func scheduledEvents(incomes []models.IncomeSource, expenses []models.Expense, from, to time.Time) []CalendarEvent {
+	events := []CalendarEvent{}
+	for _, inc := range incomes {
+		for _, d := range incomeSchedule(inc).occurrences(from, to) {
+			events = append(events, CalendarEvent{
+				Date:        d.Format("2006-01-02"),
+				Type:        "income",
+				Title:       inc.Name,
+				AmountCents: inc.AmountCents,
+				Currency:    inc.Currency,
+				RefID:       inc.ID.String(),
+			})
+		}
+	}
+	for _, exp := range expenses {
+		for _, d := range expenseSchedule(exp).occurrences(from, to) {
+			events = append(events, CalendarEvent{
+				Date:        d.Format("2006-01-02"),
+				Type:        "expense",
+				Title:       exp.Name,
+				AmountCents: exp.AmountCents,
+				Currency:    exp.Currency,
+				RefID:       exp.ID.String(),
+			})
+		}
+	}
+	return events
+}
+
+func sortCalendarEvents(events []CalendarEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Date < events[j].Date
+	})
+}
@@ -0,0 +1,478 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultNetWorthMonths = 12
+	maxNetWorthMonths     = 360
+	openInvoicesItemName  = "Faturas de cartão em aberto"
+)
+
+type CreateNetWorthItemInput struct {
+	Name     string
+	Kind     string
+	Category string
+	Currency string
+	Notes    string
+}
+
+type UpdateNetWorthItemInput struct {
+	Name     *string
+	Kind     *string
+	Category *string
+	Currency *string
+	Archived *bool
+	Notes    *string
+}
+
+type CreateNetWorthSnapshotInput struct {
+	Date       *time.Time
+	ValueCents int64
+	Notes      string
+}
+
+// NetWorthItemWithValue is an item with its latest snapshot, if any.
+type NetWorthItemWithValue struct {
+	models.NetWorthItem
+	ValueCents *int64     `json:"valueCents"`
+	ValueDate  *time.Time `json:"valueDate"`
+}
+
+// NetWorthPoint is net worth at the end of a month (or at To for the last point), in the reporting
+// currency.
+type NetWorthPoint struct {
+	Date             time.Time `json:"date"`
+	AssetsCents      int64     `json:"assetsCents"`
+	LiabilitiesCents int64     `json:"liabilitiesCents"`
+	NetCents         int64     `json:"netCents"`
+}
+
+type NetWorthSeries struct {
+	ReportingCurrency string          `json:"reportingCurrency"`
+	From              time.Time       `json:"from"`
+	To                time.Time       `json:"to"`
+	Points            []NetWorthPoint `json:"points"`
+	MissingRates      []string        `json:"missingRates,omitempty"`
+}
+
+type NetWorthRunResult struct {
+	Date    string `json:"date"`
+	Created int64  `json:"created"`
+}
+
+func (s *Service) ListNetWorthItems(ctx context.Context, includeArchived bool) ([]NetWorthItemWithValue, error) {
+	rows, err := s.repo.ListNetWorthItems(ctx, includeArchived)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load net worth items.", err)
+	}
+	latest, err := s.repo.LatestNetWorthSnapshots(ctx)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load net worth snapshots.", err)
+	}
+	out := make([]NetWorthItemWithValue, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, withLatestValue(row, latest))
+	}
+	return out, nil
+}
+
+func (s *Service) GetNetWorthItem(ctx context.Context, id uuid.UUID) (*NetWorthItemWithValue, error) {
+	row, err := s.findNetWorthItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := s.repo.LatestNetWorthSnapshots(ctx)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load net worth snapshots.", err)
+	}
+	out := withLatestValue(*row, latest)
+	return &out, nil
+}
+
+func (s *Service) CreateNetWorthItem(ctx context.Context, in CreateNetWorthItemInput) (*models.NetWorthItem, error) {
+	row := &models.NetWorthItem{
+		Name:     strings.TrimSpace(in.Name),
+		Kind:     strings.TrimSpace(strings.ToLower(in.Kind)),
+		Category: strings.TrimSpace(strings.ToLower(in.Category)),
+		Source:   "manual",
+		Currency: normalizeCurrency(in.Currency),
+		Notes:    strings.TrimSpace(in.Notes),
+	}
+	if row.Category == "" {
+		row.Category = "other"
+	}
+	if err := validateNetWorthItem(row); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateNetWorthItem(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to create net worth item.", err)
+	}
+	return row, nil
+}
+
+// UpdateNetWorthItem edits an item. Kind and currency of computed items follow their source and
+// cannot be changed. Archiving an item records it at zero from today, so the series stops counting it.
+func (s *Service) UpdateNetWorthItem(ctx context.Context, id uuid.UUID, in UpdateNetWorthItemInput) (*models.NetWorthItem, error) {
+	row, err := s.findNetWorthItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Source != "manual" && (in.Kind != nil || in.Currency != nil) {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Kind and currency of computed items cannot be changed.")
+	}
+	if in.Name != nil {
+		row.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Kind != nil {
+		row.Kind = strings.TrimSpace(strings.ToLower(*in.Kind))
+	}
+	if in.Category != nil {
+		row.Category = strings.TrimSpace(strings.ToLower(*in.Category))
+	}
+	if in.Currency != nil {
+		row.Currency = normalizeCurrency(*in.Currency)
+	}
+	archiving := in.Archived != nil && *in.Archived && !row.Archived
+	if in.Archived != nil {
+		row.Archived = *in.Archived
+	}
+	if in.Notes != nil {
+		row.Notes = strings.TrimSpace(*in.Notes)
+	}
+	if err := validateNetWorthItem(row); err != nil {
+		return nil, err
+	}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.repo.SaveNetWorthItem(ctx, row); err != nil {
+			return err
+		}
+		if !archiving {
+			return nil
+		}
+		return tx.repo.SaveNetWorthSnapshot(ctx, &models.NetWorthSnapshot{ItemID: row.ID, Date: dateOnly(time.Now().UTC()), Notes: "Archived"})
+	})
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update net worth item.", err)
+	}
+	return row, nil
+}
+
+// DeleteNetWorthItem removes a manual item and its history. Computed items would be recreated by the
+// next snapshot run, so they can only be archived.
+func (s *Service) DeleteNetWorthItem(ctx context.Context, id uuid.UUID) error {
+	row, err := s.findNetWorthItem(ctx, id)
+	if err != nil {
+		return err
+	}
+	if row.Source != "manual" {
+		return apperrors.Invalid(apperrors.CodeInternal, "Computed items cannot be deleted; archive them instead.")
+	}
+	if err := s.repo.DeleteNetWorthItem(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound(apperrors.CodeInternal, "Net worth item not found.")
+		}
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to delete net worth item.", err)
+	}
+	return nil
+}
+
+func (s *Service) ListNetWorthSnapshots(ctx context.Context, itemID uuid.UUID) ([]models.NetWorthSnapshot, error) {
+	if _, err := s.findNetWorthItem(ctx, itemID); err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.ListNetWorthSnapshots(ctx, itemID)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load net worth snapshots.", err)
+	}
+	return rows, nil
+}
+
+// RecordNetWorthSnapshot sets the item's value on a date (default today), replacing any snapshot of
+// that day, including one taken by the job.
+func (s *Service) RecordNetWorthSnapshot(ctx context.Context, itemID uuid.UUID, in CreateNetWorthSnapshotInput) (*models.NetWorthSnapshot, error) {
+	if _, err := s.findNetWorthItem(ctx, itemID); err != nil {
+		return nil, err
+	}
+	date := dateOnly(time.Now().UTC())
+	if in.Date != nil && !in.Date.IsZero() {
+		date = dateOnly(*in.Date)
+	}
+	row := &models.NetWorthSnapshot{
+		ItemID:     itemID,
+		Date:       date,
+		ValueCents: in.ValueCents,
+		Notes:      strings.TrimSpace(in.Notes),
+	}
+	if err := s.repo.SaveNetWorthSnapshot(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to save net worth snapshot.", err)
+	}
+	return row, nil
+}
+
+func (s *Service) DeleteNetWorthSnapshot(ctx context.Context, itemID, id uuid.UUID) error {
+	if err := s.repo.DeleteNetWorthSnapshot(ctx, itemID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound(apperrors.CodeInternal, "Net worth snapshot not found.")
+		}
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to delete net worth snapshot.", err)
+	}
+	return nil
+}
+
+// NetWorthSeries returns month-end net worth between from and to (default the last 12 months), each
+// item valued at its latest snapshot on or before the point and converted at that date's rate.
+func (s *Service) NetWorthSeries(ctx context.Context, from, to *time.Time) (*NetWorthSeries, error) {
+	end := dateOnly(time.Now().UTC())
+	if to != nil && !to.IsZero() {
+		end = dateOnly(*to)
+	}
+	start := time.Date(end.Year(), end.Month()-defaultNetWorthMonths+1, 1, 0, 0, 0, 0, time.UTC)
+	if from != nil && !from.IsZero() {
+		start = dateOnly(*from)
+	}
+	if start.After(end) {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "from must not be after to.")
+	}
+	dates := netWorthDates(start, end)
+	if len(dates) > maxNetWorthMonths {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Net worth series is limited to 30 years.")
+	}
+
+	items, err := s.repo.ListNetWorthItems(ctx, true)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load net worth items.", err)
+	}
+	snaps, err := s.repo.ListNetWorthSnapshotsUntil(ctx, end)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load net worth snapshots.", err)
+	}
+	conv, err := s.newConverter(ctx, end)
+	if err != nil {
+		return nil, err
+	}
+	return &NetWorthSeries{
+		ReportingCurrency: conv.target,
+		From:              start,
+		To:                end,
+		Points:            netWorthPoints(items, snaps, dates, conv.convert),
+		MissingRates:      conv.missingCurrencies(),
+	}, nil
+}
+
+// SnapshotNetWorth records the balances of active accounts and the amount owed on open invoices as of
+// today. It runs once per month: later calls in a month where the job already ran create nothing.
+// Accounts without a net worth item get one; archived items are skipped. The invoices item takes the
+// currency of the card accounts.
+func (s *Service) SnapshotNetWorth(ctx context.Context, now time.Time) (*NetWorthRunResult, error) {
+	today := dateOnly(now)
+	res := &NetWorthRunResult{Date: today.Format("2006-01-02")}
+	done, err := s.repo.HasAutoNetWorthSnapshot(ctx, time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC), today)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load net worth snapshots.", err)
+	}
+	if done {
+		return res, nil
+	}
+
+	items, err := s.repo.ListNetWorthItems(ctx, true)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load net worth items.", err)
+	}
+	byAccount := map[uuid.UUID]models.NetWorthItem{}
+	var invoices *models.NetWorthItem
+	for i, item := range items {
+		switch {
+		case item.AccountID != nil:
+			byAccount[*item.AccountID] = item
+		case item.Source == "invoices":
+			invoices = &items[i]
+		}
+	}
+	accounts, err := s.repo.ListAccounts(ctx, false)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load accounts.", err)
+	}
+	sums, err := s.repo.SumAccountMovements(ctx, &today)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to compute balances.", err)
+	}
+
+	var rows []models.NetWorthSnapshot
+	for _, acc := range accounts {
+		if acc.OpeningDate.After(today) {
+			continue
+		}
+		item, ok := byAccount[acc.ID]
+		if !ok {
+			item = accountNetWorthItem(acc)
+			if err := s.repo.CreateNetWorthItem(ctx, &item); err != nil {
+				return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to create net worth item.", err)
+			}
+		}
+		if item.Archived {
+			continue
+		}
+		value := acc.OpeningBalanceCents + sums[acc.ID]
+		if item.Kind == "liability" {
+			value = -value
+		}
+		rows = append(rows, models.NetWorthSnapshot{ItemID: item.ID, Date: today, ValueCents: value, Auto: true})
+	}
+
+	currency := cardCurrency(accounts)
+	if invoices == nil {
+		invoices = &models.NetWorthItem{Name: openInvoicesItemName, Kind: "liability", Category: "card", Source: "invoices", Currency: normalizeCurrency(currency)}
+		if err := s.repo.CreateNetWorthItem(ctx, invoices); err != nil {
+			return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to create net worth item.", err)
+		}
+	} else if currency != "" && invoices.Currency != currency {
+		// Invoices carry no currency: their amounts were always in the cards' currency.
+		invoices.Currency = currency
+		if err := s.repo.SaveNetWorthItem(ctx, invoices); err != nil {
+			return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update net worth item.", err)
+		}
+	}
+	if !invoices.Archived {
+		owed, err := s.repo.SumOpenInvoiceBalance(ctx)
+		if err != nil {
+			return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load invoices.", err)
+		}
+		rows = append(rows, models.NetWorthSnapshot{ItemID: invoices.ID, Date: today, ValueCents: owed, Auto: true})
+	}
+
+	n, err := s.repo.InsertNetWorthSnapshots(ctx, rows)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to save net worth snapshots.", err)
+	}
+	res.Created = n
+	return res, nil
+}
+
+func (s *Service) findNetWorthItem(ctx context.Context, id uuid.UUID) (*models.NetWorthItem, error) {
+	row, err := s.repo.FindNetWorthItem(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound(apperrors.CodeInternal, "Net worth item not found.")
+		}
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load net worth item.", err)
+	}
+	return row, nil
+}
+
+func validateNetWorthItem(row *models.NetWorthItem) error {
+	if row.Name == "" {
+		return apperrors.Invalid(apperrors.CodeInternal, "Name is required.")
+	}
+	if row.Kind != "asset" && row.Kind != "liability" {
+		return apperrors.Invalid(apperrors.CodeInternal, "Kind must be asset or liability.")
+	}
+	switch row.Category {
+	case "bank", "investment", "equipment", "property", "loan", "card", "other":
+	default:
+		return apperrors.Invalid(apperrors.CodeInternal, "Category must be bank, investment, equipment, property, loan, card or other.")
+	}
+	return nil
+}
+
+// accountNetWorthItem is the item tracking an account: card accounts are liabilities (their negative
+// balance is what we owe), everything else an asset.
+func accountNetWorthItem(acc models.Account) models.NetWorthItem {
+	id := acc.ID
+	item := models.NetWorthItem{Name: acc.Name, Kind: "asset", Category: "bank", Source: "account", AccountID: &id, Currency: normalizeCurrency(acc.Currency)}
+	switch acc.Type {
+	case "credit_card":
+		item.Kind, item.Category = "liability", "card"
+	case "investment":
+		item.Category = "investment"
+	}
+	return item
+}
+
+// invoiceCurrency is the currency card invoices are kept in: that of the active card accounts, BRL
+// without any.
+func (s *Service) invoiceCurrency(ctx context.Context) (string, error) {
+	accounts, err := s.repo.ListAccounts(ctx, false)
+	if err != nil {
+		return "", apperrors.InternalCause(apperrors.CodeInternal, "Failed to load accounts.", err)
+	}
+	return normalizeCurrency(cardCurrency(accounts)), nil
+}
+
+// cardCurrency is the currency most credit card accounts use, empty without any.
+func cardCurrency(accounts []models.Account) string {
+	counts := map[string]int{}
+	best := ""
+	for _, acc := range accounts {
+		if acc.Type != "credit_card" {
+			continue
+		}
+		c := normalizeCurrency(acc.Currency)
+		counts[c]++
+		if counts[c] > counts[best] {
+			best = c
+		}
+	}
+	return best
+}
+
+func withLatestValue(row models.NetWorthItem, latest map[uuid.UUID]models.NetWorthSnapshot) NetWorthItemWithValue {
+	out := NetWorthItemWithValue{NetWorthItem: row}
+	if snap, ok := latest[row.ID]; ok {
+		value, date := snap.ValueCents, snap.Date
+		out.ValueCents, out.ValueDate = &value, &date
+	}
+	return out
+}
+
+// netWorthDates lists the last day of every month from from to to, ending with to itself.
+func netWorthDates(from, to time.Time) []time.Time {
+	var out []time.Time
+	for d := time.Date(from.Year(), from.Month()+1, 0, 0, 0, 0, 0, time.UTC); d.Before(to); d = time.Date(d.Year(), d.Month()+2, 0, 0, 0, 0, 0, time.UTC) {
+		out = append(out, d)
+	}
+	return append(out, to)
+}
+
+// netWorthPoints values every item at each date from its latest snapshot on or before it. snaps must
+// be sorted by date; snapshots of unknown items are ignored.
+func netWorthPoints(items []models.NetWorthItem, snaps []models.NetWorthSnapshot, dates []time.Time, convert func(int64, string, time.Time) int64) []NetWorthPoint {
+	byID := make(map[uuid.UUID]models.NetWorthItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	current := map[uuid.UUID]int64{}
+	out := make([]NetWorthPoint, 0, len(dates))
+	next := 0
+	for _, date := range dates {
+		for ; next < len(snaps) && !snaps[next].Date.After(date); next++ {
+			current[snaps[next].ItemID] = snaps[next].ValueCents
+		}
+		p := NetWorthPoint{Date: date}
+		for id, value := range current {
+			item, ok := byID[id]
+			if !ok {
+				continue
+			}
+			amount := convert(value, item.Currency, date)
+			if item.Kind == "liability" {
+				p.LiabilitiesCents += amount
+			} else {
+				p.AssetsCents += amount
+			}
+		}
+		p.NetCents = p.AssetsCents - p.LiabilitiesCents
+		out = append(out, p)
+	}
+	return out
+}
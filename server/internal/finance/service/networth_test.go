@@ -0,0 +1,84 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func TestNetWorthDates(t *testing.T) {
+	got := netWorthDates(day(2025, 1, 15), day(2025, 3, 10))
+	want := []time.Time{day(2025, 1, 31), day(2025, 2, 28), day(2025, 3, 10)}
+	if len(got) != len(want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Fatalf("point %d: got %s want %s", i, got[i].Format("2006-01-02"), want[i].Format("2006-01-02"))
+		}
+	}
+	if got := netWorthDates(day(2025, 3, 1), day(2025, 3, 31)); len(got) != 1 || !got[0].Equal(day(2025, 3, 31)) {
+		t.Fatalf("single month: %v", got)
+	}
+}
+
+func TestNetWorthPoints(t *testing.T) {
+	bank := models.NetWorthItem{ID: uuid.New(), Kind: "asset", Currency: "BRL"}
+	broker := models.NetWorthItem{ID: uuid.New(), Kind: "asset", Currency: "USD"}
+	loan := models.NetWorthItem{ID: uuid.New(), Kind: "liability", Currency: "BRL"}
+	snaps := []models.NetWorthSnapshot{
+		{ItemID: bank.ID, Date: day(2025, 1, 1), ValueCents: 100000},
+		{ItemID: loan.ID, Date: day(2025, 1, 10), ValueCents: 30000},
+		{ItemID: broker.ID, Date: day(2025, 2, 5), ValueCents: 1000},
+		{ItemID: bank.ID, Date: day(2025, 3, 1), ValueCents: 80000},
+		{ItemID: uuid.New(), Date: day(2025, 3, 2), ValueCents: 999999},
+	}
+	// USD at 5 BRL.
+	convert := func(amount int64, currency string, _ time.Time) int64 {
+		if currency == "USD" {
+			return amount * 5
+		}
+		return amount
+	}
+	points := netWorthPoints([]models.NetWorthItem{bank, broker, loan}, snaps,
+		[]time.Time{day(2024, 12, 31), day(2025, 1, 31), day(2025, 2, 28), day(2025, 3, 31)}, convert)
+
+	want := []NetWorthPoint{
+		{},
+		{AssetsCents: 100000, LiabilitiesCents: 30000, NetCents: 70000},
+		{AssetsCents: 105000, LiabilitiesCents: 30000, NetCents: 75000},
+		{AssetsCents: 85000, LiabilitiesCents: 30000, NetCents: 55000},
+	}
+	for i, p := range points {
+		if p.AssetsCents != want[i].AssetsCents || p.LiabilitiesCents != want[i].LiabilitiesCents || p.NetCents != want[i].NetCents {
+			t.Fatalf("point %s: %+v want %+v", p.Date.Format("2006-01-02"), p, want[i])
+		}
+	}
+}
+
+func TestAccountNetWorthItem(t *testing.T) {
+	card := accountNetWorthItem(models.Account{ID: uuid.New(), Name: "Nubank", Type: "credit_card", Currency: "brl"})
+	if card.Kind != "liability" || card.Category != "card" || card.Source != "account" || card.Currency != "BRL" {
+		t.Fatalf("card account: %+v", card)
+	}
+	if inv := accountNetWorthItem(models.Account{ID: uuid.New(), Type: "investment"}); inv.Kind != "asset" || inv.Category != "investment" {
+		t.Fatalf("investment account: %+v", inv)
+	}
+}
+
+func TestCardCurrency(t *testing.T) {
+	accounts := []models.Account{
+		{Type: "checking", Currency: "BRL"},
+		{Type: "credit_card", Currency: "usd"},
+		{Type: "credit_card", Currency: "EUR"},
+		{Type: "credit_card", Currency: "USD"},
+	}
+	if got := cardCurrency(accounts); got != "USD" {
+		t.Fatalf("got %q", got)
+	}
+	if got := cardCurrency(accounts[:1]); got != "" {
+		t.Fatalf("without cards: %q", got)
+	}
+}
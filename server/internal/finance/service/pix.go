@@ -0,0 +1,214 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/pix"
+	"github.com/woragis/management/backend/server/internal/finance/qr"
+	mediasvc "github.com/woragis/management/backend/server/internal/media/service"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+// PixCharge is a Pix "copia e cola" payload with its QR code image: a stored media asset for
+// receivables, inline base64 PNG for one-off charges.
+type PixCharge struct {
+	Payload     string     `json:"payload"`
+	AmountCents int64      `json:"amountCents"`
+	TxID        string     `json:"txid"`
+	QRMediaID   *uuid.UUID `json:"qrMediaId,omitempty"`
+	QRURL       string     `json:"qrUrl,omitempty"`
+	QRPNG       string     `json:"qrPng,omitempty"`
+}
+
+type PixInput struct {
+	// AmountCents zero lets the payer type the amount.
+	AmountCents int64
+	TxID        string
+	Description string
+	// URL is the location a PSP returned for a dynamic charge; the configured key is used otherwise.
+	URL string
+}
+
+// GeneratePix builds a BR Code from the Pix receiver in the finance settings. The QR code comes back
+// inline: one-off charges are not kept, so nothing is stored for them.
+func (s *Service) GeneratePix(ctx context.Context, in PixInput) (*PixCharge, error) {
+	settings, err := s.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := pixPayload(settings, in)
+	if err != nil {
+		return nil, err
+	}
+	img, err := pixQRPNG(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &PixCharge{
+		Payload:     payload,
+		AmountCents: in.AmountCents,
+		TxID:        firstNonEmpty(strings.TrimSpace(in.TxID), "***"),
+		QRPNG:       base64.StdEncoding.EncodeToString(img),
+	}, nil
+}
+
+// ContactPix generates a charge for a contact. Without an amount it bills everything the contact
+// owes on sent BRL receivables.
+func (s *Service) ContactPix(ctx context.Context, contactID uuid.UUID, in PixInput) (*PixCharge, error) {
+	if err := s.validateContactID(ctx, &contactID); err != nil {
+		return nil, err
+	}
+	if in.AmountCents == 0 {
+		open, err := s.ListReceivables(ctx, ReceivableFilter{Status: "sent", ContactID: &contactID})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range open {
+			if row.Currency == "BRL" {
+				in.AmountCents += row.TotalCents
+			}
+		}
+		if in.AmountCents == 0 {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Contact has no open receivables; set an amount.")
+		}
+	}
+	return s.GeneratePix(ctx, in)
+}
+
+// ReceivablePix returns the BR Code of a sent BRL receivable. The QR code image is rendered on the
+// first call and reused until the payload changes.
+func (s *Service) ReceivablePix(ctx context.Context, id uuid.UUID) (*PixCharge, error) {
+	row, err := s.GetReceivable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != "sent" {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Only sent receivables can be charged by Pix.")
+	}
+	if row.Currency != "BRL" {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Pix charges must be in BRL.")
+	}
+	if s.media == nil {
+		return nil, apperrors.Unavailable(apperrors.CodeInternal, "Media storage is not configured.")
+	}
+	settings, err := s.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := pixPayload(settings, receivablePixInput(row))
+	if err != nil {
+		return nil, err
+	}
+	if payload != row.PixPayload || row.PixMediaID == nil {
+		asset, err := s.uploadPixQR(ctx, payload, "pix-"+*row.Number+".png")
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.SetReceivablePix(ctx, row.ID, payload, &asset.ID); err != nil {
+			_ = s.media.Delete(ctx, asset.ID)
+			return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update receivable.", err)
+		}
+		s.deleteMedia(ctx, row.PixMediaID)
+		row.PixPayload, row.PixMediaID = payload, &asset.ID
+	}
+	asset, err := s.media.GetByID(ctx, *row.PixMediaID)
+	if err != nil {
+		return nil, err
+	}
+	return &PixCharge{
+		Payload:     payload,
+		AmountCents: row.TotalCents,
+		TxID:        pix.TxID(*row.Number),
+		QRMediaID:   row.PixMediaID,
+		QRURL:       asset.PublicURL,
+	}, nil
+}
+
+// refreshReceivablePix keeps the stored BR Code of an issued BRL receivable in line with the Pix
+// settings, so the PDF prints the current one. Pix is optional on billing documents: an incomplete
+// receiver configuration leaves the receivable as it is.
+func (s *Service) refreshReceivablePix(ctx context.Context, row *models.Receivable, settings *models.FinanceSettings) error {
+	if row.Number == nil || row.Currency != "BRL" || settings.PixKey == "" {
+		return nil
+	}
+	payload, err := pixPayload(settings, receivablePixInput(row))
+	if err != nil || payload == row.PixPayload {
+		return nil
+	}
+	if err := s.repo.SetReceivablePix(ctx, row.ID, payload, nil); err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to update receivable.", err)
+	}
+	s.deleteMedia(ctx, row.PixMediaID)
+	row.PixPayload, row.PixMediaID = payload, nil
+	return nil
+}
+
+func receivablePixInput(row *models.Receivable) PixInput {
+	return PixInput{
+		AmountCents: row.TotalCents,
+		TxID:        pix.TxID(*row.Number),
+		Description: "Fatura " + *row.Number,
+	}
+}
+
+func pixPayload(settings *models.FinanceSettings, in PixInput) (string, error) {
+	if in.AmountCents < 0 {
+		return "", apperrors.Invalid(apperrors.CodeInternal, "Amount cannot be negative.")
+	}
+	payload, err := pix.BRCode(pix.Payload{
+		Key:          settings.PixKey,
+		URL:          in.URL,
+		MerchantName: firstNonEmpty(settings.PixMerchantName, settings.BillingName),
+		MerchantCity: settings.PixMerchantCity,
+		AmountCents:  in.AmountCents,
+		TxID:         in.TxID,
+		Description:  in.Description,
+	})
+	switch {
+	case err == nil:
+		return payload, nil
+	case errors.Is(err, pix.ErrMissingKey):
+		return "", apperrors.Invalid(apperrors.CodeInternal, "Configure a Pix key in the finance settings.")
+	case errors.Is(err, pix.ErrMissingName):
+		return "", apperrors.Invalid(apperrors.CodeInternal, "Configure the Pix merchant name and city in the finance settings.")
+	case errors.Is(err, pix.ErrInvalidTxID):
+		return "", apperrors.Invalid(apperrors.CodeInternal, "Txid must be up to 25 letters or digits.")
+	default:
+		return "", apperrors.InvalidCause(apperrors.CodeInternal, "Pix key or URL is too long.", err)
+	}
+}
+
+// uploadPixQR stores the QR code publicly: it is meant to be sent to the payer.
+func (s *Service) uploadPixQR(ctx context.Context, payload, filename string) (*models.MediaAsset, error) {
+	if s.media == nil {
+		return nil, apperrors.Unavailable(apperrors.CodeInternal, "Media storage is not configured.")
+	}
+	img, err := pixQRPNG(payload)
+	if err != nil {
+		return nil, err
+	}
+	return s.media.Upload(ctx, mediasvc.UploadInput{
+		Filename: filename,
+		MimeType: "image/png",
+		AltText:  "Pix QR code",
+		Reader:   bytes.NewReader(img),
+	})
+}
+
+func pixQRPNG(payload string) ([]byte, error) {
+	code, err := qr.Encode(payload)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to render QR code.", err)
+	}
+	img, err := code.PNG(8)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to render QR code.", err)
+	}
+	return img, nil
+}
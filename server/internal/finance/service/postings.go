@@ -0,0 +1,527 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/repository"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	expectedPending   = "pending"
+	expectedConfirmed = "confirmed"
+	expectedSkipped   = "skipped"
+
+	// postingLookbackDays bounds how far back a run still materializes missed occurrences.
+	postingLookbackDays = 45
+	// autoMatchWindowDays is how far from its due date a manually entered transaction settles an occurrence.
+	autoMatchWindowDays = 10
+)
+
+type PostingRunResult struct {
+	Created int64  `json:"created"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+// RunPostings creates pending expected transactions for every active income source and expense
+// occurrence due up to now. It is idempotent: existing occurrences are never duplicated.
+func (s *Service) RunPostings(ctx context.Context, now time.Time) (*PostingRunResult, error) {
+	today := dateOnly(now)
+	from := today.AddDate(0, 0, -postingLookbackDays)
+	incomes, err := s.repo.ListIncomeSources(ctx, repository.IncomeSourceFilter{ActiveOnly: true})
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load income sources.", err)
+	}
+	expenses, err := s.repo.ListExpenses(ctx, true)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load expenses.", err)
+	}
+	n, err := s.repo.InsertExpectedTransactions(ctx, expectedOccurrences(incomes, expenses, from, today))
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to create expected transactions.", err)
+	}
+	return &PostingRunResult{
+		Created: n,
+		From:    from.Format("2006-01-02"),
+		To:      today.Format("2006-01-02"),
+	}, nil
+}
+
+// expectedOccurrences expands sources into pending rows, never before the source was declared.
+func expectedOccurrences(incomes []models.IncomeSource, expenses []models.Expense, from, to time.Time) []models.ExpectedTransaction {
+	var out []models.ExpectedTransaction
+	for _, inc := range incomes {
+		for _, d := range incomeSchedule(inc).occurrences(laterDate(from, inc.CreatedAt), to) {
+			out = append(out, models.ExpectedTransaction{
+				SourceType:  "income",
+				SourceID:    inc.ID,
+				DueDate:     d,
+				Description: inc.Name,
+				AmountCents: inc.AmountCents,
+				Currency:    normalizeCurrency(inc.Currency),
+				Status:      expectedPending,
+			})
+		}
+	}
+	for _, exp := range expenses {
+		for _, d := range expenseSchedule(exp).occurrences(laterDate(from, exp.CreatedAt), to) {
+			out = append(out, models.ExpectedTransaction{
+				SourceType:  "expense",
+				SourceID:    exp.ID,
+				DueDate:     d,
+				Description: exp.Name,
+				AmountCents: exp.AmountCents,
+				Currency:    normalizeCurrency(exp.Currency),
+				Status:      expectedPending,
+			})
+		}
+	}
+	return out
+}
+
+// rescheduleExpected replaces the pending occurrences of one source with the ones its current schedule
+// yields, so a changed day or weekend rule does not leave rows on the old dates. The window reaches back
+// to the oldest pending row so unresolved occurrences are not dropped; incomes and expenses hold the
+// source when it is still active. prev is the source's amount and name before the edit, telling which
+// pending rows were adjusted by hand.
+func (s *Service) rescheduleExpected(ctx context.Context, sourceType string, sourceID uuid.UUID, prev expectedDefaults, incomes []models.IncomeSource, expenses []models.Expense) error {
+	pending, err := s.repo.ListExpectedTransactions(ctx, repository.ExpectedTransactionFilter{
+		Status:     expectedPending,
+		SourceType: sourceType,
+		SourceID:   &sourceID,
+	})
+	if err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to load expected transactions.", err)
+	}
+	today := dateOnly(time.Now().UTC())
+	from := today.AddDate(0, 0, -postingLookbackDays)
+	for _, row := range pending {
+		if row.DueDate.Before(from) {
+			from = dateOnly(row.DueDate)
+		}
+	}
+	if err := s.repo.DeletePendingExpected(ctx, sourceType, sourceID); err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to reset expected transactions.", err)
+	}
+	rows := carryExpectedAdjustments(expectedOccurrences(incomes, expenses, from, today), pending, prev)
+	if _, err := s.repo.InsertExpectedTransactions(ctx, rows); err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to create expected transactions.", err)
+	}
+	return nil
+}
+
+// expectedDefaults is what a source puts on its occurrences before any adjustment.
+type expectedDefaults struct {
+	AmountCents int64
+	Description string
+}
+
+// carryExpectedAdjustments keeps what AdjustExpectedTransaction changed on the pending rows still due
+// on the same day: amounts and descriptions differing from the source's previous ones, and notes.
+func carryExpectedAdjustments(rows, pending []models.ExpectedTransaction, prev expectedDefaults) []models.ExpectedTransaction {
+	byDue := make(map[time.Time]models.ExpectedTransaction, len(pending))
+	for _, p := range pending {
+		byDue[dateOnly(p.DueDate)] = p
+	}
+	for i := range rows {
+		old, ok := byDue[dateOnly(rows[i].DueDate)]
+		if !ok {
+			continue
+		}
+		rows[i].ID = old.ID
+		if old.AmountCents != prev.AmountCents {
+			rows[i].AmountCents = old.AmountCents
+		}
+		if old.Description != prev.Description {
+			rows[i].Description = old.Description
+		}
+		rows[i].Notes = old.Notes
+	}
+	return rows
+}
+
+// recurrenceChanged reports whether two recurrences yield different dates.
+func recurrenceChanged(a, b models.Recurrence) bool {
+	return !sameDate(a.AnchorDate, b.AnchorDate) || a.Interval != b.Interval || !sameInt(a.Weekday, b.Weekday) ||
+		!sameInt(a.MonthOfYear, b.MonthOfYear) || !sameDate(a.EndDate, b.EndDate) || a.WeekendAdjust != b.WeekendAdjust
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func sameInt(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func laterDate(a, b time.Time) time.Time {
+	a, b = dateOnly(a), dateOnly(b)
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+type ExpectedTransactionFilter struct {
+	Status     string
+	SourceType string
+	SourceID   *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+}
+
+type AdjustExpectedInput struct {
+	AmountCents *int64
+	Description *string
+	Notes       *string
+}
+
+type ConfirmExpectedInput struct {
+	AmountCents   *int64
+	Date          *time.Time
+	Notes         *string
+	TransactionID *uuid.UUID
+}
+
+func (s *Service) ListExpectedTransactions(ctx context.Context, f ExpectedTransactionFilter) ([]models.ExpectedTransaction, error) {
+	rows, err := s.repo.ListExpectedTransactions(ctx, repository.ExpectedTransactionFilter{
+		Status:     strings.TrimSpace(strings.ToLower(f.Status)),
+		SourceType: strings.TrimSpace(strings.ToLower(f.SourceType)),
+		SourceID:   f.SourceID,
+		From:       f.From,
+		To:         f.To,
+	})
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load expected transactions.", err)
+	}
+	return rows, nil
+}
+
+func (s *Service) GetExpectedTransaction(ctx context.Context, id uuid.UUID) (*models.ExpectedTransaction, error) {
+	row, err := s.repo.FindExpectedTransaction(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound(apperrors.CodeInternal, "Expected transaction not found.")
+		}
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load expected transaction.", err)
+	}
+	return row, nil
+}
+
+// AdjustExpectedTransaction changes what a pending occurrence is expected to be before it posts.
+func (s *Service) AdjustExpectedTransaction(ctx context.Context, id uuid.UUID, in AdjustExpectedInput) (*models.ExpectedTransaction, error) {
+	row, err := s.pendingExpected(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if in.AmountCents != nil {
+		if *in.AmountCents <= 0 {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Amount must be positive.")
+		}
+		row.AmountCents = *in.AmountCents
+	}
+	if in.Description != nil {
+		desc := strings.TrimSpace(*in.Description)
+		if desc == "" {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Description is required.")
+		}
+		row.Description = desc
+	}
+	if in.Notes != nil {
+		row.Notes = strings.TrimSpace(*in.Notes)
+	}
+	if err := s.repo.SaveExpectedTransaction(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update expected transaction.", err)
+	}
+	return row, nil
+}
+
+// ConfirmExpectedTransaction posts a pending occurrence. Without TransactionID a new transaction is
+// created (optionally with a different amount or date) the same way CreateTransaction builds one; with
+// it, an existing transaction not yet tied to another source or occurrence is linked instead.
+func (s *Service) ConfirmExpectedTransaction(ctx context.Context, id uuid.UUID, in ConfirmExpectedInput) (*models.ExpectedTransaction, error) {
+	row, err := s.pendingExpected(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var txn *models.Transaction
+	create := in.TransactionID == nil
+	if create {
+		input, err := s.transactionForExpected(ctx, row, in)
+		if err != nil {
+			return nil, err
+		}
+		if txn, err = s.newTransaction(ctx, input); err != nil {
+			return nil, err
+		}
+	} else {
+		txn, err = s.GetTransaction(ctx, *in.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		if txn.Type != row.SourceType {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Transaction type does not match the expected transaction.")
+		}
+		if source := transactionSource(txn); source != nil && *source != row.SourceID {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Transaction is already linked to another source.")
+		}
+		if _, err := s.repo.FindExpectedByTransaction(ctx, txn.ID); err == nil {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Transaction already confirms another expected transaction.")
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load expected transaction.", err)
+		}
+		linkTransactionSource(txn, row)
+	}
+	now := time.Now().UTC()
+	actual := txn.AmountCents
+	row.Status = expectedConfirmed
+	row.ActualAmountCents = &actual
+	row.ActualDate = &txn.Date
+	row.ResolvedAt = &now
+	if in.Notes != nil {
+		row.Notes = strings.TrimSpace(*in.Notes)
+	}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if create {
+			if err := tx.repo.CreateTransaction(ctx, txn); err != nil {
+				return apperrors.InternalCause(apperrors.CodeInternal, "Failed to create transaction.", err)
+			}
+			if err := tx.refreshInvoicePayments(ctx, txn.InvoiceID); err != nil {
+				return err
+			}
+		} else if err := tx.repo.SaveTransaction(ctx, txn); err != nil {
+			return apperrors.InternalCause(apperrors.CodeInternal, "Failed to update transaction.", err)
+		}
+		row.TransactionID = &txn.ID
+		if err := tx.repo.SaveExpectedTransaction(ctx, row); err != nil {
+			return apperrors.InternalCause(apperrors.CodeInternal, "Failed to confirm expected transaction.", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+func (s *Service) SkipExpectedTransaction(ctx context.Context, id uuid.UUID, notes *string) (*models.ExpectedTransaction, error) {
+	row, err := s.pendingExpected(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	row.Status = expectedSkipped
+	row.ResolvedAt = &now
+	if notes != nil {
+		row.Notes = strings.TrimSpace(*notes)
+	}
+	if err := s.repo.SaveExpectedTransaction(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to skip expected transaction.", err)
+	}
+	return row, nil
+}
+
+func (s *Service) pendingExpected(ctx context.Context, id uuid.UUID) (*models.ExpectedTransaction, error) {
+	row, err := s.GetExpectedTransaction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != expectedPending {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Expected transaction is already "+row.Status+".")
+	}
+	return row, nil
+}
+
+// transactionForExpected is the CreateTransaction input posting the occurrence.
+func (s *Service) transactionForExpected(ctx context.Context, row *models.ExpectedTransaction, in ConfirmExpectedInput) (CreateTransactionInput, error) {
+	amount := row.AmountCents
+	if in.AmountCents != nil {
+		amount = *in.AmountCents
+	}
+	if amount <= 0 {
+		return CreateTransactionInput{}, apperrors.Invalid(apperrors.CodeInternal, "Amount must be positive.")
+	}
+	date := row.DueDate
+	if in.Date != nil {
+		date = *in.Date
+	}
+	out := CreateTransactionInput{
+		Type:        row.SourceType,
+		AmountCents: amount,
+		Currency:    row.Currency,
+		Description: row.Description,
+		Date:        date,
+		Notes:       row.Notes,
+	}
+	id := row.SourceID
+	// Carry the source's project/contact so per-project and per-contact views pick the posting up.
+	switch row.SourceType {
+	case "income":
+		out.IncomeSourceID = &id
+		if inc, err := s.repo.FindIncomeSource(ctx, row.SourceID); err == nil {
+			out.ProjectID = inc.ProjectID
+			out.ContactID = inc.ContactID
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return CreateTransactionInput{}, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load income source.", err)
+		}
+	case "expense":
+		out.ExpenseID = &id
+		if exp, err := s.repo.FindExpense(ctx, row.SourceID); err == nil {
+			out.ProjectID = exp.ProjectID
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return CreateTransactionInput{}, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load expense.", err)
+		}
+	}
+	return out, nil
+}
+
+// transactionSource is the income source or expense the transaction posts, if any.
+func transactionSource(txn *models.Transaction) *uuid.UUID {
+	switch txn.Type {
+	case "income":
+		return txn.IncomeSourceID
+	case "expense":
+		return txn.ExpenseID
+	}
+	return nil
+}
+
+func linkTransactionSource(txn *models.Transaction, row *models.ExpectedTransaction) {
+	id := row.SourceID
+	switch row.SourceType {
+	case "income":
+		txn.IncomeSourceID = &id
+	case "expense":
+		txn.ExpenseID = &id
+	}
+}
+
+// settleExpectedFor marks the nearest pending occurrence of the transaction's source as confirmed by it,
+// so entering the rent by hand does not leave the engine's rent posting dangling.
+func (s *Service) settleExpectedFor(ctx context.Context, txn *models.Transaction) error {
+	sourceID := transactionSource(txn)
+	if sourceID == nil {
+		return nil
+	}
+	row, err := s.repo.FindPendingExpectedNear(ctx, txn.Type, *sourceID,
+		txn.Date.AddDate(0, 0, -autoMatchWindowDays), txn.Date.AddDate(0, 0, autoMatchWindowDays))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to match expected transaction.", err)
+	}
+	now := time.Now().UTC()
+	actual := txn.AmountCents
+	date := txn.Date
+	row.Status = expectedConfirmed
+	row.TransactionID = &txn.ID
+	row.ActualAmountCents = &actual
+	row.ActualDate = &date
+	row.ResolvedAt = &now
+	if err := s.repo.SaveExpectedTransaction(ctx, row); err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to confirm expected transaction.", err)
+	}
+	return nil
+}
+
+// ExpectedDrift compares what a recurring source was expected to post with what actually posted.
+type ExpectedDrift struct {
+	SourceType     string    `json:"sourceType"`
+	SourceID       uuid.UUID `json:"sourceId"`
+	Name           string    `json:"name"`
+	Currency       string    `json:"currency"`
+	ConfirmedCount int       `json:"confirmedCount"`
+	PendingCount   int       `json:"pendingCount"`
+	SkippedCount   int       `json:"skippedCount"`
+	ExpectedCents  int64     `json:"expectedCents"`
+	ActualCents    int64     `json:"actualCents"`
+	DriftCents     int64     `json:"driftCents"`
+	PendingCents   int64     `json:"pendingCents"`
+	AvgDelayDays   float64   `json:"avgDelayDays"`
+}
+
+// ExpectedDrift aggregates occurrences due within the window (default: the last six months) per source.
+// ExpectedCents and ActualCents cover confirmed occurrences only, so DriftCents is the realized difference.
+func (s *Service) ExpectedDrift(ctx context.Context, from, to *time.Time) ([]ExpectedDrift, error) {
+	now := dateOnly(time.Now().UTC())
+	if to == nil {
+		to = &now
+	}
+	if from == nil {
+		f := to.AddDate(0, -6, 0)
+		from = &f
+	}
+	rows, err := s.repo.ListExpectedTransactions(ctx, repository.ExpectedTransactionFilter{From: from, To: to})
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load expected transactions.", err)
+	}
+	bySource := map[uuid.UUID]*ExpectedDrift{}
+	delays := map[uuid.UUID]int{}
+	var order []uuid.UUID
+	for _, row := range rows {
+		d, ok := bySource[row.SourceID]
+		if !ok {
+			// Rows are newest first, so the first one seen carries the current name.
+			d = &ExpectedDrift{
+				SourceType: row.SourceType,
+				SourceID:   row.SourceID,
+				Name:       row.Description,
+				Currency:   row.Currency,
+			}
+			bySource[row.SourceID] = d
+			order = append(order, row.SourceID)
+		}
+		switch row.Status {
+		case expectedConfirmed:
+			d.ConfirmedCount++
+			d.ExpectedCents += row.AmountCents
+			if row.ActualAmountCents != nil {
+				d.ActualCents += *row.ActualAmountCents
+			}
+			if row.ActualDate != nil {
+				delays[row.SourceID] += daysBetween(dateOnly(row.DueDate), dateOnly(*row.ActualDate))
+			}
+		case expectedSkipped:
+			d.SkippedCount++
+		default:
+			d.PendingCount++
+			d.PendingCents += row.AmountCents
+		}
+	}
+	out := make([]ExpectedDrift, 0, len(order))
+	for _, id := range order {
+		d := bySource[id]
+		d.DriftCents = d.ActualCents - d.ExpectedCents
+		if d.ConfirmedCount > 0 {
+			d.AvgDelayDays = math.Round(float64(delays[id])/float64(d.ConfirmedCount)*10) / 10
+		}
+		out = append(out, *d)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return abs64(out[i].DriftCents) > abs64(out[j].DriftCents)
+	})
+	return out, nil
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
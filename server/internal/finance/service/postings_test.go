@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func TestExpectedOccurrences(t *testing.T) {
+	salary := models.IncomeSource{
+		ID:          uuid.New(),
+		Name:        "Salary",
+		AmountCents: 500000,
+		Frequency:   "monthly",
+		DayOfMonth:  31,
+		Recurrence:  models.Recurrence{Interval: 1, WeekendAdjust: weekendAdjustPrevious},
+		CreatedAt:   day(2025, 12, 1),
+	}
+	rent := models.Expense{
+		ID:          uuid.New(),
+		Name:        "Rent",
+		AmountCents: 200000,
+		Currency:    "usd",
+		Frequency:   "monthly",
+		DayOfMonth:  15,
+		Recurrence:  models.Recurrence{Interval: 1, WeekendAdjust: weekendAdjustNext},
+		CreatedAt:   day(2026, 2, 1),
+	}
+	rows := expectedOccurrences([]models.IncomeSource{salary}, []models.Expense{rent}, day(2026, 1, 1), day(2026, 3, 31))
+
+	var incomes, expenses []string
+	for _, row := range rows {
+		if row.Status != expectedPending {
+			t.Fatalf("status %q", row.Status)
+		}
+		switch row.SourceType {
+		case "income":
+			if row.SourceID != salary.ID || row.AmountCents != 500000 || row.Currency != "BRL" {
+				t.Fatalf("income row: %+v", row)
+			}
+			incomes = append(incomes, row.DueDate.Format("2006-01-02"))
+		case "expense":
+			if row.SourceID != rent.ID || row.Description != "Rent" || row.Currency != "USD" {
+				t.Fatalf("expense row: %+v", row)
+			}
+			expenses = append(expenses, row.DueDate.Format("2006-01-02"))
+		}
+	}
+	// Month ends falling on a Saturday move back to Friday; February clamps to its last day first.
+	if got := incomes; len(got) != 3 || got[0] != "2026-01-30" || got[1] != "2026-02-27" || got[2] != "2026-03-31" {
+		t.Fatalf("income dates: %v", got)
+	}
+	// Nothing before the expense was created; Sundays move forward to Monday.
+	if got := expenses; len(got) != 2 || got[0] != "2026-02-16" || got[1] != "2026-03-16" {
+		t.Fatalf("expense dates: %v", got)
+	}
+}
+
+func TestCarryExpectedAdjustments(t *testing.T) {
+	sourceID := uuid.New()
+	adjusted, plain, moved := uuid.New(), uuid.New(), uuid.New()
+	pending := []models.ExpectedTransaction{
+		{ID: adjusted, SourceID: sourceID, DueDate: day(2026, 1, 10), AmountCents: 120000, Description: "Rent + fees", Notes: "late fee"},
+		{ID: plain, SourceID: sourceID, DueDate: day(2026, 2, 10), AmountCents: 100000, Description: "Rent"},
+		{ID: moved, SourceID: sourceID, DueDate: day(2026, 3, 10), AmountCents: 90000, Description: "Rent"},
+	}
+	rows := []models.ExpectedTransaction{
+		{SourceID: sourceID, DueDate: day(2026, 1, 10), AmountCents: 110000, Description: "Rent"},
+		{SourceID: sourceID, DueDate: day(2026, 2, 10), AmountCents: 110000, Description: "Rent"},
+		{SourceID: sourceID, DueDate: day(2026, 3, 12), AmountCents: 110000, Description: "Rent"},
+	}
+	got := carryExpectedAdjustments(rows, pending, expectedDefaults{AmountCents: 100000, Description: "Rent"})
+	if got[0].ID != adjusted || got[0].AmountCents != 120000 || got[0].Description != "Rent + fees" || got[0].Notes != "late fee" {
+		t.Fatalf("adjusted row: %+v", got[0])
+	}
+	if got[1].ID != plain || got[1].AmountCents != 110000 || got[1].Description != "Rent" {
+		t.Fatalf("unadjusted row follows the source: %+v", got[1])
+	}
+	if got[2].ID != uuid.Nil || got[2].AmountCents != 110000 {
+		t.Fatalf("row on a new date: %+v", got[2])
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/repository"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+type ProjectPLInput struct {
+	Year   int
+	Month  int
+	Months int
+}
+
+type ProjectPLMonth struct {
+	Year         int   `json:"year"`
+	Month        int   `json:"month"`
+	RevenueCents int64 `json:"revenueCents"`
+	CostCents    int64 `json:"costCents"`
+	ProfitCents  int64 `json:"profitCents"`
+}
+
+type ProjectPL struct {
+	ProjectID         *uuid.UUID       `json:"projectId,omitempty"`
+	ReportingCurrency string           `json:"reportingCurrency"`
+	From              string           `json:"from"`
+	To                string           `json:"to"`
+	RevenueCents      int64            `json:"revenueCents"`
+	CostCents         int64            `json:"costCents"`
+	ProfitCents       int64            `json:"profitCents"`
+	MarginPercent     *float64         `json:"marginPercent"`
+	MRRCents          int64            `json:"mrrCents"`
+	RevenueByCategory map[string]int64 `json:"revenueByCategory"`
+	CostsByCategory   map[string]int64 `json:"costsByCategory"`
+	Months            []ProjectPLMonth `json:"months"`
+	MissingRates      []string         `json:"missingRates,omitempty"`
+}
+
+// ProjectsPL is the P&L of every project with finance activity, plus their combined total.
+type ProjectsPL struct {
+	Total    ProjectPL   `json:"total"`
+	Projects []ProjectPL `json:"projects"`
+}
+
+// ProjectPL reports revenue, costs, margin and MRR of one project over the months ending at the
+// requested month.
+func (s *Service) ProjectPL(ctx context.Context, projectID uuid.UUID, in ProjectPLInput) (*ProjectPL, error) {
+	report, err := s.projectsPL(ctx, &projectID, in)
+	if err != nil {
+		return nil, err
+	}
+	if len(report.Projects) > 0 {
+		return &report.Projects[0], nil
+	}
+	out := report.Total
+	out.ProjectID = &projectID
+	return &out, nil
+}
+
+// ProjectsPL reports every project that has transactions or active recurring income in the range.
+func (s *Service) ProjectsPL(ctx context.Context, in ProjectPLInput) (*ProjectsPL, error) {
+	return s.projectsPL(ctx, nil, in)
+}
+
+func (s *Service) projectsPL(ctx context.Context, projectID *uuid.UUID, in ProjectPLInput) (*ProjectsPL, error) {
+	from, to := projectPLRange(in)
+	rows, err := s.repo.SumProjectLedger(ctx, from, to, projectID)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to compute project P&L.", err)
+	}
+	incomes, err := s.repo.ListIncomeSources(ctx, repository.IncomeSourceFilter{ActiveOnly: true, ProjectID: projectID})
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load income sources.", err)
+	}
+	now := time.Now().UTC()
+	conv, err := s.newConverter(ctx, laterDate(now, to.AddDate(0, 0, -1)))
+	if err != nil {
+		return nil, err
+	}
+	newPL := func(id *uuid.UUID) *ProjectPL {
+		pl := &ProjectPL{
+			ProjectID:         id,
+			ReportingCurrency: conv.target,
+			From:              from.Format("2006-01-02"),
+			To:                to.AddDate(0, 0, -1).Format("2006-01-02"),
+			RevenueByCategory: map[string]int64{},
+			CostsByCategory:   map[string]int64{},
+		}
+		for m := from; m.Before(to); m = m.AddDate(0, 1, 0) {
+			pl.Months = append(pl.Months, ProjectPLMonth{Year: m.Year(), Month: int(m.Month())})
+		}
+		return pl
+	}
+	total := newPL(nil)
+	byProject := map[uuid.UUID]*ProjectPL{}
+	project := func(id uuid.UUID) *ProjectPL {
+		pl, ok := byProject[id]
+		if !ok {
+			pid := id
+			pl = newPL(&pid)
+			byProject[id] = pl
+		}
+		return pl
+	}
+	for _, row := range rows {
+		amount := conv.convert(row.AmountCents, row.Currency, row.Date)
+		i := monthIndex(row.Date.Year(), int(row.Date.Month())) - monthIndex(from.Year(), int(from.Month()))
+		for _, pl := range []*ProjectPL{project(row.ProjectID), total} {
+			pl.add(i, row.Type, row.Category, amount)
+		}
+	}
+	today := dateOnly(now)
+	for _, inc := range incomes {
+		if inc.ProjectID == nil || (inc.EndDate != nil && inc.EndDate.Before(today)) {
+			continue
+		}
+		mrr := conv.convert(monthlyRecurringCents(inc), inc.Currency, today)
+		project(*inc.ProjectID).MRRCents += mrr
+		total.MRRCents += mrr
+	}
+	out := &ProjectsPL{Projects: make([]ProjectPL, 0, len(byProject))}
+	total.finish(conv)
+	out.Total = *total
+	for _, pl := range byProject {
+		pl.finish(conv)
+		out.Projects = append(out.Projects, *pl)
+	}
+	sort.Slice(out.Projects, func(i, j int) bool {
+		if out.Projects[i].ProfitCents != out.Projects[j].ProfitCents {
+			return out.Projects[i].ProfitCents > out.Projects[j].ProfitCents
+		}
+		return out.Projects[i].ProjectID.String() < out.Projects[j].ProjectID.String()
+	})
+	return out, nil
+}
+
+func (pl *ProjectPL) add(month int, txType, category string, amount int64) {
+	var m *ProjectPLMonth
+	if month >= 0 && month < len(pl.Months) {
+		m = &pl.Months[month]
+	}
+	switch txType {
+	case "income":
+		pl.RevenueCents += amount
+		pl.RevenueByCategory[category] += amount
+		if m != nil {
+			m.RevenueCents += amount
+		}
+	case "expense":
+		pl.CostCents += amount
+		pl.CostsByCategory[category] += amount
+		if m != nil {
+			m.CostCents += amount
+		}
+	}
+}
+
+func (pl *ProjectPL) finish(conv *converter) {
+	pl.ProfitCents = pl.RevenueCents - pl.CostCents
+	pl.MarginPercent = marginPercent(pl.RevenueCents, pl.ProfitCents)
+	for i := range pl.Months {
+		pl.Months[i].ProfitCents = pl.Months[i].RevenueCents - pl.Months[i].CostCents
+	}
+	pl.MissingRates = conv.missingCurrencies()
+}
+
+// projectPLRange returns [from, to) covering in.Months whole months (default 12, at most 36) that
+// end with the requested month.
+func projectPLRange(in ProjectPLInput) (time.Time, time.Time) {
+	year, month := parseYearMonth(in.Year, in.Month)
+	months := in.Months
+	if months <= 0 {
+		months = 12
+	}
+	if months > 36 {
+		months = 36
+	}
+	to := time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC)
+	return to.AddDate(0, -months, 0), to
+}
+
+// marginPercent is profit as a percentage of revenue, rounded to two decimals; nil without revenue.
+func marginPercent(revenue, profit int64) *float64 {
+	if revenue <= 0 {
+		return nil
+	}
+	pct := math.Round(float64(profit)*10000/float64(revenue)) / 100
+	return &pct
+}
+
+// monthlyRecurringCents normalises a recurring income to its average amount per month. One-time
+// income contributes nothing.
+func monthlyRecurringCents(inc models.IncomeSource) int64 {
+	interval := int64(inc.Interval)
+	if interval < 1 {
+		interval = 1
+	}
+	switch normalizeFrequency(inc.Frequency) {
+	case "weekly":
+		return inc.AmountCents * 52 / (12 * interval)
+	case "yearly":
+		return inc.AmountCents / (12 * interval)
+	case "one_time":
+		return 0
+	default:
+		return inc.AmountCents / interval
+	}
+}
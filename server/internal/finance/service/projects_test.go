@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func TestMonthlyRecurringCentsNormalisesFrequency(t *testing.T) {
+	cases := []struct {
+		frequency string
+		interval  int
+		want      int64
+	}{
+		{"monthly", 1, 120000},
+		{"monthly", 3, 40000},
+		{"weekly", 1, 520000},
+		{"weekly", 2, 260000},
+		{"yearly", 1, 10000},
+		{"one_time", 1, 0},
+	}
+	for _, c := range cases {
+		inc := models.IncomeSource{AmountCents: 120000, Frequency: c.frequency}
+		inc.Interval = c.interval
+		if got := monthlyRecurringCents(inc); got != c.want {
+			t.Errorf("%s every %d: got %d want %d", c.frequency, c.interval, got, c.want)
+		}
+	}
+}
+
+func TestProjectPLRangeEndsWithRequestedMonth(t *testing.T) {
+	from, to := projectPLRange(ProjectPLInput{Year: 2026, Month: 3, Months: 6})
+	if !from.Equal(day(2025, 10, 1)) || !to.Equal(day(2026, 4, 1)) {
+		t.Fatalf("got %s to %s", from, to)
+	}
+	if m := marginPercent(30000, 10000); m == nil || *m != 33.33 {
+		t.Fatalf("unexpected margin %v", m)
+	}
+	if marginPercent(0, -500) != nil {
+		t.Fatal("margin without revenue should be nil")
+	}
+}
@@ -0,0 +1,390 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/repository"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+const maxInstallments = 72
+
+type CreateInstallmentPurchaseInput struct {
+	Description       string
+	TotalCents        int64
+	InstallmentCount  int
+	FirstInvoiceMonth time.Time
+	PurchaseDate      *time.Time
+	CardName          string
+	CardLastFour      string
+	DueDay            int
+	Category          string
+	Notes             string
+}
+
+type UpdateInstallmentPurchaseInput struct {
+	Description       *string
+	TotalCents        *int64
+	InstallmentCount  *int
+	FirstInvoiceMonth *time.Time
+	PurchaseDate      *time.Time
+	CardName          *string
+	CardLastFour      *string
+	DueDay            *int
+	Category          *string
+	Notes             *string
+}
+
+// InstallmentPurchaseDetail is a purchase with its generated installments and what is left to pay.
+type InstallmentPurchaseDetail struct {
+	models.InstallmentPurchase
+	Installments   []repository.PurchaseInstallment `json:"installments"`
+	PaidCents      int64                            `json:"paidCents"`
+	RemainingCents int64                            `json:"remainingCents"`
+	RemainingCount int                              `json:"remainingCount"`
+}
+
+func (s *Service) ListInstallmentPurchases(ctx context.Context, status string) ([]InstallmentPurchaseDetail, error) {
+	rows, err := s.repo.ListInstallmentPurchases(ctx, strings.TrimSpace(strings.ToLower(status)))
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load installment purchases.", err)
+	}
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	items, err := s.repo.ListPurchaseInstallments(ctx, ids)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load installments.", err)
+	}
+	byPurchase := map[uuid.UUID][]repository.PurchaseInstallment{}
+	for _, item := range items {
+		if item.PurchaseID != nil {
+			byPurchase[*item.PurchaseID] = append(byPurchase[*item.PurchaseID], item)
+		}
+	}
+	out := make([]InstallmentPurchaseDetail, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, purchaseDetail(row, byPurchase[row.ID]))
+	}
+	return out, nil
+}
+
+func (s *Service) GetInstallmentPurchase(ctx context.Context, id uuid.UUID) (*InstallmentPurchaseDetail, error) {
+	row, err := s.findInstallmentPurchase(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.ListPurchaseInstallments(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load installments.", err)
+	}
+	detail := purchaseDetail(*row, items)
+	return &detail, nil
+}
+
+// CreateInstallmentPurchase records the purchase and adds one item per installment to the card's
+// invoices, starting at FirstInvoiceMonth and creating invoices that do not exist yet.
+func (s *Service) CreateInstallmentPurchase(ctx context.Context, in CreateInstallmentPurchaseInput) (*InstallmentPurchaseDetail, error) {
+	purchaseDate := time.Now().UTC()
+	if in.PurchaseDate != nil && !in.PurchaseDate.IsZero() {
+		purchaseDate = *in.PurchaseDate
+	}
+	category, err := s.resolveCategory(ctx, "expense", in.Category)
+	if err != nil {
+		return nil, err
+	}
+	row := &models.InstallmentPurchase{
+		Description:       strings.TrimSpace(in.Description),
+		TotalCents:        in.TotalCents,
+		InstallmentCount:  in.InstallmentCount,
+		FirstInvoiceMonth: in.FirstInvoiceMonth,
+		PurchaseDate:      dateOnly(purchaseDate),
+		CardName:          strings.TrimSpace(in.CardName),
+		CardLastFour:      strings.TrimSpace(in.CardLastFour),
+		DueDay:            in.DueDay,
+		Category:          category,
+		Status:            "active",
+		Notes:             strings.TrimSpace(in.Notes),
+	}
+	if err := s.syncInstallmentPurchase(ctx, row); err != nil {
+		return nil, err
+	}
+	return s.GetInstallmentPurchase(ctx, row.ID)
+}
+
+// UpdateInstallmentPurchase applies the changes and rewrites installments on unpaid invoices in place.
+// Installments already on paid invoices are left untouched.
+func (s *Service) UpdateInstallmentPurchase(ctx context.Context, id uuid.UUID, in UpdateInstallmentPurchaseInput) (*InstallmentPurchaseDetail, error) {
+	row, err := s.findInstallmentPurchase(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status == "cancelled" {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Cancelled purchases cannot be edited.")
+	}
+	if in.Description != nil {
+		row.Description = strings.TrimSpace(*in.Description)
+	}
+	if in.TotalCents != nil {
+		row.TotalCents = *in.TotalCents
+	}
+	if in.InstallmentCount != nil {
+		row.InstallmentCount = *in.InstallmentCount
+	}
+	if in.FirstInvoiceMonth != nil {
+		row.FirstInvoiceMonth = *in.FirstInvoiceMonth
+	}
+	if in.PurchaseDate != nil {
+		row.PurchaseDate = dateOnly(*in.PurchaseDate)
+	}
+	if in.CardName != nil {
+		row.CardName = strings.TrimSpace(*in.CardName)
+	}
+	if in.CardLastFour != nil {
+		row.CardLastFour = strings.TrimSpace(*in.CardLastFour)
+	}
+	if in.DueDay != nil {
+		row.DueDay = *in.DueDay
+	}
+	if in.Category != nil {
+		if row.Category, err = s.resolveCategory(ctx, "expense", *in.Category); err != nil {
+			return nil, err
+		}
+	}
+	if in.Notes != nil {
+		row.Notes = strings.TrimSpace(*in.Notes)
+	}
+	if err := s.syncInstallmentPurchase(ctx, row); err != nil {
+		return nil, err
+	}
+	return s.GetInstallmentPurchase(ctx, id)
+}
+
+// CancelInstallmentPurchase removes the installments that are not yet on a paid invoice.
+func (s *Service) CancelInstallmentPurchase(ctx context.Context, id uuid.UUID) (*InstallmentPurchaseDetail, error) {
+	row, err := s.findInstallmentPurchase(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != "cancelled" {
+		now := time.Now().UTC()
+		row.Status = "cancelled"
+		row.CancelledAt = &now
+		if err := s.repo.SyncInstallmentPurchase(ctx, row, nil, nil); err != nil {
+			return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to cancel installment purchase.", err)
+		}
+	}
+	return s.GetInstallmentPurchase(ctx, id)
+}
+
+func (s *Service) DeleteInstallmentPurchase(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.DeleteInstallmentPurchase(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound(apperrors.CodeInternal, "Installment purchase not found.")
+		}
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to delete installment purchase.", err)
+	}
+	return nil
+}
+
+func (s *Service) findInstallmentPurchase(ctx context.Context, id uuid.UUID) (*models.InstallmentPurchase, error) {
+	row, err := s.repo.FindInstallmentPurchase(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound(apperrors.CodeInternal, "Installment purchase not found.")
+		}
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load installment purchase.", err)
+	}
+	return row, nil
+}
+
+// syncInstallmentPurchase validates the purchase and rewrites its unpaid installments, rescaling the
+// splits of those it keeps.
+func (s *Service) syncInstallmentPurchase(ctx context.Context, row *models.InstallmentPurchase) error {
+	if row.Description == "" {
+		return apperrors.Invalid(apperrors.CodeInternal, "Description is required.")
+	}
+	if row.TotalCents <= 0 {
+		return apperrors.Invalid(apperrors.CodeInternal, "Total must be positive.")
+	}
+	if row.InstallmentCount < 1 || row.InstallmentCount > maxInstallments {
+		return apperrors.Invalid(apperrors.CodeInternal, "Installment count must be between 1 and 72.")
+	}
+	if row.FirstInvoiceMonth.IsZero() {
+		return apperrors.Invalid(apperrors.CodeInternal, "First invoice month is required.")
+	}
+	row.FirstInvoiceMonth = time.Date(row.FirstInvoiceMonth.Year(), row.FirstInvoiceMonth.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if row.DueDay == 0 && row.CardLastFour != "" {
+		last, err := s.repo.LatestCardInvoice(ctx, row.CardLastFour)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.InternalCause(apperrors.CodeInternal, "Failed to load invoices.", err)
+		}
+		if last != nil {
+			row.DueDay = last.DueDate.Day()
+		}
+	}
+	if row.DueDay < 1 || row.DueDay > 31 {
+		return apperrors.Invalid(apperrors.CodeInternal, "Due day must be between 1 and 31.")
+	}
+
+	var existing []repository.PurchaseInstallment
+	if row.ID != uuid.Nil {
+		var err error
+		existing, err = s.repo.ListPurchaseInstallments(ctx, []uuid.UUID{row.ID})
+		if err != nil {
+			return apperrors.InternalCause(apperrors.CodeInternal, "Failed to load installments.", err)
+		}
+	}
+	paid, own := installmentMonths(existing)
+	reuse := unpaidInstallments(existing)
+
+	var invoices []models.Invoice
+	var items []models.InvoiceItem
+	prevAmounts := map[uuid.UUID]int64{}
+	for k, amount := range splitInstallments(row.TotalCents, row.InstallmentCount) {
+		number := k + 1
+		month := row.FirstInvoiceMonth.AddDate(0, k, 0)
+		if paid[month] {
+			continue
+		}
+		invoiceID, created, err := s.cardInvoiceFor(ctx, row, month, own[month])
+		if err != nil {
+			return err
+		}
+		if created != nil {
+			invoices = append(invoices, *created)
+		}
+		installment := ""
+		if row.InstallmentCount > 1 {
+			installment = fmt.Sprintf("%d/%d", number, row.InstallmentCount)
+		}
+		item := models.InvoiceItem{}
+		if prev, ok := reuse[number]; ok {
+			item = prev
+			prevAmounts[prev.ID] = prev.AmountCents
+		}
+		item.InvoiceID, item.Description, item.AmountCents = invoiceID, row.Description, amount
+		item.Date, item.Category, item.Installment = row.PurchaseDate, row.Category, installment
+		items = append(items, item)
+	}
+	return s.inTx(ctx, func(tx *Service) error {
+		if err := tx.repo.SyncInstallmentPurchase(ctx, row, invoices, items); err != nil {
+			return apperrors.InternalCause(apperrors.CodeInternal, "Failed to save installment purchase.", err)
+		}
+		for i := range items {
+			if prev, ok := prevAmounts[items[i].ID]; ok {
+				if err := tx.refreshInvoiceItemSplits(ctx, &items[i], prev); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// unpaidInstallments indexes the installments on unpaid invoices by their number, so a resync updates
+// them in place and their splits, attachments and notes survive the edit.
+func unpaidInstallments(existing []repository.PurchaseInstallment) map[int]models.InvoiceItem {
+	out := map[int]models.InvoiceItem{}
+	for _, item := range existing {
+		if item.InvoiceStatus == "paid" {
+			continue
+		}
+		number := 1
+		if item.Installment != "" {
+			var count int
+			if _, err := fmt.Sscanf(item.Installment, "%d/%d", &number, &count); err != nil {
+				continue
+			}
+		}
+		out[number] = item.InvoiceItem
+	}
+	return out
+}
+
+// installmentMonths indexes a purchase's installments by the month their invoice is due: paid holds
+// the months already settled, which a resync leaves alone, and own the invoice each month used.
+func installmentMonths(existing []repository.PurchaseInstallment) (paid map[time.Time]bool, own map[time.Time]uuid.UUID) {
+	paid, own = map[time.Time]bool{}, map[time.Time]uuid.UUID{}
+	for _, item := range existing {
+		month := time.Date(item.InvoiceDueDate.Year(), item.InvoiceDueDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+		own[month] = item.InvoiceID
+		if item.InvoiceStatus == "paid" {
+			paid[month] = true
+		}
+	}
+	return paid, own
+}
+
+// cardInvoiceFor returns the card's invoice due in month, or a new unsaved one when there is none.
+// Purchases without a card only reuse the invoice their own installment was on (current), since any
+// other card-less invoice may belong to a different card.
+func (s *Service) cardInvoiceFor(ctx context.Context, p *models.InstallmentPurchase, month time.Time, current uuid.UUID) (uuid.UUID, *models.Invoice, error) {
+	if p.CardLastFour == "" && current != uuid.Nil {
+		return current, nil, nil
+	}
+	if p.CardLastFour != "" {
+		inv, err := s.repo.FindCardInvoiceInMonth(ctx, p.CardLastFour, month, month.AddDate(0, 1, -1))
+		if err == nil {
+			return inv.ID, nil, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load invoices.", err)
+		}
+	}
+	name := p.CardName
+	if name == "" {
+		name = "Card"
+		if p.CardLastFour != "" {
+			name += " " + p.CardLastFour
+		}
+	}
+	created := &models.Invoice{
+		ID:           uuid.New(),
+		Name:         name + " " + month.Format("01/2006"),
+		CardLastFour: p.CardLastFour,
+		DueDate:      dayInMonth(month.Year(), month.Month(), p.DueDay),
+		Status:       "open",
+	}
+	return created.ID, created, nil
+}
+
+// splitInstallments divides total into n parts, spreading leftover cents over the first installments.
+func splitInstallments(total int64, n int) []int64 {
+	if n < 1 {
+		return nil
+	}
+	base, rem := total/int64(n), total%int64(n)
+	out := make([]int64, n)
+	for i := range out {
+		out[i] = base
+		if int64(i) < rem {
+			out[i]++
+		}
+	}
+	return out
+}
+
+func purchaseDetail(row models.InstallmentPurchase, items []repository.PurchaseInstallment) InstallmentPurchaseDetail {
+	d := InstallmentPurchaseDetail{InstallmentPurchase: row, Installments: items}
+	if d.Installments == nil {
+		d.Installments = []repository.PurchaseInstallment{}
+	}
+	for _, item := range items {
+		if item.InvoiceStatus == "paid" {
+			d.PaidCents += item.AmountCents
+			continue
+		}
+		d.RemainingCents += item.AmountCents
+		d.RemainingCount++
+	}
+	return d
+}
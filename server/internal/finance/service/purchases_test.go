@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/finance/repository"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func TestSplitInstallmentsSpreadsRemainder(t *testing.T) {
+	got := splitInstallments(1000, 3)
+	want := []int64{334, 333, 333}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v want %v", got, want)
+		}
+	}
+	var sum int64
+	for _, v := range splitInstallments(99999, 7) {
+		sum += v
+	}
+	if sum != 99999 {
+		t.Fatalf("parts sum to %d", sum)
+	}
+}
+
+func TestInstallmentMonthsLocksPaidMonths(t *testing.T) {
+	jan, feb := uuid.New(), uuid.New()
+	// Labels still say "n/10" after the count was edited down; only the invoice month matters.
+	existing := []repository.PurchaseInstallment{
+		{InvoiceItem: models.InvoiceItem{InvoiceID: jan, Installment: "1/10"}, InvoiceStatus: "paid", InvoiceDueDate: day(2026, 1, 10)},
+		{InvoiceItem: models.InvoiceItem{InvoiceID: feb, Installment: "2/10"}, InvoiceStatus: "open", InvoiceDueDate: day(2026, 2, 10)},
+	}
+	paid, own := installmentMonths(existing)
+	if !paid[day(2026, 1, 1)] || paid[day(2026, 2, 1)] || len(paid) != 1 {
+		t.Fatalf("paid months: %v", paid)
+	}
+	if own[day(2026, 2, 1)] != feb || own[day(2026, 1, 1)] != jan {
+		t.Fatalf("own invoices: %v", own)
+	}
+}
+
+func TestUnpaidInstallmentsByNumber(t *testing.T) {
+	open, single := uuid.New(), uuid.New()
+	existing := []repository.PurchaseInstallment{
+		{InvoiceItem: models.InvoiceItem{ID: uuid.New(), Installment: "1/10"}, InvoiceStatus: "paid"},
+		{InvoiceItem: models.InvoiceItem{ID: open, Installment: "2/10"}, InvoiceStatus: "overdue"},
+	}
+	got := unpaidInstallments(existing)
+	if len(got) != 1 || got[2].ID != open {
+		t.Fatalf("unpaid installments: %v", got)
+	}
+	got = unpaidInstallments([]repository.PurchaseInstallment{{InvoiceItem: models.InvoiceItem{ID: single}, InvoiceStatus: "open"}})
+	if got[1].ID != single {
+		t.Fatalf("single installment: %v", got)
+	}
+}
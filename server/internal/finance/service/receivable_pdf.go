@@ -0,0 +1,167 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/woragis/management/backend/server/internal/finance/pdf"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+const (
+	pdfMargin   = 50.0
+	pdfRight    = pdf.PageWidth - pdfMargin
+	pdfBottom   = pdf.PageHeight - 70
+	pdfBodySize = 10.0
+	pdfLine     = 14.0
+)
+
+// renderReceivable lays out a receivable as a one-column billing document: issuer, number and dates,
+// the billed contact, the item table, the total, the Pix code and the notes. Labels are Portuguese
+// and amounts use the Brazilian format since documents are issued from Brazil.
+func renderReceivable(row *models.Receivable, contact *models.Contact, settings *models.FinanceSettings) []byte {
+	number := ""
+	if row.Number != nil {
+		number = *row.Number
+	}
+	doc := pdf.New("Fatura " + number)
+	y := pdfMargin + 10
+
+	issuer := firstNonEmpty(settings.BillingName, "Fatura")
+	doc.Text(pdfMargin, y, 16, true, issuer)
+	doc.TextRight(pdfRight, y, 16, true, "FATURA")
+	y += 18
+	var issuerLines []string
+	if settings.BillingTaxID != "" {
+		issuerLines = append(issuerLines, "CPF/CNPJ: "+settings.BillingTaxID)
+	}
+	if settings.BillingEmail != "" {
+		issuerLines = append(issuerLines, settings.BillingEmail)
+	}
+	issuerLines = append(issuerLines, pdf.Wrap(settings.BillingAddress, pdfBodySize, 280, false)...)
+	meta := [][2]string{{"Número", number}}
+	if row.IssueDate != nil {
+		meta = append(meta, [2]string{"Emissão", row.IssueDate.Format("02/01/2006")})
+	}
+	meta = append(meta, [2]string{"Vencimento", row.DueDate.Format("02/01/2006")})
+	for i := 0; i < len(issuerLines) || i < len(meta); i++ {
+		if i < len(issuerLines) {
+			doc.Text(pdfMargin, y, pdfBodySize, false, issuerLines[i])
+		}
+		if i < len(meta) {
+			doc.TextRight(pdfRight-90, y, pdfBodySize, true, meta[i][0])
+			doc.TextRight(pdfRight, y, pdfBodySize, false, meta[i][1])
+		}
+		y += pdfLine
+	}
+
+	y += 16
+	doc.Text(pdfMargin, y, 8, true, "COBRAR DE")
+	y += pdfLine
+	if contact != nil {
+		doc.Text(pdfMargin, y, 12, true, firstNonEmpty(contact.DisplayName, contact.Name))
+		y += pdfLine + 2
+		for _, line := range []string{contact.Organization, contact.Email, contact.Phone} {
+			if strings.TrimSpace(line) != "" {
+				doc.Text(pdfMargin, y, pdfBodySize, false, line)
+				y += pdfLine
+			}
+		}
+	}
+
+	y += 16
+	tableHeader := func() {
+		doc.Rect(pdfMargin, y-11, pdfRight-pdfMargin, 16, 0.92)
+		doc.Text(pdfMargin+4, y, 9, true, "Descrição")
+		doc.TextRight(pdfRight-190, y, 9, true, "Qtd.")
+		doc.TextRight(pdfRight-90, y, 9, true, "Valor unit.")
+		doc.TextRight(pdfRight-4, y, 9, true, "Valor")
+		y += pdfLine + 6
+	}
+	tableHeader()
+	for _, item := range row.Items {
+		lines := pdf.Wrap(item.Description, pdfBodySize, pdfRight-pdfMargin-250, false)
+		if y+float64(len(lines))*pdfLine > pdfBottom {
+			doc.AddPage()
+			y = pdfMargin + 10
+			tableHeader()
+		}
+		doc.TextRight(pdfRight-190, y, pdfBodySize, false, formatQuantity(item.Quantity))
+		doc.TextRight(pdfRight-90, y, pdfBodySize, false, formatAmount(item.UnitCents, row.Currency))
+		doc.TextRight(pdfRight-4, y, pdfBodySize, false, formatAmount(item.AmountCents, row.Currency))
+		for _, line := range lines {
+			doc.Text(pdfMargin+4, y, pdfBodySize, false, line)
+			y += pdfLine
+		}
+		doc.Line(pdfMargin, y-9, pdfRight, y-9, 0.3)
+		y += 4
+	}
+
+	if y+40 > pdfBottom {
+		doc.AddPage()
+		y = pdfMargin + 10
+	}
+	y += 10
+	doc.TextRight(pdfRight-120, y, 12, true, "Total")
+	doc.TextRight(pdfRight-4, y, 12, true, formatAmount(row.TotalCents, row.Currency))
+	y += 30
+
+	if row.PixPayload != "" {
+		if y+60 > pdfBottom {
+			doc.AddPage()
+			y = pdfMargin + 10
+		}
+		doc.Text(pdfMargin, y, 8, true, "PAGAMENTO VIA PIX (COPIA E COLA)")
+		y += pdfLine
+		for rest := row.PixPayload; rest != ""; y += 11 {
+			n := min(len(rest), 90)
+			doc.Text(pdfMargin, y, 8, false, rest[:n])
+			rest = rest[n:]
+		}
+		y += 16
+	}
+
+	if row.Notes != "" {
+		doc.Text(pdfMargin, y, 8, true, "OBSERVAÇÕES")
+		y += pdfLine
+		for _, line := range pdf.Wrap(row.Notes, pdfBodySize, pdfRight-pdfMargin, false) {
+			if y > pdfBottom {
+				doc.AddPage()
+				y = pdfMargin + 10
+			}
+			doc.Text(pdfMargin, y, pdfBodySize, false, line)
+			y += pdfLine
+		}
+	}
+	return doc.Bytes()
+}
+
+// formatAmount renders cents as "R$ 1.234,56" for BRL and "USD 1.234,56" for other currencies.
+func formatAmount(cents int64, currency string) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	whole := strconv.FormatInt(cents/100, 10)
+	var grouped strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(r)
+	}
+	symbol := normalizeCurrency(currency)
+	if symbol == "BRL" {
+		symbol = "R$"
+	}
+	frac := strconv.FormatInt(cents%100, 10)
+	if len(frac) == 1 {
+		frac = "0" + frac
+	}
+	return sign + symbol + " " + grouped.String() + "," + frac
+}
+
+// formatQuantity prints up to three decimals with a decimal comma and no trailing zeros.
+func formatQuantity(q float64) string {
+	return strings.Replace(strconv.FormatFloat(q, 'f', -1, 64), ".", ",", 1)
+}
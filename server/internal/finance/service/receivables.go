@@ -0,0 +1,360 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/repository"
+	mediasvc "github.com/woragis/management/backend/server/internal/media/service"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+// MediaUploader stores rendered receivable PDFs and Pix QR codes.
+type MediaUploader interface {
+	Upload(ctx context.Context, in mediasvc.UploadInput) (*models.MediaAsset, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.MediaAsset, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+func (s *Service) SetMediaUploader(m MediaUploader) {
+	s.media = m
+}
+
+type ReceivableItemInput struct {
+	Description string
+	// Quantity defaults to 1 when zero.
+	Quantity  float64
+	UnitCents int64
+}
+
+type CreateReceivableInput struct {
+	ContactID uuid.UUID
+	ProjectID *uuid.UUID
+	Currency  string
+	DueDate   time.Time
+	Items     []ReceivableItemInput
+	Notes     string
+}
+
+type UpdateReceivableInput struct {
+	ContactID  *uuid.UUID
+	ProjectID  *uuid.UUID
+	ProjectSet bool
+	Currency   *string
+	DueDate    *time.Time
+	Items      []ReceivableItemInput
+	ItemsSet   bool
+	Notes      *string
+}
+
+type RecordReceivablePaymentInput struct {
+	// Date defaults to today.
+	Date      *time.Time
+	AccountID *uuid.UUID
+	// Category defaults to freelance.
+	Category string
+	Notes    string
+}
+
+type ReceivableFilter struct {
+	Status    string
+	ContactID *uuid.UUID
+	ProjectID *uuid.UUID
+}
+
+func (s *Service) ListReceivables(ctx context.Context, f ReceivableFilter) ([]models.Receivable, error) {
+	rows, err := s.repo.ListReceivables(ctx, repository.ReceivableFilter{
+		Status:    strings.ToLower(strings.TrimSpace(f.Status)),
+		ContactID: f.ContactID,
+		ProjectID: f.ProjectID,
+	})
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load receivables.", err)
+	}
+	return rows, nil
+}
+
+func (s *Service) GetReceivable(ctx context.Context, id uuid.UUID) (*models.Receivable, error) {
+	row, err := s.repo.FindReceivable(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound(apperrors.CodeInternal, "Receivable not found.")
+		}
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load receivable.", err)
+	}
+	return row, nil
+}
+
+func (s *Service) CreateReceivable(ctx context.Context, in CreateReceivableInput) (*models.Receivable, error) {
+	if in.ContactID == uuid.Nil {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Contact is required.")
+	}
+	if err := s.validateContactID(ctx, &in.ContactID); err != nil {
+		return nil, err
+	}
+	if in.DueDate.IsZero() {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Due date is required.")
+	}
+	items, total, err := receivableItems(in.Items)
+	if err != nil {
+		return nil, err
+	}
+	row := &models.Receivable{
+		ContactID:  in.ContactID,
+		ProjectID:  in.ProjectID,
+		Currency:   normalizeCurrency(in.Currency),
+		DueDate:    dateOnly(in.DueDate),
+		Status:     "draft",
+		TotalCents: total,
+		Notes:      strings.TrimSpace(in.Notes),
+		Items:      items,
+	}
+	if err := s.repo.CreateReceivable(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to create receivable.", err)
+	}
+	return row, nil
+}
+
+// UpdateReceivable edits a draft; issued documents are immutable and must be voided instead.
+func (s *Service) UpdateReceivable(ctx context.Context, id uuid.UUID, in UpdateReceivableInput) (*models.Receivable, error) {
+	row, err := s.GetReceivable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != "draft" {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Only draft receivables can be edited.")
+	}
+	if in.ContactID != nil {
+		if *in.ContactID == uuid.Nil {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Contact is required.")
+		}
+		if err := s.validateContactID(ctx, in.ContactID); err != nil {
+			return nil, err
+		}
+		row.ContactID = *in.ContactID
+	}
+	if in.ProjectSet {
+		row.ProjectID = in.ProjectID
+		if in.ProjectID != nil && *in.ProjectID == uuid.Nil {
+			row.ProjectID = nil
+		}
+	}
+	if in.Currency != nil {
+		row.Currency = normalizeCurrency(*in.Currency)
+	}
+	if in.DueDate != nil {
+		row.DueDate = dateOnly(*in.DueDate)
+	}
+	if in.ItemsSet {
+		items, total, err := receivableItems(in.Items)
+		if err != nil {
+			return nil, err
+		}
+		row.Items, row.TotalCents = items, total
+	}
+	if in.Notes != nil {
+		row.Notes = strings.TrimSpace(*in.Notes)
+	}
+	if err := s.repo.SaveReceivable(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update receivable.", err)
+	}
+	return row, nil
+}
+
+// DeleteReceivable removes a draft; issued receivables keep their number and can only be voided.
+func (s *Service) DeleteReceivable(ctx context.Context, id uuid.UUID) error {
+	row, err := s.GetReceivable(ctx, id)
+	if err != nil {
+		return err
+	}
+	if row.Status != "draft" {
+		return apperrors.Invalid(apperrors.CodeInternal, "Only draft receivables can be deleted.")
+	}
+	if err := s.repo.DeleteReceivable(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound(apperrors.CodeInternal, "Receivable not found.")
+		}
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to delete receivable.", err)
+	}
+	s.deleteMedia(ctx, row.PDFMediaID)
+	return nil
+}
+
+// IssueReceivable numbers a draft, marks it sent and renders its PDF. issueDate defaults to today. The
+// issue stands even when rendering fails; the PDF can be rendered again later.
+func (s *Service) IssueReceivable(ctx context.Context, id uuid.UUID, issueDate *time.Time) (*models.Receivable, error) {
+	row, err := s.GetReceivable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != "draft" {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Receivable was already issued.")
+	}
+	if row.TotalCents <= 0 {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Receivable total must be positive.")
+	}
+	date := dateOnly(time.Now().UTC())
+	if issueDate != nil {
+		date = dateOnly(*issueDate)
+	}
+	if _, err := s.repo.IssueReceivable(ctx, id, date); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Receivable was already issued.")
+		}
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to issue receivable.", err)
+	}
+	rendered, err := s.RenderReceivablePDF(ctx, id)
+	if err != nil {
+		log.Printf("receivable %s: render pdf: %v", id, err)
+		return s.GetReceivable(ctx, id)
+	}
+	return rendered, nil
+}
+
+// RenderReceivablePDF (re)renders the document of an issued receivable, stores it as a private media
+// asset and replaces the previous one.
+func (s *Service) RenderReceivablePDF(ctx context.Context, id uuid.UUID) (*models.Receivable, error) {
+	row, err := s.GetReceivable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Number == nil {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Issue the receivable before rendering it.")
+	}
+	if s.media == nil {
+		return nil, apperrors.Unavailable(apperrors.CodeInternal, "Media storage is not configured.")
+	}
+	settings, err := s.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshReceivablePix(ctx, row, settings); err != nil {
+		return nil, err
+	}
+	var contact *models.Contact
+	if s.contacts != nil {
+		if contact, err = s.contacts.GetByID(ctx, row.ContactID); err != nil {
+			return nil, err
+		}
+	}
+	doc := renderReceivable(row, contact, settings)
+	asset, err := s.media.Upload(ctx, mediasvc.UploadInput{
+		Filename: "receivable-" + *row.Number + ".pdf",
+		MimeType: "application/pdf",
+		AltText:  "Receivable " + *row.Number,
+		Private:  true,
+		Reader:   bytes.NewReader(doc),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetReceivablePDF(ctx, row.ID, &asset.ID); err != nil {
+		_ = s.media.Delete(ctx, asset.ID)
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update receivable.", err)
+	}
+	s.deleteMedia(ctx, row.PDFMediaID)
+	row.PDFMediaID = &asset.ID
+	return row, nil
+}
+
+// RecordReceivablePayment books the income transaction for a sent receivable and marks it paid.
+// Deleting that transaction later puts the receivable back to sent.
+func (s *Service) RecordReceivablePayment(ctx context.Context, id uuid.UUID, in RecordReceivablePaymentInput) (*models.Receivable, error) {
+	row, err := s.GetReceivable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != "sent" {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Only sent receivables can be paid.")
+	}
+	date := dateOnly(time.Now().UTC())
+	if in.Date != nil {
+		date = dateOnly(*in.Date)
+	}
+	tx, err := s.CreateTransaction(ctx, CreateTransactionInput{
+		Type:        "income",
+		AmountCents: row.TotalCents,
+		Currency:    row.Currency,
+		Description: "Receivable " + *row.Number,
+		Date:        date,
+		Category:    firstNonEmpty(strings.TrimSpace(in.Category), "freelance"),
+		ProjectID:   row.ProjectID,
+		ContactID:   &row.ContactID,
+		AccountID:   in.AccountID,
+		Notes:       in.Notes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if err := s.repo.MarkReceivablePaid(ctx, row.ID, tx.ID, now); err != nil {
+		_ = s.repo.DeleteTransaction(ctx, tx.ID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Only sent receivables can be paid.")
+		}
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update receivable.", err)
+	}
+	row.Status, row.TransactionID, row.PaidAt = "paid", &tx.ID, &now
+	return row, nil
+}
+
+// VoidReceivable cancels a draft or sent receivable. Its number stays taken.
+func (s *Service) VoidReceivable(ctx context.Context, id uuid.UUID) (*models.Receivable, error) {
+	if _, err := s.GetReceivable(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.VoidReceivable(ctx, id, time.Now().UTC()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Only draft or sent receivables can be voided.")
+		}
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to void receivable.", err)
+	}
+	return s.GetReceivable(ctx, id)
+}
+
+func (s *Service) deleteMedia(ctx context.Context, mediaID *uuid.UUID) {
+	if mediaID == nil || s.media == nil {
+		return
+	}
+	_ = s.media.Delete(ctx, *mediaID)
+}
+
+// receivableItems validates line items and returns them with their amounts and the document total.
+func receivableItems(in []ReceivableItemInput) ([]models.ReceivableItem, int64, error) {
+	if len(in) == 0 {
+		return nil, 0, apperrors.Invalid(apperrors.CodeInternal, "At least one item is required.")
+	}
+	items := make([]models.ReceivableItem, 0, len(in))
+	var total int64
+	for _, it := range in {
+		desc := strings.TrimSpace(it.Description)
+		if desc == "" {
+			return nil, 0, apperrors.Invalid(apperrors.CodeInternal, "Item description is required.")
+		}
+		qty := it.Quantity
+		if qty == 0 {
+			qty = 1
+		}
+		if qty < 0 || it.UnitCents < 0 {
+			return nil, 0, apperrors.Invalid(apperrors.CodeInternal, "Item quantity and unit price cannot be negative.")
+		}
+		qty = math.Round(qty*1000) / 1000
+		amount := int64(math.Round(qty * float64(it.UnitCents)))
+		items = append(items, models.ReceivableItem{
+			Description: truncateRunes(desc, 500),
+			Quantity:    qty,
+			UnitCents:   it.UnitCents,
+			AmountCents: amount,
+		})
+		total += amount
+	}
+	return items, total, nil
+}
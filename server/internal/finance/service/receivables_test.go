@@ -0,0 +1,65 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func TestReceivableItemsTotals(t *testing.T) {
+	items, total, err := receivableItems([]ReceivableItemInput{
+		{Description: "Consultoria", Quantity: 2.5, UnitCents: 15000},
+		{Description: "Hospedagem", UnitCents: 4990},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items[0].AmountCents != 37500 || items[1].Quantity != 1 || items[1].AmountCents != 4990 || total != 42490 {
+		t.Errorf("unexpected items %+v total %d", items, total)
+	}
+	if _, _, err := receivableItems(nil); err == nil {
+		t.Error("expected an error without items")
+	}
+	if _, _, err := receivableItems([]ReceivableItemInput{{Description: "x", Quantity: -1, UnitCents: 100}}); err == nil {
+		t.Error("expected an error for a negative quantity")
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	cases := map[string]string{
+		formatAmount(123456, "BRL"):    "R$ 1.234,56",
+		formatAmount(5, "brl"):         "R$ 0,05",
+		formatAmount(100000000, "USD"): "USD 1.000.000,00",
+		formatAmount(-250, "BRL"):      "-R$ 2,50",
+	}
+	for got, want := range cases {
+		if got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+	if got := formatQuantity(1.5); got != "1,5" {
+		t.Errorf("formatQuantity = %q", got)
+	}
+}
+
+func TestRenderReceivable(t *testing.T) {
+	number, issued := "2026-0007", day(2026, 10, 1)
+	row := &models.Receivable{
+		ID:         uuid.New(),
+		Number:     &number,
+		Currency:   "BRL",
+		IssueDate:  &issued,
+		DueDate:    day(2026, 10, 15),
+		TotalCents: 42490,
+		Items:      []models.ReceivableItem{{Description: "Consultoria", Quantity: 1, UnitCents: 42490, AmountCents: 42490}},
+	}
+	contact := &models.Contact{Name: "Acme Ltda"}
+	out := renderReceivable(row, contact, &models.FinanceSettings{BillingName: "Estúdio Exemplo"})
+	for _, want := range []string{"(2026-0007) Tj", "(Acme Ltda) Tj", "(R$ 424,90) Tj", "(15/10/2026) Tj"} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("document is missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+const (
+	weekendAdjustNone     = "none"
+	weekendAdjustPrevious = "previous"
+	weekendAdjustNext     = "next"
+)
+
+func normalizeWeekendAdjust(v string) string {
+	switch strings.TrimSpace(strings.ToLower(v)) {
+	case weekendAdjustPrevious, weekendAdjustNext:
+		return strings.TrimSpace(strings.ToLower(v))
+	default:
+		return weekendAdjustNone
+	}
+}
+
+// normalizeRecurrence validates ranges and fills defaults in place.
+func normalizeRecurrence(rec *models.Recurrence) error {
+	if rec.Interval < 1 {
+		rec.Interval = 1
+	}
+	if rec.Weekday != nil && (*rec.Weekday < 0 || *rec.Weekday > 6) {
+		return apperrors.Invalid(apperrors.CodeInternal, "Weekday must be between 0 (Sunday) and 6 (Saturday).")
+	}
+	if rec.MonthOfYear != nil && (*rec.MonthOfYear < 1 || *rec.MonthOfYear > 12) {
+		return apperrors.Invalid(apperrors.CodeInternal, "Month of year must be between 1 and 12.")
+	}
+	if rec.AnchorDate != nil {
+		d := dateOnly(*rec.AnchorDate)
+		rec.AnchorDate = &d
+	}
+	if rec.EndDate != nil {
+		d := dateOnly(*rec.EndDate)
+		rec.EndDate = &d
+	}
+	if rec.AnchorDate != nil && rec.EndDate != nil && rec.EndDate.Before(*rec.AnchorDate) {
+		return apperrors.Invalid(apperrors.CodeInternal, "End date must not be before the anchor date.")
+	}
+	rec.WeekendAdjust = normalizeWeekendAdjust(rec.WeekendAdjust)
+	return nil
+}
+
+// schedule is the expanded recurrence of one income source or expense.
+type schedule struct {
+	frequency  string
+	dayOfMonth int
+	rec        models.Recurrence
+	// phase is where interval counting starts: the anchor date, or the row's creation date when no
+	// anchor was set so legacy rows keep their old every-period behaviour.
+	phase time.Time
+}
+
+func incomeSchedule(inc models.IncomeSource) schedule {
+	return newSchedule(inc.Frequency, inc.DayOfMonth, inc.Recurrence, inc.CreatedAt)
+}
+
+func expenseSchedule(exp models.Expense) schedule {
+	rec := exp.Recurrence
+	if exp.Frequency == "one_time" && exp.DueDate != nil {
+		rec.AnchorDate = exp.DueDate
+	}
+	return newSchedule(exp.Frequency, exp.DayOfMonth, rec, exp.CreatedAt)
+}
+
+func newSchedule(frequency string, dayOfMonth int, rec models.Recurrence, createdAt time.Time) schedule {
+	sc := schedule{
+		frequency:  normalizeFrequency(frequency),
+		dayOfMonth: clampDay(dayOfMonth),
+		rec:        rec,
+		phase:      dateOnly(createdAt),
+	}
+	if sc.rec.Interval < 1 {
+		sc.rec.Interval = 1
+	}
+	if rec.AnchorDate != nil {
+		sc.phase = dateOnly(*rec.AnchorDate)
+	}
+	return sc
+}
+
+// occurrences returns the (weekend-adjusted) dates falling within [from, to], oldest first.
+func (sc schedule) occurrences(from, to time.Time) []time.Time {
+	from, to = dateOnly(from), dateOnly(to)
+	if to.Before(from) {
+		return nil
+	}
+	// Widen the search so a weekend shift can pull an occurrence across the window edges.
+	lo, hi := from.AddDate(0, 0, -3), to.AddDate(0, 0, 3)
+	var nominal []time.Time
+	switch sc.frequency {
+	case "one_time":
+		if sc.rec.AnchorDate != nil {
+			nominal = append(nominal, dateOnly(*sc.rec.AnchorDate))
+		}
+	case "weekly":
+		nominal = sc.weekly(lo, hi)
+	case "yearly":
+		nominal = sc.yearly(lo, hi)
+	default:
+		nominal = sc.monthly(lo, hi)
+	}
+	var out []time.Time
+	for _, d := range nominal {
+		if sc.rec.AnchorDate != nil && d.Before(dateOnly(*sc.rec.AnchorDate)) {
+			continue
+		}
+		if sc.rec.EndDate != nil && d.After(dateOnly(*sc.rec.EndDate)) {
+			continue
+		}
+		adj := adjustForWeekend(d, sc.rec.WeekendAdjust)
+		if adj.Before(from) || adj.After(to) {
+			continue
+		}
+		out = append(out, adj)
+	}
+	return out
+}
+
+func (sc schedule) monthly(lo, hi time.Time) []time.Time {
+	var out []time.Time
+	base := sc.phase.Year()*12 + int(sc.phase.Month()) - 1
+	for m := time.Date(lo.Year(), lo.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(hi); m = m.AddDate(0, 1, 0) {
+		idx := m.Year()*12 + int(m.Month()) - 1
+		if floorMod(idx-base, sc.rec.Interval) != 0 {
+			continue
+		}
+		d := dayInMonth(m.Year(), m.Month(), sc.dayOfMonth)
+		if d.Before(lo) || d.After(hi) {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func (sc schedule) yearly(lo, hi time.Time) []time.Time {
+	month := sc.phase.Month()
+	if sc.rec.MonthOfYear != nil {
+		month = time.Month(*sc.rec.MonthOfYear)
+	}
+	var out []time.Time
+	for y := lo.Year(); y <= hi.Year(); y++ {
+		if floorMod(y-sc.phase.Year(), sc.rec.Interval) != 0 {
+			continue
+		}
+		d := dayInMonth(y, month, sc.dayOfMonth)
+		if d.Before(lo) || d.After(hi) {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func (sc schedule) weekly(lo, hi time.Time) []time.Time {
+	wd := sc.phase.Weekday()
+	if sc.rec.Weekday != nil {
+		wd = time.Weekday(*sc.rec.Weekday)
+	}
+	first := sc.phase.AddDate(0, 0, (int(wd)-int(sc.phase.Weekday())+7)%7)
+	step := 7 * sc.rec.Interval
+	k := floorDiv(daysBetween(first, lo), step)
+	d := first.AddDate(0, 0, k*step)
+	if d.Before(lo) {
+		d = d.AddDate(0, 0, step)
+	}
+	var out []time.Time
+	for ; !d.After(hi); d = d.AddDate(0, 0, step) {
+		out = append(out, d)
+	}
+	return out
+}
+
+// adjustForWeekend moves Saturday/Sunday dates to the previous Friday or the next Monday.
+func adjustForWeekend(d time.Time, rule string) time.Time {
+	switch rule {
+	case weekendAdjustPrevious:
+		switch d.Weekday() {
+		case time.Saturday:
+			return d.AddDate(0, 0, -1)
+		case time.Sunday:
+			return d.AddDate(0, 0, -2)
+		}
+	case weekendAdjustNext:
+		switch d.Weekday() {
+		case time.Saturday:
+			return d.AddDate(0, 0, 2)
+		case time.Sunday:
+			return d.AddDate(0, 0, 1)
+		}
+	}
+	return d
+}
+
+// dayInMonth clamps day to the month length, so the 31st becomes Feb 28/29.
+func dayInMonth(year int, month time.Month, day int) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day > last {
+		day = last
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// dateOnly keeps the calendar date of t as midnight UTC.
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(a, b time.Time) int {
+	return int((b.Unix() - a.Unix()) / 86400)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func floorMod(a, b int) int {
+	return ((a % b) + b) % b
+}
+
+// recurrencePatch carries the recurrence fields of an update request; the *Set flags mark nullable
+// fields that were sent.
+type recurrencePatch struct {
+	AnchorDate     *time.Time
+	AnchorDateSet  bool
+	Interval       *int
+	Weekday        *int
+	WeekdaySet     bool
+	MonthOfYear    *int
+	MonthOfYearSet bool
+	EndDate        *time.Time
+	EndDateSet     bool
+	WeekendAdjust  *string
+}
+
+func (p recurrencePatch) apply(rec *models.Recurrence) {
+	if p.AnchorDateSet {
+		rec.AnchorDate = p.AnchorDate
+	}
+	if p.Interval != nil {
+		rec.Interval = *p.Interval
+	}
+	if p.WeekdaySet {
+		rec.Weekday = p.Weekday
+	}
+	if p.MonthOfYearSet {
+		rec.MonthOfYear = p.MonthOfYear
+	}
+	if p.EndDateSet {
+		rec.EndDate = p.EndDate
+	}
+	if p.WeekendAdjust != nil {
+		rec.WeekendAdjust = *p.WeekendAdjust
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func formatDates(ds []time.Time) []string {
+	out := make([]string, 0, len(ds))
+	for _, d := range ds {
+		out = append(out, d.Format("2006-01-02"))
+	}
+	return out
+}
+
+func assertDates(t *testing.T, got []time.Time, want ...string) {
+	t.Helper()
+	g := formatDates(got)
+	if len(g) != len(want) {
+		t.Fatalf("got %v want %v", g, want)
+	}
+	for i := range want {
+		if g[i] != want[i] {
+			t.Fatalf("got %v want %v", g, want)
+		}
+	}
+}
+
+func TestMonthlyClampsToMonthEndAndHonoursInterval(t *testing.T) {
+	anchor := day(2026, 1, 31)
+	sc := newSchedule("monthly", 31, models.Recurrence{AnchorDate: &anchor, Interval: 1}, anchor)
+	assertDates(t, sc.occurrences(day(2026, 2, 1), day(2026, 2, 28)), "2026-02-28")
+
+	sc = newSchedule("monthly", 10, models.Recurrence{AnchorDate: &anchor, Interval: 3}, anchor)
+	assertDates(t, sc.occurrences(day(2026, 1, 1), day(2026, 12, 31)),
+		"2026-04-10", "2026-07-10", "2026-10-10")
+}
+
+func TestWeeklyUsesWeekdayAndInterval(t *testing.T) {
+	anchor := day(2026, 3, 2) // Monday
+	friday := int(time.Friday)
+	sc := newSchedule("weekly", 1, models.Recurrence{AnchorDate: &anchor, Interval: 2, Weekday: &friday}, anchor)
+	assertDates(t, sc.occurrences(day(2026, 3, 1), day(2026, 3, 31)), "2026-03-06", "2026-03-20")
+}
+
+func TestWeeklyWithoutAnchorExtendsBeforeCreation(t *testing.T) {
+	created := day(2026, 3, 4) // Wednesday
+	sc := newSchedule("weekly", 1, models.Recurrence{Interval: 1}, created)
+	assertDates(t, sc.occurrences(day(2026, 2, 16), day(2026, 2, 28)), "2026-02-18", "2026-02-25")
+}
+
+func TestYearlyAndEndDate(t *testing.T) {
+	anchor := day(2024, 1, 1)
+	end := day(2027, 12, 31)
+	june := 6
+	sc := newSchedule("yearly", 15, models.Recurrence{AnchorDate: &anchor, Interval: 1, MonthOfYear: &june, EndDate: &end}, anchor)
+	assertDates(t, sc.occurrences(day(2026, 1, 1), day(2028, 12, 31)), "2026-06-15", "2027-06-15")
+}
+
+func TestWeekendAdjustment(t *testing.T) {
+	// 2026-08-01 is a Saturday.
+	prev := newSchedule("monthly", 1, models.Recurrence{Interval: 1, WeekendAdjust: weekendAdjustPrevious}, day(2026, 1, 1))
+	assertDates(t, prev.occurrences(day(2026, 7, 1), day(2026, 7, 31)), "2026-07-01", "2026-07-31")
+	assertDates(t, prev.occurrences(day(2026, 8, 1), day(2026, 8, 31)))
+
+	next := newSchedule("monthly", 1, models.Recurrence{Interval: 1, WeekendAdjust: weekendAdjustNext}, day(2026, 1, 1))
+	assertDates(t, next.occurrences(day(2026, 8, 1), day(2026, 8, 31)), "2026-08-03")
+}
+
+func TestOneTimeExpenseUsesDueDate(t *testing.T) {
+	due := day(2026, 5, 20)
+	exp := models.Expense{Frequency: "one_time", DueDate: &due, CreatedAt: day(2026, 1, 1)}
+	assertDates(t, expenseSchedule(exp).occurrences(day(2026, 5, 1), day(2026, 5, 31)), "2026-05-20")
+	assertDates(t, expenseSchedule(exp).occurrences(day(2026, 6, 1), day(2026, 6, 30)))
+}
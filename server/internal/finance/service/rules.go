@@ -0,0 +1,410 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+const maxRuleRunDays = 366
+
+type CreateRuleInput struct {
+	Name               string
+	Priority           *int
+	Active             *bool
+	DescriptionPattern string
+	MinAmountCents     *int64
+	MaxAmountCents     *int64
+	Currency           string
+	Type               string
+	SetCategory        string
+	SetProjectID       *uuid.UUID
+	SetContactID       *uuid.UUID
+	SetNotes           string
+}
+
+type UpdateRuleInput struct {
+	Name               *string
+	Priority           *int
+	Active             *bool
+	DescriptionPattern *string
+	MinAmountCents     *int64
+	MaxAmountCents     *int64
+	Currency           *string
+	Type               *string
+	SetCategory        *string
+	SetProjectID       *uuid.UUID
+	SetContactID       *uuid.UUID
+	SetNotes           *string
+}
+
+type RunRulesInput struct {
+	From *time.Time
+	To   *time.Time
+	// RuleID limits the run to one rule, active or not.
+	RuleID *uuid.UUID
+	// Overwrite lets rules replace values that are already set instead of only filling empty ones.
+	Overwrite bool
+	DryRun    bool
+}
+
+// RuleFields are the transaction fields rules can set.
+type RuleFields struct {
+	Category  string     `json:"category"`
+	ProjectID *uuid.UUID `json:"projectId"`
+	ContactID *uuid.UUID `json:"contactId"`
+	Notes     string     `json:"notes"`
+}
+
+type RuleChange struct {
+	TransactionID uuid.UUID   `json:"transactionId"`
+	Date          time.Time   `json:"date"`
+	Description   string      `json:"description"`
+	RuleIDs       []uuid.UUID `json:"ruleIds"`
+	Before        RuleFields  `json:"before"`
+	After         RuleFields  `json:"after"`
+}
+
+type RuleRunResult struct {
+	DryRun  bool         `json:"dryRun"`
+	Scanned int          `json:"scanned"`
+	Changed int          `json:"changed"`
+	Changes []RuleChange `json:"changes"`
+}
+
+func (s *Service) ListRules(ctx context.Context) ([]models.CategorizationRule, error) {
+	rows, err := s.repo.ListRules(ctx, false)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load rules.", err)
+	}
+	return rows, nil
+}
+
+func (s *Service) GetRule(ctx context.Context, id uuid.UUID) (*models.CategorizationRule, error) {
+	row, err := s.repo.FindRule(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound(apperrors.CodeInternal, "Rule not found.")
+		}
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load rule.", err)
+	}
+	return row, nil
+}
+
+func (s *Service) CreateRule(ctx context.Context, in CreateRuleInput) (*models.CategorizationRule, error) {
+	row := &models.CategorizationRule{
+		Name:               strings.TrimSpace(in.Name),
+		Priority:           100,
+		Active:             true,
+		DescriptionPattern: strings.TrimSpace(in.DescriptionPattern),
+		MinAmountCents:     in.MinAmountCents,
+		MaxAmountCents:     in.MaxAmountCents,
+		Currency:           in.Currency,
+		Type:               in.Type,
+		SetCategory:        in.SetCategory,
+		SetProjectID:       in.SetProjectID,
+		SetContactID:       in.SetContactID,
+		SetNotes:           strings.TrimSpace(in.SetNotes),
+	}
+	if in.Priority != nil {
+		row.Priority = *in.Priority
+	}
+	if in.Active != nil {
+		row.Active = *in.Active
+	}
+	if err := s.validateRule(ctx, row); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateRule(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to create rule.", err)
+	}
+	return row, nil
+}
+
+func (s *Service) UpdateRule(ctx context.Context, id uuid.UUID, in UpdateRuleInput) (*models.CategorizationRule, error) {
+	row, err := s.GetRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if in.Name != nil {
+		row.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Priority != nil {
+		row.Priority = *in.Priority
+	}
+	if in.Active != nil {
+		row.Active = *in.Active
+	}
+	if in.DescriptionPattern != nil {
+		row.DescriptionPattern = strings.TrimSpace(*in.DescriptionPattern)
+	}
+	if in.MinAmountCents != nil {
+		row.MinAmountCents = in.MinAmountCents
+	}
+	if in.MaxAmountCents != nil {
+		row.MaxAmountCents = in.MaxAmountCents
+	}
+	if in.Currency != nil {
+		row.Currency = *in.Currency
+	}
+	if in.Type != nil {
+		row.Type = *in.Type
+	}
+	if in.SetCategory != nil {
+		row.SetCategory = *in.SetCategory
+	}
+	if in.SetProjectID != nil {
+		row.SetProjectID = in.SetProjectID
+	}
+	if in.SetContactID != nil {
+		row.SetContactID = in.SetContactID
+	}
+	if in.SetNotes != nil {
+		row.SetNotes = strings.TrimSpace(*in.SetNotes)
+	}
+	if err := s.validateRule(ctx, row); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveRule(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update rule.", err)
+	}
+	return row, nil
+}
+
+func (s *Service) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.DeleteRule(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound(apperrors.CodeInternal, "Rule not found.")
+		}
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to delete rule.", err)
+	}
+	return nil
+}
+
+// RunRules applies rules to the transactions dated within [From, To] and, unless DryRun is set, saves
+// the ones that changed. The result lists every change either way.
+func (s *Service) RunRules(ctx context.Context, in RunRulesInput) (*RuleRunResult, error) {
+	if in.From == nil || in.To == nil {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "From and to are required.")
+	}
+	from, to := dateOnly(*in.From), dateOnly(*in.To)
+	if to.Before(from) {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "To must not be before from.")
+	}
+	if to.Sub(from) > maxRuleRunDays*24*time.Hour {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Rules can be re-run on at most one year at a time.")
+	}
+	var rules ruleSet
+	if in.RuleID != nil {
+		row, err := s.GetRule(ctx, *in.RuleID)
+		if err != nil {
+			return nil, err
+		}
+		rules = compileRules([]models.CategorizationRule{*row})
+	} else {
+		var err error
+		if rules, err = s.activeRules(ctx); err != nil {
+			return nil, err
+		}
+	}
+	txs, err := s.repo.ListTransactionsBetween(ctx, from, to)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load transactions.", err)
+	}
+	res := &RuleRunResult{DryRun: in.DryRun, Scanned: len(txs), Changes: []RuleChange{}}
+	var changed []models.Transaction
+	for _, tx := range txs {
+		before := transactionRuleFields(tx)
+		ids := rules.applyToTransaction(&tx, in.Overwrite)
+		after := transactionRuleFields(tx)
+		if len(ids) == 0 || before.equal(after) {
+			continue
+		}
+		changed = append(changed, tx)
+		res.Changes = append(res.Changes, RuleChange{
+			TransactionID: tx.ID,
+			Date:          tx.Date,
+			Description:   tx.Description,
+			RuleIDs:       ids,
+			Before:        before,
+			After:         after,
+		})
+	}
+	res.Changed = len(changed)
+	if !in.DryRun && len(changed) > 0 {
+		if err := s.repo.SaveTransactions(ctx, changed); err != nil {
+			return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to save transactions.", err)
+		}
+	}
+	return res, nil
+}
+
+func (s *Service) activeRules(ctx context.Context) (ruleSet, error) {
+	rows, err := s.repo.ListRules(ctx, true)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load rules.", err)
+	}
+	return compileRules(rows), nil
+}
+
+func (s *Service) validateRule(ctx context.Context, row *models.CategorizationRule) error {
+	if row.Name == "" {
+		return apperrors.Invalid(apperrors.CodeInternal, "Name is required.")
+	}
+	row.Type = strings.TrimSpace(strings.ToLower(row.Type))
+	if row.Type != "" && row.Type != "income" && row.Type != "expense" {
+		return apperrors.Invalid(apperrors.CodeInternal, "Type must be income or expense.")
+	}
+	row.Currency = strings.TrimSpace(row.Currency)
+	if row.Currency != "" {
+		row.Currency = normalizeCurrency(row.Currency)
+	}
+	if row.DescriptionPattern != "" {
+		if _, err := regexp.Compile("(?i)" + row.DescriptionPattern); err != nil {
+			return apperrors.Invalid(apperrors.CodeInternal, "Description pattern is not a valid regular expression.")
+		}
+	}
+	if row.MinAmountCents != nil && row.MaxAmountCents != nil && *row.MinAmountCents > *row.MaxAmountCents {
+		return apperrors.Invalid(apperrors.CodeInternal, "Minimum amount must not exceed the maximum.")
+	}
+	if row.DescriptionPattern == "" && row.MinAmountCents == nil && row.MaxAmountCents == nil && row.Currency == "" && row.Type == "" {
+		return apperrors.Invalid(apperrors.CodeInternal, "A rule needs at least one condition.")
+	}
+	row.SetCategory = strings.TrimSpace(row.SetCategory)
+	if row.SetCategory != "" {
+		if row.Type == "" {
+			return apperrors.Invalid(apperrors.CodeInternal, "Rules that set a category need a type.")
+		}
+		slug, err := s.resolveCategory(ctx, row.Type, row.SetCategory)
+		if err != nil {
+			return err
+		}
+		row.SetCategory = slug
+	}
+	if row.SetCategory == "" && row.SetProjectID == nil && row.SetContactID == nil && row.SetNotes == "" {
+		return apperrors.Invalid(apperrors.CodeInternal, "A rule needs at least one field to set.")
+	}
+	return s.validateContactID(ctx, row.SetContactID)
+}
+
+// compiledRule is a rule with its description pattern compiled case-insensitively.
+type compiledRule struct {
+	models.CategorizationRule
+	re *regexp.Regexp
+}
+
+// ruleSet is an ordered list of rules; earlier rules win.
+type ruleSet []compiledRule
+
+// compileRules drops rules whose pattern no longer compiles rather than failing the caller.
+func compileRules(rows []models.CategorizationRule) ruleSet {
+	out := make(ruleSet, 0, len(rows))
+	for _, row := range rows {
+		cr := compiledRule{CategorizationRule: row}
+		if row.DescriptionPattern != "" {
+			re, err := regexp.Compile("(?i)" + row.DescriptionPattern)
+			if err != nil {
+				continue
+			}
+			cr.re = re
+		}
+		out = append(out, cr)
+	}
+	return out
+}
+
+// matches reports whether the rule's conditions hold. An empty currency (invoice items) only matches
+// rules without a currency condition.
+func (r compiledRule) matches(txType, currency, description string, amountCents int64) bool {
+	if r.Type != "" && r.Type != txType {
+		return false
+	}
+	if r.Currency != "" && r.Currency != currency {
+		return false
+	}
+	if r.MinAmountCents != nil && amountCents < *r.MinAmountCents {
+		return false
+	}
+	if r.MaxAmountCents != nil && amountCents > *r.MaxAmountCents {
+		return false
+	}
+	return r.re == nil || r.re.MatchString(description)
+}
+
+// apply fills f from the matching rules and returns the ids of the rules that set something. Each
+// field is set by the first matching rule that provides it; without overwrite only empty fields are
+// filled.
+func (rs ruleSet) apply(f *RuleFields, txType, currency, description string, amountCents int64, overwrite bool) []uuid.UUID {
+	var ids []uuid.UUID
+	var setCategory, setProject, setContact, setNotes bool
+	for _, r := range rs {
+		if !r.matches(txType, currency, description, amountCents) {
+			continue
+		}
+		used := false
+		if r.SetCategory != "" && !setCategory && (overwrite || f.Category == "") {
+			f.Category, setCategory, used = r.SetCategory, true, true
+		}
+		if r.SetProjectID != nil && !setProject && (overwrite || f.ProjectID == nil) {
+			id := *r.SetProjectID
+			f.ProjectID, setProject, used = &id, true, true
+		}
+		if r.SetContactID != nil && !setContact && (overwrite || f.ContactID == nil) {
+			id := *r.SetContactID
+			f.ContactID, setContact, used = &id, true, true
+		}
+		if r.SetNotes != "" && !setNotes && (overwrite || f.Notes == "") {
+			f.Notes, setNotes, used = r.SetNotes, true, true
+		}
+		if used {
+			ids = append(ids, r.ID)
+		}
+	}
+	return ids
+}
+
+// applyToTransaction runs the rules on tx in place. Transfers are never categorized.
+func (rs ruleSet) applyToTransaction(tx *models.Transaction, overwrite bool) []uuid.UUID {
+	if tx.Type == "transfer" || len(rs) == 0 {
+		return nil
+	}
+	f := transactionRuleFields(*tx)
+	ids := rs.apply(&f, tx.Type, tx.Currency, tx.Description, tx.AmountCents, overwrite)
+	tx.Category, tx.ProjectID, tx.ContactID, tx.Notes = f.Category, f.ProjectID, f.ContactID, f.Notes
+	return ids
+}
+
+// applyToInvoiceItem sets category and notes on a card purchase. The "other" fallback counts as empty.
+func (rs ruleSet) applyToInvoiceItem(item *models.InvoiceItem) {
+	if len(rs) == 0 {
+		return
+	}
+	f := RuleFields{Category: item.Category, Notes: item.Notes}
+	if f.Category == "other" {
+		f.Category = ""
+	}
+	rs.apply(&f, "expense", "", item.Description, item.AmountCents, false)
+	item.Category, item.Notes = firstNonEmpty(f.Category, "other"), f.Notes
+}
+
+func transactionRuleFields(tx models.Transaction) RuleFields {
+	return RuleFields{Category: tx.Category, ProjectID: tx.ProjectID, ContactID: tx.ContactID, Notes: tx.Notes}
+}
+
+func (f RuleFields) equal(o RuleFields) bool {
+	return f.Category == o.Category && f.Notes == o.Notes && sameUUID(f.ProjectID, o.ProjectID) && sameUUID(f.ContactID, o.ContactID)
+}
+
+func sameUUID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
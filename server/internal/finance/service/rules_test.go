@@ -0,0 +1,54 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func TestRuleSetFillsEmptyFieldsInPriorityOrder(t *testing.T) {
+	project := uuid.New()
+	limit := int64(5000)
+	rules := compileRules([]models.CategorizationRule{
+		{ID: uuid.New(), DescriptionPattern: `uber|99 ?pop`, Type: "expense", MaxAmountCents: &limit, SetCategory: "transport"},
+		{ID: uuid.New(), DescriptionPattern: `uber`, Type: "expense", SetCategory: "food", SetProjectID: &project},
+		{ID: uuid.New(), DescriptionPattern: `(`, SetNotes: "broken pattern is dropped"},
+	})
+	if len(rules) != 2 {
+		t.Fatalf("expected the invalid pattern to be dropped, got %d rules", len(rules))
+	}
+
+	tx := models.Transaction{Type: "expense", AmountCents: 2300, Currency: "BRL", Description: "UBER *TRIP"}
+	ids := rules.applyToTransaction(&tx, false)
+	if len(ids) != 2 || tx.Category != "transport" || tx.ProjectID == nil || *tx.ProjectID != project {
+		t.Fatalf("unexpected result %+v (rules %v)", tx, ids)
+	}
+
+	big := models.Transaction{Type: "expense", AmountCents: 9000, Description: "Uber Eats", Category: "health"}
+	rules.applyToTransaction(&big, false)
+	if big.Category != "health" {
+		t.Fatalf("existing category was overwritten: %q", big.Category)
+	}
+	rules.applyToTransaction(&big, true)
+	if big.Category != "food" {
+		t.Fatalf("overwrite should apply the second rule, got %q", big.Category)
+	}
+
+	transfer := models.Transaction{Type: "transfer", Description: "uber"}
+	if ids := rules.applyToTransaction(&transfer, false); ids != nil || transfer.Category != "" {
+		t.Fatalf("transfers must be left alone: %+v", transfer)
+	}
+}
+
+func TestRuleSetOnInvoiceItemsTreatsOtherAsEmpty(t *testing.T) {
+	rules := compileRules([]models.CategorizationRule{
+		{ID: uuid.New(), DescriptionPattern: `netflix`, Type: "expense", SetCategory: "subscription"},
+		{ID: uuid.New(), Currency: "USD", SetNotes: "never matches items"},
+	})
+	item := models.InvoiceItem{Description: "NETFLIX.COM", AmountCents: 3990, Category: "other"}
+	rules.applyToInvoiceItem(&item)
+	if item.Category != "subscription" || item.Notes != "" {
+		t.Fatalf("unexpected item %+v", item)
+	}
+}
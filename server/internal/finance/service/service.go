@@ -16,16 +16,27 @@ import (
 type Service struct {
 	repo     *repository.Repository
 	contacts ContactValidator
+	media    MediaUploader
 }
 
 type ContactValidator interface {
 	ValidateActiveContact(ctx context.Context, id uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Contact, error)
 }
 
 func New(repo *repository.Repository) *Service {
 	return &Service{repo: repo}
 }
 
+// inTx runs fn with a copy of the service whose repository is bound to one database transaction.
+func (s *Service) inTx(ctx context.Context, fn func(*Service) error) error {
+	return s.repo.Transaction(ctx, func(r *repository.Repository) error {
+		tx := *s
+		tx.repo = r
+		return fn(&tx)
+	})
+}
+
 func (s *Service) SetContactValidator(v ContactValidator) {
 	s.contacts = v
 }
@@ -41,31 +52,47 @@ func (s *Service) validateContactID(ctx context.Context, id *uuid.UUID) error {
 }
 
 type CreateIncomeSourceInput struct {
-	Name        string
-	Type        string
-	AmountCents int64
-	Currency    string
-	Frequency   string
-	DayOfMonth  int
-	ProjectID   *uuid.UUID
-	ContactID   *uuid.UUID
-	Active      bool
-	Notes       string
+	Name          string
+	Type          string
+	AmountCents   int64
+	Currency      string
+	Frequency     string
+	DayOfMonth    int
+	AnchorDate    *time.Time
+	Interval      int
+	Weekday       *int
+	MonthOfYear   *int
+	EndDate       *time.Time
+	WeekendAdjust string
+	ProjectID     *uuid.UUID
+	ContactID     *uuid.UUID
+	Active        bool
+	Notes         string
 }
 
 type UpdateIncomeSourceInput struct {
-	Name        *string
-	Type        *string
-	AmountCents *int64
-	Currency    *string
-	Frequency   *string
-	DayOfMonth  *int
-	ProjectID   *uuid.UUID
-	ProjectSet  bool
-	ContactID   *uuid.UUID
-	ContactSet  bool
-	Active      *bool
-	Notes       *string
+	Name           *string
+	Type           *string
+	AmountCents    *int64
+	Currency       *string
+	Frequency      *string
+	DayOfMonth     *int
+	AnchorDate     *time.Time
+	AnchorDateSet  bool
+	Interval       *int
+	Weekday        *int
+	WeekdaySet     bool
+	MonthOfYear    *int
+	MonthOfYearSet bool
+	EndDate        *time.Time
+	EndDateSet     bool
+	WeekendAdjust  *string
+	ProjectID      *uuid.UUID
+	ProjectSet     bool
+	ContactID      *uuid.UUID
+	ContactSet     bool
+	Active         *bool
+	Notes          *string
 }
 
 type IncomeSourceFilter struct {
@@ -108,13 +135,29 @@ func (s *Service) CreateIncomeSource(ctx context.Context, in CreateIncomeSourceI
 	if err := s.validateContactID(ctx, in.ContactID); err != nil {
 		return nil, err
 	}
+	rec := models.Recurrence{
+		AnchorDate:    in.AnchorDate,
+		Interval:      in.Interval,
+		Weekday:       in.Weekday,
+		MonthOfYear:   in.MonthOfYear,
+		EndDate:       in.EndDate,
+		WeekendAdjust: in.WeekendAdjust,
+	}
+	if err := normalizeRecurrence(&rec); err != nil {
+		return nil, err
+	}
+	incomeType, err := s.resolveCategory(ctx, "income", in.Type)
+	if err != nil {
+		return nil, err
+	}
 	row := &models.IncomeSource{
 		Name:        name,
-		Type:        normalizeIncomeType(in.Type),
+		Type:        incomeType,
 		AmountCents: in.AmountCents,
 		Currency:    normalizeCurrency(in.Currency),
 		Frequency:   normalizeFrequency(in.Frequency),
 		DayOfMonth:  clampDay(in.DayOfMonth),
+		Recurrence:  rec,
 		ProjectID:   in.ProjectID,
 		ContactID:   in.ContactID,
 		Active:      in.Active,
@@ -131,6 +174,7 @@ func (s *Service) UpdateIncomeSource(ctx context.Context, id uuid.UUID, in Updat
 	if err != nil {
 		return nil, err
 	}
+	prev := *row
 	if in.Name != nil {
 		name := strings.TrimSpace(*in.Name)
 		if name == "" {
@@ -139,7 +183,9 @@ func (s *Service) UpdateIncomeSource(ctx context.Context, id uuid.UUID, in Updat
 		row.Name = name
 	}
 	if in.Type != nil {
-		row.Type = normalizeIncomeType(*in.Type)
+		if row.Type, err = s.resolveCategory(ctx, "income", *in.Type); err != nil {
+			return nil, err
+		}
 	}
 	if in.AmountCents != nil {
 		row.AmountCents = *in.AmountCents
@@ -153,6 +199,21 @@ func (s *Service) UpdateIncomeSource(ctx context.Context, id uuid.UUID, in Updat
 	if in.DayOfMonth != nil {
 		row.DayOfMonth = clampDay(*in.DayOfMonth)
 	}
+	recurrencePatch{
+		AnchorDate:     in.AnchorDate,
+		AnchorDateSet:  in.AnchorDateSet,
+		Interval:       in.Interval,
+		Weekday:        in.Weekday,
+		WeekdaySet:     in.WeekdaySet,
+		MonthOfYear:    in.MonthOfYear,
+		MonthOfYearSet: in.MonthOfYearSet,
+		EndDate:        in.EndDate,
+		EndDateSet:     in.EndDateSet,
+		WeekendAdjust:  in.WeekendAdjust,
+	}.apply(&row.Recurrence)
+	if err := normalizeRecurrence(&row.Recurrence); err != nil {
+		return nil, err
+	}
 	if in.ProjectSet {
 		row.ProjectID = in.ProjectID
 	}
@@ -168,8 +229,23 @@ func (s *Service) UpdateIncomeSource(ctx context.Context, id uuid.UUID, in Updat
 	if in.Notes != nil {
 		row.Notes = strings.TrimSpace(*in.Notes)
 	}
-	if err := s.repo.SaveIncomeSource(ctx, row); err != nil {
-		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update income source.", err)
+	var active []models.IncomeSource
+	if row.Active {
+		active = append(active, *row)
+	}
+	reschedule := row.AmountCents != prev.AmountCents || row.Currency != prev.Currency || row.Frequency != prev.Frequency ||
+		row.DayOfMonth != prev.DayOfMonth || row.Active != prev.Active || recurrenceChanged(row.Recurrence, prev.Recurrence)
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.repo.SaveIncomeSource(ctx, row); err != nil {
+			return apperrors.InternalCause(apperrors.CodeInternal, "Failed to update income source.", err)
+		}
+		if !reschedule {
+			return nil
+		}
+		return tx.rescheduleExpected(ctx, "income", row.ID, expectedDefaults{prev.AmountCents, prev.Name}, active, nil)
+	})
+	if err != nil {
+		return nil, err
 	}
 	return row, nil
 }
@@ -185,33 +261,49 @@ func (s *Service) DeleteIncomeSource(ctx context.Context, id uuid.UUID) error {
 }
 
 type CreateExpenseInput struct {
-	Name        string
-	Category    string
-	AmountCents int64
-	Currency    string
-	Frequency   string
-	DayOfMonth  int
-	DueDate     *time.Time
-	AutoPay     bool
-	ProjectID   *uuid.UUID
-	Active      bool
-	Notes       string
+	Name          string
+	Category      string
+	AmountCents   int64
+	Currency      string
+	Frequency     string
+	DayOfMonth    int
+	DueDate       *time.Time
+	AnchorDate    *time.Time
+	Interval      int
+	Weekday       *int
+	MonthOfYear   *int
+	EndDate       *time.Time
+	WeekendAdjust string
+	AutoPay       bool
+	ProjectID     *uuid.UUID
+	Active        bool
+	Notes         string
 }
 
 type UpdateExpenseInput struct {
-	Name        *string
-	Category    *string
-	AmountCents *int64
-	Currency    *string
-	Frequency   *string
-	DayOfMonth  *int
-	DueDate     *time.Time
-	DueDateSet  bool
-	AutoPay     *bool
-	ProjectID   *uuid.UUID
-	ProjectSet  bool
-	Active      *bool
-	Notes       *string
+	Name           *string
+	Category       *string
+	AmountCents    *int64
+	Currency       *string
+	Frequency      *string
+	DayOfMonth     *int
+	DueDate        *time.Time
+	DueDateSet     bool
+	AnchorDate     *time.Time
+	AnchorDateSet  bool
+	Interval       *int
+	Weekday        *int
+	WeekdaySet     bool
+	MonthOfYear    *int
+	MonthOfYearSet bool
+	EndDate        *time.Time
+	EndDateSet     bool
+	WeekendAdjust  *string
+	AutoPay        *bool
+	ProjectID      *uuid.UUID
+	ProjectSet     bool
+	Active         *bool
+	Notes          *string
 }
 
 func (s *Service) ListExpenses(ctx context.Context) ([]models.Expense, error) {
@@ -238,14 +330,30 @@ func (s *Service) CreateExpense(ctx context.Context, in CreateExpenseInput) (*mo
 	if name == "" {
 		return nil, apperrors.Invalid(apperrors.CodeInternal, "Name is required.")
 	}
+	rec := models.Recurrence{
+		AnchorDate:    in.AnchorDate,
+		Interval:      in.Interval,
+		Weekday:       in.Weekday,
+		MonthOfYear:   in.MonthOfYear,
+		EndDate:       in.EndDate,
+		WeekendAdjust: in.WeekendAdjust,
+	}
+	if err := normalizeRecurrence(&rec); err != nil {
+		return nil, err
+	}
+	category, err := s.resolveCategory(ctx, "expense", in.Category)
+	if err != nil {
+		return nil, err
+	}
 	row := &models.Expense{
 		Name:        name,
-		Category:    normalizeExpenseCategory(in.Category),
+		Category:    category,
 		AmountCents: in.AmountCents,
 		Currency:    normalizeCurrency(in.Currency),
 		Frequency:   normalizeFrequency(in.Frequency),
 		DayOfMonth:  clampDay(in.DayOfMonth),
 		DueDate:     in.DueDate,
+		Recurrence:  rec,
 		AutoPay:     in.AutoPay,
 		ProjectID:   in.ProjectID,
 		Active:      in.Active,
@@ -262,6 +370,7 @@ func (s *Service) UpdateExpense(ctx context.Context, id uuid.UUID, in UpdateExpe
 	if err != nil {
 		return nil, err
 	}
+	prev := *row
 	if in.Name != nil {
 		name := strings.TrimSpace(*in.Name)
 		if name == "" {
@@ -270,7 +379,9 @@ func (s *Service) UpdateExpense(ctx context.Context, id uuid.UUID, in UpdateExpe
 		row.Name = name
 	}
 	if in.Category != nil {
-		row.Category = normalizeExpenseCategory(*in.Category)
+		if row.Category, err = s.resolveCategory(ctx, "expense", *in.Category); err != nil {
+			return nil, err
+		}
 	}
 	if in.AmountCents != nil {
 		row.AmountCents = *in.AmountCents
@@ -287,6 +398,21 @@ func (s *Service) UpdateExpense(ctx context.Context, id uuid.UUID, in UpdateExpe
 	if in.DueDateSet {
 		row.DueDate = in.DueDate
 	}
+	recurrencePatch{
+		AnchorDate:     in.AnchorDate,
+		AnchorDateSet:  in.AnchorDateSet,
+		Interval:       in.Interval,
+		Weekday:        in.Weekday,
+		WeekdaySet:     in.WeekdaySet,
+		MonthOfYear:    in.MonthOfYear,
+		MonthOfYearSet: in.MonthOfYearSet,
+		EndDate:        in.EndDate,
+		EndDateSet:     in.EndDateSet,
+		WeekendAdjust:  in.WeekendAdjust,
+	}.apply(&row.Recurrence)
+	if err := normalizeRecurrence(&row.Recurrence); err != nil {
+		return nil, err
+	}
 	if in.AutoPay != nil {
 		row.AutoPay = *in.AutoPay
 	}
@@ -299,8 +425,24 @@ func (s *Service) UpdateExpense(ctx context.Context, id uuid.UUID, in UpdateExpe
 	if in.Notes != nil {
 		row.Notes = strings.TrimSpace(*in.Notes)
 	}
-	if err := s.repo.SaveExpense(ctx, row); err != nil {
-		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update expense.", err)
+	var active []models.Expense
+	if row.Active {
+		active = append(active, *row)
+	}
+	reschedule := row.AmountCents != prev.AmountCents || row.Currency != prev.Currency || row.Frequency != prev.Frequency ||
+		row.DayOfMonth != prev.DayOfMonth || !sameDate(row.DueDate, prev.DueDate) || row.Active != prev.Active ||
+		recurrenceChanged(row.Recurrence, prev.Recurrence)
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.repo.SaveExpense(ctx, row); err != nil {
+			return apperrors.InternalCause(apperrors.CodeInternal, "Failed to update expense.", err)
+		}
+		if !reschedule {
+			return nil
+		}
+		return tx.rescheduleExpected(ctx, "expense", row.ID, expectedDefaults{prev.AmountCents, prev.Name}, nil, active)
+	})
+	if err != nil {
+		return nil, err
 	}
 	return row, nil
 }
@@ -315,24 +457,6 @@ func (s *Service) DeleteExpense(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func normalizeIncomeType(t string) string {
-	switch strings.TrimSpace(strings.ToLower(t)) {
-	case "salary", "freelance", "saas", "business":
-		return strings.TrimSpace(strings.ToLower(t))
-	default:
-		return "other"
-	}
-}
-
-func normalizeExpenseCategory(c string) string {
-	switch strings.TrimSpace(strings.ToLower(c)) {
-	case "subscription", "utilities", "rent", "food", "investment", "transport", "health":
-		return strings.TrimSpace(strings.ToLower(c))
-	default:
-		return "other"
-	}
-}
-
 func normalizeFrequency(f string) string {
 	switch strings.TrimSpace(strings.ToLower(f)) {
 	case "weekly", "yearly", "one_time":
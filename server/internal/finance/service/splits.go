@@ -0,0 +1,478 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/gorm"
+)
+
+type SplitShareInput struct {
+	ContactID   uuid.UUID
+	Percent     float64
+	AmountCents int64
+}
+
+// SplitInput divides an amount among contacts. Equal splits count us as one more participant unless
+// ExcludeSelf is set; percentage and exact splits leave whatever the shares do not cover to us.
+type SplitInput struct {
+	Method      string
+	ExcludeSelf bool
+	Shares      []SplitShareInput
+}
+
+type CreateSettlementInput struct {
+	AmountCents   int64
+	Currency      string
+	Date          time.Time
+	TransactionID *uuid.UUID
+	Notes         string
+}
+
+// CurrencyBalance is what a contact owes us in one currency; negative when we owe them.
+type CurrencyBalance struct {
+	Currency    string `json:"currency"`
+	AmountCents int64  `json:"amountCents"`
+}
+
+// ContactBalance is a contact's outstanding shared-expense balance.
+type ContactBalance struct {
+	ContactID uuid.UUID         `json:"contactId"`
+	Balances  []CurrencyBalance `json:"balances"`
+}
+
+// BalanceEntry is one line of a contact's statement, a share or a settlement, with the running
+// balance of its currency after it.
+type BalanceEntry struct {
+	Kind          string     `json:"kind"`
+	ID            uuid.UUID  `json:"id"`
+	Date          time.Time  `json:"date"`
+	Description   string     `json:"description"`
+	Currency      string     `json:"currency"`
+	AmountCents   int64      `json:"amountCents"`
+	BalanceCents  int64      `json:"balanceCents"`
+	OwnerType     string     `json:"ownerType,omitempty"`
+	OwnerID       *uuid.UUID `json:"ownerId,omitempty"`
+	TransactionID *uuid.UUID `json:"transactionId,omitempty"`
+}
+
+type ContactStatement struct {
+	ContactBalance
+	Entries []BalanceEntry `json:"entries"`
+}
+
+func (s *Service) TransactionSplits(ctx context.Context, id uuid.UUID) ([]models.FinanceSplit, error) {
+	if _, err := s.GetTransaction(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.listSplits(ctx, models.FinanceSplitTransaction, id)
+}
+
+// SplitTransaction replaces the shares of an income or expense transaction.
+func (s *Service) SplitTransaction(ctx context.Context, id uuid.UUID, in SplitInput) ([]models.FinanceSplit, error) {
+	tx, err := s.GetTransaction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Type == "transfer" {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Transfers cannot be split.")
+	}
+	rows, err := s.buildSplits(ctx, tx.AmountCents, in)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		if tx.Type == "income" {
+			rows[i].AmountCents = -rows[i].AmountCents
+		}
+		rows[i].Currency, rows[i].Description, rows[i].Date = tx.Currency, tx.Description, tx.Date
+	}
+	return s.replaceSplits(ctx, models.FinanceSplitTransaction, id, rows)
+}
+
+func (s *Service) InvoiceItemSplits(ctx context.Context, invoiceID, itemID uuid.UUID) ([]models.FinanceSplit, error) {
+	if _, err := s.findInvoiceItem(ctx, invoiceID, itemID); err != nil {
+		return nil, err
+	}
+	return s.listSplits(ctx, models.FinanceSplitInvoiceItem, itemID)
+}
+
+// SplitInvoiceItem replaces the shares of a card charge, which we paid.
+func (s *Service) SplitInvoiceItem(ctx context.Context, invoiceID, itemID uuid.UUID, in SplitInput) ([]models.FinanceSplit, error) {
+	item, err := s.findInvoiceItem(ctx, invoiceID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.AmountCents <= 0 {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Only charges can be split.")
+	}
+	rows, err := s.buildSplits(ctx, item.AmountCents, in)
+	if err != nil {
+		return nil, err
+	}
+	currency, err := s.invoiceCurrency(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].Currency, rows[i].Description, rows[i].Date = currency, item.Description, item.Date
+	}
+	return s.replaceSplits(ctx, models.FinanceSplitInvoiceItem, itemID, rows)
+}
+
+func (s *Service) DeleteTransactionSplits(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.GetTransaction(ctx, id); err != nil {
+		return err
+	}
+	_, err := s.replaceSplits(ctx, models.FinanceSplitTransaction, id, nil)
+	return err
+}
+
+func (s *Service) DeleteInvoiceItemSplits(ctx context.Context, invoiceID, itemID uuid.UUID) error {
+	if _, err := s.findInvoiceItem(ctx, invoiceID, itemID); err != nil {
+		return err
+	}
+	_, err := s.replaceSplits(ctx, models.FinanceSplitInvoiceItem, itemID, nil)
+	return err
+}
+
+// refreshTransactionSplits recomputes the shares of an edited transaction from its new amount with
+// the method they were split by, and keeps them in line with its type, currency, date and
+// description. Exact shares keep their amounts, so the edit is rejected when they no longer fit.
+// Turning it into a transfer drops them.
+func (s *Service) refreshTransactionSplits(ctx context.Context, row *models.Transaction, prevAmount int64) error {
+	rows, err := s.listSplits(ctx, models.FinanceSplitTransaction, row.ID)
+	if err != nil || len(rows) == 0 {
+		return err
+	}
+	if row.Type == "transfer" {
+		_, err := s.replaceSplits(ctx, models.FinanceSplitTransaction, row.ID, nil)
+		return err
+	}
+	if err := resplit(rows, prevAmount, row.AmountCents); err != nil {
+		return err
+	}
+	for i := range rows {
+		if row.Type == "income" {
+			rows[i].AmountCents = -rows[i].AmountCents
+		}
+		rows[i].Currency, rows[i].Description, rows[i].Date = row.Currency, row.Description, row.Date
+	}
+	_, err = s.replaceSplits(ctx, models.FinanceSplitTransaction, row.ID, rows)
+	return err
+}
+
+// refreshInvoiceItemSplits does the same for a card charge an installment purchase rewrote.
+func (s *Service) refreshInvoiceItemSplits(ctx context.Context, item *models.InvoiceItem, prevAmount int64) error {
+	rows, err := s.listSplits(ctx, models.FinanceSplitInvoiceItem, item.ID)
+	if err != nil || len(rows) == 0 {
+		return err
+	}
+	if err := resplit(rows, prevAmount, item.AmountCents); err != nil {
+		return err
+	}
+	for i := range rows {
+		rows[i].Description, rows[i].Date = item.Description, item.Date
+	}
+	_, err = s.replaceSplits(ctx, models.FinanceSplitInvoiceItem, item.ID, rows)
+	return err
+}
+
+// resplit sets the shares in rows, split from prevAmount, to their shares of amount.
+func resplit(rows []models.FinanceSplit, prevAmount, amount int64) error {
+	method, in := storedSplitInput(rows, prevAmount)
+	amounts, err := splitAmounts(amount, method, in)
+	if err != nil {
+		return err
+	}
+	for i := range rows {
+		rows[i].AmountCents = amounts[i]
+	}
+	return nil
+}
+
+// storedSplitInput rebuilds the split that produced rows, shares of prevAmount. ExcludeSelf is not
+// stored, so an equal split is taken to exclude us when its shares covered the whole amount.
+func storedSplitInput(rows []models.FinanceSplit, prevAmount int64) (string, SplitInput) {
+	method := rows[0].Method
+	in := SplitInput{Method: method, Shares: make([]SplitShareInput, len(rows))}
+	var sum int64
+	for i, sp := range rows {
+		in.Shares[i] = SplitShareInput{ContactID: sp.ContactID, AmountCents: abs(sp.AmountCents)}
+		if sp.Percent != nil {
+			in.Shares[i].Percent = *sp.Percent
+		}
+		sum += abs(sp.AmountCents)
+	}
+	in.ExcludeSelf = method == "equal" && sum >= abs(prevAmount)
+	return method, in
+}
+
+func (s *Service) findInvoiceItem(ctx context.Context, invoiceID, itemID uuid.UUID) (*models.InvoiceItem, error) {
+	inv, err := s.GetInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range inv.Items {
+		if inv.Items[i].ID == itemID {
+			return &inv.Items[i], nil
+		}
+	}
+	return nil, apperrors.NotFound(apperrors.CodeInternal, "Invoice item not found.")
+}
+
+func (s *Service) listSplits(ctx context.Context, ownerType string, ownerID uuid.UUID) ([]models.FinanceSplit, error) {
+	rows, err := s.repo.ListSplits(ctx, ownerType, ownerID)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load splits.", err)
+	}
+	return rows, nil
+}
+
+func (s *Service) replaceSplits(ctx context.Context, ownerType string, ownerID uuid.UUID, rows []models.FinanceSplit) ([]models.FinanceSplit, error) {
+	if err := s.repo.ReplaceSplits(ctx, ownerType, ownerID, rows); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to save splits.", err)
+	}
+	if rows == nil {
+		rows = []models.FinanceSplit{}
+	}
+	return rows, nil
+}
+
+// buildSplits validates the contacts and computes each share of total.
+func (s *Service) buildSplits(ctx context.Context, total int64, in SplitInput) ([]models.FinanceSplit, error) {
+	method := strings.TrimSpace(strings.ToLower(in.Method))
+	if method == "" {
+		method = "equal"
+	}
+	seen := map[uuid.UUID]bool{}
+	for _, share := range in.Shares {
+		if seen[share.ContactID] {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Each contact can only have one share.")
+		}
+		seen[share.ContactID] = true
+		id := share.ContactID
+		if err := s.validateContactID(ctx, &id); err != nil {
+			return nil, err
+		}
+	}
+	amounts, err := splitAmounts(total, method, in)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]models.FinanceSplit, len(in.Shares))
+	for i, share := range in.Shares {
+		rows[i] = models.FinanceSplit{ContactID: share.ContactID, Method: method, AmountCents: amounts[i]}
+		if method == "percentage" {
+			p := share.Percent
+			rows[i].Percent = &p
+		}
+	}
+	return rows, nil
+}
+
+// splitAmounts returns the contacts' shares of total in cents. Equal splits give the leftover cents to
+// the first contacts; percentage splits round each share and never exceed total.
+func splitAmounts(total int64, method string, in SplitInput) ([]int64, error) {
+	n := len(in.Shares)
+	if n == 0 {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "At least one share is required.")
+	}
+	out := make([]int64, n)
+	switch method {
+	case "equal":
+		parts := int64(n)
+		if !in.ExcludeSelf {
+			parts++
+		}
+		for i := range out {
+			out[i] = total / parts
+			if int64(i) < total%parts {
+				out[i]++
+			}
+		}
+	case "percentage":
+		var percent float64
+		var sum int64
+		for i, share := range in.Shares {
+			if share.Percent <= 0 || share.Percent > 100 {
+				return nil, apperrors.Invalid(apperrors.CodeInternal, "Percentages must be between 0 and 100.")
+			}
+			percent += share.Percent
+			out[i] = int64(math.Round(float64(total) * share.Percent / 100))
+			sum += out[i]
+		}
+		if percent > 100.0001 {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Percentages cannot add up to more than 100.")
+		}
+		if sum > total {
+			out[n-1] -= sum - total
+		}
+	case "exact":
+		var sum int64
+		for i, share := range in.Shares {
+			if share.AmountCents <= 0 {
+				return nil, apperrors.Invalid(apperrors.CodeInternal, "Share amounts must be positive.")
+			}
+			out[i] = share.AmountCents
+			sum += share.AmountCents
+		}
+		if sum > total {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Shares cannot add up to more than the amount.")
+		}
+	default:
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Method must be equal, percentage or exact.")
+	}
+	return out, nil
+}
+
+// CreateSettlement records a payment settling part of a contact's balance: a positive amount means
+// the contact paid us.
+func (s *Service) CreateSettlement(ctx context.Context, contactID uuid.UUID, in CreateSettlementInput) (*models.FinanceSettlement, error) {
+	if err := s.validateContactID(ctx, &contactID); err != nil {
+		return nil, err
+	}
+	if in.AmountCents == 0 {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Amount is required.")
+	}
+	if in.TransactionID != nil {
+		if _, err := s.GetTransaction(ctx, *in.TransactionID); err != nil {
+			return nil, err
+		}
+	}
+	date := in.Date
+	if date.IsZero() {
+		date = time.Now().UTC()
+	}
+	row := &models.FinanceSettlement{
+		ContactID:     contactID,
+		AmountCents:   in.AmountCents,
+		Currency:      normalizeCurrency(in.Currency),
+		Date:          dateOnly(date),
+		TransactionID: in.TransactionID,
+		Notes:         strings.TrimSpace(in.Notes),
+	}
+	if err := s.repo.CreateSettlement(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to create settlement.", err)
+	}
+	return row, nil
+}
+
+func (s *Service) DeleteSettlement(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.DeleteSettlement(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound(apperrors.CodeInternal, "Settlement not found.")
+		}
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to delete settlement.", err)
+	}
+	return nil
+}
+
+// ListBalances returns every contact with an outstanding balance.
+func (s *Service) ListBalances(ctx context.Context) ([]ContactBalance, error) {
+	rows, err := s.repo.SumBalances(ctx, nil)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to compute balances.", err)
+	}
+	out := []ContactBalance{}
+	for _, row := range rows {
+		if len(out) == 0 || out[len(out)-1].ContactID != row.ContactID {
+			out = append(out, ContactBalance{ContactID: row.ContactID})
+		}
+		last := &out[len(out)-1]
+		last.Balances = append(last.Balances, CurrencyBalance{Currency: row.Currency, AmountCents: row.AmountCents})
+	}
+	return out, nil
+}
+
+func (s *Service) contactBalances(ctx context.Context, contactID uuid.UUID) ([]CurrencyBalance, error) {
+	rows, err := s.repo.SumBalances(ctx, &contactID)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to compute balances.", err)
+	}
+	out := make([]CurrencyBalance, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, CurrencyBalance{Currency: row.Currency, AmountCents: row.AmountCents})
+	}
+	return out, nil
+}
+
+// ContactStatement lists a contact's shares and settlements in date order with running balances.
+func (s *Service) ContactStatement(ctx context.Context, contactID uuid.UUID) (*ContactStatement, error) {
+	if err := s.validateContactID(ctx, &contactID); err != nil {
+		return nil, err
+	}
+	splits, err := s.repo.ListContactSplits(ctx, contactID)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load splits.", err)
+	}
+	settlements, err := s.repo.ListSettlements(ctx, contactID)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load settlements.", err)
+	}
+	entries := statementEntries(splits, settlements)
+	out := &ContactStatement{
+		ContactBalance: ContactBalance{ContactID: contactID, Balances: []CurrencyBalance{}},
+		Entries:        entries,
+	}
+	final := map[string]int64{}
+	for _, e := range entries {
+		final[e.Currency] = e.BalanceCents
+	}
+	for cur, amount := range final {
+		if amount != 0 {
+			out.Balances = append(out.Balances, CurrencyBalance{Currency: cur, AmountCents: amount})
+		}
+	}
+	sort.Slice(out.Balances, func(i, j int) bool { return out.Balances[i].Currency < out.Balances[j].Currency })
+	return out, nil
+}
+
+func statementEntries(splits []models.FinanceSplit, settlements []models.FinanceSettlement) []BalanceEntry {
+	entries := make([]BalanceEntry, 0, len(splits)+len(settlements))
+	for _, sp := range splits {
+		ownerID := sp.OwnerID
+		entries = append(entries, BalanceEntry{
+			Kind:        "share",
+			ID:          sp.ID,
+			Date:        sp.Date,
+			Description: sp.Description,
+			Currency:    sp.Currency,
+			AmountCents: sp.AmountCents,
+			OwnerType:   sp.OwnerType,
+			OwnerID:     &ownerID,
+		})
+	}
+	for _, st := range settlements {
+		entries = append(entries, BalanceEntry{
+			Kind:          "settlement",
+			ID:            st.ID,
+			Date:          st.Date,
+			Description:   firstNonEmpty(st.Notes, "Settlement"),
+			Currency:      st.Currency,
+			AmountCents:   -st.AmountCents,
+			TransactionID: st.TransactionID,
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Date.Before(entries[j].Date) })
+	running := map[string]int64{}
+	for i := range entries {
+		running[entries[i].Currency] += entries[i].AmountCents
+		entries[i].BalanceCents = running[entries[i].Currency]
+	}
+	return entries
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func shares(n int) []SplitShareInput {
+	out := make([]SplitShareInput, n)
+	for i := range out {
+		out[i].ContactID = uuid.New()
+	}
+	return out
+}
+
+func TestSplitAmounts(t *testing.T) {
+	cases := []struct {
+		method string
+		total  int64
+		in     SplitInput
+		want   []int64
+	}{
+		// Three participants counting us: the leftover cent goes to the first contact.
+		{"equal", 10000, SplitInput{Shares: shares(2)}, []int64{3334, 3333}},
+		{"equal", 10001, SplitInput{ExcludeSelf: true, Shares: shares(2)}, []int64{5001, 5000}},
+		{"percentage", 9999, SplitInput{Shares: []SplitShareInput{{Percent: 50}, {Percent: 50}}}, []int64{5000, 4999}},
+		{"exact", 10000, SplitInput{Shares: []SplitShareInput{{AmountCents: 2500}, {AmountCents: 4000}}}, []int64{2500, 4000}},
+	}
+	for _, tc := range cases {
+		got, err := splitAmounts(tc.total, tc.method, tc.in)
+		if err != nil {
+			t.Fatalf("%s %d: %v", tc.method, tc.total, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s %d: got %v want %v", tc.method, tc.total, got, tc.want)
+		}
+	}
+
+	bad := []struct {
+		method string
+		in     SplitInput
+	}{
+		{"equal", SplitInput{}},
+		{"percentage", SplitInput{Shares: []SplitShareInput{{Percent: 60}, {Percent: 50}}}},
+		{"percentage", SplitInput{Shares: []SplitShareInput{{Percent: 0}}}},
+		{"exact", SplitInput{Shares: []SplitShareInput{{AmountCents: 6000}, {AmountCents: 5000}}}},
+		{"shares", SplitInput{Shares: shares(1)}},
+	}
+	for _, tc := range bad {
+		if _, err := splitAmounts(10000, tc.method, tc.in); err == nil {
+			t.Fatalf("%s %+v: expected an error", tc.method, tc.in)
+		}
+	}
+}
+
+func TestStoredSplitInput(t *testing.T) {
+	half := 50.0
+	cases := []struct {
+		rows []models.FinanceSplit
+		prev int64
+		want []int64
+	}{
+		// Shares covering the whole amount were split excluding us, and still do after the edit.
+		{[]models.FinanceSplit{{Method: "equal", AmountCents: 50}, {Method: "equal", AmountCents: 50}}, 100, []int64{50, 49}},
+		{[]models.FinanceSplit{{Method: "equal", AmountCents: -3334}, {Method: "equal", AmountCents: -3333}}, 10000, []int64{33, 33}},
+		{[]models.FinanceSplit{{Method: "percentage", Percent: &half, AmountCents: 50}, {Method: "percentage", Percent: &half, AmountCents: 50}}, 100, []int64{50, 49}},
+		{[]models.FinanceSplit{{Method: "exact", AmountCents: 30}, {Method: "exact", AmountCents: 40}}, 100, []int64{30, 40}},
+	}
+	for _, tc := range cases {
+		method, in := storedSplitInput(tc.rows, tc.prev)
+		got, err := splitAmounts(99, method, in)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: got %v want %v", method, got, tc.want)
+		}
+	}
+
+	exact := []models.FinanceSplit{{Method: "exact", AmountCents: 60}, {Method: "exact", AmountCents: 40}}
+	method, in := storedSplitInput(exact, 100)
+	if _, err := splitAmounts(99, method, in); err == nil {
+		t.Fatal("exact shares above the new amount should be rejected")
+	}
+}
+
+func TestStatementEntries(t *testing.T) {
+	splits := []models.FinanceSplit{
+		{Description: "Jantar", Currency: "BRL", AmountCents: 8000, Date: day(2025, 3, 1)},
+		{Description: "Hospedagem", Currency: "BRL", AmountCents: 4500, Date: day(2025, 3, 10)},
+		{Description: "Repasse", Currency: "USD", AmountCents: -2000, Date: day(2025, 3, 12)},
+	}
+	settlements := []models.FinanceSettlement{
+		{Currency: "BRL", AmountCents: 8000, Date: day(2025, 3, 5)},
+	}
+	entries := statementEntries(splits, settlements)
+	var got []int64
+	for _, e := range entries {
+		got = append(got, e.BalanceCents)
+	}
+	if want := []int64{8000, 0, 4500, -2000}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("running balances %v want %v", got, want)
+	}
+	if entries[1].Kind != "settlement" || entries[1].AmountCents != -8000 {
+		t.Fatalf("settlement entry: %+v", entries[1])
+	}
+}
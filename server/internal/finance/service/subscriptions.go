@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/repository"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+const (
+	defaultSubscriptionMonths = 13
+	maxSubscriptionMonths     = 36
+
+	// subscriptionPriceTolerance is how far (as a fraction) consecutive charges may differ and
+	// still count as the same subscription.
+	subscriptionPriceTolerance = 0.35
+)
+
+// subscriptionBands are the accepted gaps in days between charges for each frequency.
+var subscriptionBands = []struct {
+	frequency  string
+	min, max   int
+	minCharges int
+}{
+	{"weekly", 5, 9, 3},
+	{"monthly", 25, 36, 3},
+	{"yearly", 340, 390, 2},
+}
+
+type SubscriptionCharge struct {
+	Source      string    `json:"source"`
+	ID          uuid.UUID `json:"id"`
+	Date        time.Time `json:"date"`
+	AmountCents int64     `json:"amountCents"`
+}
+
+type PriceChange struct {
+	FromCents int64   `json:"fromCents"`
+	ToCents   int64   `json:"toCents"`
+	Percent   float64 `json:"percent"`
+}
+
+// Subscription is a run of regular, similarly priced charges from one merchant. Key identifies it
+// for promotion; ExpenseID is set when an expense already covers it.
+type Subscription struct {
+	Key           string               `json:"key"`
+	Name          string               `json:"name"`
+	Category      string               `json:"category"`
+	Currency      string               `json:"currency"`
+	Frequency     string               `json:"frequency"`
+	AmountCents   int64                `json:"amountCents"`
+	AverageCents  int64                `json:"averageCents"`
+	Occurrences   int                  `json:"occurrences"`
+	FirstDate     time.Time            `json:"firstDate"`
+	LastDate      time.Time            `json:"lastDate"`
+	NextDate      time.Time            `json:"nextDate"`
+	ExpenseID     *uuid.UUID           `json:"expenseId,omitempty"`
+	PriceIncrease *PriceChange         `json:"priceIncrease,omitempty"`
+	Charges       []SubscriptionCharge `json:"charges"`
+}
+
+// SubscriptionReport splits detected subscriptions into candidates with no expense and known ones.
+type SubscriptionReport struct {
+	Since      time.Time      `json:"since"`
+	Candidates []Subscription `json:"candidates"`
+	Known      []Subscription `json:"known"`
+}
+
+// DetectSubscriptions scans the last months (13 by default) of expense transactions and card items
+// for recurring charges.
+func (s *Service) DetectSubscriptions(ctx context.Context, months int) (*SubscriptionReport, error) {
+	if months <= 0 {
+		months = defaultSubscriptionMonths
+	}
+	if months > maxSubscriptionMonths {
+		months = maxSubscriptionMonths
+	}
+	today := dateOnly(time.Now().UTC())
+	since := today.AddDate(0, -months, 0)
+	charges, err := s.repo.ListCharges(ctx, since)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load charges.", err)
+	}
+	expenses, err := s.repo.ListExpenses(ctx, false)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load expenses.", err)
+	}
+	settings, err := s.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := detectSubscriptions(charges, expenses, normalizeCurrency(settings.ReportingCurrency), today)
+	report.Since = since
+	return report, nil
+}
+
+type PromoteSubscriptionInput struct {
+	Key         string
+	Name        string
+	Category    string
+	AmountCents *int64
+	AutoPay     bool
+	ProjectID   *uuid.UUID
+	Notes       string
+}
+
+// PromoteSubscription creates an expense from a detected candidate, scheduled from its last charge,
+// and links the candidate's transactions to it.
+func (s *Service) PromoteSubscription(ctx context.Context, in PromoteSubscriptionInput) (*models.Expense, error) {
+	key := strings.TrimSpace(in.Key)
+	if key == "" {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Key is required.")
+	}
+	report, err := s.DetectSubscriptions(ctx, maxSubscriptionMonths)
+	if err != nil {
+		return nil, err
+	}
+	var cand *Subscription
+	for i := range report.Candidates {
+		if report.Candidates[i].Key == key {
+			cand = &report.Candidates[i]
+			break
+		}
+	}
+	if cand == nil {
+		return nil, apperrors.NotFound(apperrors.CodeInternal, "Subscription candidate not found.")
+	}
+
+	amount := cand.AmountCents
+	if in.AmountCents != nil {
+		if *in.AmountCents <= 0 {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Amount must be positive.")
+		}
+		amount = *in.AmountCents
+	}
+	anchor := cand.LastDate
+	row, err := s.CreateExpense(ctx, CreateExpenseInput{
+		Name:        firstNonEmpty(strings.TrimSpace(in.Name), cand.Name),
+		Category:    firstNonEmpty(strings.TrimSpace(in.Category), cand.Category),
+		AmountCents: amount,
+		Currency:    cand.Currency,
+		Frequency:   cand.Frequency,
+		DayOfMonth:  anchor.Day(),
+		AnchorDate:  &anchor,
+		Interval:    1,
+		AutoPay:     in.AutoPay,
+		ProjectID:   in.ProjectID,
+		Active:      true,
+		Notes:       firstNonEmpty(strings.TrimSpace(in.Notes), fmt.Sprintf("Detected from %d charges.", cand.Occurrences)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	row.MerchantKey = merchantOf(key)
+	if err := s.repo.SaveExpense(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update expense.", err)
+	}
+	var txIDs []uuid.UUID
+	for _, c := range cand.Charges {
+		if c.Source == "transaction" {
+			txIDs = append(txIDs, c.ID)
+		}
+	}
+	if _, err := s.repo.LinkTransactionsToExpense(ctx, txIDs, row.ID); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to link transactions.", err)
+	}
+	return row, nil
+}
+
+// detectSubscriptions groups charges by merchant and currency and keeps the groups whose gaps fit a
+// frequency band and whose prices stay close, dropping those that stopped more than two periods
+// before today.
+func detectSubscriptions(charges []repository.ChargeRow, expenses []models.Expense, reportingCurrency string, today time.Time) *SubscriptionReport {
+	groups := map[string][]repository.ChargeRow{}
+	var order []string
+	for _, c := range charges {
+		merchant := merchantKey(c.Description)
+		if merchant == "" {
+			continue
+		}
+		currency := reportingCurrency
+		if c.Currency != "" {
+			currency = normalizeCurrency(c.Currency)
+		}
+		key := merchant + "|" + currency
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], c)
+	}
+
+	byMerchant := map[string]*models.Expense{}
+	byID := map[uuid.UUID]*models.Expense{}
+	for i := range expenses {
+		exp := &expenses[i]
+		byID[exp.ID] = exp
+		if exp.MerchantKey != "" {
+			byMerchant[exp.MerchantKey+"|"+normalizeCurrency(exp.Currency)] = exp
+		}
+		if k := merchantKey(exp.Name); k != "" {
+			if _, ok := byMerchant[k+"|"+normalizeCurrency(exp.Currency)]; !ok {
+				byMerchant[k+"|"+normalizeCurrency(exp.Currency)] = exp
+			}
+		}
+	}
+
+	report := &SubscriptionReport{Candidates: []Subscription{}, Known: []Subscription{}}
+	for _, key := range order {
+		rows := groups[key]
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].Date.Before(rows[j].Date) })
+		sub, ok := recurringRun(rows)
+		if !ok {
+			continue
+		}
+		sub.Key = key
+		sub.Currency = key[strings.LastIndex(key, "|")+1:]
+		if stale := today.AddDate(0, 0, -2*subscriptionPeriodDays(sub.Frequency)); sub.LastDate.Before(stale) {
+			continue
+		}
+
+		var exp *models.Expense
+		for _, c := range rows {
+			if c.ExpenseID != nil && byID[*c.ExpenseID] != nil {
+				exp = byID[*c.ExpenseID]
+			}
+		}
+		if exp == nil {
+			exp = byMerchant[key]
+		}
+		if exp != nil {
+			id := exp.ID
+			sub.ExpenseID = &id
+			sub.PriceIncrease = priceIncrease(exp.AmountCents, sub.AmountCents)
+			report.Known = append(report.Known, sub)
+			continue
+		}
+		if n := len(sub.Charges); n > 1 {
+			sub.PriceIncrease = priceIncrease(sub.Charges[n-2].AmountCents, sub.AmountCents)
+		}
+		report.Candidates = append(report.Candidates, sub)
+	}
+	sort.SliceStable(report.Candidates, func(i, j int) bool {
+		return report.Candidates[i].AverageCents > report.Candidates[j].AverageCents
+	})
+	return report
+}
+
+// recurringRun checks that rows, sorted by date, look like one subscription: gaps in a single
+// frequency band (one irregular gap is tolerated) and no price jump beyond the tolerance.
+func recurringRun(rows []repository.ChargeRow) (Subscription, bool) {
+	if len(rows) < 2 {
+		return Subscription{}, false
+	}
+	gaps := make([]int, 0, len(rows)-1)
+	for i := 1; i < len(rows); i++ {
+		gaps = append(gaps, int(dateOnly(rows[i].Date).Sub(dateOnly(rows[i-1].Date)).Hours()/24))
+	}
+	sorted := append([]int(nil), gaps...)
+	sort.Ints(sorted)
+	median := sorted[len(sorted)/2]
+
+	frequency := ""
+	for _, band := range subscriptionBands {
+		if median < band.min || median > band.max || len(rows) < band.minCharges {
+			continue
+		}
+		irregular := 0
+		for _, g := range gaps {
+			if g < band.min || g > band.max {
+				irregular++
+			}
+		}
+		if irregular <= 1 && irregular*3 < len(gaps) {
+			frequency = band.frequency
+		}
+		break
+	}
+	if frequency == "" {
+		return Subscription{}, false
+	}
+
+	var total int64
+	jumps := 0
+	for i, r := range rows {
+		total += r.AmountCents
+		if i > 0 {
+			prev := float64(rows[i-1].AmountCents)
+			if diff := float64(r.AmountCents) - prev; diff > prev*subscriptionPriceTolerance || -diff > prev*subscriptionPriceTolerance {
+				jumps++
+			}
+		}
+	}
+	if jumps > 1 || (jumps == 1 && len(rows) < 4) {
+		return Subscription{}, false
+	}
+
+	last := rows[len(rows)-1]
+	sub := Subscription{
+		Name:         strings.TrimSpace(last.Description),
+		Category:     last.Category,
+		Frequency:    frequency,
+		AmountCents:  last.AmountCents,
+		AverageCents: total / int64(len(rows)),
+		Occurrences:  len(rows),
+		FirstDate:    dateOnly(rows[0].Date),
+		LastDate:     dateOnly(last.Date),
+		Charges:      make([]SubscriptionCharge, len(rows)),
+	}
+	switch frequency {
+	case "weekly":
+		sub.NextDate = sub.LastDate.AddDate(0, 0, 7)
+	case "yearly":
+		sub.NextDate = sub.LastDate.AddDate(1, 0, 0)
+	default:
+		sub.NextDate = sub.LastDate.AddDate(0, 1, 0)
+	}
+	for i, r := range rows {
+		sub.Charges[i] = SubscriptionCharge{Source: r.Source, ID: r.ID, Date: dateOnly(r.Date), AmountCents: r.AmountCents}
+	}
+	return sub, true
+}
+
+func subscriptionPeriodDays(frequency string) int {
+	switch frequency {
+	case "weekly":
+		return 7
+	case "yearly":
+		return 365
+	default:
+		return 31
+	}
+}
+
+// priceIncrease reports a rise from the old to the new amount, or nil when there is none.
+func priceIncrease(from, to int64) *PriceChange {
+	if from <= 0 || to <= from {
+		return nil
+	}
+	pct := float64(to-from) / float64(from) * 100
+	return &PriceChange{FromCents: from, ToCents: to, Percent: math.Round(pct*100) / 100}
+}
+
+// merchantKey reduces a statement description to the merchant: the part after a payment processor
+// prefix ("PG *NETFLIX", "EBANX*SPOTIFY"), letters only, first two words, lower case.
+func merchantKey(description string) string {
+	d := strings.ToLower(description)
+	if i := strings.LastIndex(d, "*"); i >= 0 && strings.TrimSpace(d[i+1:]) != "" {
+		d = d[i+1:]
+	}
+	words := strings.Fields(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return r
+		}
+		return ' '
+	}, d))
+	if len(words) > 2 {
+		words = words[:2]
+	}
+	return truncateRunes(strings.Join(words, " "), 64)
+}
+
+// merchantOf strips the currency from a subscription key.
+func merchantOf(key string) string {
+	if i := strings.LastIndex(key, "|"); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
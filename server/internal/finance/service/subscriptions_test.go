@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/finance/repository"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func charge(desc string, amount int64, d time.Time) repository.ChargeRow {
+	return repository.ChargeRow{Source: "invoice_item", ID: uuid.New(), Description: desc, AmountCents: amount, Date: d}
+}
+
+func TestMerchantKey(t *testing.T) {
+	cases := map[string]string{
+		"PG *NETFLIX.COM SAO PAULO": "netflix com",
+		"EBANX*SPOTIFY":             "spotify",
+		"Github, Inc. 12/03":        "github inc",
+		"1234":                      "",
+	}
+	for in, want := range cases {
+		if got := merchantKey(in); got != want {
+			t.Errorf("merchantKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDetectSubscriptions(t *testing.T) {
+	today := day(2026, 6, 20)
+	charges := []repository.ChargeRow{
+		charge("PG *NETFLIX.COM", 3990, day(2026, 2, 5)),
+		charge("PG *NETFLIX.COM", 3990, day(2026, 3, 5)),
+		charge("PG *NETFLIX.COM", 3990, day(2026, 4, 6)),
+		charge("PG *NETFLIX.COM", 4490, day(2026, 5, 5)),
+		charge("PG *NETFLIX.COM", 4490, day(2026, 6, 5)),
+		charge("Spotify", 2190, day(2026, 4, 10)),
+		charge("Spotify", 2190, day(2026, 5, 10)),
+		charge("Spotify", 2390, day(2026, 6, 10)),
+		// irregular merchant
+		charge("Padaria Central", 1200, day(2026, 3, 1)),
+		charge("Padaria Central", 900, day(2026, 3, 4)),
+		charge("Padaria Central", 3000, day(2026, 5, 20)),
+		// stopped long ago
+		charge("Old Gym", 9900, day(2025, 10, 1)),
+		charge("Old Gym", 9900, day(2025, 11, 1)),
+		charge("Old Gym", 9900, day(2025, 12, 1)),
+	}
+	expenses := []models.Expense{{ID: uuid.New(), Name: "Spotify", AmountCents: 2190, Currency: "BRL"}}
+
+	report := detectSubscriptions(charges, expenses, "BRL", today)
+	if len(report.Candidates) != 1 || len(report.Known) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	netflix := report.Candidates[0]
+	if netflix.Key != "netflix com|BRL" || netflix.Frequency != "monthly" || netflix.AmountCents != 4490 || netflix.Occurrences != 5 {
+		t.Errorf("unexpected candidate %+v", netflix)
+	}
+	if !netflix.NextDate.Equal(day(2026, 7, 5)) {
+		t.Errorf("unexpected next date %s", netflix.NextDate)
+	}
+	spotify := report.Known[0]
+	if spotify.ExpenseID == nil || *spotify.ExpenseID != expenses[0].ID {
+		t.Fatalf("expected spotify linked to its expense: %+v", spotify)
+	}
+	if spotify.PriceIncrease == nil || spotify.PriceIncrease.FromCents != 2190 || spotify.PriceIncrease.ToCents != 2390 || spotify.PriceIncrease.Percent != 9.13 {
+		t.Errorf("unexpected price increase %+v", spotify.PriceIncrease)
+	}
+}
+
+func TestDetectSubscriptionsYearly(t *testing.T) {
+	charges := []repository.ChargeRow{
+		charge("JetBrains", 89000, day(2025, 3, 14)),
+		charge("JetBrains", 89000, day(2026, 3, 14)),
+	}
+	report := detectSubscriptions(charges, nil, "BRL", day(2026, 6, 1))
+	if len(report.Candidates) != 1 || report.Candidates[0].Frequency != "yearly" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
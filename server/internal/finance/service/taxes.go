@@ -0,0 +1,468 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var taxRegimes = map[string]bool{"carne_leao": true, "simples": true, "mei": true}
+
+var simplesAnnexes = map[string]bool{"I": true, "II": true, "III": true, "IV": true, "V": true}
+
+// taxableIncomeCategories are the income categories, with their subcategories, counted as revenue.
+// Salary is taxed at source and other income is not earned by the business.
+var taxableIncomeCategories = map[string]bool{"freelance": true, "saas": true, "business": true}
+
+type CreateTaxTableInput struct {
+	Regime     string
+	Annex      string
+	ValidFrom  time.Time
+	Brackets   []models.TaxBracket
+	FixedCents int64
+	Reduction  models.TaxReduction
+	Notes      string
+}
+
+type UpdateTaxTableInput struct {
+	ValidFrom   *time.Time
+	Brackets    []models.TaxBracket
+	BracketsSet bool
+	FixedCents  *int64
+	Reduction   *models.TaxReduction
+	Notes       *string
+}
+
+// TaxMonth is the estimate for one month. BaseCents is what the rate applies to: income minus
+// deductible expenses for carnê-leão, revenue for Simples Nacional and MEI. Rate is the nominal rate
+// of the bracket for carnê-leão and the effective rate for Simples Nacional.
+type TaxMonth struct {
+	Month           int       `json:"month"`
+	IncomeCents     int64     `json:"incomeCents"`
+	DeductibleCents int64     `json:"deductibleCents"`
+	BaseCents       int64     `json:"baseCents"`
+	Revenue12mCents int64     `json:"revenue12mCents,omitempty"`
+	Bracket         int       `json:"bracket,omitempty"`
+	Rate            float64   `json:"rate"`
+	TaxCents        int64     `json:"taxCents"`
+	DueDate         time.Time `json:"dueDate"`
+}
+
+// TaxSummary is a year of estimates in BRL under the configured regime, with the yearly totals and
+// the income per category the IRPF declaration asks for.
+type TaxSummary struct {
+	Year             int              `json:"year"`
+	Regime           string           `json:"regime"`
+	Annex            string           `json:"annex,omitempty"`
+	Currency         string           `json:"currency"`
+	Months           []TaxMonth       `json:"months"`
+	IncomeCents      int64            `json:"incomeCents"`
+	DeductibleCents  int64            `json:"deductibleCents"`
+	BaseCents        int64            `json:"baseCents"`
+	TaxCents         int64            `json:"taxCents"`
+	IncomeByCategory map[string]int64 `json:"incomeByCategory"`
+	MissingRates     []string         `json:"missingRates,omitempty"`
+}
+
+// TaxEstimate computes the monthly tax due for year from income transactions in the taxable
+// categories and, for carnê-leão, expense transactions marked deductible. Foreign-currency amounts
+// are converted to BRL on the transaction date.
+func (s *Service) TaxEstimate(ctx context.Context, year int) (*TaxSummary, error) {
+	if year <= 0 {
+		year = time.Now().UTC().Year()
+	}
+	settings, err := s.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if settings.TaxRegime == "" {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Configure a tax regime in the finance settings.")
+	}
+	if settings.TaxRegime == "simples" && settings.TaxAnnex == "" {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Configure the Simples Nacional annex in the finance settings.")
+	}
+	tables, err := s.repo.ListTaxTables(ctx, settings.TaxRegime)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load tax tables.", err)
+	}
+	// Simples Nacional rates depend on the revenue of the previous 12 months.
+	from := time.Date(year-1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	txs, err := s.ListTransactions(ctx, TransactionFilter{From: &from, To: &to})
+	if err != nil {
+		return nil, err
+	}
+	idx, err := s.categoryIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conv, err := s.newConverterTo(ctx, "BRL", to)
+	if err != nil {
+		return nil, err
+	}
+
+	in := taxInput{year: year}
+	byCategory := map[string]int64{}
+	for _, tx := range txs {
+		i := (tx.Date.Year()-from.Year())*12 + int(tx.Date.Month()) - 1
+		if i < 0 || i >= len(in.income) {
+			continue
+		}
+		amount := conv.convert(tx.AmountCents, tx.Currency, tx.Date)
+		switch tx.Type {
+		case "income":
+			category := idx.root("income", tx.Category)
+			if !taxableIncomeCategories[category] {
+				continue
+			}
+			in.income[i] += amount
+			if i >= 12 {
+				byCategory[category] += amount
+			}
+		case "expense":
+			if tx.Deductible && i >= 12 {
+				in.deductible[i-12] += amount
+			}
+		}
+	}
+	months, err := estimateTaxes(settings, tables, in)
+	if err != nil {
+		return nil, err
+	}
+	out := &TaxSummary{
+		Year:             year,
+		Regime:           settings.TaxRegime,
+		Currency:         "BRL",
+		Months:           months,
+		IncomeByCategory: byCategory,
+		MissingRates:     conv.missingCurrencies(),
+	}
+	if settings.TaxRegime == "simples" {
+		out.Annex = settings.TaxAnnex
+	}
+	for _, m := range months {
+		out.IncomeCents += m.IncomeCents
+		out.DeductibleCents += m.DeductibleCents
+		out.BaseCents += m.BaseCents
+		out.TaxCents += m.TaxCents
+	}
+	return out, nil
+}
+
+type taxInput struct {
+	year int
+	// income holds the taxable revenue of each month from January of the previous year.
+	income     [24]int64
+	deductible [12]int64
+}
+
+// estimateTaxes applies the table in effect on the first day of each month. Carnê-leão is due on the
+// last business day of the next month and the Simples Nacional and MEI DAS on the 20th, weekends
+// only being taken into account.
+func estimateTaxes(settings *models.FinanceSettings, tables []models.TaxTable, in taxInput) ([]TaxMonth, error) {
+	months := make([]TaxMonth, 0, 12)
+	for m := 0; m < 12; m++ {
+		start := time.Date(in.year, time.Month(m+1), 1, 0, 0, 0, 0, time.UTC)
+		table := taxTableOn(tables, settings.TaxRegime, settings.TaxAnnex, start)
+		if table == nil {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, fmt.Sprintf("No %s tax table is in effect on %s.", settings.TaxRegime, start.Format("2006-01-02")))
+		}
+		brackets, err := parseTaxBrackets(table.Brackets)
+		if err != nil {
+			return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to read tax table.", err)
+		}
+		if len(brackets) == 0 && settings.TaxRegime != "mei" {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "The tax table in effect has no brackets.")
+		}
+		row := TaxMonth{
+			Month:           m + 1,
+			IncomeCents:     in.income[12+m],
+			DeductibleCents: in.deductible[m],
+			DueDate:         adjustForWeekend(time.Date(in.year, time.Month(m+2), 20, 0, 0, 0, 0, time.UTC), weekendAdjustNext),
+		}
+		switch settings.TaxRegime {
+		case "carne_leao":
+			row.BaseCents = max(row.IncomeCents-row.DeductibleCents, 0)
+			i := taxBracketFor(brackets, row.BaseCents)
+			b := brackets[i]
+			row.Bracket, row.Rate = i+1, b.Rate
+			row.TaxCents = max(int64(math.Round(float64(row.BaseCents)*b.Rate/100))-b.DeductionCents, 0)
+			row.TaxCents = reduceTax(table.Reduction, row.IncomeCents, row.TaxCents)
+			row.DueDate = adjustForWeekend(time.Date(in.year, time.Month(m+3), 0, 0, 0, 0, 0, time.UTC), weekendAdjustPrevious)
+		case "simples":
+			row.BaseCents = row.IncomeCents
+			for _, v := range in.income[m : 12+m] {
+				row.Revenue12mCents += v
+			}
+			rbt12 := row.Revenue12mCents
+			if active := activeMonths(in.income[:12+m]); rbt12 == 0 {
+				// First month of activity: the month's revenue stands for the whole year.
+				rbt12 = row.IncomeCents * 12
+			} else if active < 12 {
+				// Less than a year of activity: the average of the months so far is annualised.
+				rbt12 = rbt12 * 12 / int64(active)
+			}
+			i := taxBracketFor(brackets, rbt12)
+			if settings.TaxBracket > 0 {
+				if settings.TaxBracket > len(brackets) {
+					return nil, apperrors.Invalid(apperrors.CodeInternal, fmt.Sprintf("Annex %s has no bracket %d.", settings.TaxAnnex, settings.TaxBracket))
+				}
+				i = settings.TaxBracket - 1
+			}
+			b := brackets[i]
+			row.Bracket, row.Rate = i+1, b.Rate
+			if rbt12 > 0 {
+				effective := (float64(rbt12)*b.Rate/100 - float64(b.DeductionCents)) / float64(rbt12) * 100
+				row.Rate = math.Round(max(effective, 0)*10000) / 10000
+			}
+			row.TaxCents = int64(math.Round(float64(row.BaseCents) * row.Rate / 100))
+		case "mei":
+			row.BaseCents = row.IncomeCents
+			row.TaxCents = table.FixedCents
+		}
+		months = append(months, row)
+	}
+	return months, nil
+}
+
+// activeMonths counts the months of the last 12 in income since the first one with revenue.
+func activeMonths(income []int64) int {
+	for i, v := range income {
+		if v > 0 {
+			return min(len(income)-i, 12)
+		}
+	}
+	return 0
+}
+
+// reduceTax applies the carnê-leão reduction of the table to the tax due on income.
+func reduceTax(r models.TaxReduction, income, tax int64) int64 {
+	switch {
+	case r.ExemptUpToCents > 0 && income <= r.ExemptUpToCents:
+		return 0
+	case income <= r.PhaseOutUpToCents:
+		reduction := r.BaseCents - int64(math.Round(float64(income)*r.Rate/100))
+		return tax - min(max(reduction, 0), tax)
+	}
+	return tax
+}
+
+// taxTableOn returns the latest table of the regime and annex valid on date, or nil.
+func taxTableOn(tables []models.TaxTable, regime, annex string, date time.Time) *models.TaxTable {
+	if regime != "simples" {
+		annex = ""
+	}
+	var found *models.TaxTable
+	for i := range tables {
+		t := &tables[i]
+		if t.Regime != regime || t.Annex != annex || t.ValidFrom.After(date) {
+			continue
+		}
+		if found == nil || t.ValidFrom.After(found.ValidFrom) {
+			found = t
+		}
+	}
+	return found
+}
+
+// taxBracketFor returns the index of the first bracket covering amount, or the last one.
+func taxBracketFor(brackets []models.TaxBracket, amount int64) int {
+	for i, b := range brackets {
+		if b.UpToCents == 0 || amount <= b.UpToCents {
+			return i
+		}
+	}
+	return len(brackets) - 1
+}
+
+func parseTaxBrackets(raw datatypes.JSON) ([]models.TaxBracket, error) {
+	var out []models.TaxBracket
+	if len(raw) == 0 {
+		return out, nil
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *Service) ListTaxTables(ctx context.Context, regime string) ([]models.TaxTable, error) {
+	rows, err := s.repo.ListTaxTables(ctx, strings.TrimSpace(strings.ToLower(regime)))
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load tax tables.", err)
+	}
+	return rows, nil
+}
+
+func (s *Service) GetTaxTable(ctx context.Context, id uuid.UUID) (*models.TaxTable, error) {
+	row, err := s.repo.FindTaxTable(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound(apperrors.CodeInternal, "Tax table not found.")
+		}
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load tax table.", err)
+	}
+	return row, nil
+}
+
+func (s *Service) CreateTaxTable(ctx context.Context, in CreateTaxTableInput) (*models.TaxTable, error) {
+	regime := strings.TrimSpace(strings.ToLower(in.Regime))
+	if !taxRegimes[regime] {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Regime must be carne_leao, simples or mei.")
+	}
+	annex := strings.TrimSpace(strings.ToUpper(in.Annex))
+	if regime == "simples" && !simplesAnnexes[annex] {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Annex must be I, II, III, IV or V.")
+	}
+	if regime != "simples" {
+		annex = ""
+	}
+	if in.ValidFrom.IsZero() {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Valid from date is required.")
+	}
+	row := &models.TaxTable{
+		ID:         uuid.New(),
+		Regime:     regime,
+		Annex:      annex,
+		ValidFrom:  dateOnly(in.ValidFrom),
+		FixedCents: in.FixedCents,
+		Reduction:  in.Reduction,
+		Notes:      strings.TrimSpace(in.Notes),
+	}
+	if err := setTaxBrackets(row, in.Brackets); err != nil {
+		return nil, err
+	}
+	if err := checkTaxReduction(row); err != nil {
+		return nil, err
+	}
+	if err := s.checkTaxTablePeriod(ctx, row); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateTaxTable(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to create tax table.", err)
+	}
+	return row, nil
+}
+
+// UpdateTaxTable edits the rates or validity of a table. Regime and annex are fixed.
+func (s *Service) UpdateTaxTable(ctx context.Context, id uuid.UUID, in UpdateTaxTableInput) (*models.TaxTable, error) {
+	row, err := s.GetTaxTable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if in.ValidFrom != nil {
+		row.ValidFrom = dateOnly(*in.ValidFrom)
+		if err := s.checkTaxTablePeriod(ctx, row); err != nil {
+			return nil, err
+		}
+	}
+	if in.FixedCents != nil {
+		row.FixedCents = *in.FixedCents
+	}
+	if in.Reduction != nil {
+		row.Reduction = *in.Reduction
+		if err := checkTaxReduction(row); err != nil {
+			return nil, err
+		}
+	}
+	if in.Notes != nil {
+		row.Notes = strings.TrimSpace(*in.Notes)
+	}
+	brackets := in.Brackets
+	if !in.BracketsSet {
+		if brackets, err = parseTaxBrackets(row.Brackets); err != nil {
+			return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to read tax table.", err)
+		}
+	}
+	if err := setTaxBrackets(row, brackets); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveTaxTable(ctx, row); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to update tax table.", err)
+	}
+	return row, nil
+}
+
+func (s *Service) DeleteTaxTable(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.DeleteTaxTable(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound(apperrors.CodeInternal, "Tax table not found.")
+		}
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to delete tax table.", err)
+	}
+	return nil
+}
+
+func (s *Service) checkTaxTablePeriod(ctx context.Context, row *models.TaxTable) error {
+	rows, err := s.repo.ListTaxTables(ctx, row.Regime)
+	if err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to load tax tables.", err)
+	}
+	for _, other := range rows {
+		if other.ID != row.ID && other.Annex == row.Annex && other.ValidFrom.Equal(row.ValidFrom) {
+			return apperrors.Invalid(apperrors.CodeInternal, fmt.Sprintf("A %s table already starts on %s.", row.Regime, row.ValidFrom.Format("2006-01-02")))
+		}
+	}
+	return nil
+}
+
+// setTaxBrackets validates the table and stores its brackets sorted by limit. Only the last bracket
+// may be unbounded; MEI tables have no brackets but a fixed amount.
+func setTaxBrackets(row *models.TaxTable, brackets []models.TaxBracket) error {
+	if row.Regime == "mei" {
+		if row.FixedCents <= 0 {
+			return apperrors.Invalid(apperrors.CodeInternal, "MEI tables need the fixed monthly amount.")
+		}
+		brackets = []models.TaxBracket{}
+	} else if len(brackets) == 0 {
+		return apperrors.Invalid(apperrors.CodeInternal, "At least one bracket is required.")
+	}
+	sorted := append([]models.TaxBracket{}, brackets...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].UpToCents == 0 || sorted[j].UpToCents == 0 {
+			return sorted[j].UpToCents == 0 && sorted[i].UpToCents != 0
+		}
+		return sorted[i].UpToCents < sorted[j].UpToCents
+	})
+	for i, b := range sorted {
+		if b.UpToCents < 0 || b.DeductionCents < 0 || b.Rate < 0 || b.Rate > 100 {
+			return apperrors.Invalid(apperrors.CodeInternal, "Brackets need non-negative limits and deductions and a rate between 0 and 100.")
+		}
+		if i > 0 && (sorted[i-1].UpToCents == 0 || b.UpToCents == sorted[i-1].UpToCents) {
+			return apperrors.Invalid(apperrors.CodeInternal, "Bracket limits must be distinct and only the last one may be unbounded.")
+		}
+	}
+	raw, err := json.Marshal(sorted)
+	if err != nil {
+		return apperrors.InternalCause(apperrors.CodeInternal, "Failed to encode tax brackets.", err)
+	}
+	row.Brackets = datatypes.JSON(raw)
+	return nil
+}
+
+// checkTaxReduction only allows reductions on carnê-leão tables, phasing out above the exempt income.
+func checkTaxReduction(row *models.TaxTable) error {
+	r := row.Reduction
+	if r == (models.TaxReduction{}) {
+		return nil
+	}
+	if row.Regime != "carne_leao" {
+		return apperrors.Invalid(apperrors.CodeInternal, "Only carnê-leão tables take a reduction.")
+	}
+	if r.ExemptUpToCents < 0 || r.BaseCents < 0 || r.Rate < 0 || r.Rate > 100 ||
+		(r.PhaseOutUpToCents != 0 && r.PhaseOutUpToCents < r.ExemptUpToCents) {
+		return apperrors.Invalid(apperrors.CodeInternal, "The reduction needs non-negative amounts, a rate between 0 and 100 and a phase-out limit above the exempt income.")
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/datatypes"
+)
+
+func taxTable(regime, annex string, validFrom time.Time, fixed int64, brackets ...models.TaxBracket) models.TaxTable {
+	raw, _ := json.Marshal(brackets)
+	return models.TaxTable{Regime: regime, Annex: annex, ValidFrom: validFrom, FixedCents: fixed, Brackets: datatypes.JSON(raw)}
+}
+
+func TestEstimateTaxesCarneLeao(t *testing.T) {
+	tables := []models.TaxTable{
+		taxTable("carne_leao", "", day(2024, 2, 1), 0,
+			models.TaxBracket{UpToCents: 225920},
+			models.TaxBracket{UpToCents: 282665, Rate: 7.5, DeductionCents: 16944},
+			models.TaxBracket{UpToCents: 375105, Rate: 15, DeductionCents: 38144},
+			models.TaxBracket{UpToCents: 466468, Rate: 22.5, DeductionCents: 66277},
+			models.TaxBracket{Rate: 27.5, DeductionCents: 89600}),
+		taxTable("carne_leao", "", day(2025, 5, 1), 0,
+			models.TaxBracket{UpToCents: 242880},
+			models.TaxBracket{UpToCents: 282665, Rate: 7.5, DeductionCents: 18216},
+			models.TaxBracket{UpToCents: 375105, Rate: 15, DeductionCents: 39416},
+			models.TaxBracket{UpToCents: 466468, Rate: 22.5, DeductionCents: 67549},
+			models.TaxBracket{Rate: 27.5, DeductionCents: 90873}),
+	}
+	in := taxInput{year: 2025}
+	in.income[12] = 200000
+	in.income[12+5] = 500000
+	in.deductible[5] = 100000
+	in.income[12+6] = 50000
+	in.deductible[6] = 80000
+
+	months, err := estimateTaxes(&models.FinanceSettings{TaxRegime: "carne_leao"}, tables, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jan, jun, jul := months[0], months[5], months[6]
+	if jan.TaxCents != 0 || jan.Bracket != 1 {
+		t.Fatalf("january should be exempt: %+v", jan)
+	}
+	if !jan.DueDate.Equal(day(2025, 2, 28)) {
+		t.Fatalf("january due %s", jan.DueDate.Format("2006-01-02"))
+	}
+	// 4.000,00 at 22,5% minus 675,49.
+	if jun.BaseCents != 400000 || jun.Bracket != 4 || jun.TaxCents != 22451 {
+		t.Fatalf("june: %+v", jun)
+	}
+	if jul.BaseCents != 0 || jul.TaxCents != 0 {
+		t.Fatalf("deductions above income must not go negative: %+v", jul)
+	}
+	// August 31st 2025 is a Sunday.
+	if !jul.DueDate.Equal(day(2025, 8, 29)) {
+		t.Fatalf("july due %s", jul.DueDate.Format("2006-01-02"))
+	}
+
+	reduced := taxTable("carne_leao", "", day(2026, 1, 1), 0,
+		models.TaxBracket{UpToCents: 242880},
+		models.TaxBracket{UpToCents: 282665, Rate: 7.5, DeductionCents: 18216},
+		models.TaxBracket{UpToCents: 375105, Rate: 15, DeductionCents: 39416},
+		models.TaxBracket{UpToCents: 466468, Rate: 22.5, DeductionCents: 67549},
+		models.TaxBracket{Rate: 27.5, DeductionCents: 90873})
+	reduced.Reduction = models.TaxReduction{ExemptUpToCents: 500000, PhaseOutUpToCents: 735000, BaseCents: 97862, Rate: 13.3145}
+	in = taxInput{year: 2026}
+	in.income[12] = 500000
+	in.income[12+1] = 600000
+	in.income[12+2] = 800000
+	months, err = estimateTaxes(&models.FinanceSettings{TaxRegime: "carne_leao"}, append(tables, reduced), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if months[0].TaxCents != 0 || months[0].Bracket != 5 {
+		t.Fatalf("income up to 5.000,00 is exempt from 2026: %+v", months[0])
+	}
+	// 6.000,00 at 27,5% minus 908,73 is 741,27, reduced by 978,62 − 13,3145% × 6.000,00 = 179,75.
+	if months[1].TaxCents != 56152 {
+		t.Fatalf("february: %+v", months[1])
+	}
+	// 8.000,00 at 27,5% minus 908,73, without reduction.
+	if months[2].TaxCents != 129127 {
+		t.Fatalf("march: %+v", months[2])
+	}
+}
+
+func TestEstimateTaxesSimples(t *testing.T) {
+	tables := []models.TaxTable{
+		taxTable("simples", "III", day(2018, 1, 1), 0,
+			models.TaxBracket{UpToCents: 18000000, Rate: 6},
+			models.TaxBracket{UpToCents: 36000000, Rate: 11.2, DeductionCents: 936000},
+			models.TaxBracket{UpToCents: 72000000, Rate: 13.5, DeductionCents: 1764000}),
+		taxTable("simples", "V", day(2018, 1, 1), 0, models.TaxBracket{UpToCents: 18000000, Rate: 15.5}),
+	}
+	settings := &models.FinanceSettings{TaxRegime: "simples", TaxAnnex: "III"}
+
+	in := taxInput{year: 2025}
+	for i := 0; i < 13; i++ {
+		in.income[i] = 2000000
+	}
+	months, err := estimateTaxes(settings, tables, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// RBT12 240.000,00: (240.000 × 11,2% − 9.360) / 240.000 = 7,3%.
+	jan := months[0]
+	if jan.Revenue12mCents != 24000000 || jan.Bracket != 2 || jan.Rate != 7.3 || jan.TaxCents != 146000 {
+		t.Fatalf("january: %+v", jan)
+	}
+	if !jan.DueDate.Equal(day(2025, 2, 20)) {
+		t.Fatalf("january due %s", jan.DueDate.Format("2006-01-02"))
+	}
+	// September 20th 2025 is a Saturday.
+	if !months[7].DueDate.Equal(day(2025, 9, 22)) {
+		t.Fatalf("august due %s", months[7].DueDate.Format("2006-01-02"))
+	}
+
+	settings.TaxBracket = 1
+	months, err = estimateTaxes(settings, tables, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if months[0].Bracket != 1 || months[0].TaxCents != 120000 {
+		t.Fatalf("pinned bracket: %+v", months[0])
+	}
+
+	// Without previous revenue the month is annualised.
+	start := taxInput{year: 2025}
+	start.income[12] = 1000000
+	months, err = estimateTaxes(&models.FinanceSettings{TaxRegime: "simples", TaxAnnex: "III"}, tables, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if months[0].Revenue12mCents != 0 || months[0].Rate != 6 || months[0].TaxCents != 60000 {
+		t.Fatalf("first month: %+v", months[0])
+	}
+
+	// With four months of activity their average is annualised: 80.000,00 / 4 × 12 = 240.000,00.
+	young := taxInput{year: 2025}
+	for i := 8; i < 13; i++ {
+		young.income[i] = 2000000
+	}
+	months, err = estimateTaxes(&models.FinanceSettings{TaxRegime: "simples", TaxAnnex: "III"}, tables, young)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if months[0].Revenue12mCents != 8000000 || months[0].Bracket != 2 || months[0].Rate != 7.3 {
+		t.Fatalf("four months of activity: %+v", months[0])
+	}
+}
+
+func TestEstimateTaxesMEI(t *testing.T) {
+	tables := []models.TaxTable{
+		taxTable("mei", "", day(2024, 1, 1), 7560),
+		taxTable("mei", "", day(2025, 1, 1), 8090),
+	}
+	months, err := estimateTaxes(&models.FinanceSettings{TaxRegime: "mei"}, tables, taxInput{year: 2025})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range months {
+		if m.TaxCents != 8090 {
+			t.Fatalf("month %d: %d", m.Month, m.TaxCents)
+		}
+	}
+	if _, err := estimateTaxes(&models.FinanceSettings{TaxRegime: "mei"}, tables, taxInput{year: 2023}); err == nil {
+		t.Fatal("expected an error without a table in effect")
+	}
+}
+
+func TestSetTaxBrackets(t *testing.T) {
+	row := &models.TaxTable{Regime: "carne_leao"}
+	err := setTaxBrackets(row, []models.TaxBracket{
+		{Rate: 27.5, DeductionCents: 89600},
+		{UpToCents: 282665, Rate: 7.5, DeductionCents: 16944},
+		{UpToCents: 225920},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseTaxBrackets(row.Brackets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].UpToCents != 225920 || got[1].UpToCents != 282665 || got[2].UpToCents != 0 {
+		t.Fatalf("brackets not sorted: %+v", got)
+	}
+	if err := setTaxBrackets(row, []models.TaxBracket{{Rate: 10}, {Rate: 20}}); err == nil {
+		t.Fatal("expected an error for two unbounded brackets")
+	}
+	if err := setTaxBrackets(row, nil); err == nil {
+		t.Fatal("expected an error without brackets")
+	}
+	if err := setTaxBrackets(&models.TaxTable{Regime: "mei"}, nil); err == nil {
+		t.Fatal("expected an error for a MEI table without amount")
+	}
+}
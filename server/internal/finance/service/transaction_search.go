@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/repository"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+const (
+	defaultTransactionPageSize = 50
+	maxTransactionPageSize     = 200
+)
+
+// transactionSorts maps the accepted sort names to their column and direction.
+var transactionSorts = map[string]repository.TransactionSort{
+	"date_desc":    {Column: "date", Desc: true},
+	"date_asc":     {Column: "date"},
+	"amount_desc":  {Column: "amount_cents", Desc: true},
+	"amount_asc":   {Column: "amount_cents"},
+	"created_desc": {Column: "created_at", Desc: true},
+	"created_asc":  {Column: "created_at"},
+}
+
+// TransactionSearch is a TransactionFilter plus paging. Cursor is the NextCursor of the previous page
+// and only valid with the same sort.
+type TransactionSearch struct {
+	TransactionFilter
+	Sort   string
+	Cursor string
+	Limit  int
+}
+
+type TransactionPage struct {
+	Items      []models.Transaction `json:"items"`
+	Total      int64                `json:"total"`
+	NextCursor string               `json:"nextCursor,omitempty"`
+}
+
+// SearchTransactions returns one page of matching transactions, newest first by default, with the
+// total number of matches.
+func (s *Service) SearchTransactions(ctx context.Context, in TransactionSearch) (*TransactionPage, error) {
+	sortName := strings.TrimSpace(strings.ToLower(in.Sort))
+	if sortName == "" {
+		sortName = "date_desc"
+	}
+	sort, ok := transactionSorts[sortName]
+	if !ok {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Sort must be date_desc, date_asc, amount_desc, amount_asc, created_desc or created_asc.")
+	}
+	var after *repository.TransactionCursor
+	if in.Cursor != "" {
+		c, err := decodeTransactionCursor(in.Cursor, sortName)
+		if err != nil {
+			return nil, apperrors.Invalid(apperrors.CodeInternal, "Cursor is invalid.")
+		}
+		after = c
+	}
+	if in.Type != "" && normalizeTransactionType(in.Type) == "" {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "Type must be income, expense or transfer.")
+	}
+	if in.MinAmountCents != nil && in.MaxAmountCents != nil && *in.MinAmountCents > *in.MaxAmountCents {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "minAmountCents must not exceed maxAmountCents.")
+	}
+	if in.From != nil && in.To != nil && in.To.Before(*in.From) {
+		return nil, apperrors.Invalid(apperrors.CodeInternal, "From must not be after to.")
+	}
+	limit := in.Limit
+	if limit <= 0 {
+		limit = defaultTransactionPageSize
+	}
+	if limit > maxTransactionPageSize {
+		limit = maxTransactionPageSize
+	}
+	rows, total, err := s.repo.SearchTransactions(ctx, in.repository(), sort, after, limit)
+	if err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load transactions.", err)
+	}
+	page := &TransactionPage{Items: rows, Total: total}
+	if page.Items == nil {
+		page.Items = []models.Transaction{}
+	}
+	if len(rows) == limit {
+		page.NextCursor = encodeTransactionCursor(sortName, rows[len(rows)-1])
+	}
+	return page, nil
+}
+
+func (f TransactionFilter) repository() repository.TransactionFilter {
+	out := repository.TransactionFilter{
+		Type:           strings.TrimSpace(strings.ToLower(f.Type)),
+		Query:          strings.TrimSpace(f.Query),
+		MinAmountCents: f.MinAmountCents,
+		MaxAmountCents: f.MaxAmountCents,
+		ProjectID:      f.ProjectID,
+		ContactID:      f.ContactID,
+		AccountID:      f.AccountID,
+		InvoiceID:      f.InvoiceID,
+		IncomeSourceID: f.IncomeSourceID,
+		ExpenseID:      f.ExpenseID,
+		GoalID:         f.GoalID,
+	}
+	if c := strings.TrimSpace(f.Currency); c != "" {
+		out.Currency = normalizeCurrency(c)
+	}
+	switch {
+	case f.From != nil || f.To != nil:
+		if f.From != nil {
+			d := dateOnly(*f.From)
+			out.From = &d
+		}
+		if f.To != nil {
+			d := dateOnly(*f.To).AddDate(0, 0, 1)
+			out.To = &d
+		}
+	case f.Year == 0 && f.Month == 0 && out.Query != "":
+	default:
+		out.Year, out.Month = parseYearMonth(f.Year, f.Month)
+	}
+	return out
+}
+
+// encodeTransactionCursor packs the sort name, the row's sort value and its id into an opaque token.
+func encodeTransactionCursor(sortName string, tx models.Transaction) string {
+	var value string
+	switch transactionSorts[sortName].Column {
+	case "amount_cents":
+		value = strconv.FormatInt(tx.AmountCents, 10)
+	case "created_at":
+		value = tx.CreatedAt.UTC().Format(time.RFC3339Nano)
+	default:
+		value = tx.Date.Format("2006-01-02")
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(sortName + "|" + value + "|" + tx.ID.String()))
+}
+
+func decodeTransactionCursor(token, sortName string) (*repository.TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 || parts[0] != sortName {
+		return nil, errors.New("cursor does not match sort")
+	}
+	id, err := uuid.Parse(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	c := &repository.TransactionCursor{ID: id}
+	switch transactionSorts[sortName].Column {
+	case "amount_cents":
+		c.Value, err = strconv.ParseInt(parts[1], 10, 64)
+	case "created_at":
+		c.Value, err = time.Parse(time.RFC3339Nano, parts[1])
+	default:
+		c.Value, err = time.Parse("2006-01-02", parts[1])
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func TestTransactionCursorRoundTrip(t *testing.T) {
+	tx := models.Transaction{ID: uuid.New(), AmountCents: 4590, Date: day(2026, 2, 14), CreatedAt: time.Date(2026, 2, 14, 9, 30, 1, 500, time.UTC)}
+	for sortName, want := range map[string]any{
+		"date_desc":   day(2026, 2, 14),
+		"amount_asc":  int64(4590),
+		"created_asc": tx.CreatedAt,
+	} {
+		c, err := decodeTransactionCursor(encodeTransactionCursor(sortName, tx), sortName)
+		if err != nil {
+			t.Fatalf("%s: %v", sortName, err)
+		}
+		if c.ID != tx.ID {
+			t.Errorf("%s: id %s", sortName, c.ID)
+		}
+		if got, ok := c.Value.(time.Time); ok {
+			if !got.Equal(want.(time.Time)) {
+				t.Errorf("%s: value %v", sortName, got)
+			}
+		} else if c.Value != want {
+			t.Errorf("%s: value %v", sortName, c.Value)
+		}
+	}
+	if _, err := decodeTransactionCursor(encodeTransactionCursor("date_desc", tx), "amount_desc"); err == nil {
+		t.Error("cursor from another sort should be rejected")
+	}
+}
+
+func TestTransactionFilterDateRange(t *testing.T) {
+	from, to := day(2026, 1, 10), day(2026, 1, 20)
+	f := TransactionFilter{From: &from, To: &to, Year: 2025, Month: 5}.repository()
+	if f.Year != 0 || !f.From.Equal(from) || !f.To.Equal(day(2026, 1, 21)) {
+		t.Fatalf("unexpected range %+v", f)
+	}
+	if f := (TransactionFilter{Query: "uber"}).repository(); f.Year != 0 || f.From != nil {
+		t.Fatalf("search without dates should cover all dates, got %+v", f)
+	}
+	if f := (TransactionFilter{}).repository(); f.Year == 0 || f.Month == 0 {
+		t.Fatal("default should be the current month")
+	}
+}
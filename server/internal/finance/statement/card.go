@@ -0,0 +1,147 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CardEntry is one credit card statement line. AmountCents is positive for charges and negative for
+// refunds and payments.
+type CardEntry struct {
+	Entry
+	Category          string `json:"category,omitempty"`
+	InstallmentNumber int    `json:"installmentNumber,omitempty"`
+	InstallmentTotal  int    `json:"installmentTotal,omitempty"`
+}
+
+// Installment formats the entry's installment as "3/10", or "" for single-payment purchases.
+func (e CardEntry) Installment() string {
+	if e.InstallmentTotal <= 1 {
+		return ""
+	}
+	return strconv.Itoa(e.InstallmentNumber) + "/" + strconv.Itoa(e.InstallmentTotal)
+}
+
+// IsPayment reports whether the line is the payment of a previous bill rather than a purchase.
+func (e CardEntry) IsPayment() bool {
+	d := NormalizeDescription(e.Description)
+	return strings.HasPrefix(d, "pagamento") || strings.HasPrefix(d, "payment")
+}
+
+// CardMapping extends CSVMapping with the columns card exports carry besides date, description and
+// amount. Amounts are read as charges-positive; set InvertSign for exports that list them negative.
+type CardMapping struct {
+	CSVMapping
+	CategoryColumn    string `json:"categoryColumn"`
+	InstallmentColumn string `json:"installmentColumn"`
+}
+
+// CardPresets are the column layouts of the card exports we see most often.
+var CardPresets = map[string]CardMapping{
+	// Nubank: date,title,amount (newer files add category) with ISO dates and dot decimals.
+	"nubank": {
+		CSVMapping: CSVMapping{
+			Delimiter:         ",",
+			DateColumn:        "date",
+			DateFormat:        "2006-01-02",
+			DescriptionColumn: "title",
+			AmountColumn:      "amount",
+			DecimalSeparator:  ".",
+		},
+		CategoryColumn: "category",
+	},
+	// Inter: Data;Lançamento;Categoria;Tipo;Valor with "Parcela 3/10" in Tipo and "R$ 1.234,56" values.
+	"inter": {
+		CSVMapping: CSVMapping{
+			Delimiter:         ";",
+			DateColumn:        "Data",
+			DateFormat:        "02/01/2006",
+			DescriptionColumn: "Lançamento",
+			AmountColumn:      "Valor",
+			DecimalSeparator:  ",",
+		},
+		CategoryColumn:    "Categoria",
+		InstallmentColumn: "Tipo",
+	},
+}
+
+var (
+	installmentLabeled = regexp.MustCompile(`(?i)parc(?:ela)?\.?\s*(\d{1,3})\s*(?:/|de)\s*(\d{1,3})`)
+	installmentTrailer = regexp.MustCompile(`(\d{1,3})\s*/\s*(\d{1,3})\s*$`)
+)
+
+// ParseInstallment extracts "3/10", "03/10", "Parcela 3 de 10" or "PARC 3/10" from s. A bare trailing
+// "n/m" only counts when it is a plausible installment (1 <= n <= m, m > 1).
+func ParseInstallment(s string) (number, total int, ok bool) {
+	return parseInstallment(s, true)
+}
+
+// ParseLabeledInstallment is ParseInstallment for free text such as descriptions, where a bare
+// trailing "01/12" is as likely a date: only the "Parcela"/"PARC" forms count.
+func ParseLabeledInstallment(s string) (number, total int, ok bool) {
+	return parseInstallment(s, false)
+}
+
+func parseInstallment(s string, bare bool) (number, total int, ok bool) {
+	s = strings.TrimSpace(s)
+	m := installmentLabeled.FindStringSubmatch(s)
+	if m == nil && bare {
+		m = installmentTrailer.FindStringSubmatch(s)
+	}
+	if m == nil {
+		return 0, 0, false
+	}
+	number, _ = strconv.Atoi(m[1])
+	total, _ = strconv.Atoi(m[2])
+	if number < 1 || total < 2 || number > total {
+		return 0, 0, false
+	}
+	return number, total, true
+}
+
+// ParseCardCSV reads a card statement export according to m.
+func ParseCardCSV(r io.Reader, m CardMapping) ([]CardEntry, error) {
+	if m.DateColumn == "" || m.DescriptionColumn == "" || m.AmountColumn == "" {
+		return nil, fmt.Errorf("date, description and amount columns are required")
+	}
+	rows, err := ReadCSV(r, m.Delimiter, m.NoHeader, m.SkipRows)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]CardEntry, 0, len(rows))
+	for _, row := range rows {
+		date, err := ParseDate(row.Lookup(m.DateColumn), m.DateFormat)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", row.Line, err)
+		}
+		amount, err := ParseAmountCents(row.Lookup(m.AmountColumn), m.DecimalSeparator)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", row.Line, err)
+		}
+		if m.InvertSign {
+			amount = -amount
+		}
+		desc := row.Lookup(m.DescriptionColumn)
+		entry := CardEntry{
+			Entry: Entry{
+				Date:        date,
+				AmountCents: amount,
+				Description: desc,
+				ExternalID:  row.Lookup(m.IDColumn),
+			},
+			Category: row.Lookup(m.CategoryColumn),
+		}
+		n, total, ok := ParseLabeledInstallment(desc)
+		if m.InstallmentColumn != "" {
+			n, total, ok = ParseInstallment(row.Lookup(m.InstallmentColumn))
+		}
+		if ok {
+			entry.InstallmentNumber, entry.InstallmentTotal = n, total
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
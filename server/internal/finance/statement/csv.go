@@ -0,0 +1,197 @@
+package statement
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSVMapping tells ParseCSV where each field lives. Columns are matched by header name
+// (case-insensitive) or, when no header matches, by 0-based index.
+type CSVMapping struct {
+	Delimiter         string `json:"delimiter"`
+	NoHeader          bool   `json:"noHeader"`
+	SkipRows          int    `json:"skipRows"`
+	DateColumn        string `json:"dateColumn"`
+	DateFormat        string `json:"dateFormat"`
+	DescriptionColumn string `json:"descriptionColumn"`
+	// AmountColumn holds a signed amount; alternatively DebitColumn/CreditColumn hold unsigned ones.
+	AmountColumn     string `json:"amountColumn"`
+	DebitColumn      string `json:"debitColumn"`
+	CreditColumn     string `json:"creditColumn"`
+	IDColumn         string `json:"idColumn"`
+	DecimalSeparator string `json:"decimalSeparator"`
+	// InvertSign flips amounts for exports that list spending as positive numbers.
+	InvertSign bool `json:"invertSign"`
+}
+
+// Row is a CSV record with its header, for callers that need columns beyond the mapped ones.
+type Row struct {
+	Line   int
+	Header []string
+	Fields []string
+}
+
+// Lookup returns the value of a column referenced by header name or 0-based index.
+func (r Row) Lookup(col string) string {
+	i := columnIndex(r.Header, col)
+	if i < 0 || i >= len(r.Fields) {
+		return ""
+	}
+	return strings.TrimSpace(r.Fields[i])
+}
+
+// ReadCSV splits content into rows, detecting the delimiter when delimiter is empty.
+func ReadCSV(r io.Reader, delimiter string, noHeader bool, skipRows int) ([]Row, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read csv: %w", err)
+	}
+	text := toUTF8(data)
+	lines := strings.SplitAfter(text, "\n")
+	if skipRows > 0 {
+		if skipRows >= len(lines) {
+			return nil, ErrNoEntries
+		}
+		text = strings.Join(lines[skipRows:], "")
+	}
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = detectDelimiter(text, delimiter)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse csv: %w", err)
+	}
+	var header []string
+	if !noHeader && len(records) > 0 {
+		header = records[0]
+		records = records[1:]
+	}
+	out := make([]Row, 0, len(records))
+	for i, rec := range records {
+		if isBlank(rec) {
+			continue
+		}
+		line := skipRows + i + 1
+		if !noHeader {
+			line++
+		}
+		out = append(out, Row{Line: line, Header: header, Fields: rec})
+	}
+	if len(out) == 0 {
+		return nil, ErrNoEntries
+	}
+	return out, nil
+}
+
+// ParseCSV reads a bank export according to m.
+func ParseCSV(r io.Reader, m CSVMapping) ([]Entry, error) {
+	if m.DateColumn == "" || m.DescriptionColumn == "" {
+		return nil, fmt.Errorf("date and description columns are required")
+	}
+	if m.AmountColumn == "" && m.DebitColumn == "" && m.CreditColumn == "" {
+		return nil, fmt.Errorf("an amount, debit or credit column is required")
+	}
+	rows, err := ReadCSV(r, m.Delimiter, m.NoHeader, m.SkipRows)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		date, err := ParseDate(row.Lookup(m.DateColumn), m.DateFormat)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", row.Line, err)
+		}
+		amount, err := mappedAmount(row, m)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", row.Line, err)
+		}
+		if m.InvertSign {
+			amount = -amount
+		}
+		out = append(out, Entry{
+			Date:        date,
+			AmountCents: amount,
+			Description: row.Lookup(m.DescriptionColumn),
+			ExternalID:  row.Lookup(m.IDColumn),
+		})
+	}
+	return out, nil
+}
+
+func mappedAmount(row Row, m CSVMapping) (int64, error) {
+	if m.AmountColumn != "" {
+		return ParseAmountCents(row.Lookup(m.AmountColumn), m.DecimalSeparator)
+	}
+	var amount int64
+	if v := row.Lookup(m.CreditColumn); m.CreditColumn != "" && v != "" {
+		c, err := ParseAmountCents(v, m.DecimalSeparator)
+		if err != nil {
+			return 0, err
+		}
+		amount += abs(c)
+	}
+	if v := row.Lookup(m.DebitColumn); m.DebitColumn != "" && v != "" {
+		d, err := ParseAmountCents(v, m.DecimalSeparator)
+		if err != nil {
+			return 0, err
+		}
+		amount -= abs(d)
+	}
+	return amount, nil
+}
+
+func columnIndex(header []string, col string) int {
+	col = strings.TrimSpace(col)
+	if col == "" {
+		return -1
+	}
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), col) {
+			return i
+		}
+	}
+	if i, err := strconv.Atoi(col); err == nil {
+		return i
+	}
+	return -1
+}
+
+func detectDelimiter(text, delimiter string) rune {
+	switch delimiter {
+	case ",":
+		return ','
+	case ";":
+		return ';'
+	case "\t", "tab":
+		return '\t'
+	}
+	first, _, _ := strings.Cut(text, "\n")
+	best, bestCount := ',', strings.Count(first, ",")
+	for _, c := range []rune{';', '\t'} {
+		if n := strings.Count(first, string(c)); n > bestCount {
+			best, bestCount = c, n
+		}
+	}
+	return best
+}
+
+func isBlank(rec []string) bool {
+	for _, f := range rec {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,110 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// LooksLikeOFX reports whether the content is an OFX/QFX document.
+func LooksLikeOFX(data []byte) bool {
+	head := strings.ToUpper(string(data[:min(len(data), 4096)]))
+	return strings.Contains(head, "OFXHEADER") || strings.Contains(head, "<OFX>")
+}
+
+// ParseOFX reads bank and credit card transactions from OFX 1.x (SGML, unclosed tags) and 2.x (XML).
+func ParseOFX(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read ofx: %w", err)
+	}
+	doc := toUTF8(data)
+	upper := strings.ToUpper(doc)
+	currency := ofxTag(doc, upper, "CURDEF")
+
+	var out []Entry
+	pos := 0
+	for {
+		start := strings.Index(upper[pos:], "<STMTTRN>")
+		if start < 0 {
+			break
+		}
+		start += pos + len("<STMTTRN>")
+		end := strings.Index(upper[start:], "</STMTTRN>")
+		if end < 0 {
+			// SGML files may omit the closing tag; the block then runs to the next transaction.
+			end = strings.Index(upper[start:], "<STMTTRN>")
+			if end < 0 {
+				end = len(upper) - start
+			}
+		}
+		block, blockUpper := doc[start:start+end], upper[start:start+end]
+		pos = start + end
+
+		entry, err := ofxEntry(block, blockUpper)
+		if err != nil {
+			return nil, err
+		}
+		entry.Currency = currency
+		out = append(out, entry)
+	}
+	if len(out) == 0 {
+		return nil, ErrNoEntries
+	}
+	return out, nil
+}
+
+func ofxEntry(block, upper string) (Entry, error) {
+	posted := ofxTag(block, upper, "DTPOSTED")
+	date, err := parseOFXDate(posted)
+	if err != nil {
+		return Entry{}, err
+	}
+	amount, err := ParseAmountCents(ofxTag(block, upper, "TRNAMT"), "")
+	if err != nil {
+		return Entry{}, err
+	}
+	name := ofxTag(block, upper, "NAME")
+	memo := ofxTag(block, upper, "MEMO")
+	desc := memo
+	switch {
+	case desc == "":
+		desc = name
+	case name != "" && !strings.Contains(strings.ToLower(memo), strings.ToLower(name)):
+		desc = name + " - " + memo
+	}
+	return Entry{
+		Date:        date,
+		AmountCents: amount,
+		Description: strings.TrimSpace(desc),
+		ExternalID:  ofxTag(block, upper, "FITID"),
+	}, nil
+}
+
+// ofxTag returns the text after <TAG> up to the next tag or line break.
+func ofxTag(doc, upper, tag string) string {
+	open := "<" + tag + ">"
+	i := strings.Index(upper, open)
+	if i < 0 {
+		return ""
+	}
+	v := doc[i+len(open):]
+	if j := strings.IndexAny(v, "<\r\n"); j >= 0 {
+		v = v[:j]
+	}
+	return strings.TrimSpace(v)
+}
+
+// parseOFXDate reads YYYYMMDD[HHMMSS[.XXX]][[offset:TZ]]; only the calendar date is kept.
+func parseOFXDate(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if len(v) < 8 {
+		return time.Time{}, fmt.Errorf("invalid ofx date %q", v)
+	}
+	t, err := time.Parse("20060102", v[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid ofx date %q", v)
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,175 @@
+// Package statement parses bank statement files (OFX and column-mapped CSV) into signed entries.
+package statement
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Entry is one statement line. AmountCents is signed: credits are positive, debits negative.
+type Entry struct {
+	Date        time.Time `json:"date"`
+	AmountCents int64     `json:"amountCents"`
+	Description string    `json:"description"`
+	ExternalID  string    `json:"externalId,omitempty"`
+	Currency    string    `json:"currency,omitempty"`
+}
+
+var ErrNoEntries = errors.New("statement has no entries")
+
+// Fingerprint identifies an entry by date, signed amount and normalized description, for files
+// without stable ids and for matching rows that were typed in by hand.
+func Fingerprint(date time.Time, signedCents int64, description string) string {
+	key := date.Format("2006-01-02") + "|" + strconv.FormatInt(signedCents, 10) + "|" + NormalizeDescription(description)
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
+
+// NormalizeDescription lowercases, strips accents and punctuation and collapses whitespace.
+func NormalizeDescription(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		if folded, ok := accentFold[r]; ok {
+			r = folded
+		}
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+var accentFold = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n',
+}
+
+// ParseAmountCents reads amounts such as "1.234,56", "-1234.56", "R$ 12,00" or "(12.00)".
+// decimalSep forces the decimal separator ("," or "."); empty means detect from the value.
+func ParseAmountCents(raw, decimalSep string) (int64, error) {
+	v := strings.TrimSpace(raw)
+	negative := false
+	if strings.HasPrefix(v, "(") && strings.HasSuffix(v, ")") {
+		negative = true
+		v = strings.TrimSuffix(strings.TrimPrefix(v, "("), ")")
+	}
+	var digits strings.Builder
+	for _, r := range v {
+		switch {
+		case r >= '0' && r <= '9', r == ',', r == '.':
+			digits.WriteRune(r)
+		case r == '-':
+			negative = !negative
+		}
+	}
+	v = digits.String()
+	if v == "" {
+		return 0, fmt.Errorf("invalid amount %q", raw)
+	}
+	sep := decimalSep
+	if sep == "" {
+		sep = detectDecimalSeparator(v)
+	}
+	intPart, fracPart := v, ""
+	if sep != "" {
+		if i := strings.LastIndex(v, sep); i >= 0 {
+			intPart, fracPart = v[:i], v[i+1:]
+		}
+	}
+	intPart = strings.NewReplacer(",", "", ".", "").Replace(intPart)
+	if strings.ContainsAny(fracPart, ",.") {
+		return 0, fmt.Errorf("invalid amount %q", raw)
+	}
+	// A third decimal rounds the cents half away from zero.
+	roundUp := false
+	if len(fracPart) > 2 {
+		roundUp = fracPart[2] >= '5'
+		fracPart = fracPart[:2]
+	}
+	for len(fracPart) < 2 {
+		fracPart += "0"
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	cents, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", raw)
+	}
+	if roundUp {
+		cents++
+	}
+	if negative {
+		cents = -cents
+	}
+	return cents, nil
+}
+
+// detectDecimalSeparator picks the last of "," and "." when both appear; a lone separator counts as
+// decimal only when one or two digits follow it, so "1.234" reads as a thousand.
+func detectDecimalSeparator(v string) string {
+	comma, dot := strings.LastIndex(v, ","), strings.LastIndex(v, ".")
+	switch {
+	case comma >= 0 && dot >= 0:
+		if comma > dot {
+			return ","
+		}
+		return "."
+	case comma >= 0:
+		if n := len(v) - comma - 1; n >= 1 && n <= 2 && strings.Count(v, ",") == 1 {
+			return ","
+		}
+	case dot >= 0:
+		if n := len(v) - dot - 1; n >= 1 && n <= 2 && strings.Count(v, ".") == 1 {
+			return "."
+		}
+	}
+	return ""
+}
+
+// ParseDate tries layout first (when set) and then the usual ISO and Brazilian formats.
+func ParseDate(raw, layout string) (time.Time, error) {
+	v := strings.TrimSpace(raw)
+	layouts := []string{"2006-01-02", "02/01/2006", "02/01/06", "2006/01/02", "02-01-2006", time.RFC3339}
+	if layout != "" {
+		layouts = append([]string{layout}, layouts...)
+	}
+	for _, l := range layouts {
+		if t, err := time.Parse(l, v); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", raw)
+}
+
+// toUTF8 decodes Latin-1/Windows-1252 content (common in Brazilian bank exports) when the bytes are
+// not valid UTF-8.
+func toUTF8(data []byte) string {
+	if utf8.Valid(data) {
+		return strings.TrimPrefix(string(data), "\ufeff")
+	}
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
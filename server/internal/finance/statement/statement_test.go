@@ -0,0 +1,191 @@
+package statement
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAmountCents(t *testing.T) {
+	cases := map[string]int64{
+		"1.234,56":  123456,
+		"-1234.56":  -123456,
+		"R$ 12,00":  1200,
+		"(12.00)":   -1200,
+		"1.234":     123400,
+		"-0,5":      -50,
+		"1,234.5":   123450,
+		"  42  ":    4200,
+		"R$ -3,99 ": -399,
+		"1.234,565": 123457,
+	}
+	for raw, want := range cases {
+		got, err := ParseAmountCents(raw, "")
+		if err != nil {
+			t.Fatalf("%q: %v", raw, err)
+		}
+		if got != want {
+			t.Fatalf("%q: got %d want %d", raw, got, want)
+		}
+	}
+	if got, _ := ParseAmountCents("1,5", ","); got != 150 {
+		t.Fatalf("forced separator: got %d", got)
+	}
+	if got, _ := ParseAmountCents("-0,125", ","); got != -13 {
+		t.Fatalf("third decimal: got %d", got)
+	}
+	if _, err := ParseAmountCents("abc", ""); err == nil {
+		t.Fatal("expected error for non-numeric amount")
+	}
+}
+
+const sgmlSample = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>BRL
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20260105120000[-3:BRT]
+<TRNAMT>-45.90
+<FITID>abc-1
+<MEMO>Padaria São João
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20260106
+<TRNAMT>1500.00
+<FITID>abc-2
+<NAME>ACME
+<MEMO>Salary
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+func TestParseOFXSGML(t *testing.T) {
+	if !LooksLikeOFX([]byte(sgmlSample)) {
+		t.Fatal("sample should be detected as OFX")
+	}
+	entries, err := ParseOFX(strings.NewReader(sgmlSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	e := entries[0]
+	if e.AmountCents != -4590 || e.ExternalID != "abc-1" || e.Currency != "BRL" || e.Date.Format("2006-01-02") != "2026-01-05" {
+		t.Fatalf("unexpected first entry %+v", e)
+	}
+	if entries[1].Description != "ACME - Salary" || entries[1].AmountCents != 150000 {
+		t.Fatalf("unexpected second entry %+v", entries[1])
+	}
+}
+
+func TestParseCSVWithMapping(t *testing.T) {
+	data := "Data;Histórico;Débito;Crédito\n" +
+		"05/01/2026;Mercado;123,45;\n" +
+		";;;\n" +
+		"06/01/2026;PIX recebido;;1.000,00\n"
+	entries, err := ParseCSV(strings.NewReader(data), CSVMapping{
+		DateColumn:        "data",
+		DescriptionColumn: "Histórico",
+		DebitColumn:       "Débito",
+		CreditColumn:      "3",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	if entries[0].AmountCents != -12345 || entries[1].AmountCents != 100000 {
+		t.Fatalf("unexpected amounts %+v", entries)
+	}
+	if entries[1].Date.Format("2006-01-02") != "2026-01-06" {
+		t.Fatalf("unexpected date %v", entries[1].Date)
+	}
+}
+
+func TestFingerprintIgnoresCaseAndAccents(t *testing.T) {
+	d := mustDate(t, "2026-01-05")
+	a := Fingerprint(d, -4590, "Padaria São João")
+	b := Fingerprint(d, -4590, "  PADARIA sao  joao. ")
+	if a != b {
+		t.Fatal("fingerprints should match after normalization")
+	}
+	if a == Fingerprint(d, 4590, "Padaria São João") {
+		t.Fatal("sign must be part of the fingerprint")
+	}
+}
+
+func mustDate(t *testing.T, v string) time.Time {
+	t.Helper()
+	d, err := ParseDate(v, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestParseInstallment(t *testing.T) {
+	cases := []struct {
+		in          string
+		number, tot int
+		ok          bool
+	}{
+		{"Parcela 3/10", 3, 10, true},
+		{"Loja X - Parcela 03/10", 3, 10, true},
+		{"PARC 2 DE 6", 2, 6, true},
+		{"Magazine 4/12", 4, 12, true},
+		{"Compra à vista", 0, 0, false},
+		{"Item 5/1", 0, 0, false},
+		{"Padaria 1/1", 0, 0, false},
+	}
+	for _, c := range cases {
+		n, tot, ok := ParseInstallment(c.in)
+		if ok != c.ok || n != c.number || tot != c.tot {
+			t.Fatalf("%q: got %d/%d %v", c.in, n, tot, ok)
+		}
+	}
+	if _, _, ok := ParseLabeledInstallment("UBER 01/12"); ok {
+		t.Fatal("a date-suffixed description is not an installment")
+	}
+	if n, tot, ok := ParseLabeledInstallment("Loja X - Parcela 03/10"); !ok || n != 3 || tot != 10 {
+		t.Fatalf("labeled: got %d/%d %v", n, tot, ok)
+	}
+}
+
+func TestParseCardCSVPresets(t *testing.T) {
+	nubank := "date,title,amount\n" +
+		"2026-01-03,Mercado Livre - Parcela 3/10,89.90\n" +
+		"2026-01-04,Pagamento recebido,-1500.00\n" +
+		"2026-01-05,UBER 01/12,23.40\n"
+	entries, err := ParseCardCSV(strings.NewReader(nubank), CardPresets["nubank"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[0].AmountCents != 8990 || entries[0].Installment() != "3/10" || entries[2].Installment() != "" {
+		t.Fatalf("unexpected nubank entries %+v", entries)
+	}
+	if !entries[1].IsPayment() {
+		t.Fatal("payment line should be detected")
+	}
+
+	inter := "Data;Lançamento;Categoria;Tipo;Valor\n" +
+		"05/01/2026;LOJA ABC;VESTUARIO;Parcela 2/4;R$ 1.250,00\n" +
+		"06/01/2026;PADARIA;RESTAURANTES;Compra à vista;R$ 12,50\n"
+	entries, err = ParseCardCSV(strings.NewReader(inter), CardPresets["inter"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries[0].AmountCents != 125000 || entries[0].Installment() != "2/4" || entries[0].Category != "VESTUARIO" {
+		t.Fatalf("unexpected inter entry %+v", entries[0])
+	}
+	if entries[1].Installment() != "" || entries[1].Date.Format("2006-01-02") != "2026-01-06" {
+		t.Fatalf("unexpected inter entry %+v", entries[1])
+	}
+}
@@ -32,10 +32,10 @@ func newAgentToolsHandler(app *App) *agentToolsHandler {
 		h.contactsH = newContactsHandler(app.Contacts, app.Finance)
 	}
 	if app.Finance != nil {
-		h.financeH = newFinanceHandler(app.Finance)
+		h.financeH = newFinanceHandler(app.Finance, app.Media)
 	}
 	if app.DevProjects != nil {
-		h.devH = newDevprojectHandler(app.DevProjects, app.Media)
+		h.devH = newDevprojectHandler(app.DevProjects, app.Media, app.Finance)
 		h.devProjects = app.DevProjects
 	}
 	if app.Presence != nil {
@@ -184,6 +184,14 @@ func (h *agentToolsHandler) financeCalendar(w http.ResponseWriter, r *http.Reque
 	h.financeH.calendar(w, r)
 }
 
+func (h *agentToolsHandler) financeForecast(w http.ResponseWriter, r *http.Request) {
+	if h.financeH == nil {
+		apperrors.WriteError(w, apperrors.InternalErr(apperrors.CodeInternal, "Finance service unavailable."))
+		return
+	}
+	h.financeH.forecast(w, r)
+}
+
 func (h *agentToolsHandler) listIncomeSources(w http.ResponseWriter, r *http.Request) {
 	if h.financeH == nil {
 		apperrors.WriteError(w, apperrors.InternalErr(apperrors.CodeInternal, "Finance service unavailable."))
@@ -215,3 +223,19 @@ func (h *agentToolsHandler) createIncomeSource(w http.ResponseWriter, r *http.Re
 	}
 	h.financeH.createIncomeSource(w, r)
 }
+
+func (h *agentToolsHandler) listSavingsGoals(w http.ResponseWriter, r *http.Request) {
+	if h.financeH == nil {
+		apperrors.WriteError(w, apperrors.InternalErr(apperrors.CodeInternal, "Finance service unavailable."))
+		return
+	}
+	h.financeH.listSavingsGoals(w, r)
+}
+
+func (h *agentToolsHandler) getSavingsGoal(w http.ResponseWriter, r *http.Request) {
+	if h.financeH == nil {
+		apperrors.WriteError(w, apperrors.InternalErr(apperrors.CodeInternal, "Finance service unavailable."))
+		return
+	}
+	h.financeH.getSavingsGoal(w, r)
+}
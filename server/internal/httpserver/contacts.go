@@ -153,6 +153,74 @@ func (h *contactsHandler) contactFinance(w http.ResponseWriter, r *http.Request)
 	apperrors.WriteJSON(w, http.StatusOK, out)
 }
 
+// contactPix generates a Pix charge for the contact; without amountCents it bills the contact's open
+// BRL receivables.
+func (h *contactsHandler) contactPix(w http.ResponseWriter, r *http.Request) {
+	if h.finance == nil {
+		apperrors.WriteError(w, apperrors.InternalErr(apperrors.CodeInternal, "Finance service unavailable."))
+		return
+	}
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body pixBody
+	if err := decodeOptionalJSON(r, &body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	out, err := h.finance.ContactPix(r.Context(), id, financesvc.PixInput(body))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, out)
+}
+
+// contactBalance returns the contact's shared-expense statement with running balances.
+func (h *contactsHandler) contactBalance(w http.ResponseWriter, r *http.Request) {
+	if h.finance == nil {
+		apperrors.WriteError(w, apperrors.InternalErr(apperrors.CodeInternal, "Finance service unavailable."))
+		return
+	}
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	out, err := h.finance.ContactStatement(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, out)
+}
+
+// createSettlement records a settle-up with the contact; a positive amountCents means they paid us.
+func (h *contactsHandler) createSettlement(w http.ResponseWriter, r *http.Request) {
+	if h.finance == nil {
+		apperrors.WriteError(w, apperrors.InternalErr(apperrors.CodeInternal, "Finance service unavailable."))
+		return
+	}
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body settlementBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.finance.CreateSettlement(r.Context(), id, financesvc.CreateSettlementInput(body))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, row)
+}
+
 type contactBody struct {
 	Name           string     `json:"name"`
 	DisplayName    string     `json:"displayName"`
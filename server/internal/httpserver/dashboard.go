@@ -1,14 +1,22 @@
 package httpserver
 
 import (
+	"log"
 	"net/http"
 
 	"github.com/woragis/management/backend/server/internal/apperrors"
 	devprojectsvc "github.com/woragis/management/backend/server/internal/devproject/service"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
 	mediarepo "github.com/woragis/management/backend/server/internal/media/repository"
 )
 
-func handleDashboard(projects *devprojectsvc.Service, media *mediarepo.Repository) http.HandlerFunc {
+// dashboardResponse adds this month's per-project P&L to the project dashboard when finance is enabled.
+type dashboardResponse struct {
+	*devprojectsvc.Dashboard
+	ProjectFinance []financesvc.ProjectPL `json:"projectFinance,omitempty"`
+}
+
+func handleDashboard(projects *devprojectsvc.Service, media *mediarepo.Repository, finance *financesvc.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var counter devprojectsvc.MediaCounter
 		if media != nil {
@@ -19,6 +27,15 @@ func handleDashboard(projects *devprojectsvc.Service, media *mediarepo.Repositor
 			apperrors.WriteError(w, err)
 			return
 		}
-		apperrors.WriteJSON(w, http.StatusOK, d)
+		out := dashboardResponse{Dashboard: d}
+		if finance != nil {
+			// The P&L is optional here: a finance failure leaves it out instead of failing the dashboard.
+			if pl, err := finance.ProjectsPL(r.Context(), financesvc.ProjectPLInput{Months: 1}); err != nil {
+				log.Printf("dashboard: project finance: %v", err)
+			} else {
+				out.ProjectFinance = pl.Projects
+			}
+		}
+		apperrors.WriteJSON(w, http.StatusOK, out)
 	}
 }
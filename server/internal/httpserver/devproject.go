@@ -8,16 +8,18 @@ import (
 	"github.com/google/uuid"
 	"github.com/woragis/management/backend/server/internal/apperrors"
 	devprojectsvc "github.com/woragis/management/backend/server/internal/devproject/service"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
 	mediasvc "github.com/woragis/management/backend/server/internal/media/service"
 )
 
 type devprojectHandler struct {
-	svc   *devprojectsvc.Service
-	media *mediasvc.Service
+	svc     *devprojectsvc.Service
+	media   *mediasvc.Service
+	finance *financesvc.Service
 }
 
-func newDevprojectHandler(svc *devprojectsvc.Service, media *mediasvc.Service) *devprojectHandler {
-	return &devprojectHandler{svc: svc, media: media}
+func newDevprojectHandler(svc *devprojectsvc.Service, media *mediasvc.Service, finance *financesvc.Service) *devprojectHandler {
+	return &devprojectHandler{svc: svc, media: media, finance: finance}
 }
 
 func (h *devprojectHandler) list(w http.ResponseWriter, r *http.Request) {
@@ -81,6 +83,29 @@ func (h *devprojectHandler) get(w http.ResponseWriter, r *http.Request) {
 	apperrors.WriteJSON(w, http.StatusOK, p)
 }
 
+// projectFinance returns the project's P&L; year, month and months select the range.
+func (h *devprojectHandler) projectFinance(w http.ResponseWriter, r *http.Request) {
+	if h.finance == nil {
+		apperrors.WriteError(w, apperrors.Unavailable(apperrors.CodeInternal, "Finance service unavailable."))
+		return
+	}
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeProjectGetV1HandlerPathIDInvalid, apperrors.MsgProjectGetV1HandlerPathIDInvalid))
+		return
+	}
+	if _, err := h.svc.GetByID(r.Context(), id); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	out, err := h.finance.ProjectPL(r.Context(), id, projectPLQuery(r))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, out)
+}
+
 func (h *devprojectHandler) create(w http.ResponseWriter, r *http.Request) {
 	var body createProjectBody
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
@@ -4,19 +4,22 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/woragis/management/backend/server/internal/apperrors"
 	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+	mediasvc "github.com/woragis/management/backend/server/internal/media/service"
 )
 
 type financeHandler struct {
-	svc *financesvc.Service
+	svc   *financesvc.Service
+	media *mediasvc.Service
 }
 
-func newFinanceHandler(svc *financesvc.Service) *financeHandler {
-	return &financeHandler{svc: svc}
+func newFinanceHandler(svc *financesvc.Service, media *mediasvc.Service) *financeHandler {
+	return &financeHandler{svc: svc, media: media}
 }
 
 func (h *financeHandler) dashboard(w http.ResponseWriter, r *http.Request) {
@@ -198,9 +201,17 @@ func (h *financeHandler) deleteExpense(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// listTransactions returns a page of transactions. Besides type, year, month, projectId, contactId
+// and accountId it takes from and to (YYYY-MM-DD), q (description or notes), minAmountCents,
+// maxAmountCents, currency, invoiceId, incomeSourceId, expenseId, goalId, sort, cursor and limit.
 func (h *financeHandler) listTransactions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
 	f := financesvc.TransactionFilter{
-		Type: r.URL.Query().Get("type"),
+		Type:     q.Get("type"),
+		From:     parseDateQuery(r, "from"),
+		To:       parseDateQuery(r, "to"),
+		Query:    q.Get("q"),
+		Currency: q.Get("currency"),
 	}
 	if y := r.URL.Query().Get("year"); y != "" {
 		if v, err := strconv.Atoi(y); err == nil {
@@ -222,12 +233,59 @@ func (h *financeHandler) listTransactions(w http.ResponseWriter, r *http.Request
 			f.ContactID = &id
 		}
 	}
-	rows, err := h.svc.ListTransactions(r.Context(), f)
+	if aid := r.URL.Query().Get("accountId"); aid != "" {
+		if id, err := uuid.Parse(aid); err == nil {
+			f.AccountID = &id
+		}
+	}
+	if iid := q.Get("invoiceId"); iid != "" {
+		if id, err := uuid.Parse(iid); err == nil {
+			f.InvoiceID = &id
+		}
+	}
+	if sid := q.Get("incomeSourceId"); sid != "" {
+		if id, err := uuid.Parse(sid); err == nil {
+			f.IncomeSourceID = &id
+		}
+	}
+	if eid := q.Get("expenseId"); eid != "" {
+		if id, err := uuid.Parse(eid); err == nil {
+			f.ExpenseID = &id
+		}
+	}
+	if gid := q.Get("goalId"); gid != "" {
+		if id, err := uuid.Parse(gid); err == nil {
+			f.GoalID = &id
+		}
+	}
+	if raw := strings.TrimSpace(q.Get("minAmountCents")); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "minAmountCents must be an integer."))
+			return
+		}
+		f.MinAmountCents = &v
+	}
+	if raw := strings.TrimSpace(q.Get("maxAmountCents")); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "maxAmountCents must be an integer."))
+			return
+		}
+		f.MaxAmountCents = &v
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	page, err := h.svc.SearchTransactions(r.Context(), financesvc.TransactionSearch{
+		TransactionFilter: f,
+		Sort:              q.Get("sort"),
+		Cursor:            q.Get("cursor"),
+		Limit:             limit,
+	})
 	if err != nil {
 		apperrors.WriteError(w, err)
 		return
 	}
-	apperrors.WriteJSON(w, http.StatusOK, rows)
+	apperrors.WriteJSON(w, http.StatusOK, page)
 }
 
 func (h *financeHandler) getTransaction(w http.ResponseWriter, r *http.Request) {
@@ -319,7 +377,12 @@ func (h *financeHandler) createInvoice(w http.ResponseWriter, r *http.Request) {
 		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
 		return
 	}
-	row, err := h.svc.CreateInvoice(r.Context(), body.toCreate())
+	in, err := body.toCreate()
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	row, err := h.svc.CreateInvoice(r.Context(), in)
 	if err != nil {
 		apperrors.WriteError(w, err)
 		return
@@ -338,7 +401,12 @@ func (h *financeHandler) updateInvoice(w http.ResponseWriter, r *http.Request) {
 		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
 		return
 	}
-	row, err := h.svc.UpdateInvoice(r.Context(), id, body.toUpdate())
+	in, err := body.toUpdate()
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	row, err := h.svc.UpdateInvoice(r.Context(), id, in)
 	if err != nil {
 		apperrors.WriteError(w, err)
 		return
@@ -482,16 +550,22 @@ func parseYearMonthQuery(r *http.Request) (int, int) {
 }
 
 type incomeSourceBody struct {
-	Name        string     `json:"name"`
-	Type        string     `json:"type"`
-	AmountCents int64      `json:"amountCents"`
-	Currency    string     `json:"currency"`
-	Frequency   string     `json:"frequency"`
-	DayOfMonth  int        `json:"dayOfMonth"`
-	ProjectID   *uuid.UUID `json:"projectId"`
-	ContactID   *uuid.UUID `json:"contactId"`
-	Active      bool       `json:"active"`
-	Notes       string     `json:"notes"`
+	Name          string     `json:"name"`
+	Type          string     `json:"type"`
+	AmountCents   int64      `json:"amountCents"`
+	Currency      string     `json:"currency"`
+	Frequency     string     `json:"frequency"`
+	DayOfMonth    int        `json:"dayOfMonth"`
+	AnchorDate    *time.Time `json:"anchorDate"`
+	Interval      int        `json:"interval"`
+	Weekday       *int       `json:"weekday"`
+	MonthOfYear   *int       `json:"monthOfYear"`
+	EndDate       *time.Time `json:"endDate"`
+	WeekendAdjust string     `json:"weekendAdjust"`
+	ProjectID     *uuid.UUID `json:"projectId"`
+	ContactID     *uuid.UUID `json:"contactId"`
+	Active        bool       `json:"active"`
+	Notes         string     `json:"notes"`
 }
 
 func (b incomeSourceBody) toCreate() financesvc.CreateIncomeSourceInput {
@@ -499,28 +573,52 @@ func (b incomeSourceBody) toCreate() financesvc.CreateIncomeSourceInput {
 }
 
 type incomeSourceUpdateBody struct {
-	Name        *string    `json:"name"`
-	Type        *string    `json:"type"`
-	AmountCents *int64     `json:"amountCents"`
-	Currency    *string    `json:"currency"`
-	Frequency   *string    `json:"frequency"`
-	DayOfMonth  *int       `json:"dayOfMonth"`
-	ProjectID   *uuid.UUID `json:"projectId"`
-	ContactID   *uuid.UUID `json:"contactId"`
-	Active      *bool      `json:"active"`
-	Notes       *string    `json:"notes"`
+	Name          *string    `json:"name"`
+	Type          *string    `json:"type"`
+	AmountCents   *int64     `json:"amountCents"`
+	Currency      *string    `json:"currency"`
+	Frequency     *string    `json:"frequency"`
+	DayOfMonth    *int       `json:"dayOfMonth"`
+	AnchorDate    *time.Time `json:"anchorDate"`
+	Interval      *int       `json:"interval"`
+	Weekday       *int       `json:"weekday"`
+	MonthOfYear   *int       `json:"monthOfYear"`
+	EndDate       *time.Time `json:"endDate"`
+	WeekendAdjust *string    `json:"weekendAdjust"`
+	ProjectID     *uuid.UUID `json:"projectId"`
+	ContactID     *uuid.UUID `json:"contactId"`
+	Active        *bool      `json:"active"`
+	Notes         *string    `json:"notes"`
 }
 
 func (b incomeSourceUpdateBody) toUpdate() financesvc.UpdateIncomeSourceInput {
 	in := financesvc.UpdateIncomeSourceInput{
-		Name:        b.Name,
-		Type:        b.Type,
-		AmountCents: b.AmountCents,
-		Currency:    b.Currency,
-		Frequency:   b.Frequency,
-		DayOfMonth:  b.DayOfMonth,
-		Active:      b.Active,
-		Notes:       b.Notes,
+		Name:          b.Name,
+		Type:          b.Type,
+		AmountCents:   b.AmountCents,
+		Currency:      b.Currency,
+		Frequency:     b.Frequency,
+		DayOfMonth:    b.DayOfMonth,
+		Interval:      b.Interval,
+		WeekendAdjust: b.WeekendAdjust,
+		Active:        b.Active,
+		Notes:         b.Notes,
+	}
+	if b.AnchorDate != nil {
+		in.AnchorDate = b.AnchorDate
+		in.AnchorDateSet = true
+	}
+	if b.Weekday != nil {
+		in.Weekday = b.Weekday
+		in.WeekdaySet = true
+	}
+	if b.MonthOfYear != nil {
+		in.MonthOfYear = b.MonthOfYear
+		in.MonthOfYearSet = true
+	}
+	if b.EndDate != nil {
+		in.EndDate = b.EndDate
+		in.EndDateSet = true
 	}
 	if b.ProjectID != nil {
 		in.ProjectID = b.ProjectID
@@ -534,17 +632,23 @@ func (b incomeSourceUpdateBody) toUpdate() financesvc.UpdateIncomeSourceInput {
 }
 
 type expenseBody struct {
-	Name        string     `json:"name"`
-	Category    string     `json:"category"`
-	AmountCents int64      `json:"amountCents"`
-	Currency    string     `json:"currency"`
-	Frequency   string     `json:"frequency"`
-	DayOfMonth  int        `json:"dayOfMonth"`
-	DueDate     *time.Time `json:"dueDate"`
-	AutoPay     bool       `json:"autoPay"`
-	ProjectID   *uuid.UUID `json:"projectId"`
-	Active      bool       `json:"active"`
-	Notes       string     `json:"notes"`
+	Name          string     `json:"name"`
+	Category      string     `json:"category"`
+	AmountCents   int64      `json:"amountCents"`
+	Currency      string     `json:"currency"`
+	Frequency     string     `json:"frequency"`
+	DayOfMonth    int        `json:"dayOfMonth"`
+	DueDate       *time.Time `json:"dueDate"`
+	AnchorDate    *time.Time `json:"anchorDate"`
+	Interval      int        `json:"interval"`
+	Weekday       *int       `json:"weekday"`
+	MonthOfYear   *int       `json:"monthOfYear"`
+	EndDate       *time.Time `json:"endDate"`
+	WeekendAdjust string     `json:"weekendAdjust"`
+	AutoPay       bool       `json:"autoPay"`
+	ProjectID     *uuid.UUID `json:"projectId"`
+	Active        bool       `json:"active"`
+	Notes         string     `json:"notes"`
 }
 
 func (b expenseBody) toCreate() financesvc.CreateExpenseInput {
@@ -552,35 +656,59 @@ func (b expenseBody) toCreate() financesvc.CreateExpenseInput {
 }
 
 type expenseUpdateBody struct {
-	Name        *string    `json:"name"`
-	Category    *string    `json:"category"`
-	AmountCents *int64     `json:"amountCents"`
-	Currency    *string    `json:"currency"`
-	Frequency   *string    `json:"frequency"`
-	DayOfMonth  *int       `json:"dayOfMonth"`
-	DueDate     *time.Time `json:"dueDate"`
-	AutoPay     *bool      `json:"autoPay"`
-	ProjectID   *uuid.UUID `json:"projectId"`
-	Active      *bool      `json:"active"`
-	Notes       *string    `json:"notes"`
+	Name          *string    `json:"name"`
+	Category      *string    `json:"category"`
+	AmountCents   *int64     `json:"amountCents"`
+	Currency      *string    `json:"currency"`
+	Frequency     *string    `json:"frequency"`
+	DayOfMonth    *int       `json:"dayOfMonth"`
+	DueDate       *time.Time `json:"dueDate"`
+	AnchorDate    *time.Time `json:"anchorDate"`
+	Interval      *int       `json:"interval"`
+	Weekday       *int       `json:"weekday"`
+	MonthOfYear   *int       `json:"monthOfYear"`
+	EndDate       *time.Time `json:"endDate"`
+	WeekendAdjust *string    `json:"weekendAdjust"`
+	AutoPay       *bool      `json:"autoPay"`
+	ProjectID     *uuid.UUID `json:"projectId"`
+	Active        *bool      `json:"active"`
+	Notes         *string    `json:"notes"`
 }
 
 func (b expenseUpdateBody) toUpdate() financesvc.UpdateExpenseInput {
 	in := financesvc.UpdateExpenseInput{
-		Name:        b.Name,
-		Category:    b.Category,
-		AmountCents: b.AmountCents,
-		Currency:    b.Currency,
-		Frequency:   b.Frequency,
-		DayOfMonth:  b.DayOfMonth,
-		AutoPay:     b.AutoPay,
-		Active:      b.Active,
-		Notes:       b.Notes,
+		Name:          b.Name,
+		Category:      b.Category,
+		AmountCents:   b.AmountCents,
+		Currency:      b.Currency,
+		Frequency:     b.Frequency,
+		DayOfMonth:    b.DayOfMonth,
+		Interval:      b.Interval,
+		WeekendAdjust: b.WeekendAdjust,
+		AutoPay:       b.AutoPay,
+		Active:        b.Active,
+		Notes:         b.Notes,
 	}
 	if b.DueDate != nil {
 		in.DueDate = b.DueDate
 		in.DueDateSet = true
 	}
+	if b.AnchorDate != nil {
+		in.AnchorDate = b.AnchorDate
+		in.AnchorDateSet = true
+	}
+	if b.Weekday != nil {
+		in.Weekday = b.Weekday
+		in.WeekdaySet = true
+	}
+	if b.MonthOfYear != nil {
+		in.MonthOfYear = b.MonthOfYear
+		in.MonthOfYearSet = true
+	}
+	if b.EndDate != nil {
+		in.EndDate = b.EndDate
+		in.EndDateSet = true
+	}
 	if b.ProjectID != nil {
 		in.ProjectID = b.ProjectID
 		in.ProjectSet = true
@@ -594,12 +722,18 @@ type transactionBody struct {
 	Currency       string     `json:"currency"`
 	Description    string     `json:"description"`
 	Date           time.Time  `json:"date"`
+	Category       string     `json:"category"`
 	IncomeSourceID *uuid.UUID `json:"incomeSourceId"`
 	ExpenseID      *uuid.UUID `json:"expenseId"`
 	ProjectID      *uuid.UUID `json:"projectId"`
 	ContactID      *uuid.UUID `json:"contactId"`
 	InvoiceID      *uuid.UUID `json:"invoiceId"`
+	AccountID      *uuid.UUID `json:"accountId"`
+	ToAccountID    *uuid.UUID `json:"toAccountId"`
+	ToAmountCents  *int64     `json:"toAmountCents"`
 	Notes          string     `json:"notes"`
+	Deductible     bool       `json:"deductible"`
+	GoalID         *uuid.UUID `json:"goalId"`
 }
 
 func (b transactionBody) toCreate() financesvc.CreateTransactionInput {
@@ -612,22 +746,31 @@ type transactionUpdateBody struct {
 	Currency        *string    `json:"currency"`
 	Description     *string    `json:"description"`
 	Date            *time.Time `json:"date"`
+	Category        *string    `json:"category"`
 	IncomeSourceID  *uuid.UUID `json:"incomeSourceId"`
 	ExpenseID       *uuid.UUID `json:"expenseId"`
 	ProjectID       *uuid.UUID `json:"projectId"`
 	ContactID       *uuid.UUID `json:"contactId"`
 	InvoiceID       *uuid.UUID `json:"invoiceId"`
+	AccountID       *uuid.UUID `json:"accountId"`
+	ToAccountID     *uuid.UUID `json:"toAccountId"`
+	ToAmountCents   *int64     `json:"toAmountCents"`
 	Notes           *string    `json:"notes"`
+	Deductible      *bool      `json:"deductible"`
+	GoalID          *uuid.UUID `json:"goalId"`
 }
 
 func (b transactionUpdateBody) toUpdate() financesvc.UpdateTransactionInput {
 	in := financesvc.UpdateTransactionInput{
-		Type:        b.Type,
-		AmountCents: b.AmountCents,
-		Currency:    b.Currency,
-		Description: b.Description,
-		Date:        b.Date,
-		Notes:       b.Notes,
+		Type:          b.Type,
+		AmountCents:   b.AmountCents,
+		Currency:      b.Currency,
+		Description:   b.Description,
+		Date:          b.Date,
+		Category:      b.Category,
+		ToAmountCents: b.ToAmountCents,
+		Notes:         b.Notes,
+		Deductible:    b.Deductible,
 	}
 	if b.IncomeSourceID != nil {
 		in.IncomeSourceID = b.IncomeSourceID
@@ -649,6 +792,18 @@ func (b transactionUpdateBody) toUpdate() financesvc.UpdateTransactionInput {
 		in.InvoiceID = b.InvoiceID
 		in.InvoiceSet = true
 	}
+	if b.AccountID != nil {
+		in.AccountID = b.AccountID
+		in.AccountSet = true
+	}
+	if b.ToAccountID != nil {
+		in.ToAccountID = b.ToAccountID
+		in.ToAccountSet = true
+	}
+	if b.GoalID != nil {
+		in.GoalID = b.GoalID
+		in.GoalSet = true
+	}
 	return in
 }
 
@@ -658,13 +813,28 @@ type invoiceBody struct {
 	DueDate      time.Time  `json:"dueDate"`
 	ClosedAt     *time.Time `json:"closedAt"`
 	TotalCents   int64      `json:"totalCents"`
-	PaidCents    int64      `json:"paidCents"`
-	Status       string     `json:"status"`
 	Notes        string     `json:"notes"`
+	PaidCents    *int64     `json:"paidCents"`
+	Status       *string    `json:"status"`
+}
+
+func (b invoiceBody) toCreate() (financesvc.CreateInvoiceInput, error) {
+	if b.PaidCents != nil || b.Status != nil {
+		return financesvc.CreateInvoiceInput{}, invoicePaidFieldsError()
+	}
+	return financesvc.CreateInvoiceInput{
+		Name:         b.Name,
+		CardLastFour: b.CardLastFour,
+		DueDate:      b.DueDate,
+		ClosedAt:     b.ClosedAt,
+		TotalCents:   b.TotalCents,
+		Notes:        b.Notes,
+	}, nil
 }
 
-func (b invoiceBody) toCreate() financesvc.CreateInvoiceInput {
-	return financesvc.CreateInvoiceInput(b)
+// invoicePaidFieldsError rejects paidCents and status, which follow the payments linked to the invoice.
+func invoicePaidFieldsError() error {
+	return apperrors.Invalid(apperrors.CodeInternal, "paidCents and status follow the payments linked to the invoice and cannot be set.")
 }
 
 type invoiceUpdateBody struct {
@@ -673,26 +843,27 @@ type invoiceUpdateBody struct {
 	DueDate      *time.Time `json:"dueDate"`
 	ClosedAt     *time.Time `json:"closedAt"`
 	TotalCents   *int64     `json:"totalCents"`
+	Notes        *string    `json:"notes"`
 	PaidCents    *int64     `json:"paidCents"`
 	Status       *string    `json:"status"`
-	Notes        *string    `json:"notes"`
 }
 
-func (b invoiceUpdateBody) toUpdate() financesvc.UpdateInvoiceInput {
+func (b invoiceUpdateBody) toUpdate() (financesvc.UpdateInvoiceInput, error) {
+	if b.PaidCents != nil || b.Status != nil {
+		return financesvc.UpdateInvoiceInput{}, invoicePaidFieldsError()
+	}
 	in := financesvc.UpdateInvoiceInput{
 		Name:         b.Name,
 		CardLastFour: b.CardLastFour,
 		DueDate:      b.DueDate,
 		TotalCents:   b.TotalCents,
-		PaidCents:    b.PaidCents,
-		Status:       b.Status,
 		Notes:        b.Notes,
 	}
 	if b.ClosedAt != nil {
 		in.ClosedAt = b.ClosedAt
 		in.ClosedAtSet = true
 	}
-	return in
+	return in, nil
 }
 
 type invoiceItemBody struct {
@@ -713,6 +884,7 @@ type budgetBody struct {
 	Month        int    `json:"month"`
 	Category     string `json:"category"`
 	PlannedCents int64  `json:"plannedCents"`
+	Rollover     bool   `json:"rollover"`
 	Notes        string `json:"notes"`
 }
 
@@ -725,6 +897,7 @@ type budgetUpdateBody struct {
 	Month        *int    `json:"month"`
 	Category     *string `json:"category"`
 	PlannedCents *int64  `json:"plannedCents"`
+	Rollover     *bool   `json:"rollover"`
 	Notes        *string `json:"notes"`
 }
 
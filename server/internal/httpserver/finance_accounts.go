@@ -0,0 +1,121 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+func (h *financeHandler) listAccounts(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.svc.ListAccounts(r.Context(), r.URL.Query().Get("archived") == "true")
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) getAccount(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	row, err := h.svc.GetAccount(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) createAccount(w http.ResponseWriter, r *http.Request) {
+	var body accountBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.CreateAccount(r.Context(), body.toCreate())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, row)
+}
+
+func (h *financeHandler) updateAccount(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body accountUpdateBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.UpdateAccount(r.Context(), id, body.toUpdate())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) deleteAccount(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	if err := h.svc.DeleteAccount(r.Context(), id); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// accountLedger returns the account's transactions with running balances; from and to are YYYY-MM-DD.
+func (h *financeHandler) accountLedger(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	res, err := h.svc.AccountLedger(r.Context(), id, parseDateQuery(r, "from"), parseDateQuery(r, "to"))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, res)
+}
+
+type accountBody struct {
+	Name                string     `json:"name"`
+	Type                string     `json:"type"`
+	Currency            string     `json:"currency"`
+	OpeningBalanceCents int64      `json:"openingBalanceCents"`
+	OpeningDate         *time.Time `json:"openingDate"`
+	Notes               string     `json:"notes"`
+}
+
+func (b accountBody) toCreate() financesvc.CreateAccountInput {
+	return financesvc.CreateAccountInput(b)
+}
+
+type accountUpdateBody struct {
+	Name                *string    `json:"name"`
+	Type                *string    `json:"type"`
+	Currency            *string    `json:"currency"`
+	OpeningBalanceCents *int64     `json:"openingBalanceCents"`
+	OpeningDate         *time.Time `json:"openingDate"`
+	Archived            *bool      `json:"archived"`
+	Notes               *string    `json:"notes"`
+}
+
+func (b accountUpdateBody) toUpdate() financesvc.UpdateAccountInput {
+	return financesvc.UpdateAccountInput(b)
+}
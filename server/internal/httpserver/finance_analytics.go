@@ -0,0 +1,32 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+// analytics returns monthly income, expense and category totals between ?from= and ?to= (default the
+// last 12 months) with year-over-year deltas and the ?top= largest merchants.
+func (h *financeHandler) analytics(w http.ResponseWriter, r *http.Request) {
+	in := financesvc.AnalyticsInput{
+		From: parseDateQuery(r, "from"),
+		To:   parseDateQuery(r, "to"),
+	}
+	if raw := r.URL.Query().Get("top"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "top must be an integer."))
+			return
+		}
+		in.TopMerchants = v
+	}
+	out, err := h.svc.Analytics(r.Context(), in)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,167 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+	mediasvc "github.com/woragis/management/backend/server/internal/media/service"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func (h *financeHandler) attachTransactionMedia(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	h.attach(w, r, financesvc.AttachmentOwner{Type: models.FinanceAttachmentTransaction, ID: id})
+}
+
+func (h *financeHandler) attachInvoiceMedia(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	h.attach(w, r, financesvc.AttachmentOwner{Type: models.FinanceAttachmentInvoice, ID: id})
+}
+
+func (h *financeHandler) attachInvoiceItemMedia(w http.ResponseWriter, r *http.Request) {
+	owner, ok := invoiceItemOwner(w, r)
+	if !ok {
+		return
+	}
+	h.attach(w, r, owner)
+}
+
+func (h *financeHandler) detachTransactionMedia(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	h.detach(w, r, financesvc.AttachmentOwner{Type: models.FinanceAttachmentTransaction, ID: id})
+}
+
+func (h *financeHandler) detachInvoiceMedia(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	h.detach(w, r, financesvc.AttachmentOwner{Type: models.FinanceAttachmentInvoice, ID: id})
+}
+
+func (h *financeHandler) detachInvoiceItemMedia(w http.ResponseWriter, r *http.Request) {
+	owner, ok := invoiceItemOwner(w, r)
+	if !ok {
+		return
+	}
+	h.detach(w, r, owner)
+}
+
+func invoiceItemOwner(w http.ResponseWriter, r *http.Request) (financesvc.AttachmentOwner, bool) {
+	invoiceID, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return financesvc.AttachmentOwner{}, false
+	}
+	itemID, err := parseUUID(r.PathValue("itemId"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid item id."))
+		return financesvc.AttachmentOwner{}, false
+	}
+	return financesvc.AttachmentOwner{Type: models.FinanceAttachmentInvoiceItem, ID: itemID, InvoiceID: invoiceID}, true
+}
+
+// attach accepts either a multipart upload in "file", which is stored as a private asset and
+// attached in one call, or a JSON body {"mediaId"} naming an existing asset, which becomes private.
+func (h *financeHandler) attach(w http.ResponseWriter, r *http.Request, owner financesvc.AttachmentOwner) {
+	if h.media == nil {
+		apperrors.WriteError(w, apperrors.InternalErr(apperrors.CodeInternal, "Media service unavailable."))
+		return
+	}
+	if err := h.svc.CheckAttachmentOwner(r.Context(), owner); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(105 << 20); err != nil {
+			apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid multipart form."))
+			return
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "File is required."))
+			return
+		}
+		defer func() { _ = file.Close() }()
+		asset, err := h.media.Upload(r.Context(), mediasvc.UploadInput{
+			Filename: header.Filename,
+			MimeType: header.Header.Get("Content-Type"),
+			AltText:  r.FormValue("altText"),
+			Private:  true,
+			Reader:   file,
+		})
+		if err != nil {
+			apperrors.WriteError(w, err)
+			return
+		}
+		if err := h.svc.AttachMedia(r.Context(), owner, asset.ID); err != nil {
+			_ = h.media.Delete(r.Context(), asset.ID)
+			apperrors.WriteError(w, err)
+			return
+		}
+		apperrors.WriteJSON(w, http.StatusCreated, asset)
+		return
+	}
+
+	var body struct {
+		MediaID uuid.UUID `json:"mediaId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	if body.MediaID == uuid.Nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Media id is required."))
+		return
+	}
+	existing, err := h.media.GetByID(r.Context(), body.MediaID)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	// Hide the asset before it is attached so a receipt is never public while linked to a record.
+	asset, err := h.media.MakePrivate(r.Context(), body.MediaID)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	if err := h.svc.AttachMedia(r.Context(), owner, body.MediaID); err != nil {
+		if !existing.Private {
+			_, _ = h.media.MakePublic(r.Context(), body.MediaID)
+		}
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, asset)
+}
+
+func (h *financeHandler) detach(w http.ResponseWriter, r *http.Request, owner financesvc.AttachmentOwner) {
+	mediaID, err := parseUUID(r.PathValue("mediaId"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid media id."))
+		return
+	}
+	if err := h.svc.DetachMedia(r.Context(), owner, mediaID); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
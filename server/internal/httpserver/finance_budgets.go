@@ -0,0 +1,39 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+func (h *financeHandler) budgetReport(w http.ResponseWriter, r *http.Request) {
+	year, month := parseYearMonthQuery(r)
+	rep, err := h.svc.BudgetReport(r.Context(), year, month)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rep)
+}
+
+func (h *financeHandler) copyBudgets(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		FromYear  int  `json:"fromYear"`
+		FromMonth int  `json:"fromMonth"`
+		ToYear    int  `json:"toYear"`
+		ToMonth   int  `json:"toMonth"`
+		Overwrite bool `json:"overwrite"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	res, err := h.svc.CopyBudgets(r.Context(), financesvc.CopyBudgetsInput(body))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, res)
+}
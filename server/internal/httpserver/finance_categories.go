@@ -0,0 +1,120 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+func (h *financeHandler) listCategories(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	rows, err := h.svc.ListCategories(r.Context(), q.Get("kind"), q.Get("archived") == "true")
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) getCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	row, err := h.svc.GetCategory(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) createCategory(w http.ResponseWriter, r *http.Request) {
+	var body categoryBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.CreateCategory(r.Context(), body.toCreate())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, row)
+}
+
+func (h *financeHandler) updateCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body categoryUpdateBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	in, err := body.toUpdate()
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.UpdateCategory(r.Context(), id, in)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) deleteCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	if err := h.svc.DeleteCategory(r.Context(), id); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type categoryBody struct {
+	Kind     string     `json:"kind"`
+	Slug     string     `json:"slug"`
+	Name     string     `json:"name"`
+	ParentID *uuid.UUID `json:"parentId"`
+	Color    string     `json:"color"`
+}
+
+func (b categoryBody) toCreate() financesvc.CreateCategoryInput {
+	return financesvc.CreateCategoryInput(b)
+}
+
+// categoryUpdateBody moves a category to the top level when parentId is sent as null.
+type categoryUpdateBody struct {
+	Name     *string         `json:"name"`
+	ParentID json.RawMessage `json:"parentId"`
+	Color    *string         `json:"color"`
+	Archived *bool           `json:"archived"`
+}
+
+func (b categoryUpdateBody) toUpdate() (financesvc.UpdateCategoryInput, error) {
+	in := financesvc.UpdateCategoryInput{
+		Name:     b.Name,
+		Color:    b.Color,
+		Archived: b.Archived,
+	}
+	if len(b.ParentID) > 0 {
+		in.ParentSet = true
+		if err := json.Unmarshal(b.ParentID, &in.ParentID); err != nil {
+			return in, err
+		}
+	}
+	return in, nil
+}
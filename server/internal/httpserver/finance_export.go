@@ -0,0 +1,70 @@
+package httpserver
+
+import (
+	"io"
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	"github.com/woragis/management/backend/server/internal/finance/export"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+// exportFinance streams the dataset in the path as ?format=csv|xlsx|json. It takes from and to
+// (YYYY-MM-DD) or year and month, plus the filters of the dataset's list endpoint: type, projectId,
+// contactId and accountId for transactions, status for invoices, and active for income sources and
+// expenses.
+func (h *financeHandler) exportFinance(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	year, month := 0, 0
+	if q.Get("year") != "" {
+		year, month = parseYearMonthQuery(r)
+		if q.Get("month") == "" {
+			month = 0
+		}
+	}
+	in := financesvc.ExportInput{
+		Dataset:    r.PathValue("dataset"),
+		Format:     q.Get("format"),
+		From:       parseDateQuery(r, "from"),
+		To:         parseDateQuery(r, "to"),
+		Year:       year,
+		Month:      month,
+		Type:       q.Get("type"),
+		Status:     q.Get("status"),
+		ActiveOnly: q.Get("active") == "true",
+	}
+	if pid := q.Get("projectId"); pid != "" {
+		if id, err := uuid.Parse(pid); err == nil {
+			in.ProjectID = &id
+		}
+	}
+	if cid := q.Get("contactId"); cid != "" {
+		if id, err := uuid.Parse(cid); err == nil {
+			in.ContactID = &id
+		}
+	}
+	if aid := q.Get("accountId"); aid != "" {
+		if id, err := uuid.Parse(aid); err == nil {
+			in.AccountID = &id
+		}
+	}
+	started := false
+	err := h.svc.Export(r.Context(), in, func(format export.Format, filename string) io.Writer {
+		started = true
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		w.WriteHeader(http.StatusOK)
+		return w
+	})
+	if err == nil {
+		return
+	}
+	if !started {
+		apperrors.WriteError(w, err)
+		return
+	}
+	log.Printf("finance export %s: %v", in.Dataset, err)
+}
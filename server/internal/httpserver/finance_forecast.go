@@ -0,0 +1,57 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+// forecast reads months, startBalanceCents and from (YYYY-MM-DD) from the query string; a POST body
+// may set the same fields plus oneOffs.
+func (h *financeHandler) forecast(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var body struct {
+		StartBalanceCents *int64                    `json:"startBalanceCents"`
+		From              *time.Time                `json:"from"`
+		Months            int                       `json:"months"`
+		OneOffs           []financesvc.ForecastItem `json:"oneOffs"`
+	}
+	if r.Method == http.MethodPost {
+		if err := decodeOptionalJSON(r, &body); err != nil {
+			apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+			return
+		}
+	}
+	in := financesvc.ForecastInput{
+		StartBalanceCents: body.StartBalanceCents,
+		From:              body.From,
+		Months:            body.Months,
+		OneOffs:           body.OneOffs,
+	}
+	if in.StartBalanceCents == nil {
+		if raw := strings.TrimSpace(q.Get("startBalanceCents")); raw != "" {
+			v, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "startBalanceCents must be an integer."))
+				return
+			}
+			in.StartBalanceCents = &v
+		}
+	}
+	if in.From == nil {
+		in.From = parseDateQuery(r, "from")
+	}
+	if in.Months == 0 {
+		in.Months, _ = strconv.Atoi(q.Get("months"))
+	}
+	res, err := h.svc.Forecast(r.Context(), in)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, res)
+}
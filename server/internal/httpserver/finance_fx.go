@@ -0,0 +1,143 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+func (h *financeHandler) getSettings(w http.ResponseWriter, r *http.Request) {
+	row, err := h.svc.GetSettings(r.Context())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) updateSettings(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ReportingCurrency *string `json:"reportingCurrency"`
+		BillingName       *string `json:"billingName"`
+		BillingTaxID      *string `json:"billingTaxId"`
+		BillingEmail      *string `json:"billingEmail"`
+		BillingAddress    *string `json:"billingAddress"`
+		PixKey            *string `json:"pixKey"`
+		PixMerchantName   *string `json:"pixMerchantName"`
+		PixMerchantCity   *string `json:"pixMerchantCity"`
+		TaxRegime         *string `json:"taxRegime"`
+		TaxAnnex          *string `json:"taxAnnex"`
+		TaxBracket        *int    `json:"taxBracket"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.UpdateSettings(r.Context(), financesvc.UpdateSettingsInput{
+		ReportingCurrency: body.ReportingCurrency,
+		BillingName:       body.BillingName,
+		BillingTaxID:      body.BillingTaxID,
+		BillingEmail:      body.BillingEmail,
+		BillingAddress:    body.BillingAddress,
+		PixKey:            body.PixKey,
+		PixMerchantName:   body.PixMerchantName,
+		PixMerchantCity:   body.PixMerchantCity,
+		TaxRegime:         body.TaxRegime,
+		TaxAnnex:          body.TaxAnnex,
+		TaxBracket:        body.TaxBracket,
+	})
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) listExchangeRates(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	rows, err := h.svc.ListExchangeRates(r.Context(), financesvc.ExchangeRateFilter{
+		Currency: q.Get("currency"),
+		From:     parseDateQuery(r, "from"),
+		To:       parseDateQuery(r, "to"),
+	})
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) createExchangeRate(w http.ResponseWriter, r *http.Request) {
+	var body exchangeRateBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.CreateExchangeRate(r.Context(), body.toCreate())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, row)
+}
+
+func (h *financeHandler) deleteExchangeRate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	if err := h.svc.DeleteExchangeRate(r.Context(), id); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *financeHandler) importExchangeRates(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid multipart form."))
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "File is required."))
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	res, err := h.svc.ImportExchangeRatesCSV(r.Context(), file)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, res)
+}
+
+type exchangeRateBody struct {
+	Date          time.Time `json:"date"`
+	BaseCurrency  string    `json:"baseCurrency"`
+	QuoteCurrency string    `json:"quoteCurrency"`
+	Rate          float64   `json:"rate"`
+}
+
+func (b exchangeRateBody) toCreate() financesvc.CreateExchangeRateInput {
+	return financesvc.CreateExchangeRateInput(b)
+}
+
+// parseDateQuery reads a YYYY-MM-DD query parameter; missing or malformed values yield nil.
+func parseDateQuery(r *http.Request, key string) *time.Time {
+	raw := strings.TrimSpace(r.URL.Query().Get(key))
+	if raw == "" {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,151 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+// listSavingsGoals returns goals with their progress; ?q= matches the name and ?archived=true includes
+// archived goals.
+func (h *financeHandler) listSavingsGoals(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	rows, err := h.svc.ListSavingsGoals(r.Context(), q.Get("archived") == "true", q.Get("q"))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) getSavingsGoal(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	row, err := h.svc.GetSavingsGoal(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) createSavingsGoal(w http.ResponseWriter, r *http.Request) {
+	var body savingsGoalBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.CreateSavingsGoal(r.Context(), financesvc.CreateSavingsGoalInput(body))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, row)
+}
+
+func (h *financeHandler) updateSavingsGoal(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body savingsGoalUpdateBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	in, err := body.toUpdate()
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.UpdateSavingsGoal(r.Context(), id, in)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) deleteSavingsGoal(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	if err := h.svc.DeleteSavingsGoal(r.Context(), id); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *financeHandler) listSavingsGoalContributions(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	rows, err := h.svc.SavingsGoalContributions(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+type savingsGoalBody struct {
+	Name        string     `json:"name"`
+	TargetCents int64      `json:"targetCents"`
+	Currency    string     `json:"currency"`
+	StartDate   *time.Time `json:"startDate"`
+	Deadline    *time.Time `json:"deadline"`
+	AccountID   *uuid.UUID `json:"accountId"`
+	Category    string     `json:"category"`
+	Notes       string     `json:"notes"`
+}
+
+// savingsGoalUpdateBody clears the deadline or the linked account when they are sent as null.
+type savingsGoalUpdateBody struct {
+	Name        *string         `json:"name"`
+	TargetCents *int64          `json:"targetCents"`
+	Currency    *string         `json:"currency"`
+	StartDate   *time.Time      `json:"startDate"`
+	Deadline    json.RawMessage `json:"deadline"`
+	AccountID   json.RawMessage `json:"accountId"`
+	Category    *string         `json:"category"`
+	Archived    *bool           `json:"archived"`
+	Notes       *string         `json:"notes"`
+}
+
+func (b savingsGoalUpdateBody) toUpdate() (financesvc.UpdateSavingsGoalInput, error) {
+	in := financesvc.UpdateSavingsGoalInput{
+		Name:        b.Name,
+		TargetCents: b.TargetCents,
+		Currency:    b.Currency,
+		StartDate:   b.StartDate,
+		Category:    b.Category,
+		Archived:    b.Archived,
+		Notes:       b.Notes,
+	}
+	if len(b.Deadline) > 0 {
+		in.DeadlineSet = true
+		if err := json.Unmarshal(b.Deadline, &in.Deadline); err != nil {
+			return in, err
+		}
+	}
+	if len(b.AccountID) > 0 {
+		in.AccountSet = true
+		if err := json.Unmarshal(b.AccountID, &in.AccountID); err != nil {
+			return in, err
+		}
+	}
+	return in, nil
+}
@@ -0,0 +1,167 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+	"github.com/woragis/management/backend/server/internal/finance/statement"
+)
+
+func (h *financeHandler) listImports(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.svc.ListImports(r.Context())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) getImport(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	row, err := h.svc.GetImport(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+// previewImport accepts a multipart upload: file, optional format (ofx|csv), currency, accountId and,
+// for CSV, mapping as a JSON object.
+func (h *financeHandler) previewImport(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid multipart form."))
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "File is required."))
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	var mapping statement.CSVMapping
+	if raw := strings.TrimSpace(r.FormValue("mapping")); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Mapping is invalid."))
+			return
+		}
+	}
+	var accountID *uuid.UUID
+	if raw := strings.TrimSpace(r.FormValue("accountId")); raw != "" {
+		id, err := parseUUID(raw)
+		if err != nil {
+			apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid accountId."))
+			return
+		}
+		accountID = &id
+	}
+	row, err := h.svc.PreviewImport(r.Context(), financesvc.PreviewImportInput{
+		Filename:  header.Filename,
+		Format:    r.FormValue("format"),
+		Currency:  r.FormValue("currency"),
+		AccountID: accountID,
+		Mapping:   mapping,
+		Reader:    file,
+	})
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, row)
+}
+
+func (h *financeHandler) commitImport(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body struct {
+		SkipRows  []int `json:"skipRows"`
+		ForceRows []int `json:"forceRows"`
+	}
+	if err := decodeOptionalJSON(r, &body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.CommitImport(r.Context(), id, financesvc.CommitImportInput(body))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) undoImport(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	row, err := h.svc.UndoImport(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+// importCardStatement accepts a multipart upload: file, preset (nubank|inter) or mapping as a JSON
+// object, and either invoiceId or dueDate (YYYY-MM-DD) with optional name and cardLastFour.
+func (h *financeHandler) importCardStatement(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid multipart form."))
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "File is required."))
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	in := financesvc.CardStatementInput{
+		Preset:       r.FormValue("preset"),
+		Name:         r.FormValue("name"),
+		CardLastFour: r.FormValue("cardLastFour"),
+		Reader:       file,
+	}
+	if raw := strings.TrimSpace(r.FormValue("mapping")); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &in.Mapping); err != nil {
+			apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Mapping is invalid."))
+			return
+		}
+	}
+	if raw := strings.TrimSpace(r.FormValue("invoiceId")); raw != "" {
+		id, err := parseUUID(raw)
+		if err != nil {
+			apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid invoice id."))
+			return
+		}
+		in.InvoiceID = &id
+	}
+	if raw := strings.TrimSpace(r.FormValue("dueDate")); raw != "" {
+		due, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Due date must be YYYY-MM-DD."))
+			return
+		}
+		in.DueDate = &due
+	}
+	res, err := h.svc.ImportCardStatement(r.Context(), in)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,87 @@
+package httpserver
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+func (h *financeHandler) listInvoicePayments(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	rows, err := h.svc.ListInvoicePayments(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+// recordInvoicePayment pays the outstanding balance when the body omits amountCents.
+func (h *financeHandler) recordInvoicePayment(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body struct {
+		AmountCents *int64     `json:"amountCents"`
+		Date        *time.Time `json:"date"`
+		Currency    string     `json:"currency"`
+		Description string     `json:"description"`
+		Notes       string     `json:"notes"`
+	}
+	if err := decodeOptionalJSON(r, &body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.RecordInvoicePayment(r.Context(), id, financesvc.RecordInvoicePaymentInput(body))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, row)
+}
+
+func (h *financeHandler) deleteInvoicePayment(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	paymentID, err := parseUUID(r.PathValue("paymentId"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid payment id."))
+		return
+	}
+	if err := h.svc.DeleteInvoicePayment(r.Context(), id, paymentID); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *financeHandler) markOverdueInvoices(w http.ResponseWriter, r *http.Request) {
+	res, err := h.svc.MarkOverdueInvoices(r.Context(), time.Now().UTC())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, res)
+}
+
+func handleFinanceMarkOverdue(svc *financesvc.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := svc.MarkOverdueInvoices(r.Context(), time.Now().UTC())
+		if err != nil {
+			apperrors.WriteError(w, err)
+			return
+		}
+		apperrors.WriteJSON(w, http.StatusOK, res)
+	}
+}
@@ -0,0 +1,176 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+// netWorthSeries returns month-end net worth between ?from= and ?to= (default the last 12 months).
+func (h *financeHandler) netWorthSeries(w http.ResponseWriter, r *http.Request) {
+	out, err := h.svc.NetWorthSeries(r.Context(), parseDateQuery(r, "from"), parseDateQuery(r, "to"))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *financeHandler) listNetWorthItems(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.svc.ListNetWorthItems(r.Context(), r.URL.Query().Get("archived") == "true")
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) getNetWorthItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	row, err := h.svc.GetNetWorthItem(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) createNetWorthItem(w http.ResponseWriter, r *http.Request) {
+	var body netWorthItemBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.CreateNetWorthItem(r.Context(), financesvc.CreateNetWorthItemInput(body))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, row)
+}
+
+func (h *financeHandler) updateNetWorthItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body netWorthItemUpdateBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.UpdateNetWorthItem(r.Context(), id, financesvc.UpdateNetWorthItemInput(body))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) deleteNetWorthItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	if err := h.svc.DeleteNetWorthItem(r.Context(), id); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *financeHandler) listNetWorthSnapshots(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	rows, err := h.svc.ListNetWorthSnapshots(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) recordNetWorthSnapshot(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body netWorthSnapshotBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.RecordNetWorthSnapshot(r.Context(), id, financesvc.CreateNetWorthSnapshotInput(body))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, row)
+}
+
+func (h *financeHandler) deleteNetWorthSnapshot(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	snapshotID, err := parseUUID(r.PathValue("snapshotId"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid snapshot id."))
+		return
+	}
+	if err := h.svc.DeleteNetWorthSnapshot(r.Context(), id, snapshotID); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleFinanceSnapshotNetWorth takes the monthly net worth snapshot; the worker calls it every tick
+// and it does nothing once the month has a snapshot.
+func handleFinanceSnapshotNetWorth(svc *financesvc.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := svc.SnapshotNetWorth(r.Context(), time.Now().UTC())
+		if err != nil {
+			apperrors.WriteError(w, err)
+			return
+		}
+		apperrors.WriteJSON(w, http.StatusOK, res)
+	}
+}
+
+type netWorthItemBody struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	Category string `json:"category"`
+	Currency string `json:"currency"`
+	Notes    string `json:"notes"`
+}
+
+type netWorthItemUpdateBody struct {
+	Name     *string `json:"name"`
+	Kind     *string `json:"kind"`
+	Category *string `json:"category"`
+	Currency *string `json:"currency"`
+	Archived *bool   `json:"archived"`
+	Notes    *string `json:"notes"`
+}
+
+type netWorthSnapshotBody struct {
+	Date       *time.Time `json:"date"`
+	ValueCents int64      `json:"valueCents"`
+	Notes      string     `json:"notes"`
+}
@@ -0,0 +1,44 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+type pixBody struct {
+	AmountCents int64  `json:"amountCents"`
+	TxID        string `json:"txid"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+// generatePix returns a BR Code for the configured Pix key (or a PSP location url) and its QR code.
+func (h *financeHandler) generatePix(w http.ResponseWriter, r *http.Request) {
+	var body pixBody
+	if err := decodeOptionalJSON(r, &body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	out, err := h.svc.GeneratePix(r.Context(), financesvc.PixInput(body))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, out)
+}
+
+func (h *financeHandler) receivablePix(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	out, err := h.svc.ReceivablePix(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,140 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+func (h *financeHandler) listExpectedTransactions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := financesvc.ExpectedTransactionFilter{
+		Status:     q.Get("status"),
+		SourceType: q.Get("sourceType"),
+		From:       parseDateQuery(r, "from"),
+		To:         parseDateQuery(r, "to"),
+	}
+	if sid := q.Get("sourceId"); sid != "" {
+		if id, err := uuid.Parse(sid); err == nil {
+			f.SourceID = &id
+		}
+	}
+	rows, err := h.svc.ListExpectedTransactions(r.Context(), f)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) expectedDrift(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.svc.ExpectedDrift(r.Context(), parseDateQuery(r, "from"), parseDateQuery(r, "to"))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) runPostings(w http.ResponseWriter, r *http.Request) {
+	res, err := h.svc.RunPostings(r.Context(), time.Now().UTC())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, res)
+}
+
+func (h *financeHandler) adjustExpectedTransaction(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body struct {
+		AmountCents *int64  `json:"amountCents"`
+		Description *string `json:"description"`
+		Notes       *string `json:"notes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.AdjustExpectedTransaction(r.Context(), id, financesvc.AdjustExpectedInput(body))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) confirmExpectedTransaction(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body struct {
+		AmountCents   *int64     `json:"amountCents"`
+		Date          *time.Time `json:"date"`
+		Notes         *string    `json:"notes"`
+		TransactionID *uuid.UUID `json:"transactionId"`
+	}
+	if err := decodeOptionalJSON(r, &body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.ConfirmExpectedTransaction(r.Context(), id, financesvc.ConfirmExpectedInput(body))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) skipExpectedTransaction(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body struct {
+		Notes *string `json:"notes"`
+	}
+	if err := decodeOptionalJSON(r, &body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.SkipExpectedTransaction(r.Context(), id, body.Notes)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func handleFinanceRunPostings(svc *financesvc.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := svc.RunPostings(r.Context(), time.Now().UTC())
+		if err != nil {
+			apperrors.WriteError(w, err)
+			return
+		}
+		apperrors.WriteJSON(w, http.StatusOK, res)
+	}
+}
+
+// decodeOptionalJSON decodes the request body into dst, treating an empty body as no fields.
+func decodeOptionalJSON(r *http.Request, dst any) error {
+	err := json.NewDecoder(r.Body).Decode(dst)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,39 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+func (h *financeHandler) projectsPL(w http.ResponseWriter, r *http.Request) {
+	out, err := h.svc.ProjectsPL(r.Context(), projectPLQuery(r))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *financeHandler) projectPL(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	out, err := h.svc.ProjectPL(r.Context(), id, projectPLQuery(r))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, out)
+}
+
+// projectPLQuery reads year and month (the last month of the report) and months (its length).
+func projectPLQuery(r *http.Request) financesvc.ProjectPLInput {
+	year, month := parseYearMonthQuery(r)
+	months, _ := strconv.Atoi(r.URL.Query().Get("months"))
+	return financesvc.ProjectPLInput{Year: year, Month: month, Months: months}
+}
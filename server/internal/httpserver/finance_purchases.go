@@ -0,0 +1,127 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+func (h *financeHandler) listInstallmentPurchases(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.svc.ListInstallmentPurchases(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) getInstallmentPurchase(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	row, err := h.svc.GetInstallmentPurchase(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) createInstallmentPurchase(w http.ResponseWriter, r *http.Request) {
+	var body installmentPurchaseBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.CreateInstallmentPurchase(r.Context(), body.toCreate())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, row)
+}
+
+func (h *financeHandler) updateInstallmentPurchase(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body installmentPurchaseUpdateBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.UpdateInstallmentPurchase(r.Context(), id, body.toUpdate())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) cancelInstallmentPurchase(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	row, err := h.svc.CancelInstallmentPurchase(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) deleteInstallmentPurchase(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	if err := h.svc.DeleteInstallmentPurchase(r.Context(), id); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type installmentPurchaseBody struct {
+	Description       string     `json:"description"`
+	TotalCents        int64      `json:"totalCents"`
+	InstallmentCount  int        `json:"installmentCount"`
+	FirstInvoiceMonth time.Time  `json:"firstInvoiceMonth"`
+	PurchaseDate      *time.Time `json:"purchaseDate"`
+	CardName          string     `json:"cardName"`
+	CardLastFour      string     `json:"cardLastFour"`
+	DueDay            int        `json:"dueDay"`
+	Category          string     `json:"category"`
+	Notes             string     `json:"notes"`
+}
+
+func (b installmentPurchaseBody) toCreate() financesvc.CreateInstallmentPurchaseInput {
+	return financesvc.CreateInstallmentPurchaseInput(b)
+}
+
+type installmentPurchaseUpdateBody struct {
+	Description       *string    `json:"description"`
+	TotalCents        *int64     `json:"totalCents"`
+	InstallmentCount  *int       `json:"installmentCount"`
+	FirstInvoiceMonth *time.Time `json:"firstInvoiceMonth"`
+	PurchaseDate      *time.Time `json:"purchaseDate"`
+	CardName          *string    `json:"cardName"`
+	CardLastFour      *string    `json:"cardLastFour"`
+	DueDay            *int       `json:"dueDay"`
+	Category          *string    `json:"category"`
+	Notes             *string    `json:"notes"`
+}
+
+func (b installmentPurchaseUpdateBody) toUpdate() financesvc.UpdateInstallmentPurchaseInput {
+	return financesvc.UpdateInstallmentPurchaseInput(b)
+}
@@ -0,0 +1,230 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+// listReceivables accepts optional status, contactId and projectId filters.
+func (h *financeHandler) listReceivables(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := financesvc.ReceivableFilter{Status: q.Get("status")}
+	if cid := q.Get("contactId"); cid != "" {
+		if id, err := uuid.Parse(cid); err == nil {
+			f.ContactID = &id
+		}
+	}
+	if pid := q.Get("projectId"); pid != "" {
+		if id, err := uuid.Parse(pid); err == nil {
+			f.ProjectID = &id
+		}
+	}
+	rows, err := h.svc.ListReceivables(r.Context(), f)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) getReceivable(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	row, err := h.svc.GetReceivable(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) createReceivable(w http.ResponseWriter, r *http.Request) {
+	var body receivableBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.CreateReceivable(r.Context(), body.toCreate())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, row)
+}
+
+func (h *financeHandler) updateReceivable(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body receivableUpdateBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.UpdateReceivable(r.Context(), id, body.toUpdate())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) deleteReceivable(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	if err := h.svc.DeleteReceivable(r.Context(), id); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// issueReceivable numbers a draft and renders its PDF; the optional body sets the issue date.
+func (h *financeHandler) issueReceivable(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body struct {
+		IssueDate *time.Time `json:"issueDate"`
+	}
+	if err := decodeOptionalJSON(r, &body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.IssueReceivable(r.Context(), id, body.IssueDate)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+// renderReceivablePDF re-renders the PDF of an issued receivable, e.g. after the billing details in
+// the finance settings changed. The file is served by GET /v1/admin/media/{pdfMediaId}/file.
+func (h *financeHandler) renderReceivablePDF(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	row, err := h.svc.RenderReceivablePDF(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) recordReceivablePayment(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body struct {
+		Date      *time.Time `json:"date"`
+		AccountID *uuid.UUID `json:"accountId"`
+		Category  string     `json:"category"`
+		Notes     string     `json:"notes"`
+	}
+	if err := decodeOptionalJSON(r, &body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.RecordReceivablePayment(r.Context(), id, financesvc.RecordReceivablePaymentInput(body))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) voidReceivable(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	row, err := h.svc.VoidReceivable(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+type receivableItemBody struct {
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity"`
+	UnitCents   int64   `json:"unitCents"`
+}
+
+func receivableItemInputs(items []receivableItemBody) []financesvc.ReceivableItemInput {
+	out := make([]financesvc.ReceivableItemInput, len(items))
+	for i, it := range items {
+		out[i] = financesvc.ReceivableItemInput(it)
+	}
+	return out
+}
+
+type receivableBody struct {
+	ContactID uuid.UUID            `json:"contactId"`
+	ProjectID *uuid.UUID           `json:"projectId"`
+	Currency  string               `json:"currency"`
+	DueDate   time.Time            `json:"dueDate"`
+	Items     []receivableItemBody `json:"items"`
+	Notes     string               `json:"notes"`
+}
+
+func (b receivableBody) toCreate() financesvc.CreateReceivableInput {
+	return financesvc.CreateReceivableInput{
+		ContactID: b.ContactID,
+		ProjectID: b.ProjectID,
+		Currency:  b.Currency,
+		DueDate:   b.DueDate,
+		Items:     receivableItemInputs(b.Items),
+		Notes:     b.Notes,
+	}
+}
+
+type receivableUpdateBody struct {
+	ContactID *uuid.UUID            `json:"contactId"`
+	ProjectID *uuid.UUID            `json:"projectId"`
+	Currency  *string               `json:"currency"`
+	DueDate   *time.Time            `json:"dueDate"`
+	Items     *[]receivableItemBody `json:"items"`
+	Notes     *string               `json:"notes"`
+}
+
+func (b receivableUpdateBody) toUpdate() financesvc.UpdateReceivableInput {
+	in := financesvc.UpdateReceivableInput{
+		ContactID: b.ContactID,
+		Currency:  b.Currency,
+		DueDate:   b.DueDate,
+		Notes:     b.Notes,
+	}
+	if b.ProjectID != nil {
+		in.ProjectID = b.ProjectID
+		in.ProjectSet = true
+	}
+	if b.Items != nil {
+		in.Items = receivableItemInputs(*b.Items)
+		in.ItemsSet = true
+	}
+	return in
+}
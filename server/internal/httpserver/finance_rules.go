@@ -0,0 +1,140 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+func (h *financeHandler) listRules(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.svc.ListRules(r.Context())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) getRule(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	row, err := h.svc.GetRule(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) createRule(w http.ResponseWriter, r *http.Request) {
+	var body ruleBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.CreateRule(r.Context(), body.toCreate())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, row)
+}
+
+func (h *financeHandler) updateRule(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body ruleUpdateBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.UpdateRule(r.Context(), id, body.toUpdate())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) deleteRule(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	if err := h.svc.DeleteRule(r.Context(), id); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// runRules re-applies rules to the transactions in a date range. With dryRun set nothing is saved and
+// the response previews the changes.
+func (h *financeHandler) runRules(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		From      *time.Time `json:"from"`
+		To        *time.Time `json:"to"`
+		RuleID    *uuid.UUID `json:"ruleId"`
+		Overwrite bool       `json:"overwrite"`
+		DryRun    bool       `json:"dryRun"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	res, err := h.svc.RunRules(r.Context(), financesvc.RunRulesInput(body))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, res)
+}
+
+type ruleBody struct {
+	Name               string     `json:"name"`
+	Priority           *int       `json:"priority"`
+	Active             *bool      `json:"active"`
+	DescriptionPattern string     `json:"descriptionPattern"`
+	MinAmountCents     *int64     `json:"minAmountCents"`
+	MaxAmountCents     *int64     `json:"maxAmountCents"`
+	Currency           string     `json:"currency"`
+	Type               string     `json:"type"`
+	SetCategory        string     `json:"setCategory"`
+	SetProjectID       *uuid.UUID `json:"setProjectId"`
+	SetContactID       *uuid.UUID `json:"setContactId"`
+	SetNotes           string     `json:"setNotes"`
+}
+
+func (b ruleBody) toCreate() financesvc.CreateRuleInput {
+	return financesvc.CreateRuleInput(b)
+}
+
+type ruleUpdateBody struct {
+	Name               *string    `json:"name"`
+	Priority           *int       `json:"priority"`
+	Active             *bool      `json:"active"`
+	DescriptionPattern *string    `json:"descriptionPattern"`
+	MinAmountCents     *int64     `json:"minAmountCents"`
+	MaxAmountCents     *int64     `json:"maxAmountCents"`
+	Currency           *string    `json:"currency"`
+	Type               *string    `json:"type"`
+	SetCategory        *string    `json:"setCategory"`
+	SetProjectID       *uuid.UUID `json:"setProjectId"`
+	SetContactID       *uuid.UUID `json:"setContactId"`
+	SetNotes           *string    `json:"setNotes"`
+}
+
+func (b ruleUpdateBody) toUpdate() financesvc.UpdateRuleInput {
+	return financesvc.UpdateRuleInput(b)
+}
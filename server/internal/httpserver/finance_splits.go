@@ -0,0 +1,149 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+func (h *financeHandler) getTransactionSplits(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	rows, err := h.svc.TransactionSplits(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) splitTransaction(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body splitBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	rows, err := h.svc.SplitTransaction(r.Context(), id, body.toInput())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) deleteTransactionSplits(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	if err := h.svc.DeleteTransactionSplits(r.Context(), id); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *financeHandler) getInvoiceItemSplits(w http.ResponseWriter, r *http.Request) {
+	owner, ok := invoiceItemOwner(w, r)
+	if !ok {
+		return
+	}
+	rows, err := h.svc.InvoiceItemSplits(r.Context(), owner.InvoiceID, owner.ID)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) splitInvoiceItem(w http.ResponseWriter, r *http.Request) {
+	owner, ok := invoiceItemOwner(w, r)
+	if !ok {
+		return
+	}
+	var body splitBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	rows, err := h.svc.SplitInvoiceItem(r.Context(), owner.InvoiceID, owner.ID, body.toInput())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) deleteInvoiceItemSplits(w http.ResponseWriter, r *http.Request) {
+	owner, ok := invoiceItemOwner(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteInvoiceItemSplits(r.Context(), owner.InvoiceID, owner.ID); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listBalances returns the contacts that owe us or that we owe on shared expenses.
+func (h *financeHandler) listBalances(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.svc.ListBalances(r.Context())
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) deleteSettlement(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	if err := h.svc.DeleteSettlement(r.Context(), id); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type splitBody struct {
+	Method      string `json:"method"`
+	ExcludeSelf bool   `json:"excludeSelf"`
+	Shares      []struct {
+		ContactID   uuid.UUID `json:"contactId"`
+		Percent     float64   `json:"percent"`
+		AmountCents int64     `json:"amountCents"`
+	} `json:"shares"`
+}
+
+func (b splitBody) toInput() financesvc.SplitInput {
+	in := financesvc.SplitInput{Method: b.Method, ExcludeSelf: b.ExcludeSelf}
+	for _, s := range b.Shares {
+		in.Shares = append(in.Shares, financesvc.SplitShareInput(s))
+	}
+	return in
+}
+
+type settlementBody struct {
+	AmountCents   int64      `json:"amountCents"`
+	Currency      string     `json:"currency"`
+	Date          time.Time  `json:"date"`
+	TransactionID *uuid.UUID `json:"transactionId"`
+	Notes         string     `json:"notes"`
+}
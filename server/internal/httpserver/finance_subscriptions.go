@@ -0,0 +1,53 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+)
+
+func (h *financeHandler) detectSubscriptions(w http.ResponseWriter, r *http.Request) {
+	months, _ := strconv.Atoi(r.URL.Query().Get("months"))
+	out, err := h.svc.DetectSubscriptions(r.Context(), months)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, out)
+}
+
+type promoteSubscriptionBody struct {
+	Key         string     `json:"key"`
+	Name        string     `json:"name"`
+	Category    string     `json:"category"`
+	AmountCents *int64     `json:"amountCents"`
+	AutoPay     bool       `json:"autoPay"`
+	ProjectID   *uuid.UUID `json:"projectId"`
+	Notes       string     `json:"notes"`
+}
+
+func (h *financeHandler) promoteSubscription(w http.ResponseWriter, r *http.Request) {
+	var body promoteSubscriptionBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.PromoteSubscription(r.Context(), financesvc.PromoteSubscriptionInput{
+		Key:         body.Key,
+		Name:        body.Name,
+		Category:    body.Category,
+		AmountCents: body.AmountCents,
+		AutoPay:     body.AutoPay,
+		ProjectID:   body.ProjectID,
+		Notes:       body.Notes,
+	})
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, row)
+}
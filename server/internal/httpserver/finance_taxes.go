@@ -0,0 +1,116 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+// taxEstimate returns the monthly tax estimates of ?year= (default current) under the configured regime.
+func (h *financeHandler) taxEstimate(w http.ResponseWriter, r *http.Request) {
+	year, _ := parseYearMonthQuery(r)
+	out, err := h.svc.TaxEstimate(r.Context(), year)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *financeHandler) listTaxTables(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.svc.ListTaxTables(r.Context(), r.URL.Query().Get("regime"))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, rows)
+}
+
+func (h *financeHandler) getTaxTable(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	row, err := h.svc.GetTaxTable(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) createTaxTable(w http.ResponseWriter, r *http.Request) {
+	var body taxTableBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.CreateTaxTable(r.Context(), financesvc.CreateTaxTableInput(body))
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusCreated, row)
+}
+
+func (h *financeHandler) updateTaxTable(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	var body taxTableUpdateBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Request body is invalid."))
+		return
+	}
+	row, err := h.svc.UpdateTaxTable(r.Context(), id, financesvc.UpdateTaxTableInput{
+		ValidFrom:   body.ValidFrom,
+		Brackets:    body.Brackets,
+		BracketsSet: body.Brackets != nil,
+		FixedCents:  body.FixedCents,
+		Reduction:   body.Reduction,
+		Notes:       body.Notes,
+	})
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, row)
+}
+
+func (h *financeHandler) deleteTaxTable(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeInternal, "Invalid id."))
+		return
+	}
+	if err := h.svc.DeleteTaxTable(r.Context(), id); err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type taxTableBody struct {
+	Regime     string              `json:"regime"`
+	Annex      string              `json:"annex"`
+	ValidFrom  time.Time           `json:"validFrom"`
+	Brackets   []models.TaxBracket `json:"brackets"`
+	FixedCents int64               `json:"fixedCents"`
+	Reduction  models.TaxReduction `json:"reduction"`
+	Notes      string              `json:"notes"`
+}
+
+type taxTableUpdateBody struct {
+	ValidFrom  *time.Time           `json:"validFrom"`
+	Brackets   []models.TaxBracket  `json:"brackets"`
+	FixedCents *int64               `json:"fixedCents"`
+	Reduction  *models.TaxReduction `json:"reduction"`
+	Notes      *string              `json:"notes"`
+}
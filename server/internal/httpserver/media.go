@@ -2,6 +2,7 @@ package httpserver
 
 import (
 	"io"
+	"mime"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -40,6 +41,20 @@ func (h *mediaHandler) get(w http.ResponseWriter, r *http.Request) {
 	apperrors.WriteJSON(w, http.StatusOK, item)
 }
 
+func (h *mediaHandler) getPublic(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeMediaGetV1HandlerPathIDInvalid, apperrors.MsgMediaGetV1HandlerPathIDInvalid))
+		return
+	}
+	item, err := h.svc.GetPublic(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	apperrors.WriteJSON(w, http.StatusOK, item)
+}
+
 func (h *mediaHandler) upload(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseMultipartForm(105 << 20); err != nil {
 		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeMediaPostV1ServiceFileMissing, "Invalid multipart form."))
@@ -84,7 +99,7 @@ func (h *mediaHandler) serveFile(w http.ResponseWriter, r *http.Request) {
 		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeMediaGetV1HandlerPathIDInvalid, apperrors.MsgMediaGetV1HandlerPathIDInvalid))
 		return
 	}
-	asset, f, err := h.svc.Open(r.Context(), id)
+	asset, f, err := h.svc.OpenPublic(r.Context(), id)
 	if err != nil {
 		apperrors.WriteError(w, err)
 		return
@@ -94,3 +109,22 @@ func (h *mediaHandler) serveFile(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Cache-Control", "public, max-age=86400")
 	_, _ = io.Copy(w, f)
 }
+
+// serveFileAdmin serves any asset, private ones included, without letting caches keep it.
+func (h *mediaHandler) serveFileAdmin(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(r.PathValue("id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.Invalid(apperrors.CodeMediaGetV1HandlerPathIDInvalid, apperrors.MsgMediaGetV1HandlerPathIDInvalid))
+		return
+	}
+	asset, f, err := h.svc.Open(r.Context(), id)
+	if err != nil {
+		apperrors.WriteError(w, err)
+		return
+	}
+	defer func() { _ = f.Close() }()
+	w.Header().Set("Content-Type", asset.MimeType)
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": asset.Filename}))
+	_, _ = io.Copy(w, f)
+}
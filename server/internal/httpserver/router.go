@@ -15,16 +15,17 @@ func Mount(mux *http.ServeMux, app *App) {
 	}
 
 	if app.DevProjects != nil {
-		mux.Handle("GET /v1/admin/dashboard", admin(handleDashboard(app.DevProjects, app.MediaRepo)))
+		mux.Handle("GET /v1/admin/dashboard", admin(handleDashboard(app.DevProjects, app.MediaRepo, app.Finance)))
 	}
 
 	if app.DevProjects != nil {
-		dh := newDevprojectHandler(app.DevProjects, app.Media)
+		dh := newDevprojectHandler(app.DevProjects, app.Media, app.Finance)
 		mux.Handle("GET /v1/admin/projects", admin(dh.list))
 		mux.Handle("POST /v1/admin/projects", admin(dh.create))
 		mux.Handle("GET /v1/admin/projects/{id}", admin(dh.get))
 		mux.Handle("PATCH /v1/admin/projects/{id}", admin(dh.update))
 		mux.Handle("DELETE /v1/admin/projects/{id}", admin(dh.delete))
+		mux.Handle("GET /v1/admin/projects/{id}/finance", admin(dh.projectFinance))
 		mux.Handle("POST /v1/admin/projects/{id}/links", admin(dh.createLink))
 		mux.Handle("DELETE /v1/admin/projects/{id}/links/{linkId}", admin(dh.deleteLink))
 		mux.Handle("POST /v1/admin/projects/{id}/domains", admin(dh.createDomain))
@@ -49,8 +50,9 @@ func Mount(mux *http.ServeMux, app *App) {
 		mux.Handle("POST /v1/admin/media", admin(mh.upload))
 		mux.Handle("GET /v1/admin/media/{id}", admin(mh.get))
 		mux.Handle("DELETE /v1/admin/media/{id}", admin(mh.delete))
+		mux.Handle("GET /v1/admin/media/{id}/file", admin(mh.serveFileAdmin))
 		mux.HandleFunc("GET /v1/public/media/{id}/file", mh.serveFile)
-		mux.HandleFunc("GET /v1/public/media/{id}", mh.get)
+		mux.HandleFunc("GET /v1/public/media/{id}", mh.getPublic)
 	}
 
 	if app.Profile != nil {
@@ -73,6 +75,9 @@ func Mount(mux *http.ServeMux, app *App) {
 		mux.Handle("GET /v1/admin/contacts/{id}/interactions", admin(ch.listInteractions))
 		mux.Handle("POST /v1/admin/contacts/{id}/interactions", admin(ch.createInteraction))
 		mux.Handle("GET /v1/admin/contacts/{id}/finance", admin(ch.contactFinance))
+		mux.Handle("POST /v1/admin/contacts/{id}/finance/pix", admin(ch.contactPix))
+		mux.Handle("GET /v1/admin/contacts/{id}/finance/balance", admin(ch.contactBalance))
+		mux.Handle("POST /v1/admin/contacts/{id}/finance/settlements", admin(ch.createSettlement))
 	}
 
 	if app.DevProjects != nil {
@@ -82,9 +87,10 @@ func Mount(mux *http.ServeMux, app *App) {
 	}
 
 	if app.Finance != nil {
-		fh := newFinanceHandler(app.Finance)
+		fh := newFinanceHandler(app.Finance, app.Media)
 		mux.Handle("GET /v1/admin/finance/dashboard", admin(fh.dashboard))
 		mux.Handle("GET /v1/admin/finance/summary", admin(fh.summary))
+		mux.Handle("GET /v1/admin/finance/analytics", admin(fh.analytics))
 		mux.Handle("GET /v1/admin/finance/calendar", admin(fh.calendar))
 		mux.Handle("GET /v1/admin/finance/income-sources", admin(fh.listIncomeSources))
 		mux.Handle("POST /v1/admin/finance/income-sources", admin(fh.createIncomeSource))
@@ -96,23 +102,123 @@ func Mount(mux *http.ServeMux, app *App) {
 		mux.Handle("GET /v1/admin/finance/expenses/{id}", admin(fh.getExpense))
 		mux.Handle("PATCH /v1/admin/finance/expenses/{id}", admin(fh.updateExpense))
 		mux.Handle("DELETE /v1/admin/finance/expenses/{id}", admin(fh.deleteExpense))
+		mux.Handle("GET /v1/admin/finance/subscriptions", admin(fh.detectSubscriptions))
+		mux.Handle("POST /v1/admin/finance/subscriptions/promote", admin(fh.promoteSubscription))
 		mux.Handle("GET /v1/admin/finance/transactions", admin(fh.listTransactions))
 		mux.Handle("POST /v1/admin/finance/transactions", admin(fh.createTransaction))
 		mux.Handle("GET /v1/admin/finance/transactions/{id}", admin(fh.getTransaction))
 		mux.Handle("PATCH /v1/admin/finance/transactions/{id}", admin(fh.updateTransaction))
 		mux.Handle("DELETE /v1/admin/finance/transactions/{id}", admin(fh.deleteTransaction))
+		mux.Handle("POST /v1/admin/finance/transactions/{id}/attachments", admin(fh.attachTransactionMedia))
+		mux.Handle("DELETE /v1/admin/finance/transactions/{id}/attachments/{mediaId}", admin(fh.detachTransactionMedia))
+		mux.Handle("GET /v1/admin/finance/transactions/{id}/split", admin(fh.getTransactionSplits))
+		mux.Handle("PUT /v1/admin/finance/transactions/{id}/split", admin(fh.splitTransaction))
+		mux.Handle("DELETE /v1/admin/finance/transactions/{id}/split", admin(fh.deleteTransactionSplits))
 		mux.Handle("GET /v1/admin/finance/invoices", admin(fh.listInvoices))
 		mux.Handle("POST /v1/admin/finance/invoices", admin(fh.createInvoice))
+		mux.Handle("POST /v1/admin/finance/invoices/import", admin(fh.importCardStatement))
+		mux.Handle("POST /v1/admin/finance/invoices/overdue/run", admin(fh.markOverdueInvoices))
 		mux.Handle("GET /v1/admin/finance/invoices/{id}", admin(fh.getInvoice))
 		mux.Handle("PATCH /v1/admin/finance/invoices/{id}", admin(fh.updateInvoice))
 		mux.Handle("DELETE /v1/admin/finance/invoices/{id}", admin(fh.deleteInvoice))
 		mux.Handle("POST /v1/admin/finance/invoices/{id}/items", admin(fh.createInvoiceItem))
 		mux.Handle("DELETE /v1/admin/finance/invoices/{id}/items/{itemId}", admin(fh.deleteInvoiceItem))
+		mux.Handle("POST /v1/admin/finance/invoices/{id}/attachments", admin(fh.attachInvoiceMedia))
+		mux.Handle("DELETE /v1/admin/finance/invoices/{id}/attachments/{mediaId}", admin(fh.detachInvoiceMedia))
+		mux.Handle("POST /v1/admin/finance/invoices/{id}/items/{itemId}/attachments", admin(fh.attachInvoiceItemMedia))
+		mux.Handle("DELETE /v1/admin/finance/invoices/{id}/items/{itemId}/attachments/{mediaId}", admin(fh.detachInvoiceItemMedia))
+		mux.Handle("GET /v1/admin/finance/invoices/{id}/items/{itemId}/split", admin(fh.getInvoiceItemSplits))
+		mux.Handle("PUT /v1/admin/finance/invoices/{id}/items/{itemId}/split", admin(fh.splitInvoiceItem))
+		mux.Handle("DELETE /v1/admin/finance/invoices/{id}/items/{itemId}/split", admin(fh.deleteInvoiceItemSplits))
+		mux.Handle("GET /v1/admin/finance/invoices/{id}/payments", admin(fh.listInvoicePayments))
+		mux.Handle("POST /v1/admin/finance/invoices/{id}/payments", admin(fh.recordInvoicePayment))
+		mux.Handle("DELETE /v1/admin/finance/invoices/{id}/payments/{paymentId}", admin(fh.deleteInvoicePayment))
+		mux.Handle("GET /v1/admin/finance/receivables", admin(fh.listReceivables))
+		mux.Handle("POST /v1/admin/finance/receivables", admin(fh.createReceivable))
+		mux.Handle("GET /v1/admin/finance/receivables/{id}", admin(fh.getReceivable))
+		mux.Handle("PATCH /v1/admin/finance/receivables/{id}", admin(fh.updateReceivable))
+		mux.Handle("DELETE /v1/admin/finance/receivables/{id}", admin(fh.deleteReceivable))
+		mux.Handle("POST /v1/admin/finance/receivables/{id}/issue", admin(fh.issueReceivable))
+		mux.Handle("POST /v1/admin/finance/receivables/{id}/pdf", admin(fh.renderReceivablePDF))
+		mux.Handle("POST /v1/admin/finance/receivables/{id}/payments", admin(fh.recordReceivablePayment))
+		mux.Handle("POST /v1/admin/finance/receivables/{id}/void", admin(fh.voidReceivable))
+		mux.Handle("POST /v1/admin/finance/receivables/{id}/pix", admin(fh.receivablePix))
+		mux.Handle("POST /v1/admin/finance/pix", admin(fh.generatePix))
+		mux.Handle("GET /v1/admin/finance/balances", admin(fh.listBalances))
+		mux.Handle("DELETE /v1/admin/finance/settlements/{id}", admin(fh.deleteSettlement))
+		mux.Handle("GET /v1/admin/finance/taxes", admin(fh.taxEstimate))
+		mux.Handle("GET /v1/admin/finance/taxes/tables", admin(fh.listTaxTables))
+		mux.Handle("POST /v1/admin/finance/taxes/tables", admin(fh.createTaxTable))
+		mux.Handle("GET /v1/admin/finance/taxes/tables/{id}", admin(fh.getTaxTable))
+		mux.Handle("PATCH /v1/admin/finance/taxes/tables/{id}", admin(fh.updateTaxTable))
+		mux.Handle("DELETE /v1/admin/finance/taxes/tables/{id}", admin(fh.deleteTaxTable))
+		mux.Handle("GET /v1/admin/finance/net-worth", admin(fh.netWorthSeries))
+		mux.Handle("GET /v1/admin/finance/net-worth/items", admin(fh.listNetWorthItems))
+		mux.Handle("POST /v1/admin/finance/net-worth/items", admin(fh.createNetWorthItem))
+		mux.Handle("GET /v1/admin/finance/net-worth/items/{id}", admin(fh.getNetWorthItem))
+		mux.Handle("PATCH /v1/admin/finance/net-worth/items/{id}", admin(fh.updateNetWorthItem))
+		mux.Handle("DELETE /v1/admin/finance/net-worth/items/{id}", admin(fh.deleteNetWorthItem))
+		mux.Handle("GET /v1/admin/finance/net-worth/items/{id}/snapshots", admin(fh.listNetWorthSnapshots))
+		mux.Handle("POST /v1/admin/finance/net-worth/items/{id}/snapshots", admin(fh.recordNetWorthSnapshot))
+		mux.Handle("DELETE /v1/admin/finance/net-worth/items/{id}/snapshots/{snapshotId}", admin(fh.deleteNetWorthSnapshot))
+		mux.Handle("GET /v1/admin/finance/goals", admin(fh.listSavingsGoals))
+		mux.Handle("POST /v1/admin/finance/goals", admin(fh.createSavingsGoal))
+		mux.Handle("GET /v1/admin/finance/goals/{id}", admin(fh.getSavingsGoal))
+		mux.Handle("PATCH /v1/admin/finance/goals/{id}", admin(fh.updateSavingsGoal))
+		mux.Handle("DELETE /v1/admin/finance/goals/{id}", admin(fh.deleteSavingsGoal))
+		mux.Handle("GET /v1/admin/finance/goals/{id}/contributions", admin(fh.listSavingsGoalContributions))
 		mux.Handle("GET /v1/admin/finance/budgets", admin(fh.listBudgets))
 		mux.Handle("POST /v1/admin/finance/budgets", admin(fh.createBudget))
+		mux.Handle("GET /v1/admin/finance/budgets/report", admin(fh.budgetReport))
+		mux.Handle("POST /v1/admin/finance/budgets/copy", admin(fh.copyBudgets))
+		mux.Handle("GET /v1/admin/finance/forecast", admin(fh.forecast))
+		mux.Handle("POST /v1/admin/finance/forecast", admin(fh.forecast))
+		mux.Handle("GET /v1/admin/finance/projects/pl", admin(fh.projectsPL))
+		mux.Handle("GET /v1/admin/finance/export/{dataset}", admin(fh.exportFinance))
+		mux.Handle("GET /v1/admin/finance/projects/{id}/pl", admin(fh.projectPL))
 		mux.Handle("GET /v1/admin/finance/budgets/{id}", admin(fh.getBudget))
 		mux.Handle("PATCH /v1/admin/finance/budgets/{id}", admin(fh.updateBudget))
 		mux.Handle("DELETE /v1/admin/finance/budgets/{id}", admin(fh.deleteBudget))
+		mux.Handle("GET /v1/admin/finance/settings", admin(fh.getSettings))
+		mux.Handle("PATCH /v1/admin/finance/settings", admin(fh.updateSettings))
+		mux.Handle("GET /v1/admin/finance/exchange-rates", admin(fh.listExchangeRates))
+		mux.Handle("POST /v1/admin/finance/exchange-rates", admin(fh.createExchangeRate))
+		mux.Handle("POST /v1/admin/finance/exchange-rates/import", admin(fh.importExchangeRates))
+		mux.Handle("DELETE /v1/admin/finance/exchange-rates/{id}", admin(fh.deleteExchangeRate))
+		mux.Handle("GET /v1/admin/finance/expected", admin(fh.listExpectedTransactions))
+		mux.Handle("GET /v1/admin/finance/expected/drift", admin(fh.expectedDrift))
+		mux.Handle("POST /v1/admin/finance/expected/run", admin(fh.runPostings))
+		mux.Handle("PATCH /v1/admin/finance/expected/{id}", admin(fh.adjustExpectedTransaction))
+		mux.Handle("POST /v1/admin/finance/expected/{id}/confirm", admin(fh.confirmExpectedTransaction))
+		mux.Handle("POST /v1/admin/finance/expected/{id}/skip", admin(fh.skipExpectedTransaction))
+		mux.Handle("GET /v1/admin/finance/imports", admin(fh.listImports))
+		mux.Handle("POST /v1/admin/finance/imports", admin(fh.previewImport))
+		mux.Handle("GET /v1/admin/finance/imports/{id}", admin(fh.getImport))
+		mux.Handle("POST /v1/admin/finance/imports/{id}/commit", admin(fh.commitImport))
+		mux.Handle("POST /v1/admin/finance/imports/{id}/undo", admin(fh.undoImport))
+		mux.Handle("GET /v1/admin/finance/installment-purchases", admin(fh.listInstallmentPurchases))
+		mux.Handle("POST /v1/admin/finance/installment-purchases", admin(fh.createInstallmentPurchase))
+		mux.Handle("GET /v1/admin/finance/installment-purchases/{id}", admin(fh.getInstallmentPurchase))
+		mux.Handle("PATCH /v1/admin/finance/installment-purchases/{id}", admin(fh.updateInstallmentPurchase))
+		mux.Handle("DELETE /v1/admin/finance/installment-purchases/{id}", admin(fh.deleteInstallmentPurchase))
+		mux.Handle("POST /v1/admin/finance/installment-purchases/{id}/cancel", admin(fh.cancelInstallmentPurchase))
+		mux.Handle("GET /v1/admin/finance/accounts", admin(fh.listAccounts))
+		mux.Handle("POST /v1/admin/finance/accounts", admin(fh.createAccount))
+		mux.Handle("GET /v1/admin/finance/accounts/{id}", admin(fh.getAccount))
+		mux.Handle("PATCH /v1/admin/finance/accounts/{id}", admin(fh.updateAccount))
+		mux.Handle("DELETE /v1/admin/finance/accounts/{id}", admin(fh.deleteAccount))
+		mux.Handle("GET /v1/admin/finance/accounts/{id}/ledger", admin(fh.accountLedger))
+		mux.Handle("GET /v1/admin/finance/categories", admin(fh.listCategories))
+		mux.Handle("POST /v1/admin/finance/categories", admin(fh.createCategory))
+		mux.Handle("GET /v1/admin/finance/categories/{id}", admin(fh.getCategory))
+		mux.Handle("PATCH /v1/admin/finance/categories/{id}", admin(fh.updateCategory))
+		mux.Handle("DELETE /v1/admin/finance/categories/{id}", admin(fh.deleteCategory))
+		mux.Handle("GET /v1/admin/finance/rules", admin(fh.listRules))
+		mux.Handle("POST /v1/admin/finance/rules", admin(fh.createRule))
+		mux.Handle("POST /v1/admin/finance/rules/run", admin(fh.runRules))
+		mux.Handle("GET /v1/admin/finance/rules/{id}", admin(fh.getRule))
+		mux.Handle("PATCH /v1/admin/finance/rules/{id}", admin(fh.updateRule))
+		mux.Handle("DELETE /v1/admin/finance/rules/{id}", admin(fh.deleteRule))
 	}
 
 	if app.Content != nil {
@@ -212,6 +318,11 @@ func Mount(mux *http.ServeMux, app *App) {
 		if app.PresenceReminder != nil {
 			mux.Handle("POST /v1/internal/presence/reminders/{id}/send", worker(handlePresenceSendReminder(app.PresenceReminder)))
 		}
+		if app.Finance != nil {
+			mux.Handle("POST /v1/internal/finance/postings/run", worker(handleFinanceRunPostings(app.Finance)))
+			mux.Handle("POST /v1/internal/finance/invoices/overdue/run", worker(handleFinanceMarkOverdue(app.Finance)))
+			mux.Handle("POST /v1/internal/finance/net-worth/snapshots/run", worker(handleFinanceSnapshotNetWorth(app.Finance)))
+		}
 	}
 
 	if app.Personality != nil {
@@ -245,10 +356,14 @@ func Mount(mux *http.ServeMux, app *App) {
 		mux.Handle("GET /v1/internal/agent/tools/finance/dashboard", agent(tools.financeDashboard))
 		mux.Handle("GET /v1/internal/agent/tools/finance/summary", agent(tools.financeSummary))
 		mux.Handle("GET /v1/internal/agent/tools/finance/calendar", agent(tools.financeCalendar))
+		mux.Handle("GET /v1/internal/agent/tools/finance/forecast", agent(tools.financeForecast))
+		mux.Handle("POST /v1/internal/agent/tools/finance/forecast", agent(tools.financeForecast))
 		mux.Handle("GET /v1/internal/agent/tools/finance/income-sources", agent(tools.listIncomeSources))
 		mux.Handle("POST /v1/internal/agent/tools/finance/income-sources", agent(tools.createIncomeSource))
 		mux.Handle("GET /v1/internal/agent/tools/finance/transactions", agent(tools.listTransactions))
 		mux.Handle("POST /v1/internal/agent/tools/finance/transactions", agent(tools.createTransaction))
+		mux.Handle("GET /v1/internal/agent/tools/finance/goals", agent(tools.listSavingsGoals))
+		mux.Handle("GET /v1/internal/agent/tools/finance/goals/{id}", agent(tools.getSavingsGoal))
 
 		mux.Handle("GET /v1/internal/agent/tools/presence/posts", agent(tools.listSocialPosts))
 		mux.Handle("POST /v1/internal/agent/tools/presence/posts", agent(tools.createSocialPost))
@@ -56,6 +56,17 @@ func (r *Repository) Create(ctx context.Context, m *models.MediaAsset) error {
 	return nil
 }
 
+func (r *Repository) SetPrivate(ctx context.Context, id uuid.UUID, private bool) error {
+	res := r.db.WithContext(ctx).Model(&models.MediaAsset{}).Where("id = ?", id).Update("private", private)
+	if res.Error != nil {
+		return fmt.Errorf("set media private: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
 	res := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.MediaAsset{})
 	if res.Error != nil {
@@ -32,6 +32,7 @@ type UploadInput struct {
 	Filename string
 	MimeType string
 	AltText  string
+	Private  bool
 	Reader   io.Reader
 }
 
@@ -101,6 +102,7 @@ func (s *Service) Upload(ctx context.Context, in UploadInput) (*models.MediaAsse
 		StorageKey: key,
 		PublicURL:  fmt.Sprintf("%s/%s/file", s.baseURL, id.String()),
 		AltText:    strings.TrimSpace(in.AltText),
+		Private:    in.Private,
 	}
 	if err := s.repo.Create(ctx, asset); err != nil {
 		_ = s.store.Delete(ctx, key)
@@ -109,6 +111,52 @@ func (s *Service) Upload(ctx context.Context, in UploadInput) (*models.MediaAsse
 	return asset, nil
 }
 
+// GetPublic is GetByID for the public routes: private assets are reported as not found.
+func (s *Service) GetPublic(ctx context.Context, id uuid.UUID) (*models.MediaAsset, error) {
+	m, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m.Private {
+		return nil, apperrors.NotFound(apperrors.CodeMediaGetV1ServiceNotFound, apperrors.MsgMediaGetV1ServiceNotFound)
+	}
+	return m, nil
+}
+
+// MakePrivate stops an asset from being served on the public routes. It is used when the asset is
+// attached to a finance record.
+func (s *Service) MakePrivate(ctx context.Context, id uuid.UUID) (*models.MediaAsset, error) {
+	return s.setPrivate(ctx, id, true)
+}
+
+// MakePublic serves an asset on the public routes again, undoing MakePrivate when attaching fails.
+func (s *Service) MakePublic(ctx context.Context, id uuid.UUID) (*models.MediaAsset, error) {
+	return s.setPrivate(ctx, id, false)
+}
+
+func (s *Service) setPrivate(ctx context.Context, id uuid.UUID, private bool) (*models.MediaAsset, error) {
+	m, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m.Private == private {
+		return m, nil
+	}
+	if err := s.repo.SetPrivate(ctx, id, private); err != nil {
+		return nil, apperrors.InternalCause(apperrors.CodeMediaPatchV1ServiceUpdateFailed, apperrors.MsgMediaPatchV1ServiceUpdateFailed, err)
+	}
+	m.Private = private
+	return m, nil
+}
+
+// OpenPublic is Open for the public file route; private assets are reported as not found.
+func (s *Service) OpenPublic(ctx context.Context, id uuid.UUID) (*models.MediaAsset, io.ReadCloser, error) {
+	if _, err := s.GetPublic(ctx, id); err != nil {
+		return nil, nil, err
+	}
+	return s.Open(ctx, id)
+}
+
 func (s *Service) Open(ctx context.Context, id uuid.UUID) (*models.MediaAsset, io.ReadCloser, error) {
 	m, err := s.GetByID(ctx, id)
 	if err != nil {
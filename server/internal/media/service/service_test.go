@@ -0,0 +1,65 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/woragis/management/backend/server/internal/media/repository"
+	"github.com/woragis/management/backend/server/internal/media/storage"
+	"github.com/woragis/management/backend/server/internal/models"
+	"github.com/woragis/management/backend/server/internal/testutil"
+)
+
+func TestPublicRoutesHidePrivateAssets(t *testing.T) {
+	db := testutil.OpenSQLite(t)
+	if err := db.AutoMigrate(&models.MediaAsset{}); err != nil {
+		t.Fatal(err)
+	}
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := New(repository.New(db), store, "http://localhost/v1/public/media")
+	ctx := t.Context()
+
+	receipt, err := svc.Upload(ctx, UploadInput{Filename: "receipt.pdf", MimeType: "application/pdf", Private: true, Reader: bytes.NewReader([]byte("%PDF-1.4"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.GetPublic(ctx, receipt.ID); err == nil {
+		t.Fatal("GetPublic served a private asset")
+	}
+	if _, f, err := svc.OpenPublic(ctx, receipt.ID); err == nil {
+		_ = f.Close()
+		t.Fatal("OpenPublic served a private asset")
+	}
+
+	photo, err := svc.Upload(ctx, UploadInput{Filename: "photo.pdf", MimeType: "application/pdf", Reader: bytes.NewReader([]byte("%PDF-1.4"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, f, err := svc.OpenPublic(ctx, photo.ID)
+	if err != nil {
+		t.Fatalf("public asset: %v", err)
+	}
+	data, _ := io.ReadAll(f)
+	_ = f.Close()
+	if string(data) != "%PDF-1.4" {
+		t.Fatalf("public asset content %q", data)
+	}
+
+	// Attaching an existing asset to a finance record makes it private; a failed attach restores it.
+	if _, err := svc.MakePrivate(ctx, photo.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.GetPublic(ctx, photo.ID); err == nil {
+		t.Fatal("GetPublic served an asset made private")
+	}
+	if _, err := svc.MakePublic(ctx, photo.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.GetPublic(ctx, photo.ID); err != nil {
+		t.Fatalf("asset made public again: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	msgtemplaterender "github.com/woragis/management/backend/server/internal/messaging/templaterender"
+	"github.com/woragis/management/backend/server/internal/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// defaultFinanceTemplates are the bill alerts a ScheduledJob can reference as
+// "finance/<slug>", with dataSource {"program":"finance","days":7}, and the receivable reminder, with
+// dataSource {"program":"finance","receivableId":"<id>"}.
+func defaultFinanceTemplates() []models.MessageTemplate {
+	return []models.MessageTemplate{
+		{
+			Slug: "bills-due",
+			Name: "Contas a vencer",
+			Body: `💸 *CONTAS A VENCER*
+
+Próximos {{days}} dias ({{from}} a {{to}}):
+{{dueList}}
+
+Total: *{{totalDue}}*`,
+		},
+		{
+			Slug: "bills-due-weekly",
+			Name: "Resumo semanal de contas",
+			Body: `📅 *CONTAS DA SEMANA*
+
+{{dueList}}
+
+{{dueCount}} contas, total de *{{totalDue}}*.
+Em atraso: {{overdueCount}} ({{overdueTotal}})`,
+		},
+		{
+			Slug: "receivable-pix",
+			Name: "Cobrança com Pix",
+			Body: `🧾 *FATURA {{receivableNumber}}*
+
+Valor: *{{receivableTotal}}*
+Vencimento: {{receivableDue}}
+
+Pix copia e cola:
+{{pixCode}}
+
+QR code: {{pixQrUrl}}`,
+		},
+	}
+}
+
+// EnsureFinanceTemplates creates the default finance templates when missing; edited ones are kept.
+func (s *Service) EnsureFinanceTemplates(ctx context.Context) error {
+	bindingsJSON, _ := json.Marshal(msgtemplaterender.DefaultBindings("finance"))
+	for _, tpl := range defaultFinanceTemplates() {
+		if _, err := s.repo.FindTemplateBySlug(ctx, "finance", tpl.Slug, nil); err == nil {
+			continue
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.InternalCause(apperrors.CodeInternal, "Failed to check template.", err)
+		}
+		row := tpl
+		row.ProgramSlug = "finance"
+		row.ComposeMode = models.ComposeModeStatic
+		row.Bindings = datatypes.JSON(bindingsJSON)
+		row.Active = true
+		if err := s.repo.CreateTemplate(ctx, &row); err != nil {
+			return apperrors.InternalCause(apperrors.CodeInternal, "Failed to seed finance template.", err)
+		}
+	}
+	return nil
+}
@@ -445,20 +445,26 @@ func (s *Service) ListDeliveries(ctx context.Context, destinationID *uuid.UUID,
 	return rows, nil
 }
 
+// FindTemplateBySlug prefers a template bound to the destination over a shared one. A slug of the
+// form "program/slug" (e.g. "finance/bills-due") selects a seeded program template.
 func (s *Service) FindTemplateBySlug(ctx context.Context, slug string, destinationID uuid.UUID) (*models.MessageTemplate, error) {
 	slug = strings.TrimSpace(slug)
+	program := ""
+	if p, rest, ok := strings.Cut(slug, "/"); ok {
+		program, slug = strings.TrimSpace(p), strings.TrimSpace(rest)
+	}
 	if slug == "" {
 		return nil, apperrors.NotFound(apperrors.CodeInternal, "Template not found.")
 	}
 	destID := &destinationID
-	row, err := s.repo.FindTemplateBySlug(ctx, "", slug, destID)
+	row, err := s.repo.FindTemplateBySlug(ctx, program, slug, destID)
 	if err == nil {
 		return row, nil
 	}
 	if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, apperrors.InternalCause(apperrors.CodeInternal, "Failed to load template.", err)
 	}
-	row, err = s.repo.FindTemplateBySlug(ctx, "", slug, nil)
+	row, err = s.repo.FindTemplateBySlug(ctx, program, slug, nil)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, apperrors.NotFound(apperrors.CodeInternal, "Template not found.")
@@ -17,6 +17,9 @@ type DataSource struct {
 	Date        string `json:"date"`
 	ProjectID   string `json:"projectId"`
 	ProjectSlug string `json:"projectSlug"`
+	Days        int    `json:"days"`
+	// ReceivableID switches the finance program from bills due to one receivable and its Pix charge.
+	ReceivableID string `json:"receivableId"`
 }
 
 func ParseDataSource(raw datatypes.JSON) DataSource {
@@ -80,6 +83,8 @@ func CatalogFields(program string) []CatalogField {
 		return leetcodeCatalog
 	case "project":
 		return projectCatalog
+	case "finance":
+		return financeCatalog
 	default:
 		return nil
 	}
@@ -117,6 +122,22 @@ var projectCatalog = []CatalogField{
 	{Key: "stack", Label: "Stack", Binding: "project.stack"},
 }
 
+var financeCatalog = []CatalogField{
+	{Key: "dueList", Label: "Due list", Binding: "finance.dueList", Description: "One line per bill, overdue ones first"},
+	{Key: "totalDue", Label: "Total due", Binding: "finance.totalDue", Description: "Everything listed, in the reporting currency"},
+	{Key: "dueCount", Label: "Bills due", Binding: "finance.dueCount"},
+	{Key: "overdueCount", Label: "Overdue bills", Binding: "finance.overdueCount"},
+	{Key: "overdueTotal", Label: "Overdue total", Binding: "finance.overdueTotal"},
+	{Key: "days", Label: "Days ahead", Binding: "finance.days"},
+	{Key: "from", Label: "From", Binding: "finance.from"},
+	{Key: "to", Label: "To", Binding: "finance.to"},
+	{Key: "receivableNumber", Label: "Receivable number", Binding: "finance.receivableNumber", Description: "Receivable jobs (dataSource.receivableId) only"},
+	{Key: "receivableTotal", Label: "Receivable total", Binding: "finance.receivableTotal", Description: "Receivable jobs only"},
+	{Key: "receivableDue", Label: "Receivable due date", Binding: "finance.receivableDue", Description: "Receivable jobs only"},
+	{Key: "pixCode", Label: "Pix copy-and-paste code", Binding: "finance.pixCode", Description: "Receivable jobs only, BRL receivables"},
+	{Key: "pixQrUrl", Label: "Pix QR code URL", Binding: "finance.pixQrUrl", Description: "Receivable jobs only, BRL receivables"},
+}
+
 func DefaultBindings(program string) Bindings {
 	fields := CatalogFields(program)
 	out := Bindings{}
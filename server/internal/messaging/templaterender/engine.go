@@ -9,16 +9,18 @@ import (
 	contentsvc "github.com/woragis/management/backend/server/internal/content/service"
 	contentrender "github.com/woragis/management/backend/server/internal/content/templaterender"
 	devprojectsvc "github.com/woragis/management/backend/server/internal/devproject/service"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
 	"github.com/woragis/management/backend/server/internal/models"
 )
 
 type Engine struct {
 	content     *contentsvc.Service
 	devProjects *devprojectsvc.Service
+	finance     *financesvc.Service
 }
 
-func NewEngine(content *contentsvc.Service, devProjects *devprojectsvc.Service) *Engine {
-	return &Engine{content: content, devProjects: devProjects}
+func NewEngine(content *contentsvc.Service, devProjects *devprojectsvc.Service, finance *financesvc.Service) *Engine {
+	return &Engine{content: content, devProjects: devProjects, finance: finance}
 }
 
 type RenderInput struct {
@@ -85,6 +87,8 @@ func (e *Engine) resolveVars(ctx context.Context, ds DataSource, job *models.Sch
 		return e.resolveLeetcode(ctx, ds, job)
 	case "project":
 		return e.resolveProject(ctx, ds)
+	case "finance":
+		return e.resolveFinance(ctx, ds, job)
 	default:
 		return map[string]string{}, false, "", "", nil
 	}
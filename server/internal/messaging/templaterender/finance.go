@@ -0,0 +1,155 @@
+package templaterender
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	contentrender "github.com/woragis/management/backend/server/internal/content/templaterender"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+// resolveFinance loads the bills due from the job's today (in its timezone, or dataSource.date)
+// through dataSource.days ahead. The job is skipped when nothing is due or overdue.
+func (e *Engine) resolveFinance(ctx context.Context, ds DataSource, job *models.ScheduledJob) (map[string]string, bool, string, string, error) {
+	if e.finance == nil {
+		return nil, true, "finance service unavailable", "", nil
+	}
+	if strings.TrimSpace(ds.ReceivableID) != "" {
+		return e.resolveReceivable(ctx, ds)
+	}
+	today := contentrender.TodayInTZ(job.Timezone)
+	if strings.TrimSpace(ds.Date) != "" {
+		d, err := contentrender.ParseDateInTZ(strings.TrimSpace(ds.Date), job.Timezone)
+		if err != nil {
+			return nil, true, "invalid date", "", nil
+		}
+		today = d
+	}
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	due, err := e.finance.BillsDue(ctx, today, ds.Days)
+	if err != nil {
+		return nil, false, "", "", err
+	}
+	ref := "bills-due/" + due.From.Format("2006-01-02")
+	if len(due.Bills) == 0 {
+		return nil, true, "nothing due", ref, nil
+	}
+	return financeVars(due), false, "", ref, nil
+}
+
+// resolveReceivable loads one receivable for a payment reminder, with its Pix charge when it is in
+// BRL. The job is skipped once the receivable is no longer open.
+func (e *Engine) resolveReceivable(ctx context.Context, ds DataSource) (map[string]string, bool, string, string, error) {
+	id, err := uuid.Parse(strings.TrimSpace(ds.ReceivableID))
+	if err != nil {
+		return nil, true, "invalid receivable id", "", nil
+	}
+	row, err := e.finance.GetReceivable(ctx, id)
+	if err != nil {
+		return nil, false, "", "", err
+	}
+	ref := "receivable/" + id.String()
+	if row.Status != "sent" {
+		return nil, true, "receivable not open", ref, nil
+	}
+	var charge *financesvc.PixCharge
+	if row.Currency == "BRL" {
+		if charge, err = e.finance.ReceivablePix(ctx, id); err != nil && !pixNotConfigured(err) {
+			return nil, false, "", "", err
+		}
+	}
+	return receivableVars(row, charge), false, "", ref, nil
+}
+
+// pixNotConfigured reports a Pix charge that cannot be made with the current settings (no key,
+// merchant or media storage); the Pix placeholders then render empty.
+func pixNotConfigured(err error) bool {
+	ae, ok := apperrors.As(err)
+	return ok && (ae.Kind == apperrors.KindInvalid || ae.Kind == apperrors.KindUnavailable)
+}
+
+func receivableVars(row *models.Receivable, charge *financesvc.PixCharge) map[string]string {
+	vars := map[string]string{
+		"receivableTotal": FormatMoney(row.TotalCents, row.Currency),
+		"receivableDue":   row.DueDate.Format("02/01/2006"),
+	}
+	if row.Number != nil {
+		vars["receivableNumber"] = *row.Number
+	}
+	if charge != nil {
+		vars["pixCode"] = charge.Payload
+		vars["pixQrUrl"] = charge.QRURL
+	}
+	return prefixed("finance.", vars)
+}
+
+func financeVars(due *financesvc.BillsDue) map[string]string {
+	days := strconv.Itoa(int(due.To.Sub(due.From).Hours() / 24))
+	vars := map[string]string{
+		"dueList":      FormatDueList(due.Bills),
+		"totalDue":     FormatMoney(due.TotalDueCents, due.ReportingCurrency),
+		"dueCount":     strconv.Itoa(len(due.Bills)),
+		"overdueCount": strconv.Itoa(due.OverdueCount),
+		"overdueTotal": FormatMoney(due.OverdueCents, due.ReportingCurrency),
+		"days":         days,
+		"from":         due.From.Format("02/01"),
+		"to":           due.To.Format("02/01"),
+	}
+	return prefixed("finance.", vars)
+}
+
+// prefixed exposes vars under both their bare and program-qualified names.
+func prefixed(prefix string, vars map[string]string) map[string]string {
+	out := make(map[string]string, len(vars)*2)
+	for k, v := range vars {
+		out[k] = v
+		out[prefix+k] = v
+	}
+	return out
+}
+
+// FormatDueList renders one line per bill, overdue ones first, e.g.
+// "• 20/10 — Nubank — R$ 1.234,56".
+func FormatDueList(bills []financesvc.DueBill) string {
+	var overdue, upcoming []string
+	for _, b := range bills {
+		line := fmt.Sprintf("%s — %s — %s", b.Date.Format("02/01"), b.Title, FormatMoney(b.AmountCents, b.Currency))
+		switch {
+		case b.Overdue:
+			overdue = append(overdue, "⚠️ "+line+" (atrasada)")
+		case b.AutoPay:
+			upcoming = append(upcoming, "• "+line+" (débito automático)")
+		default:
+			upcoming = append(upcoming, "• "+line)
+		}
+	}
+	return strings.Join(append(overdue, upcoming...), "\n")
+}
+
+// FormatMoney writes cents the Brazilian way: "R$ 1.234,56" for BRL, "USD 1.234,56" otherwise.
+func FormatMoney(cents int64, currency string) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	whole := strconv.FormatInt(cents/100, 10)
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	prefix := strings.ToUpper(strings.TrimSpace(currency))
+	if prefix == "" || prefix == "BRL" {
+		prefix = "R$"
+	}
+	return fmt.Sprintf("%s%s %s,%02d", sign, prefix, b.String(), cents%100)
+}
@@ -0,0 +1,70 @@
+package templaterender
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/woragis/management/backend/server/internal/apperrors"
+	financesvc "github.com/woragis/management/backend/server/internal/finance/service"
+	"github.com/woragis/management/backend/server/internal/models"
+)
+
+func TestFormatMoney(t *testing.T) {
+	cases := map[string]string{
+		FormatMoney(123456, "BRL"):    "R$ 1.234,56",
+		FormatMoney(5, ""):            "R$ 0,05",
+		FormatMoney(100000000, "usd"): "USD 1.000.000,00",
+		FormatMoney(-250, "BRL"):      "-R$ 2,50",
+	}
+	for got, want := range cases {
+		if got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestFormatDueListPutsOverdueFirst(t *testing.T) {
+	bills := []financesvc.DueBill{
+		{Date: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), Title: "Internet", AmountCents: 9990, Currency: "BRL", AutoPay: true},
+		{Date: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), Title: "Nubank", AmountCents: 150000, Currency: "BRL", Overdue: true},
+	}
+	want := "⚠️ 12/10 — Nubank — R$ 1.500,00 (atrasada)\n• 20/10 — Internet — R$ 99,90 (débito automático)"
+	if got := FormatDueList(bills); got != want {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestFinanceBindingsResolve(t *testing.T) {
+	due := &financesvc.BillsDue{
+		From:              time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+		To:                time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC),
+		ReportingCurrency: "BRL",
+		Bills:             []financesvc.DueBill{{Title: "Aluguel", AmountCents: 200000, Currency: "BRL"}},
+		TotalDueCents:     200000,
+	}
+	vars := financeVars(due)
+	body := RenderBody("{{totalDue}} em {{days}} dias, {{overdueCount}} atrasadas", map[string]string{
+		"totalDue":     resolveBinding("finance.totalDue", vars),
+		"days":         resolveBinding("finance.days", vars),
+		"overdueCount": resolveBinding("finance.overdueCount", vars),
+	})
+	if body != "R$ 2.000,00 em 7 dias, 0 atrasadas" {
+		t.Fatalf("got %q", body)
+	}
+}
+
+func TestPixNotConfiguredRendersEmpty(t *testing.T) {
+	if !pixNotConfigured(apperrors.Invalid(apperrors.CodeInternal, "Configure a Pix key in the finance settings.")) ||
+		!pixNotConfigured(apperrors.Unavailable(apperrors.CodeInternal, "Media storage is not configured.")) {
+		t.Fatal("configuration errors should be skipped")
+	}
+	if pixNotConfigured(apperrors.InternalCause(apperrors.CodeInternal, "Failed to update receivable.", errors.New("db down"))) {
+		t.Fatal("internal errors must fail the job")
+	}
+	number := "2026-0001"
+	vars := receivableVars(&models.Receivable{Number: &number, TotalCents: 1000, Currency: "BRL"}, nil)
+	if body := RenderBody("Pix: {{pix}}", map[string]string{"pix": resolveBinding("finance.pixCode", vars)}); body != "Pix: " {
+		t.Fatalf("got %q", body)
+	}
+}
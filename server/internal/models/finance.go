@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 )
 
 type IncomeSource struct {
@@ -20,6 +21,8 @@ type IncomeSource struct {
 	Notes        string     `gorm:"type:text" json:"notes"`
 	CreatedAt    time.Time  `json:"createdAt"`
 	UpdatedAt    time.Time  `json:"updatedAt"`
+
+	Recurrence `gorm:"embedded"`
 }
 
 type Expense struct {
@@ -37,6 +40,25 @@ type Expense struct {
 	Notes       string     `gorm:"type:text" json:"notes"`
 	CreatedAt   time.Time  `json:"createdAt"`
 	UpdatedAt   time.Time  `json:"updatedAt"`
+
+	Recurrence `gorm:"embedded"`
+
+	// MerchantKey ties the expense to the charges it was promoted from by subscription detection.
+	MerchantKey string `gorm:"column:merchant_key;size:64;index" json:"merchantKey,omitempty"`
+}
+
+// Recurrence refines Frequency/DayOfMonth on scheduled incomes and expenses. AnchorDate is the first
+// possible occurrence (and the due date of one_time rows); Interval repeats every N weeks, months or
+// years counted from the anchor. Weekday (0 = Sunday) applies to weekly rows and MonthOfYear to yearly
+// rows; both default to the anchor's. WeekendAdjust moves occurrences that fall on a weekend to the
+// previous or next business day.
+type Recurrence struct {
+	AnchorDate    *time.Time `gorm:"column:anchor_date;type:date" json:"anchorDate"`
+	Interval      int        `gorm:"column:recurrence_interval;not null;default:1" json:"interval"`
+	Weekday       *int       `gorm:"column:weekday" json:"weekday"`
+	MonthOfYear   *int       `gorm:"column:month_of_year" json:"monthOfYear"`
+	EndDate       *time.Time `gorm:"column:end_date;type:date" json:"endDate"`
+	WeekendAdjust string     `gorm:"column:weekend_adjust;size:16;not null;default:none" json:"weekendAdjust"`
 }
 
 type Transaction struct {
@@ -46,42 +68,64 @@ type Transaction struct {
 	Currency       string     `gorm:"size:8;not null;default:BRL" json:"currency"`
 	Description    string     `gorm:"size:500;not null" json:"description"`
 	Date           time.Time  `gorm:"type:date;not null;index" json:"date"`
+	Category       string     `gorm:"size:64;index" json:"category"`
 	IncomeSourceID *uuid.UUID `gorm:"column:income_source_id;type:uuid;index" json:"incomeSourceId"`
 	ExpenseID      *uuid.UUID `gorm:"column:expense_id;type:uuid;index" json:"expenseId"`
 	ProjectID      *uuid.UUID `gorm:"column:project_id;type:uuid;index" json:"projectId"`
 	ContactID      *uuid.UUID `gorm:"column:contact_id;type:uuid;index" json:"contactId"`
 	InvoiceID      *uuid.UUID `gorm:"column:invoice_id;type:uuid;index" json:"invoiceId"`
+	AccountID      *uuid.UUID `gorm:"column:account_id;type:uuid;index" json:"accountId"`
+	ToAccountID    *uuid.UUID `gorm:"column:to_account_id;type:uuid;index" json:"toAccountId,omitempty"`
+	ToAmountCents  *int64     `gorm:"column:to_amount_cents" json:"toAmountCents,omitempty"`
+	ImportID       *uuid.UUID `gorm:"column:import_id;type:uuid;index" json:"importId,omitempty"`
+	ExternalID     string     `gorm:"column:external_id;size:128;index" json:"externalId,omitempty"`
 	Notes          string     `gorm:"type:text" json:"notes"`
 	CreatedAt      time.Time  `json:"createdAt"`
 	UpdatedAt      time.Time  `json:"updatedAt"`
+
+	// Deductible marks an expense as deductible from carnê-leão income (livro-caixa).
+	Deductible bool `gorm:"not null;default:false" json:"deductible"`
+
+	// GoalID tags the transaction as a contribution to, or a withdrawal from, a savings goal.
+	GoalID *uuid.UUID `gorm:"column:goal_id;type:uuid;index" json:"goalId,omitempty"`
+
+	Attachments []MediaAsset `gorm:"-" json:"attachments,omitempty"`
 }
 
+// Invoice is a card bill. PaidCents is the sum of its linked payment transactions plus
+// PaidAdjustmentCents, what was marked paid by hand before payments were derived from them.
 type Invoice struct {
-	ID           uuid.UUID     `gorm:"type:uuid;primaryKey" json:"id"`
-	Name         string        `gorm:"size:200;not null" json:"name"`
-	CardLastFour string        `gorm:"column:card_last_four;size:4" json:"cardLastFour"`
-	DueDate      time.Time     `gorm:"column:due_date;type:date;not null;index" json:"dueDate"`
-	ClosedAt     *time.Time    `gorm:"column:closed_at" json:"closedAt"`
-	TotalCents   int64         `gorm:"column:total_cents;not null;default:0" json:"totalCents"`
-	PaidCents    int64         `gorm:"column:paid_cents;not null;default:0" json:"paidCents"`
-	Status       string        `gorm:"size:16;not null;default:open" json:"status"`
-	Notes        string        `gorm:"type:text" json:"notes"`
-	CreatedAt    time.Time     `json:"createdAt"`
-	UpdatedAt    time.Time     `json:"updatedAt"`
-	Items        []InvoiceItem `gorm:"foreignKey:InvoiceID" json:"items,omitempty"`
+	ID                  uuid.UUID     `gorm:"type:uuid;primaryKey" json:"id"`
+	Name                string        `gorm:"size:200;not null" json:"name"`
+	CardLastFour        string        `gorm:"column:card_last_four;size:4" json:"cardLastFour"`
+	DueDate             time.Time     `gorm:"column:due_date;type:date;not null;index" json:"dueDate"`
+	ClosedAt            *time.Time    `gorm:"column:closed_at" json:"closedAt"`
+	TotalCents          int64         `gorm:"column:total_cents;not null;default:0" json:"totalCents"`
+	PaidCents           int64         `gorm:"column:paid_cents;not null;default:0" json:"paidCents"`
+	PaidAdjustmentCents int64         `gorm:"column:paid_adjustment_cents;not null;default:0" json:"paidAdjustmentCents"`
+	Status              string        `gorm:"size:16;not null;default:open" json:"status"`
+	Notes               string        `gorm:"type:text" json:"notes"`
+	CreatedAt           time.Time     `json:"createdAt"`
+	UpdatedAt           time.Time     `json:"updatedAt"`
+	Items               []InvoiceItem `gorm:"foreignKey:InvoiceID" json:"items,omitempty"`
+
+	Attachments []MediaAsset `gorm:"-" json:"attachments,omitempty"`
 }
 
 type InvoiceItem struct {
-	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
-	InvoiceID   uuid.UUID `gorm:"column:invoice_id;type:uuid;not null;index" json:"invoiceId"`
-	Description string    `gorm:"size:500;not null" json:"description"`
-	AmountCents int64     `gorm:"column:amount_cents;not null" json:"amountCents"`
-	Date        time.Time `gorm:"type:date;not null" json:"date"`
-	Category    string    `gorm:"size:64" json:"category"`
-	Installment string    `gorm:"size:32" json:"installment"`
-	Notes       string    `gorm:"type:text" json:"notes"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	InvoiceID   uuid.UUID  `gorm:"column:invoice_id;type:uuid;not null;index" json:"invoiceId"`
+	Description string     `gorm:"size:500;not null" json:"description"`
+	AmountCents int64      `gorm:"column:amount_cents;not null" json:"amountCents"`
+	Date        time.Time  `gorm:"type:date;not null" json:"date"`
+	Category    string     `gorm:"size:64" json:"category"`
+	Installment string     `gorm:"size:32" json:"installment"`
+	PurchaseID  *uuid.UUID `gorm:"column:purchase_id;type:uuid;index" json:"purchaseId,omitempty"`
+	Notes       string     `gorm:"type:text" json:"notes"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+
+	Attachments []MediaAsset `gorm:"-" json:"attachments,omitempty"`
 }
 
 type BudgetPlan struct {
@@ -90,7 +134,331 @@ type BudgetPlan struct {
 	Month        int       `gorm:"not null;index:idx_budget_period" json:"month"`
 	Category     string    `gorm:"size:64;not null;index:idx_budget_period" json:"category"`
 	PlannedCents int64     `gorm:"column:planned_cents;not null;default:0" json:"plannedCents"`
+	Rollover     bool      `gorm:"not null;default:false" json:"rollover"`
 	Notes        string    `gorm:"type:text" json:"notes"`
 	CreatedAt    time.Time `json:"createdAt"`
 	UpdatedAt    time.Time `json:"updatedAt"`
 }
+
+// ExchangeRate stores how many units of QuoteCurrency one unit of BaseCurrency buys on Date.
+type ExchangeRate struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	Date          time.Time `gorm:"type:date;not null;uniqueIndex:idx_fx_rate_pair_date,priority:3" json:"date"`
+	BaseCurrency  string    `gorm:"column:base_currency;size:8;not null;uniqueIndex:idx_fx_rate_pair_date,priority:1" json:"baseCurrency"`
+	QuoteCurrency string    `gorm:"column:quote_currency;size:8;not null;uniqueIndex:idx_fx_rate_pair_date,priority:2" json:"quoteCurrency"`
+	Rate          float64   `gorm:"not null" json:"rate"`
+	Source        string    `gorm:"size:32;not null;default:manual" json:"source"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+type FinanceSettings struct {
+	ID                uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	ReportingCurrency string    `gorm:"column:reporting_currency;size:8;not null;default:BRL" json:"reportingCurrency"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+
+	// Issuer details printed on receivable billing documents.
+	BillingName    string `gorm:"column:billing_name;size:200" json:"billingName"`
+	BillingTaxID   string `gorm:"column:billing_tax_id;size:32" json:"billingTaxId"`
+	BillingEmail   string `gorm:"column:billing_email;size:200" json:"billingEmail"`
+	BillingAddress string `gorm:"column:billing_address;type:text" json:"billingAddress"`
+
+	// Pix receiver used for BR Codes; the merchant name falls back to BillingName.
+	PixKey          string `gorm:"column:pix_key;size:77" json:"pixKey"`
+	PixMerchantName string `gorm:"column:pix_merchant_name;size:25" json:"pixMerchantName"`
+	PixMerchantCity string `gorm:"column:pix_merchant_city;size:15" json:"pixMerchantCity"`
+
+	// Tax regime used for estimates: carne_leao, simples or mei (empty disables them). TaxAnnex is the
+	// Simples Nacional annex and TaxBracket pins its bracket; 0 picks it from the last 12 months' revenue.
+	TaxRegime  string `gorm:"column:tax_regime;size:16" json:"taxRegime"`
+	TaxAnnex   string `gorm:"column:tax_annex;size:8" json:"taxAnnex"`
+	TaxBracket int    `gorm:"column:tax_bracket;not null;default:0" json:"taxBracket"`
+}
+
+// ExpectedTransaction is one due occurrence of a recurring income source or expense, waiting to be
+// confirmed into a real Transaction or skipped.
+type ExpectedTransaction struct {
+	ID                uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	SourceType        string     `gorm:"column:source_type;size:16;not null;uniqueIndex:idx_expected_tx_source_due,priority:1" json:"sourceType"`
+	SourceID          uuid.UUID  `gorm:"column:source_id;type:uuid;not null;uniqueIndex:idx_expected_tx_source_due,priority:2" json:"sourceId"`
+	DueDate           time.Time  `gorm:"column:due_date;type:date;not null;uniqueIndex:idx_expected_tx_source_due,priority:3;index" json:"dueDate"`
+	Description       string     `gorm:"size:500;not null" json:"description"`
+	AmountCents       int64      `gorm:"column:amount_cents;not null" json:"amountCents"`
+	Currency          string     `gorm:"size:8;not null;default:BRL" json:"currency"`
+	Status            string     `gorm:"size:16;not null;default:pending;index" json:"status"`
+	TransactionID     *uuid.UUID `gorm:"column:transaction_id;type:uuid;index" json:"transactionId"`
+	ActualAmountCents *int64     `gorm:"column:actual_amount_cents" json:"actualAmountCents"`
+	ActualDate        *time.Time `gorm:"column:actual_date;type:date" json:"actualDate"`
+	ResolvedAt        *time.Time `gorm:"column:resolved_at" json:"resolvedAt"`
+	Notes             string     `gorm:"type:text" json:"notes"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+}
+
+// TransactionImport records one uploaded bank statement: its parsed rows while previewed and the
+// transactions it created once committed, so the batch can be undone as a unit.
+type TransactionImport struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	Filename       string         `gorm:"size:255" json:"filename"`
+	Format         string         `gorm:"size:16;not null" json:"format"`
+	Status         string         `gorm:"size:16;not null;default:preview;index" json:"status"`
+	Mapping        datatypes.JSON `gorm:"type:jsonb" json:"mapping,omitempty"`
+	Rows           datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"rows"`
+	AccountID      *uuid.UUID     `gorm:"column:account_id;type:uuid" json:"accountId,omitempty"`
+	RowCount       int            `gorm:"column:row_count;not null;default:0" json:"rowCount"`
+	DuplicateCount int            `gorm:"column:duplicate_count;not null;default:0" json:"duplicateCount"`
+	ImportedCount  int            `gorm:"column:imported_count;not null;default:0" json:"importedCount"`
+	CommittedAt    *time.Time     `gorm:"column:committed_at" json:"committedAt"`
+	UndoneAt       *time.Time     `gorm:"column:undone_at" json:"undoneAt"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+}
+
+// InstallmentPurchase is a card purchase split into monthly installments. Each installment is an
+// InvoiceItem linked through PurchaseID on the card's invoice for that month.
+type InstallmentPurchase struct {
+	ID                uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Description       string     `gorm:"size:500;not null" json:"description"`
+	TotalCents        int64      `gorm:"column:total_cents;not null" json:"totalCents"`
+	InstallmentCount  int        `gorm:"column:installment_count;not null" json:"installmentCount"`
+	FirstInvoiceMonth time.Time  `gorm:"column:first_invoice_month;type:date;not null" json:"firstInvoiceMonth"`
+	PurchaseDate      time.Time  `gorm:"column:purchase_date;type:date;not null" json:"purchaseDate"`
+	CardName          string     `gorm:"column:card_name;size:200" json:"cardName"`
+	CardLastFour      string     `gorm:"column:card_last_four;size:4;index" json:"cardLastFour"`
+	DueDay            int        `gorm:"column:due_day;not null" json:"dueDay"`
+	Category          string     `gorm:"size:64" json:"category"`
+	Status            string     `gorm:"size:16;not null;default:active;index" json:"status"`
+	CancelledAt       *time.Time `gorm:"column:cancelled_at" json:"cancelledAt"`
+	Notes             string     `gorm:"type:text" json:"notes"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+}
+
+// Account is where money sits: a bank account, wallet, card or brokerage. Its balance is the opening
+// balance plus every transaction dated on or after OpeningDate that references it.
+type Account struct {
+	ID                  uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	Name                string    `gorm:"size:200;not null" json:"name"`
+	Type                string    `gorm:"size:32;not null;default:checking" json:"type"`
+	Currency            string    `gorm:"size:8;not null;default:BRL" json:"currency"`
+	OpeningBalanceCents int64     `gorm:"column:opening_balance_cents;not null;default:0" json:"openingBalanceCents"`
+	OpeningDate         time.Time `gorm:"column:opening_date;type:date;not null" json:"openingDate"`
+	Archived            bool      `gorm:"not null;default:false" json:"archived"`
+	Notes               string    `gorm:"type:text" json:"notes"`
+	CreatedAt           time.Time `json:"createdAt"`
+	UpdatedAt           time.Time `json:"updatedAt"`
+}
+
+// FinanceCategory classifies income or expenses. Slug is what expenses, transactions, invoice items
+// and budgets store in their category column, so it never changes after creation; ParentID nests
+// categories of the same kind for roll-up reports.
+type FinanceCategory struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Kind      string     `gorm:"size:16;not null;uniqueIndex:idx_finance_category_slug" json:"kind"`
+	Slug      string     `gorm:"size:64;not null;uniqueIndex:idx_finance_category_slug" json:"slug"`
+	Name      string     `gorm:"size:200;not null" json:"name"`
+	ParentID  *uuid.UUID `gorm:"column:parent_id;type:uuid;index" json:"parentId"`
+	Color     string     `gorm:"size:16" json:"color"`
+	Archived  bool       `gorm:"not null;default:false" json:"archived"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// CategorizationRule fills in fields of new transactions and invoice items whose description, amount,
+// currency and type match. Empty conditions match anything; rules run in Priority order and each field
+// is taken from the first matching rule that sets it.
+type CategorizationRule struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Name               string     `gorm:"size:200;not null" json:"name"`
+	Priority           int        `gorm:"not null;default:100;index" json:"priority"`
+	Active             bool       `gorm:"not null;default:true" json:"active"`
+	DescriptionPattern string     `gorm:"column:description_pattern;size:500" json:"descriptionPattern"`
+	MinAmountCents     *int64     `gorm:"column:min_amount_cents" json:"minAmountCents"`
+	MaxAmountCents     *int64     `gorm:"column:max_amount_cents" json:"maxAmountCents"`
+	Currency           string     `gorm:"size:8" json:"currency"`
+	Type               string     `gorm:"size:16" json:"type"`
+	SetCategory        string     `gorm:"column:set_category;size:64" json:"setCategory"`
+	SetProjectID       *uuid.UUID `gorm:"column:set_project_id;type:uuid" json:"setProjectId"`
+	SetContactID       *uuid.UUID `gorm:"column:set_contact_id;type:uuid" json:"setContactId"`
+	SetNotes           string     `gorm:"column:set_notes;type:text" json:"setNotes"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	UpdatedAt          time.Time  `json:"updatedAt"`
+}
+
+const (
+	FinanceAttachmentTransaction = "transaction"
+	FinanceAttachmentInvoice     = "invoice"
+	FinanceAttachmentInvoiceItem = "invoice_item"
+)
+
+// FinanceAttachment links a media asset (receipt, invoice PDF, contract) to a transaction, invoice or
+// invoice item. Attached assets are private: they are only served on the admin media routes.
+type FinanceAttachment struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	OwnerType string    `gorm:"column:owner_type;size:16;not null;uniqueIndex:idx_finance_attachment_owner_media,priority:1" json:"ownerType"`
+	OwnerID   uuid.UUID `gorm:"column:owner_id;type:uuid;not null;uniqueIndex:idx_finance_attachment_owner_media,priority:2" json:"ownerId"`
+	MediaID   uuid.UUID `gorm:"column:media_id;type:uuid;not null;uniqueIndex:idx_finance_attachment_owner_media,priority:3;index" json:"mediaId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Receivable is a billing document issued to a contact. It starts as a draft, gets its number and PDF
+// when issued (status sent), and becomes paid once the payment is recorded as an income transaction.
+// Status is one of draft, sent, paid, void.
+type Receivable struct {
+	ID            uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	Number        *string          `gorm:"size:32;uniqueIndex" json:"number"`
+	ContactID     uuid.UUID        `gorm:"column:contact_id;type:uuid;not null;index" json:"contactId"`
+	ProjectID     *uuid.UUID       `gorm:"column:project_id;type:uuid;index" json:"projectId"`
+	Currency      string           `gorm:"size:8;not null;default:BRL" json:"currency"`
+	IssueDate     *time.Time       `gorm:"column:issue_date;type:date" json:"issueDate"`
+	DueDate       time.Time        `gorm:"column:due_date;type:date;not null;index" json:"dueDate"`
+	Status        string           `gorm:"size:16;not null;default:draft;index" json:"status"`
+	TotalCents    int64            `gorm:"column:total_cents;not null;default:0" json:"totalCents"`
+	Notes         string           `gorm:"type:text" json:"notes"`
+	PDFMediaID    *uuid.UUID       `gorm:"column:pdf_media_id;type:uuid" json:"pdfMediaId"`
+	TransactionID *uuid.UUID       `gorm:"column:transaction_id;type:uuid;index" json:"transactionId"`
+	PaidAt        *time.Time       `gorm:"column:paid_at" json:"paidAt"`
+	VoidedAt      *time.Time       `gorm:"column:voided_at" json:"voidedAt"`
+	CreatedAt     time.Time        `json:"createdAt"`
+	UpdatedAt     time.Time        `json:"updatedAt"`
+	Items         []ReceivableItem `gorm:"foreignKey:ReceivableID" json:"items,omitempty"`
+
+	// PixPayload is the BR Code set when the receivable is issued in BRL with a Pix key configured;
+	// PixMediaID is its QR code image, rendered on demand.
+	PixPayload string     `gorm:"column:pix_payload;type:text" json:"pixPayload,omitempty"`
+	PixMediaID *uuid.UUID `gorm:"column:pix_media_id;type:uuid" json:"pixMediaId,omitempty"`
+}
+
+type ReceivableItem struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	ReceivableID uuid.UUID `gorm:"column:receivable_id;type:uuid;not null;index" json:"receivableId"`
+	Position     int       `gorm:"not null;default:0" json:"position"`
+	Description  string    `gorm:"size:500;not null" json:"description"`
+	Quantity     float64   `gorm:"type:numeric(12,3);not null;default:1" json:"quantity"`
+	UnitCents    int64     `gorm:"column:unit_cents;not null" json:"unitCents"`
+	AmountCents  int64     `gorm:"column:amount_cents;not null" json:"amountCents"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+const (
+	FinanceSplitTransaction = "transaction"
+	FinanceSplitInvoiceItem = "invoice_item"
+)
+
+// FinanceSplit is a contact's share of a transaction or invoice item, one row per contact. Method is
+// equal, percentage or exact. AmountCents is positive when the contact owes it to us (an expense we
+// paid) and negative when we owe it (income received on their behalf). Description and Date are
+// copied from the split row for the contact's statement.
+type FinanceSplit struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	OwnerType   string    `gorm:"column:owner_type;size:16;not null;uniqueIndex:idx_finance_split_owner_contact,priority:1" json:"ownerType"`
+	OwnerID     uuid.UUID `gorm:"column:owner_id;type:uuid;not null;uniqueIndex:idx_finance_split_owner_contact,priority:2" json:"ownerId"`
+	ContactID   uuid.UUID `gorm:"column:contact_id;type:uuid;not null;uniqueIndex:idx_finance_split_owner_contact,priority:3;index" json:"contactId"`
+	Method      string    `gorm:"size:16;not null" json:"method"`
+	Percent     *float64  `json:"percent,omitempty"`
+	AmountCents int64     `gorm:"column:amount_cents;not null" json:"amountCents"`
+	Currency    string    `gorm:"size:8;not null;default:BRL" json:"currency"`
+	Description string    `gorm:"size:500" json:"description"`
+	Date        time.Time `gorm:"type:date;not null" json:"date"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// FinanceSettlement is money settling a contact's shared-expense balance: positive when the contact
+// paid us, negative when we paid them. TransactionID optionally points at the recorded transfer.
+type FinanceSettlement struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	ContactID     uuid.UUID  `gorm:"column:contact_id;type:uuid;not null;index" json:"contactId"`
+	AmountCents   int64      `gorm:"column:amount_cents;not null" json:"amountCents"`
+	Currency      string     `gorm:"size:8;not null;default:BRL" json:"currency"`
+	Date          time.Time  `gorm:"type:date;not null" json:"date"`
+	TransactionID *uuid.UUID `gorm:"column:transaction_id;type:uuid;index" json:"transactionId"`
+	Notes         string     `gorm:"type:text" json:"notes"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// TaxTable is an editable rate table of a tax regime, in effect from ValidFrom until the next table of
+// the same regime and annex. Carnê-leão brackets apply to the monthly taxable base and Simples Nacional
+// brackets to the revenue of the last 12 months; MEI tables carry the fixed monthly DAS instead.
+type TaxTable struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	Regime     string         `gorm:"size:16;not null;uniqueIndex:idx_tax_table_period,priority:1" json:"regime"`
+	Annex      string         `gorm:"size:8;not null;default:'';uniqueIndex:idx_tax_table_period,priority:2" json:"annex"`
+	ValidFrom  time.Time      `gorm:"column:valid_from;type:date;not null;uniqueIndex:idx_tax_table_period,priority:3" json:"validFrom"`
+	Brackets   datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"brackets"`
+	FixedCents int64          `gorm:"column:fixed_cents;not null;default:0" json:"fixedCents"`
+	Reduction  TaxReduction   `gorm:"embedded" json:"reduction"`
+	Notes      string         `gorm:"type:text" json:"notes"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	UpdatedAt  time.Time      `json:"updatedAt"`
+}
+
+// TaxBracket is one row of TaxTable.Brackets: amounts up to UpToCents (0 for no limit) are taxed at
+// Rate percent minus DeductionCents, the "parcela a deduzir".
+type TaxBracket struct {
+	UpToCents      int64   `json:"upToCents"`
+	Rate           float64 `json:"rate"`
+	DeductionCents int64   `json:"deductionCents"`
+}
+
+// TaxReduction is the monthly carnê-leão reduction of Lei 15.270/2025, applied to the tax of the
+// bracket from the month's taxable income: up to ExemptUpToCents no tax is due, and up to
+// PhaseOutUpToCents the tax drops by BaseCents minus Rate percent of the income. Zero means none.
+type TaxReduction struct {
+	ExemptUpToCents   int64   `gorm:"column:reduction_exempt_up_to_cents;not null;default:0" json:"exemptUpToCents"`
+	PhaseOutUpToCents int64   `gorm:"column:reduction_phase_out_up_to_cents;not null;default:0" json:"phaseOutUpToCents"`
+	BaseCents         int64   `gorm:"column:reduction_base_cents;not null;default:0" json:"baseCents"`
+	Rate              float64 `gorm:"column:reduction_rate;not null;default:0" json:"rate"`
+}
+
+// NetWorthItem is an asset or liability tracked for net worth. Kind is asset or liability; Category
+// is bank, investment, equipment, property, loan, card or other. Source tells where its snapshots
+// come from: manual items are valued by hand, account items take the balance of AccountID and the
+// invoices item the amount still owed on open card invoices, both recorded by the monthly job.
+type NetWorthItem struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Name      string     `gorm:"size:255;not null" json:"name"`
+	Kind      string     `gorm:"size:16;not null;index" json:"kind"`
+	Category  string     `gorm:"size:32;not null;default:other" json:"category"`
+	Source    string     `gorm:"size:16;not null;default:manual" json:"source"`
+	AccountID *uuid.UUID `gorm:"column:account_id;type:uuid;uniqueIndex" json:"accountId"`
+	Currency  string     `gorm:"size:8;not null;default:BRL" json:"currency"`
+	Archived  bool       `gorm:"not null;default:false" json:"archived"`
+	Notes     string     `gorm:"type:text" json:"notes"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// NetWorthSnapshot is the value of an item on Date in the item's currency; liabilities record the
+// amount owed. A value holds until the item's next snapshot. Auto marks rows written by the job.
+type NetWorthSnapshot struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	ItemID     uuid.UUID `gorm:"column:item_id;type:uuid;not null;uniqueIndex:idx_net_worth_snapshot_item_date,priority:1" json:"itemId"`
+	Date       time.Time `gorm:"type:date;not null;uniqueIndex:idx_net_worth_snapshot_item_date,priority:2;index" json:"date"`
+	ValueCents int64     `gorm:"column:value_cents;not null" json:"valueCents"`
+	Auto       bool      `gorm:"not null;default:false" json:"auto"`
+	Notes      string    `gorm:"type:text" json:"notes"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// SavingsGoal is money put aside towards TargetCents, optionally by Deadline. Its progress is the sum
+// of the transactions tagged with it through Transaction.GoalID; only transfers out of AccountID count
+// as withdrawals. New transactions that name no goal are tagged automatically when they are transfers
+// into AccountID or use Category.
+type SavingsGoal struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Name        string     `gorm:"size:255;not null" json:"name"`
+	TargetCents int64      `gorm:"column:target_cents;not null" json:"targetCents"`
+	Currency    string     `gorm:"size:8;not null;default:BRL" json:"currency"`
+	StartDate   time.Time  `gorm:"column:start_date;type:date;not null" json:"startDate"`
+	Deadline    *time.Time `gorm:"type:date" json:"deadline"`
+	AccountID   *uuid.UUID `gorm:"column:account_id;type:uuid;index" json:"accountId"`
+	Category    string     `gorm:"size:64" json:"category"`
+	Archived    bool       `gorm:"not null;default:false" json:"archived"`
+	Notes       string     `gorm:"type:text" json:"notes"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/google/uuid"
 )
 
+// MediaAsset is an uploaded file. Private assets, such as finance attachments, are only served on
+// the admin media routes.
 type MediaAsset struct {
 	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
 	Filename   string    `gorm:"size:255;not null" json:"filename"`
@@ -16,5 +18,6 @@ type MediaAsset struct {
 	AltText    string    `gorm:"column:alt_text;size:300" json:"altText"`
 	Width      int       `json:"width"`
 	Height     int       `json:"height"`
+	Private    bool      `gorm:"not null;default:false;index" json:"private"`
 	CreatedAt  time.Time `json:"createdAt"`
 }